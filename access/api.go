@@ -6,8 +6,10 @@ import (
 	"github.com/onflow/flow/protobuf/go/flow/access"
 	"github.com/onflow/flow/protobuf/go/flow/entities"
 
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
 )
 
 // API provides all public-facing functionality of the Flow Access API.
@@ -41,6 +43,18 @@ type API interface {
 	GetEventsForBlockIDs(ctx context.Context, eventType string, blockIDs []flow.Identifier) ([]flow.BlockEvents, error)
 
 	GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error)
+	GetProtocolStateSnapshotByHeight(ctx context.Context, height uint64) ([]byte, error)
+	GetProtocolStateSnapshotByEpoch(ctx context.Context, counter uint64) ([]byte, error)
+
+	GetEpochs(ctx context.Context) ([]*protocol.EpochInfo, error)
+	GetEpochAtHeight(ctx context.Context, height uint64) (*protocol.EpochInfo, error)
+	GetNodeIdentityAtHeight(ctx context.Context, nodeID flow.Identifier, height uint64) (*flow.Identity, error)
+
+	GetFinalityProof(ctx context.Context, blockID flow.Identifier) (*finality.Proof, error)
+	GetEpochProof(ctx context.Context, counter uint64) (*finality.EpochProof, error)
+
+	GetTransactionLocation(ctx context.Context, id flow.Identifier) (*flow.TransactionLocation, error)
+	GetAccountTransactions(ctx context.Context, address flow.Address, limit uint, cursor string) (*AccountTransactions, error)
 }
 
 // TODO: Combine this with flow.TransactionResult?
@@ -77,3 +91,11 @@ func MessageToTransactionResult(message *access.TransactionResultResponse) *Tran
 type NetworkParameters struct {
 	ChainID flow.ChainID
 }
+
+// AccountTransactions is a page of the transactions involving an account, as
+// payer, proposer or authorizer, from the newest to the oldest.
+type AccountTransactions struct {
+	Transactions []flow.TransactionLocation
+	// NextCursor requests the next page, it is empty on the last page.
+	NextCursor string
+}
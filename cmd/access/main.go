@@ -69,6 +69,7 @@ func main() {
 		collectionsToMarkFinalized   *stdmap.Times
 		collectionsToMarkExecuted    *stdmap.Times
 		blocksToMarkExecuted         *stdmap.Times
+		transactionLocations         *storage.TransactionLocations
 		transactionMetrics           module.TransactionMetrics
 		pingMetrics                  module.PingMetrics
 		logTxTimeToFinalized         bool
@@ -219,6 +220,10 @@ func main() {
 			pingMetrics = metrics.NewPingCollector()
 			return nil
 		}).
+		Module("transaction locations", func(node *cmd.FlowNodeBuilder) error {
+			transactionLocations = storage.NewTransactionLocations(node.DB)
+			return nil
+		}).
 		Component("RPC engine", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 			rpcEng = rpc.New(
 				node.Logger,
@@ -232,6 +237,8 @@ func main() {
 				node.Storage.Collections,
 				node.Storage.Transactions,
 				node.Storage.Receipts,
+				node.Storage.Results,
+				transactionLocations,
 				node.RootChainID,
 				transactionMetrics,
 				collectionGRPCPort,
@@ -257,7 +264,7 @@ func main() {
 			if err != nil {
 				return nil, fmt.Errorf("could not create requester engine: %w", err)
 			}
-			ingestEng, err = ingestion.New(node.Logger, node.Network, node.State, node.Me, requestEng, node.Storage.Blocks, node.Storage.Headers, node.Storage.Collections, node.Storage.Transactions, node.Storage.Receipts, transactionMetrics,
+			ingestEng, err = ingestion.New(node.Logger, node.Network, node.State, node.Me, requestEng, node.Storage.Blocks, node.Storage.Headers, node.Storage.Collections, node.Storage.Transactions, node.Storage.Receipts, transactionLocations, transactionMetrics,
 				collectionsToMarkFinalized, collectionsToMarkExecuted, blocksToMarkExecuted, rpcEng)
 			requestEng.WithHandle(ingestEng.OnCollection)
 			return ingestEng, err
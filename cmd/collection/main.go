@@ -16,6 +16,7 @@ import (
 	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/collection/epochmgr"
 	"github.com/onflow/flow-go/engine/collection/epochmgr/factories"
+	"github.com/onflow/flow-go/engine/collection/health"
 	"github.com/onflow/flow-go/engine/collection/ingest"
 	"github.com/onflow/flow-go/engine/collection/pusher"
 	followereng "github.com/onflow/flow-go/engine/common/follower"
@@ -53,12 +54,15 @@ func main() {
 		builderExpiryBuffer                    uint
 		builderPayerRateLimit                  float64
 		builderUnlimitedPayers                 []string
+		builderPriorityPayers                  []string
 		hotstuffTimeout                        time.Duration
 		hotstuffMinTimeout                     time.Duration
 		hotstuffTimeoutIncreaseFactor          float64
 		hotstuffTimeoutDecreaseFactor          float64
 		hotstuffTimeoutVoteAggregationFraction float64
 		blockRateDelay                         time.Duration
+		healthAddr                             string
+		healthLivenessWindow                   time.Duration
 
 		followerState protocol.MutableState
 		ingestConf    ingest.Config
@@ -66,6 +70,7 @@ func main() {
 
 		pools          *epochpool.TransactionPools // epoch-scoped transaction pools
 		followerBuffer *buffer.PendingBlocks       // pending block cache for follower
+		clusterHealth  *health.Monitor             // health of the cluster consensus
 
 		push              *pusher.Engine
 		ing               *ingest.Engine
@@ -81,6 +86,10 @@ func main() {
 				"maximum number of transactions in the memory pool")
 			flags.StringVarP(&ingressConf.ListenAddr, "ingress-addr", "i", "localhost:9000",
 				"the address the ingress server listens on")
+			flags.StringVar(&healthAddr, "cluster-health-addr", "localhost:9001",
+				"the address the cluster health server listens on")
+			flags.DurationVar(&healthLivenessWindow, "cluster-health-liveness-window", health.DefaultLivenessWindow,
+				"the duration after which cluster members we received no consensus messages from are considered not live")
 			flags.Uint64Var(&ingestConf.MaxGasLimit, "ingest-max-gas-limit", flow.DefaultMaxTransactionGasLimit,
 				"maximum per-transaction computation limit (gas limit)")
 			flags.Uint64Var(&ingestConf.MaxTransactionByteSize, "ingest-max-tx-byte-size", flow.DefaultMaxTransactionByteSize,
@@ -101,6 +110,8 @@ func main() {
 				"rate limit for each payer (transactions/collection)")
 			flags.StringSliceVar(&builderUnlimitedPayers, "builder-unlimited-payers", []string{}, // no unlimited payers
 				"set of payer addresses which are omitted from rate limiting")
+			flags.StringSliceVar(&builderPriorityPayers, "builder-priority-payers", []string{}, // no priority payers
+				"priority list of payer addresses whose transactions are included first")
 			flags.UintVar(&maxCollectionSize, "builder-max-collection-size", flow.DefaultMaxCollectionSize,
 				"maximum number of transactions in proposed collections")
 			flags.Uint64Var(&maxCollectionByteSize, "builder-max-collection-byte-size", flow.DefaultMaxCollectionByteSize,
@@ -140,7 +151,11 @@ func main() {
 			return err
 		}).
 		Module("transactions mempool", func(node *cmd.FlowNodeBuilder) error {
-			create := func() mempool.Transactions { return stdmap.NewTransactions(txLimit) }
+			// order transactions by payer priority, and in the order they were
+			// received within a priority band; without priority payers, all
+			// transactions are in the same band
+			priority := builder.PayerPriority(toAddresses(builderPriorityPayers)...)
+			create := func() mempool.Transactions { return stdmap.NewPriorityTransactions(txLimit, priority) }
 			pools = epochpool.NewTransactionPools(create)
 			err := node.Metrics.Mempool.Register(metrics.ResourceTransaction, pools.CombinedSize)
 			return err
@@ -153,6 +168,10 @@ func main() {
 			colMetrics = metrics.NewCollectionCollector(node.Tracer)
 			return nil
 		}).
+		Module("cluster health monitor", func(node *cmd.FlowNodeBuilder) error {
+			clusterHealth = health.NewMonitor(colMetrics, healthLivenessWindow)
+			return nil
+		}).
 		Module("main chain sync core", func(node *cmd.FlowNodeBuilder) error {
 			mainChainSyncCore, err = synchronization.New(node.Logger, synchronization.DefaultConfig())
 			return err
@@ -264,6 +283,10 @@ func main() {
 			server := ingress.New(ingressConf, ing, node.RootChainID)
 			return server, nil
 		}).
+		Component("cluster health server", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
+			server := health.NewServer(node.Logger, healthAddr, clusterHealth)
+			return server, nil
+		}).
 		Component("provider engine", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 			retrieve := func(collID flow.Identifier) (flow.Entity, error) {
 				coll, err := node.Storage.Collections.ByID(collID)
@@ -297,13 +320,6 @@ func main() {
 				return nil, err
 			}
 
-			// convert hex string flag values to addresses
-			unlimitedPayers := make([]flow.Address, 0, len(builderUnlimitedPayers))
-			for _, payerStr := range builderUnlimitedPayers {
-				payerAddr := flow.HexToAddress(payerStr)
-				unlimitedPayers = append(unlimitedPayers, payerAddr)
-			}
-
 			builderFactory, err := factories.NewBuilderFactory(
 				node.DB,
 				node.Storage.Headers,
@@ -315,7 +331,7 @@ func main() {
 				builder.WithMaxCollectionTotalGas(maxCollectionTotalGas),
 				builder.WithExpiryBuffer(builderExpiryBuffer),
 				builder.WithMaxPayerTransactionRate(builderPayerRateLimit),
-				builder.WithUnlimitedPayers(unlimitedPayers...),
+				builder.WithUnlimitedPayers(toAddresses(builderUnlimitedPayers)...),
 			)
 			if err != nil {
 				return nil, err
@@ -380,6 +396,7 @@ func main() {
 				hotstuffFactory,
 				proposalFactory,
 				syncFactory,
+				clusterHealth,
 			)
 
 			heightEvents := gadgets.NewHeights()
@@ -405,3 +422,13 @@ func main() {
 		}).
 		Run()
 }
+
+// toAddresses converts hex string flag values to addresses.
+func toAddresses(payers []string) []flow.Address {
+	addresses := make([]flow.Address, 0, len(payers))
+	for _, payerStr := range payers {
+		payerAddr := flow.HexToAddress(payerStr)
+		addresses = append(addresses, payerAddr)
+	}
+	return addresses
+}
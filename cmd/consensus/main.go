@@ -62,6 +62,11 @@ func main() {
 		maxInterval                            time.Duration
 		maxSealPerBlock                        uint
 		maxGuaranteePerBlock                   uint
+		maxSealBytesPerBlock                   uint
+		maxGuaranteeBytesPerBlock              uint
+		maxReceiptBytesPerBlock                uint
+		oldestGuaranteesFirst                  bool
+		underrepresentedExecutorsFirst         bool
 		hotstuffTimeout                        time.Duration
 		hotstuffMinTimeout                     time.Duration
 		hotstuffTimeoutIncreaseFactor          float64
@@ -72,6 +77,7 @@ func main() {
 		requiredApprovalsForSealVerification   uint
 		requiredApprovalsForSealConstruction   uint
 		emergencySealing                       bool
+		stalledClusterThreshold                time.Duration
 
 		err               error
 		mutableState      protocol.MutableState
@@ -91,6 +97,7 @@ func main() {
 		receiptValidator  module.ReceiptValidator
 		approvalValidator module.ApprovalValidator
 		chunkAssigner     *chmodule.ChunkAssigner
+		stalls            *ingestion.StallDetector
 	)
 
 	cmd.FlowNode(flow.RoleConsensus.String()).
@@ -104,6 +111,11 @@ func main() {
 			flags.DurationVar(&maxInterval, "max-interval", 90*time.Second, "the maximum amount of time between two blocks")
 			flags.UintVar(&maxSealPerBlock, "max-seal-per-block", 100, "the maximum number of seals to be included in a block")
 			flags.UintVar(&maxGuaranteePerBlock, "max-guarantee-per-block", 100, "the maximum number of collection guarantees to be included in a block")
+			flags.UintVar(&maxSealBytesPerBlock, "max-seal-bytes-per-block", 2_000_000, "the maximum encoded byte size of the seals included in a block")
+			flags.UintVar(&maxGuaranteeBytesPerBlock, "max-guarantee-bytes-per-block", 500_000, "the maximum encoded byte size of the collection guarantees included in a block")
+			flags.UintVar(&maxReceiptBytesPerBlock, "max-receipt-bytes-per-block", 4_000_000, "the maximum encoded byte size of the execution receipts and results included in a block")
+			flags.BoolVar(&oldestGuaranteesFirst, "oldest-guarantees-first", false, "whether to include the collection guarantees with the oldest reference block first")
+			flags.BoolVar(&underrepresentedExecutorsFirst, "underrepresented-executors-first", false, "whether to include execution receipts from executors with the fewest pending receipts first")
 			flags.DurationVar(&hotstuffTimeout, "hotstuff-timeout", 60*time.Second, "the initial timeout for the hotstuff pacemaker")
 			flags.DurationVar(&hotstuffMinTimeout, "hotstuff-min-timeout", 2500*time.Millisecond, "the lower timeout bound for the hotstuff pacemaker")
 			flags.Float64Var(&hotstuffTimeoutIncreaseFactor, "hotstuff-timeout-increase-factor", timeout.DefaultConfig.TimeoutIncrease, "multiplicative increase of timeout value in case of time out event")
@@ -114,6 +126,7 @@ func main() {
 			flags.UintVar(&requiredApprovalsForSealVerification, "required-verification-seal-approvals", validation.DefaultRequiredApprovalsForSealValidation, "minimum number of approvals that are required to verify a seal")
 			flags.UintVar(&requiredApprovalsForSealConstruction, "required-construction-seal-approvals", sealing.DefaultRequiredApprovalsForSealConstruction, "minimum number of approvals that are required to construct a seal")
 			flags.BoolVar(&emergencySealing, "emergency-sealing-active", sealing.DefaultEmergencySealingActive, "(de)activation of emergency sealing")
+			flags.DurationVar(&stalledClusterThreshold, "stalled-cluster-threshold", ingestion.DefaultStallThreshold, "the duration without collection guarantees after which a collection cluster is considered stalled")
 		}).
 		Module("consensus node metrics", func(node *cmd.FlowNodeBuilder) error {
 			conMetrics = metrics.NewConsensusCollector(node.Tracer, node.MetricsRegisterer)
@@ -281,6 +294,10 @@ func main() {
 			)
 			return prov, err
 		}).
+		Component("stalled cluster detector", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
+			stalls = ingestion.NewStallDetector(node.Logger, conMetrics, node.State, stalledClusterThreshold)
+			return stalls, nil
+		}).
 		Component("ingestion engine", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 			ing, err := ingestion.New(
 				node.Logger,
@@ -294,7 +311,11 @@ func main() {
 				node.Me,
 				guarantees,
 			)
-			return ing, err
+			if err != nil {
+				return nil, err
+			}
+			ing = ing.WithStallDetector(stalls)
+			return ing, nil
 		}).
 		Component("consensus components", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 
@@ -328,9 +349,25 @@ func main() {
 			}
 
 			// initialize the block builder
+			builderOpts := []func(*builder.Config){
+				builder.WithMinInterval(minInterval),
+				builder.WithMaxInterval(maxInterval),
+				builder.WithMaxSealCount(maxSealPerBlock),
+				builder.WithMaxGuaranteeCount(maxGuaranteePerBlock),
+				builder.WithMaxSealByteSize(maxSealBytesPerBlock),
+				builder.WithMaxGuaranteeByteSize(maxGuaranteeBytesPerBlock),
+				builder.WithMaxReceiptByteSize(maxReceiptBytesPerBlock),
+			}
+			if oldestGuaranteesFirst {
+				builderOpts = append(builderOpts, builder.WithGuaranteeOrdering(builder.OldestGuaranteesFirst()))
+			}
+			if underrepresentedExecutorsFirst {
+				builderOpts = append(builderOpts, builder.WithReceiptOrdering(builder.UnderrepresentedExecutorsFirst()))
+			}
 			var build module.Builder
 			build = builder.NewBuilder(
 				node.Metrics.Mempool,
+				metrics.NewBuilderCollector(),
 				node.DB,
 				mutableState,
 				node.Storage.Headers,
@@ -342,10 +379,7 @@ func main() {
 				seals,
 				receipts,
 				node.Tracer,
-				builder.WithMinInterval(minInterval),
-				builder.WithMaxInterval(maxInterval),
-				builder.WithMaxSealCount(maxSealPerBlock),
-				builder.WithMaxGuaranteeCount(maxGuaranteePerBlock),
+				builderOpts...,
 			)
 			build = blockproducer.NewMetricsWrapper(build, mainMetrics) // wrapper for measuring time spent building block payload component
 
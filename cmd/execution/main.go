@@ -2,13 +2,16 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
 
 	"github.com/onflow/flow-go/cmd"
@@ -40,14 +43,18 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/backup"
 	"github.com/onflow/flow-go/module/buffer"
+	"github.com/onflow/flow-go/module/checkpointsync"
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/signature"
 	chainsync "github.com/onflow/flow-go/module/synchronization"
 	"github.com/onflow/flow-go/state/protocol"
 	badgerState "github.com/onflow/flow-go/state/protocol/badger"
+	storageapi "github.com/onflow/flow-go/storage"
 	storage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/pebble"
 )
 
 func main() {
@@ -90,6 +97,11 @@ func main() {
 		extensiveLog                bool
 		checkStakedAtBlock          func(blockID flow.Identifier) (bool, error)
 		diskWAL                     *wal.DiskWAL
+		checkpointSyncExecutionAddr string
+		checkpointServeAddr         string
+		chunkDataPackBackend        string
+		chunkDataPackDir            string
+		chunkDataPackStore          storageapi.KVStore
 	)
 
 	cmd.FlowNode(flow.RoleExecution.String()).
@@ -111,6 +123,10 @@ func main() {
 			flags.BoolVar(&syncFast, "sync-fast", false, "fast sync allows execution node to skip fetching collection during state syncing, and rely on state syncing to catch up")
 			flags.IntVar(&syncThreshold, "sync-threshold", 100, "the maximum number of sealed and unexecuted blocks before triggering state syncing")
 			flags.BoolVar(&extensiveLog, "extensive-logging", false, "extensive logging logs tx contents and block headers")
+			flags.StringVar(&checkpointSyncExecutionAddr, "checkpoint-sync-execution-addr", "", "HTTP address of an execution node to download the checkpoint of the sealed state of the root snapshot from if the database is empty, empty uses the checkpoint in the bootstrap directory")
+			flags.StringVar(&checkpointServeAddr, "checkpoint-serve-addr", "", "address to serve checkpoints of recent execution states to new execution nodes on, empty disables serving checkpoints")
+			flags.StringVar(&chunkDataPackBackend, "chunk-data-pack-backend", "badger", "key-value store to keep chunk data packs in, either badger (the node database) or pebble; chunk data packs in pebble can not be pruned")
+			flags.StringVar(&chunkDataPackDir, "chunk-data-pack-dir", filepath.Join(datadir, "chunk-data-packs"), "directory of the pebble database for chunk data packs")
 		}).
 		Module("mutable follower state", func(node *cmd.FlowNodeBuilder) error {
 			// For now, we only support state implementations from package badger.
@@ -142,6 +158,22 @@ func main() {
 			myReceipts = storage.NewMyExecutionReceipts(node.Metrics.Cache, node.DB, receipts)
 			return nil
 		}).
+		Module("chunk data pack storage", func(node *cmd.FlowNodeBuilder) error {
+			// the pruner only removes chunk data packs from the node database
+			if chunkDataPackBackend != chunkDataPackBackendBadger && node.BaseConfig.Pruning.Retention.ChunkDataPacks > 0 {
+				return fmt.Errorf("chunk data packs can only be pruned with the %s chunk data pack backend, not with %s", chunkDataPackBackendBadger, chunkDataPackBackend)
+			}
+			chunkDataPackStore, err = openChunkDataPackStore(chunkDataPackBackend, chunkDataPackDir, node)
+			if err != nil {
+				return err
+			}
+			// a separate chunk data pack database is backed up along with the
+			// node database, which holds the chunk data packs otherwise
+			if source, ok := chunkDataPackStore.(backup.Source); ok {
+				node.Backup.AddSource(backup.SourceChunkDataPacks, source)
+			}
+			return nil
+		}).
 		Module("pending block cache", func(node *cmd.FlowNodeBuilder) error {
 			pendingBlocks = buffer.NewPendingBlocks() // for following main chain consensus
 			return nil
@@ -156,9 +188,21 @@ func main() {
 			}
 			return nil
 		}).
+		Component("chunk data pack database", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
+			// the node closes its own database, and the first component is
+			// done last, once no other component writes chunk data packs
+			if chunkDataPackBackend == chunkDataPackBackendBadger {
+				return &module.NoopReadyDoneAware{}, nil
+			}
+			return &kvStoreCloser{log: node.Logger, store: chunkDataPackStore}, nil
+		}).
 		Component("Write-Ahead Log", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 			diskWAL, err = wal.NewDiskWAL(node.Logger.With().Str("subcomponent", "wal").Logger(), node.MetricsRegisterer, collector, triedir, int(mTrieCacheSize), pathfinder.PathByteSize, wal.SegmentSize)
-			return diskWAL, err
+			if err != nil {
+				return nil, err
+			}
+			node.Backup.AddSource(backup.SourceExecutionState, diskWAL)
+			return diskWAL, nil
 		}).
 		Component("execution state ledger", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 
@@ -172,6 +216,17 @@ func main() {
 
 			// if the execution database does not exist, then we need to bootstrap the execution database.
 			if !bootstrapped {
+				// when bootstrapping from a recent snapshot of the network, download the checkpoint of its
+				// sealed state from an execution node, which replaces the checkpoint of the spork
+				if checkpointSyncExecutionAddr != "" {
+					client := checkpointsync.NewClient(node.Logger, http.DefaultClient)
+					err = client.Checkpoint(context.Background(), checkpointSyncExecutionAddr, node.RootSeal.FinalState,
+						filepath.Join(node.BaseConfig.BootstrapDir, bootstrapFilenames.PathRootCheckpoint))
+					if err != nil {
+						return nil, fmt.Errorf("could not download checkpoint from %s: %w", checkpointSyncExecutionAddr, err)
+					}
+				}
+
 				// when bootstrapping, the bootstrap folder must have a checkpoint file
 				// we need to cover this file to the trie folder to restore the trie to restore the execution state.
 				err = copyBootstrapState(node.BaseConfig.BootstrapDir, triedir)
@@ -181,7 +236,14 @@ func main() {
 
 				// TODO: check that the checkpoint file contains the root block's statecommit hash
 
-				err = bootstrapper.BootstrapExecutionDatabase(node.DB, node.RootSeal.FinalState, node.RootBlock.Header)
+				// the checkpoint holds the state of the sealed block of the root snapshot, which is the
+				// root block itself, unless the node bootstraps from a recent snapshot of the network
+				sealed, err := node.State.AtBlockID(node.RootSeal.BlockID).Head()
+				if err != nil {
+					return nil, fmt.Errorf("could not get sealed root block: %w", err)
+				}
+
+				err = bootstrapper.BootstrapExecutionDatabase(node.DB, node.RootSeal.FinalState, sealed)
 				if err != nil {
 					return nil, fmt.Errorf("could not bootstrap execution database: %w", err)
 				}
@@ -208,6 +270,13 @@ func main() {
 
 			return compactor, nil
 		}).
+		Component("checkpoint server", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
+			if checkpointServeAddr == "" {
+				return &module.NoopReadyDoneAware{}, nil
+			}
+			cacheDir := path.Join(triedir, "checkpoint-cache")
+			return checkpointsync.NewServer(node.Logger, checkpointServeAddr, ledgerStorage, cacheDir), nil
+		}).
 		Component("provider engine", func(node *cmd.FlowNodeBuilder) (module.ReadyDoneAware, error) {
 			extraLogPath := path.Join(triedir, "extralogs")
 			err := os.MkdirAll(extraLogPath, 0777)
@@ -239,7 +308,7 @@ func main() {
 			}
 			computationManager = manager
 
-			chunkDataPacks := storage.NewChunkDataPacks(node.DB)
+			chunkDataPacks := storage.NewChunkDataPacks(chunkDataPackStore)
 			stateCommitments := storage.NewCommits(node.Metrics.Cache, node.DB)
 
 			// Needed for gRPC server, make sure to assign to main scoped vars
@@ -505,3 +574,51 @@ func copyBootstrapState(dir, trie string) error {
 
 	return out.Close()
 }
+
+const (
+	chunkDataPackBackendBadger = "badger"
+	chunkDataPackBackendPebble = "pebble"
+)
+
+// openChunkDataPackStore opens the key-value store of the given backend for
+// chunk data packs, which is either the database of the node, or a separate
+// pebble database in the given directory.
+func openChunkDataPackStore(backend string, dir string, node *cmd.FlowNodeBuilder) (storageapi.KVStore, error) {
+	switch backend {
+	case chunkDataPackBackendBadger:
+		return storage.NewKVStore(node.DB), nil
+	case chunkDataPackBackendPebble:
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return nil, fmt.Errorf("could not create chunk data pack directory: %w", err)
+		}
+		return pebble.Open(dir, nil)
+	default:
+		return nil, fmt.Errorf("unknown chunk data pack backend: %s", backend)
+	}
+}
+
+// kvStoreCloser closes a key-value store which is not the node database once
+// the node shuts down.
+type kvStoreCloser struct {
+	log   zerolog.Logger
+	store storageapi.KVStore
+}
+
+func (c *kvStoreCloser) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+func (c *kvStoreCloser) Done() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		err := c.store.Close()
+		if err != nil {
+			c.log.Error().Err(err).Msg("could not close key-value store")
+		}
+		close(done)
+	}()
+	return done
+}
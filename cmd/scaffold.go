@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -21,12 +23,18 @@ import (
 	"github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/admin"
+	"github.com/onflow/flow-go/module/backup"
+	"github.com/onflow/flow-go/module/checkpointsync"
 	"github.com/onflow/flow-go/module/local"
 	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/pruner"
 	"github.com/onflow/flow-go/module/trace"
 	"github.com/onflow/flow-go/network"
+	cborcodec "github.com/onflow/flow-go/network/codec/cbor"
 	jsoncodec "github.com/onflow/flow-go/network/codec/json"
 	"github.com/onflow/flow-go/network/p2p"
+	"github.com/onflow/flow-go/network/recorder"
 	"github.com/onflow/flow-go/network/topology"
 	"github.com/onflow/flow-go/state/protocol"
 	badgerState "github.com/onflow/flow-go/state/protocol/badger"
@@ -35,6 +43,7 @@ import (
 	"github.com/onflow/flow-go/state/protocol/inmem"
 	"github.com/onflow/flow-go/storage"
 	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/migration"
 	"github.com/onflow/flow-go/storage/badger/operation"
 	sutil "github.com/onflow/flow-go/storage/util"
 	"github.com/onflow/flow-go/utils/debug"
@@ -59,6 +68,26 @@ type BaseConfig struct {
 	profilerInterval time.Duration
 	profilerDuration time.Duration
 	tracerEnabled    bool
+	rateLimits       p2p.RateLimiterConfig
+	compression      p2p.CompressionConfig
+	pubSubCBOR       bool
+	networkRecordLog string
+	weightedTopology bool
+	adminAddr        string
+	Pruning          pruner.Config
+	checkpointSync   CheckpointSyncConfig
+	cacheBudget      uint64
+}
+
+// CheckpointSyncConfig configures bootstrapping a node with an empty database
+// from a recent snapshot of the network downloaded from an access node, rather
+// than from the root snapshot of the spork in the bootstrap directory. The
+// access node is not trusted, so the snapshot is verified against the root
+// snapshot of the spork with the epoch and finality proofs of the access node.
+type CheckpointSyncConfig struct {
+	AccessAddr string // HTTP address of the access node, empty disables checkpoint sync
+	Height     uint64 // height of the snapshot, zero for the latest sealed snapshot
+	Epoch      uint64 // epoch of the snapshot, zero for the latest sealed snapshot
 }
 
 type Metrics struct {
@@ -118,6 +147,7 @@ type FlowNodeBuilder struct {
 	MetricsRegisterer prometheus.Registerer
 	Metrics           Metrics
 	DB                *badger.DB
+	Backup            *backup.Backuper
 	Storage           Storage
 	ProtocolEvents    *events.Distributor
 	State             protocol.State
@@ -132,6 +162,7 @@ type FlowNodeBuilder struct {
 	postInitFns       []func(*FlowNodeBuilder)
 	stakingKey        crypto.PrivateKey
 	networkKey        crypto.PrivateKey
+	latencyMonitor    *topology.LatencyMonitor
 
 	// root state information
 	RootBlock   *flow.Block
@@ -161,18 +192,105 @@ func (fnb *FlowNodeBuilder) baseFlags() {
 	fnb.flags.BoolVar(&fnb.BaseConfig.tracerEnabled, "tracer-enabled", false,
 		"whether to enable tracer")
 
+	// inbound rate limits, in messages per second, a rate of zero disables the limit
+	rateLimits := p2p.DefaultRateLimiterConfig()
+	fnb.flags.Float64Var((*float64)(&fnb.BaseConfig.rateLimits.Unicast.PerSender.Rate), "unicast-rate-limit",
+		float64(rateLimits.Unicast.PerSender.Rate), "rate limit on inbound unicast messages per sender")
+	fnb.flags.IntVar(&fnb.BaseConfig.rateLimits.Unicast.PerSender.Burst, "unicast-burst-limit",
+		rateLimits.Unicast.PerSender.Burst, "burst limit on inbound unicast messages per sender")
+	fnb.flags.Float64Var((*float64)(&fnb.BaseConfig.rateLimits.Unicast.PerChannel.Rate), "unicast-channel-rate-limit",
+		float64(rateLimits.Unicast.PerChannel.Rate), "rate limit on inbound unicast messages per channel")
+	fnb.flags.IntVar(&fnb.BaseConfig.rateLimits.Unicast.PerChannel.Burst, "unicast-channel-burst-limit",
+		rateLimits.Unicast.PerChannel.Burst, "burst limit on inbound unicast messages per channel")
+	fnb.flags.Float64Var((*float64)(&fnb.BaseConfig.rateLimits.PubSub.PerSender.Rate), "pubsub-rate-limit",
+		float64(rateLimits.PubSub.PerSender.Rate), "rate limit on inbound pubsub messages per sender")
+	fnb.flags.IntVar(&fnb.BaseConfig.rateLimits.PubSub.PerSender.Burst, "pubsub-burst-limit",
+		rateLimits.PubSub.PerSender.Burst, "burst limit on inbound pubsub messages per sender")
+	fnb.flags.Float64Var((*float64)(&fnb.BaseConfig.rateLimits.PubSub.PerChannel.Rate), "pubsub-channel-rate-limit",
+		float64(rateLimits.PubSub.PerChannel.Rate), "rate limit on inbound pubsub messages per channel")
+	fnb.flags.IntVar(&fnb.BaseConfig.rateLimits.PubSub.PerChannel.Burst, "pubsub-channel-burst-limit",
+		rateLimits.PubSub.PerChannel.Burst, "burst limit on inbound pubsub messages per channel")
+	fnb.flags.DurationVar(&fnb.BaseConfig.rateLimits.BlockDuration, "rate-limit-block-duration",
+		rateLimits.BlockDuration, "how long to drop all messages of a sender exceeding its rate limit, zero disables blocking")
+
+	// compression of large message payloads, negotiated on unicast streams
+	compression := p2p.DefaultCompressionConfig()
+	fnb.flags.StringVar((*string)(&fnb.BaseConfig.compression.Algorithm), "compression", string(compression.Algorithm),
+		"algorithm to compress large message payloads with (snappy), empty disables compression")
+	fnb.flags.IntVar(&fnb.BaseConfig.compression.Threshold, "compression-threshold", compression.Threshold,
+		"size in bytes from which message payloads are compressed")
+	fnb.flags.BoolVar(&fnb.BaseConfig.compression.PubSub, "pubsub-compression", compression.PubSub,
+		"whether to compress pubsub messages, which requires all nodes to support compression")
+	fnb.flags.BoolVar(&fnb.BaseConfig.pubSubCBOR, "pubsub-cbor", false,
+		"whether to publish pubsub messages encoded with CBOR, which requires all nodes to support CBOR")
+
+	fnb.flags.StringVar(&fnb.BaseConfig.networkRecordLog, "network-record-log", "",
+		"file to record all network messages to for offline replay, recording is disabled if empty")
+	fnb.flags.BoolVar(&fnb.BaseConfig.weightedTopology, "weighted-topology", false,
+		"whether to prefer peers with low round trip time and high stake in the topology")
+	fnb.flags.StringVar(&fnb.BaseConfig.adminAddr, "admin-addr", "",
+		"address of the unauthenticated admin server serving the /network diagnostics and /backup endpoints, the server is disabled if empty")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.cacheBudget, "cache-budget", 0,
+		"memory budget in MiB within which the storage caches are resized by their hit rates, zero keeps their fixed sizes")
+
+	// pruning of historical data, the number of sealed heights to keep of each
+	// type of data, where zero keeps the data forever
+	pruning := pruner.DefaultConfig()
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.Blocks, "prune-blocks", 0,
+		"number of sealed heights to keep blocks for, must cover the retention of all other data")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.Collections, "prune-collections", 0,
+		"number of sealed heights to keep collections and transactions for, must cover the retention of transaction locations")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.TransactionLocations, "prune-transaction-locations", 0,
+		"number of sealed heights to keep the transaction location and account transaction indexes for")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.Events, "prune-events", 0,
+		"number of sealed heights to keep events for")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.TransactionResults, "prune-transaction-results", 0,
+		"number of sealed heights to keep transaction results for")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.ChunkDataPacks, "prune-chunk-data-packs", 0,
+		"number of sealed heights to keep chunk data packs for")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.Retention.StateInteractions, "prune-state-interactions", 0,
+		"number of sealed heights to keep execution state interactions for")
+	fnb.flags.DurationVar(&fnb.BaseConfig.Pruning.Interval, "prune-interval", pruning.Interval,
+		"interval between pruning rounds")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.Pruning.BatchSize, "prune-batch", pruning.BatchSize,
+		"maximum number of heights pruned per type of data in a pruning round")
+	fnb.flags.BoolVar(&fnb.BaseConfig.Pruning.DryRun, "prune-dry-run", false,
+		"whether to only log the heights which would be pruned, without deleting any data")
+
+	// bootstrapping from a recent snapshot of the network
+	fnb.flags.StringVar(&fnb.BaseConfig.checkpointSync.AccessAddr, "checkpoint-sync-access-addr", "",
+		"HTTP address of an access node to download the root protocol snapshot from if the database is empty, "+
+			"empty bootstraps from the snapshot in the bootstrap directory")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.checkpointSync.Height, "checkpoint-sync-height", 0,
+		"finalized height of the downloaded root protocol snapshot, zero for the latest sealed snapshot")
+	fnb.flags.Uint64Var(&fnb.BaseConfig.checkpointSync.Epoch, "checkpoint-sync-epoch", 0,
+		"epoch counter to download the first root protocol snapshot of, zero for the latest sealed snapshot")
+
 }
 
 func (fnb *FlowNodeBuilder) enqueueNetworkInit() {
 	fnb.Component("network", func(builder *FlowNodeBuilder) (module.ReadyDoneAware, error) {
 
-		codec := jsoncodec.NewCodec()
+		// the overlay encodes payloads with CBOR, while JSON remains supported
+		// for nodes which predate codec negotiation; pubsub messages are
+		// published with JSON until all nodes are known to support CBOR
+		codec := cborcodec.NewCodec()
+		pubSubVersion := p2p.CodecVersionJSON
+		if fnb.BaseConfig.pubSubCBOR {
+			pubSubVersion = p2p.CodecVersionCBOR
+		}
 
 		myAddr := fnb.Me.Address()
 		if fnb.BaseConfig.bindAddr != notSet {
 			myAddr = fnb.BaseConfig.bindAddr
 		}
 
+		switch fnb.BaseConfig.compression.Algorithm {
+		case p2p.CompressionNone, p2p.CompressionSnappy:
+		default:
+			return nil, fmt.Errorf("unknown compression algorithm: %q", fnb.BaseConfig.compression.Algorithm)
+		}
+
 		libP2PNodeFactory, err := p2p.DefaultLibP2PNodeFactory(fnb.Logger.Level(zerolog.ErrorLevel),
 			fnb.Me.NodeID(),
 			myAddr,
@@ -189,7 +307,24 @@ func (fnb *FlowNodeBuilder) enqueueNetworkInit() {
 			fnb.Me.NodeID(),
 			fnb.Metrics.Network,
 			fnb.RootBlock.ID().String(),
-			fnb.MsgValidators...)
+			fnb.MsgValidators...).
+			WithCodecs(
+				p2p.VersionedCodec{Version: p2p.CodecVersionCBOR, Codec: codec},
+				p2p.VersionedCodec{Version: p2p.CodecVersionJSON, Codec: jsoncodec.NewCodec()},
+			).
+			WithPubSubCodec(pubSubVersion).
+			WithRateLimits(fnb.BaseConfig.rateLimits).
+			WithCompression(fnb.BaseConfig.compression)
+
+		// optionally records all messages, so that they can be replayed offline
+		var mw network.Middleware = fnb.Middleware
+		if fnb.BaseConfig.networkRecordLog != "" {
+			writer, err := recorder.OpenFile(fnb.BaseConfig.networkRecordLog)
+			if err != nil {
+				return nil, fmt.Errorf("could not open network record log: %w", err)
+			}
+			mw = recorder.NewMiddleware(fnb.Logger, fnb.Middleware, writer)
+		}
 
 		participants, err := fnb.State.Final().Identities(p2p.NetworkingSetFilter)
 		if err != nil {
@@ -200,19 +335,33 @@ func (fnb *FlowNodeBuilder) enqueueNetworkInit() {
 		//
 		// topology
 		// subscription manager
-		subscriptionManager := p2p.NewChannelSubscriptionManager(fnb.Middleware)
-		top, err := topology.NewTopicBasedTopology(fnb.NodeID, fnb.Logger, fnb.State)
-		if err != nil {
-			return nil, fmt.Errorf("could not create topology: %w", err)
+		subscriptionManager := p2p.NewChannelSubscriptionManager(mw)
+		var topologyCache *topology.Cache
+		if fnb.BaseConfig.weightedTopology {
+			// the latency monitor measures the round trip times asked for by the weighted topology
+			fnb.latencyMonitor = topology.NewLatencyMonitor(fnb.Logger,
+				fnb.Middleware,
+				topology.DefaultLatencyProbeInterval,
+				topology.DefaultLatencyProbeBatch)
+			top, err := topology.NewWeightedTopology(fnb.NodeID, fnb.Logger, fnb.State, fnb.latencyMonitor)
+			if err != nil {
+				return nil, fmt.Errorf("could not create topology: %w", err)
+			}
+			topologyCache = topology.NewCache(fnb.Logger, top).WithRefreshInterval(topology.DefaultTopologyRefreshInterval)
+		} else {
+			top, err := topology.NewTopicBasedTopology(fnb.NodeID, fnb.Logger, fnb.State)
+			if err != nil {
+				return nil, fmt.Errorf("could not create topology: %w", err)
+			}
+			topologyCache = topology.NewCache(fnb.Logger, top)
 		}
-		topologyCache := topology.NewCache(fnb.Logger, top)
 
 		// creates network instance
 		net, err := p2p.NewNetwork(fnb.Logger,
 			codec,
 			participants,
 			fnb.Me,
-			fnb.Middleware,
+			mw,
 			10e6,
 			topologyCache,
 			subscriptionManager,
@@ -229,6 +378,14 @@ func (fnb *FlowNodeBuilder) enqueueNetworkInit() {
 
 		return net, err
 	})
+
+	fnb.Component("latency monitor", func(builder *FlowNodeBuilder) (module.ReadyDoneAware, error) {
+		// round trip times are only measured for the weighted topology
+		if fnb.latencyMonitor == nil {
+			return &module.NoopReadyDoneAware{}, nil
+		}
+		return fnb.latencyMonitor, nil
+	})
 }
 
 func (fnb *FlowNodeBuilder) enqueueMetricsServerInit() {
@@ -238,6 +395,25 @@ func (fnb *FlowNodeBuilder) enqueueMetricsServerInit() {
 	})
 }
 
+func (fnb *FlowNodeBuilder) enqueueAdminServerInit() {
+	fnb.Component("admin server", func(builder *FlowNodeBuilder) (module.ReadyDoneAware, error) {
+		// the admin endpoints are not authenticated, so operators have to opt in
+		if fnb.BaseConfig.adminAddr == "" {
+			return &module.NoopReadyDoneAware{}, nil
+		}
+		server := admin.NewServer(fnb.Logger, fnb.BaseConfig.adminAddr).
+			Handle("/network", p2p.NewDiagnosticsHandler(fnb.Logger, fnb.Network, fnb.Middleware)).
+			Handle("/backup", backup.NewHandler(fnb.Logger, fnb.Backup))
+		return server, nil
+	})
+}
+
+func (fnb *FlowNodeBuilder) enqueuePrunerInit() {
+	fnb.Component("pruner", func(builder *FlowNodeBuilder) (module.ReadyDoneAware, error) {
+		return pruner.New(fnb.Logger, fnb.DB, metrics.NewPrunerCollector(), fnb.BaseConfig.Pruning)
+	})
+}
+
 func (fnb *FlowNodeBuilder) registerBadgerMetrics() {
 	metrics.RegisterBadgerMetrics()
 }
@@ -260,6 +436,13 @@ func (fnb *FlowNodeBuilder) parseAndPrintFlags() {
 	})
 
 	log.Msg("flags loaded")
+
+	// downloaded snapshots are verified against the QCs of their epochs,
+	// which requires the BLS library
+	if fnb.BaseConfig.checkpointSync.AccessAddr != "" && !checkpointsync.VerificationSupported {
+		fnb.Logger.Fatal().Msg("--checkpoint-sync-access-addr is not supported by builds without the relic build tag, " +
+			"as the downloaded snapshot can not be verified")
+	}
 }
 
 func (fnb *FlowNodeBuilder) initNodeInfo() {
@@ -328,6 +511,17 @@ func (fnb *FlowNodeBuilder) initMetrics() {
 	fnb.Component("mempools metrics", func(builder *FlowNodeBuilder) (module.ReadyDoneAware, error) {
 		return mempools, nil
 	})
+
+	// the storage caches created with the cache metrics of the node are
+	// resized within the cache budget, if there is one
+	if fnb.BaseConfig.cacheBudget > 0 {
+		budget, err := bstorage.NewCacheBudget(fnb.Logger, fnb.Metrics.Cache, fnb.BaseConfig.cacheBudget<<20, bstorage.DefaultCacheBudgetInterval)
+		fnb.MustNot(err).Msg("could not create cache budget")
+		fnb.Metrics.Cache = budget
+		fnb.Component("cache budget", func(builder *FlowNodeBuilder) (module.ReadyDoneAware, error) {
+			return budget, nil
+		})
+	}
 }
 
 func (fnb *FlowNodeBuilder) initProfiler() {
@@ -373,7 +567,16 @@ func (fnb *FlowNodeBuilder) initDB() {
 
 	db, err := badger.Open(opts)
 	fnb.MustNot(err).Msg("could not open key-value store")
+
+	// bring databases of previous versions to the current schema before any
+	// component reads from them
+	err = migration.Migrate(fnb.Logger, db)
+	fnb.MustNot(err).Msg("could not migrate database")
+
 	fnb.DB = db
+
+	// other stores of the node are added to the backups by the node itself
+	fnb.Backup = backup.New(fnb.Logger, db)
 }
 
 func (fnb *FlowNodeBuilder) initStorage() {
@@ -420,10 +623,27 @@ func (fnb *FlowNodeBuilder) initStorage() {
 func (fnb *FlowNodeBuilder) initState() {
 	fnb.ProtocolEvents = events.NewDistributor()
 
+	isBootStrapped, err := badgerState.IsBootstrapped(fnb.DB)
+	fnb.MustNot(err).Msg("failed to determine whether database contains bootstrapped state")
+
 	// load the root protocol state snapshot from disk
-	rootSnapshot, err := loadRootProtocolSnapshot(fnb.BaseConfig.BootstrapDir)
+	rootSnapshot, err := loadRootProtocolSnapshot(fnb.BaseConfig.BootstrapDir, bootstrap.PathRootProtocolStateSnapshot)
 	fnb.MustNot(err).Msg("failed to read protocol snapshot from disk")
 
+	// a node with an empty database can bootstrap from a recent snapshot of
+	// the network, which is verified against the root snapshot of the spork
+	if !isBootStrapped && fnb.BaseConfig.checkpointSync.AccessAddr != "" {
+		err = downloadRootProtocolSnapshot(fnb.Logger, fnb.BaseConfig.BootstrapDir, fnb.BaseConfig.checkpointSync, rootSnapshot)
+		fnb.MustNot(err).Msg("failed to download root protocol snapshot")
+	}
+
+	// a node bootstrapped from a recent snapshot keeps using it as root
+	checkpointPath := filepath.Join(fnb.BaseConfig.BootstrapDir, bootstrap.PathCheckpointProtocolStateSnapshot)
+	if _, err := os.Stat(checkpointPath); err == nil {
+		rootSnapshot, err = loadRootProtocolSnapshot(fnb.BaseConfig.BootstrapDir, bootstrap.PathCheckpointProtocolStateSnapshot)
+		fnb.MustNot(err).Msg("failed to read checkpoint protocol snapshot from disk")
+	}
+
 	fnb.RootResult, fnb.RootSeal, err = rootSnapshot.SealedResult()
 	fnb.MustNot(err).Msg("failed to read root sealed result")
 	sealingSegment, err := rootSnapshot.SealingSegment()
@@ -438,8 +658,6 @@ func (fnb *FlowNodeBuilder) initState() {
 	// => https://github.com/dapperlabs/flow-go/issues/4167
 	fnb.RootChainID = fnb.RootBlock.Header.ChainID
 
-	isBootStrapped, err := badgerState.IsBootstrapped(fnb.DB)
-	fnb.MustNot(err).Msg("failed to determine whether database contains bootstrapped state")
 	if isBootStrapped {
 		state, err := badgerState.OpenState(
 			fnb.Metrics.Compliance,
@@ -668,6 +886,10 @@ func FlowNode(role string) *FlowNodeBuilder {
 
 	builder.enqueueMetricsServerInit()
 
+	builder.enqueueAdminServerInit()
+
+	builder.enqueuePrunerInit()
+
 	builder.registerBadgerMetrics()
 
 	builder.enqueueTracer()
@@ -751,9 +973,9 @@ func (fnb *FlowNodeBuilder) closeDatabase() {
 	}
 }
 
-// loadRootProtocolSnapshot loads the root protocol snapshot from disk
-func loadRootProtocolSnapshot(dir string) (*inmem.Snapshot, error) {
-	data, err := io.ReadFile(filepath.Join(dir, bootstrap.PathRootProtocolStateSnapshot))
+// loadRootProtocolSnapshot loads the root protocol snapshot with the given path from disk
+func loadRootProtocolSnapshot(dir string, path string) (*inmem.Snapshot, error) {
+	data, err := io.ReadFile(filepath.Join(dir, path))
 	if err != nil {
 		return nil, err
 	}
@@ -767,6 +989,33 @@ func loadRootProtocolSnapshot(dir string) (*inmem.Snapshot, error) {
 	return inmem.SnapshotFromEncodable(snapshot), nil
 }
 
+// downloadRootProtocolSnapshot downloads a snapshot of the network from an
+// access node, which is not trusted, and verifies it against the root snapshot
+// of the spork. The verified snapshot is written to the bootstrap directory
+// next to the root snapshot, so that the node keeps using it as root once it
+// has bootstrapped from it.
+func downloadRootProtocolSnapshot(log zerolog.Logger, dir string, config CheckpointSyncConfig, root protocol.Snapshot) error {
+	httpClient := &http.Client{Timeout: time.Minute}
+	client := checkpointsync.NewClient(log, httpClient)
+	data, snapshot, err := client.Snapshot(context.Background(), config.AccessAddr, config.Height, config.Epoch)
+	if err != nil {
+		return fmt.Errorf("could not download snapshot from %s: %w", config.AccessAddr, err)
+	}
+
+	source := checkpointsync.NewHTTPSource(config.AccessAddr, httpClient)
+	err = checkpointsync.VerifySnapshot(context.Background(), source, root, snapshot, checkpointsync.NewEpochVerifier)
+	if err != nil {
+		return fmt.Errorf("could not verify snapshot from %s: %w", config.AccessAddr, err)
+	}
+
+	err = io.WriteFile(filepath.Join(dir, bootstrap.PathCheckpointProtocolStateSnapshot), data)
+	if err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+
+	return nil
+}
+
 // Loads the private info for this node from disk (eg. private staking/network keys).
 func loadPrivateNodeInfo(dir string, myID flow.Identifier) (*bootstrap.NodeInfoPriv, error) {
 	data, err := io.ReadFile(filepath.Join(dir, fmt.Sprintf(bootstrap.PathNodeInfoPriv, myID)))
@@ -0,0 +1,129 @@
+package check_database
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/integrity"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage/pebble"
+)
+
+var (
+	flagDatadir          string
+	flagTriedir          string
+	flagChunkDataPackDir string
+	flagRepair           bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "check-database",
+	Short: "Checks the internal consistency of the protocol state database of a stopped node",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().StringVar(&flagTriedir, "triedir", "",
+		"directory that stores the execution state, to check the state of the latest executed block (execution nodes only)")
+
+	Cmd.Flags().StringVar(&flagChunkDataPackDir, "chunk-data-pack-dir", "",
+		"directory of the pebble database that stores the chunk data packs (execution nodes with the pebble chunk data pack backend only)")
+
+	Cmd.Flags().BoolVar(&flagRepair, "repair", false,
+		"repair the broken indexes which can be rebuilt from the finalized chain")
+}
+
+func run(*cobra.Command, []string) {
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	config := integrity.Config{
+		Repair: flagRepair,
+	}
+	if flagTriedir != "" {
+		forest := loadExecutionState(flagTriedir)
+		config.HasState = func(commit flow.StateCommitment) bool {
+			_, err := forest.GetTrie(ledger.RootHash(commit))
+			return err == nil
+		}
+	}
+	if flagChunkDataPackDir != "" {
+		// opening a pebble database creates it if it does not exist
+		_, err := os.Stat(flagChunkDataPackDir)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not find chunk data pack database")
+		}
+		chunkDataPacks, err := pebble.Open(flagChunkDataPackDir, nil)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not open chunk data pack database")
+		}
+		defer chunkDataPacks.Close()
+		config.ChunkDataPacks = chunkDataPacks
+	}
+
+	report, err := integrity.Run(log.Logger, db, config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not check database")
+	}
+
+	unrepaired := report.Unrepaired()
+	if unrepaired > 0 {
+		log.Fatal().
+			Int("issues", len(report.Issues)).
+			Int("unrepaired", unrepaired).
+			Msg("database is inconsistent")
+	}
+
+	log.Info().
+		Uint64("blocks", report.Blocks).
+		Int("repaired", len(report.Issues)).
+		Msg("database is consistent")
+}
+
+// loadExecutionState replays the execution state in the given directory.
+func loadExecutionState(dir string) *mtrie.Forest {
+
+	w, err := wal.NewDiskWAL(
+		zerolog.Nop(),
+		nil,
+		metrics.NewNoopCollector(),
+		dir,
+		complete.DefaultCacheSize,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create WAL")
+	}
+	defer func() {
+		<-w.Done()
+	}()
+
+	forest, err := mtrie.NewForest(pathfinder.PathByteSize, complete.DefaultCacheSize, metrics.NewNoopCollector(), nil)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create forest")
+	}
+
+	err = w.ReplayOnForest(forest)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not replay execution state")
+	}
+
+	return forest
+}
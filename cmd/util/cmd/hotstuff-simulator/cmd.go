@@ -0,0 +1,197 @@
+package hotstuff_simulator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/pacemaker/timeout"
+	"github.com/onflow/flow-go/consensus/hotstuff/simulation"
+)
+
+var (
+	flagReplicas                       uint
+	flagStakes                         []uint
+	flagDelay                          time.Duration
+	flagJitter                         time.Duration
+	flagDropRate                       float64
+	flagPartitions                     []string
+	flagByzantine                      []string
+	flagDuration                       time.Duration
+	flagFinalView                      uint64
+	flagSeed                           int64
+	flagReplicaTimeout                 time.Duration
+	flagMinReplicaTimeout              time.Duration
+	flagVoteAggregationTimeoutFraction float64
+	flagTimeoutIncrease                float64
+	flagTimeoutDecrease                float64
+	flagBlockRateDelay                 time.Duration
+)
+
+var Cmd = &cobra.Command{
+	Use:   "hotstuff-simulator",
+	Short: "Simulates a HotStuff committee under adverse network conditions and byzantine behaviour",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().UintVar(&flagReplicas, "replicas", 4,
+		"number of replicas, each with a stake of 1000, unless stakes are given")
+	Cmd.Flags().UintSliceVar(&flagStakes, "stakes", nil,
+		"stake of each replica, overrides the number of replicas")
+	Cmd.Flags().DurationVar(&flagDelay, "delay", 10*time.Millisecond,
+		"minimum delay for delivering a message")
+	Cmd.Flags().DurationVar(&flagJitter, "jitter", 10*time.Millisecond,
+		"maximum random delay added to the delay of each message")
+	Cmd.Flags().Float64Var(&flagDropRate, "drop-rate", 0,
+		"probability of dropping a message")
+	Cmd.Flags().StringArrayVar(&flagPartitions, "partition", nil,
+		"network partition in the format <from>-<to>:<group>/<group>/..., where each group is a comma-separated list of replica indices, e.g. 5s-10s:0,1/2,3")
+	Cmd.Flags().StringArrayVar(&flagByzantine, "byzantine", nil,
+		fmt.Sprintf("byzantine replica in the format <index>:<behaviour>, with behaviour one of %s, %s, %s",
+			simulation.Equivocate, simulation.WithholdVotes, simulation.WithholdProposals))
+	Cmd.Flags().DurationVar(&flagDuration, "duration", 30*time.Second,
+		"maximum duration of the simulation")
+	Cmd.Flags().Uint64Var(&flagFinalView, "final-view", 0,
+		"stop once all honest replicas finalized this view (0 to run for the full duration)")
+	Cmd.Flags().Int64Var(&flagSeed, "seed", time.Now().UnixNano(),
+		"seed for the random delays and drops of the network")
+
+	Cmd.Flags().DurationVar(&flagReplicaTimeout, "replica-timeout", 2*time.Second,
+		"initial timeout of a view")
+	Cmd.Flags().DurationVar(&flagMinReplicaTimeout, "min-replica-timeout", 500*time.Millisecond,
+		"minimum timeout of a view")
+	Cmd.Flags().Float64Var(&flagVoteAggregationTimeoutFraction, "vote-aggregation-timeout-fraction", 0.5,
+		"fraction of the view timeout the leader waits for votes")
+	Cmd.Flags().Float64Var(&flagTimeoutIncrease, "timeout-increase", 1.5,
+		"multiplicative factor for increasing the timeout on timeout")
+	Cmd.Flags().Float64Var(&flagTimeoutDecrease, "timeout-decrease", 0.85,
+		"multiplicative factor for decreasing the timeout on progress")
+	Cmd.Flags().DurationVar(&flagBlockRateDelay, "block-rate-delay", 0,
+		"delay for broadcasting proposals")
+}
+
+func run(*cobra.Command, []string) {
+
+	timeouts, err := timeout.NewConfig(
+		flagReplicaTimeout,
+		flagMinReplicaTimeout,
+		flagVoteAggregationTimeoutFraction,
+		flagTimeoutIncrease,
+		flagTimeoutDecrease,
+		flagBlockRateDelay,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid timeout configuration")
+	}
+
+	stakes := make([]uint64, 0, flagReplicas)
+	for _, stake := range flagStakes {
+		stakes = append(stakes, uint64(stake))
+	}
+	if len(stakes) == 0 {
+		for i := uint(0); i < flagReplicas; i++ {
+			stakes = append(stakes, 1000)
+		}
+	}
+
+	options := []simulation.Option{
+		simulation.WithStakes(stakes...),
+		simulation.WithTimeouts(timeouts),
+		simulation.WithDelay(flagDelay, flagJitter),
+		simulation.WithDropRate(flagDropRate),
+		simulation.WithDuration(flagDuration),
+		simulation.WithFinalView(flagFinalView),
+	}
+	for _, value := range flagPartitions {
+		partition, err := parsePartition(value)
+		if err != nil {
+			log.Fatal().Err(err).Str("partition", value).Msg("invalid partition")
+		}
+		options = append(options, simulation.WithPartition(partition))
+	}
+	for _, value := range flagByzantine {
+		index, behaviour, err := parseByzantine(value)
+		if err != nil {
+			log.Fatal().Err(err).Str("byzantine", value).Msg("invalid byzantine replica")
+		}
+		options = append(options, simulation.WithByzantine(index, behaviour))
+	}
+
+	sim, err := simulation.New(log.Logger, flagSeed, options...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create simulation")
+	}
+
+	log.Info().Int("replicas", len(stakes)).Int64("seed", flagSeed).Msg("starting simulation")
+
+	report := sim.Run()
+	report.Print(os.Stdout)
+
+	if len(report.Violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// parsePartition parses a partition in the format <from>-<to>:<group>/<group>/...
+func parsePartition(value string) (simulation.Partition, error) {
+	var partition simulation.Partition
+
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return partition, fmt.Errorf("missing groups")
+	}
+	window := strings.SplitN(parts[0], "-", 2)
+	if len(window) != 2 {
+		return partition, fmt.Errorf("missing time window")
+	}
+	from, err := time.ParseDuration(window[0])
+	if err != nil {
+		return partition, fmt.Errorf("invalid start: %w", err)
+	}
+	to, err := time.ParseDuration(window[1])
+	if err != nil {
+		return partition, fmt.Errorf("invalid end: %w", err)
+	}
+	partition.From = from
+	partition.To = to
+
+	for _, group := range strings.Split(parts[1], "/") {
+		var indices []int
+		for _, index := range strings.Split(group, ",") {
+			i, err := strconv.Atoi(strings.TrimSpace(index))
+			if err != nil {
+				return partition, fmt.Errorf("invalid replica index: %w", err)
+			}
+			indices = append(indices, i)
+		}
+		partition.Groups = append(partition.Groups, indices)
+	}
+
+	return partition, nil
+}
+
+// parseByzantine parses a byzantine replica in the format <index>:<behaviour>
+func parseByzantine(value string) (int, simulation.Behaviour, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("missing behaviour")
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid replica index: %w", err)
+	}
+	behaviour := simulation.Behaviour(parts[1])
+	switch behaviour {
+	case simulation.Equivocate, simulation.WithholdVotes, simulation.WithholdProposals:
+	default:
+		return 0, "", fmt.Errorf("unknown behaviour %s", behaviour)
+	}
+	return index, behaviour, nil
+}
@@ -0,0 +1,115 @@
+package light_follower
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/engine/common/follower/light"
+	"github.com/onflow/flow-go/module/checkpointsync"
+	"github.com/onflow/flow-go/state/protocol/inmem"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+var (
+	flagDatadir      string
+	flagAccessAddr   string
+	flagRootSnapshot string
+	flagInterval     time.Duration
+)
+
+var Cmd = &cobra.Command{
+	Use:   "light-follower",
+	Short: "Follows the finalized headers of the chain served by an access node, verifying them from a trusted root snapshot",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory of the database of the light follower")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().StringVar(&flagAccessAddr, "access-address", "",
+		"HTTP address of the access node serving the headers")
+	_ = Cmd.MarkFlagRequired("access-address")
+
+	Cmd.Flags().StringVar(&flagRootSnapshot, "root-snapshot", "",
+		"trusted root protocol state snapshot, with which an empty database is bootstrapped")
+
+	Cmd.Flags().DurationVar(&flagInterval, "interval", light.DefaultConfig().Interval,
+		"interval between polls of the access node")
+}
+
+func run(*cobra.Command, []string) {
+
+	if !checkpointsync.VerificationSupported {
+		log.Fatal().Msg("the light follower is not supported by builds without the relic build tag, as QCs can not be verified")
+	}
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	err := db.View(operation.RetrieveRootHeight(new(uint64)))
+	if errors.Is(err, storage.ErrNotFound) {
+		bootstrap(db)
+	} else if err != nil {
+		log.Fatal().Err(err).Msg("could not check whether the database is bootstrapped")
+	}
+
+	config := light.DefaultConfig()
+	config.Interval = flagInterval
+	source := checkpointsync.NewHTTPSource(flagAccessAddr, &http.Client{Timeout: time.Minute})
+	follower, err := light.New(log.Logger, db, source, checkpointsync.NewEpochVerifier, config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create light follower")
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	<-follower.Ready()
+	log.Info().Str("access_address", flagAccessAddr).Msg("following finalized headers")
+	<-sig
+	<-follower.Done()
+
+	head, err := follower.Head()
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not get finalized head")
+	}
+	log.Info().Uint64("height", head.Height).Str("block_id", head.ID().String()).Msg("stopped at finalized head")
+}
+
+// bootstrap initializes the empty database with the trusted root snapshot.
+func bootstrap(db *badger.DB) {
+	if flagRootSnapshot == "" {
+		log.Fatal().Msg("database is empty, a root snapshot is required to bootstrap it")
+	}
+
+	data, err := ioutil.ReadFile(flagRootSnapshot)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not read root snapshot")
+	}
+	var encodable inmem.EncodableSnapshot
+	err = json.Unmarshal(data, &encodable)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not decode root snapshot")
+	}
+
+	err = light.Bootstrap(db, inmem.SnapshotFromEncodable(encodable))
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not bootstrap database")
+	}
+	log.Info().Str("root_snapshot", flagRootSnapshot).Msg("bootstrapped database")
+}
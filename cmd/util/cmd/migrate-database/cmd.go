@@ -0,0 +1,92 @@
+package migrate_database
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/migration"
+)
+
+var (
+	flagDatadir string
+	flagTarget  uint32
+)
+
+var Cmd = &cobra.Command{
+	Use:   "migrate-database",
+	Short: "Inspects and runs the schema migrations of the protocol state database",
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the schema version of the database and the pending migrations",
+	Run:   runStatus,
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Migrates the database up to the target schema version",
+	Run:   runMigrations,
+}
+
+func init() {
+
+	Cmd.PersistentFlags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state")
+	_ = Cmd.MarkPersistentFlagRequired("datadir")
+
+	runCmd.Flags().Uint32Var(&flagTarget, "target", migration.Latest(),
+		"schema version to migrate to, defaults to the latest version")
+
+	Cmd.AddCommand(statusCmd)
+	Cmd.AddCommand(runCmd)
+}
+
+func runStatus(*cobra.Command, []string) {
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	version, err := migration.Version(db)
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Info().Uint32("latest", migration.Latest()).Msg("database is empty")
+		return
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not get schema version")
+	}
+
+	log.Info().
+		Uint32("version", version).
+		Uint32("latest", migration.Latest()).
+		Msg("schema version")
+
+	for _, m := range migration.Migrations() {
+		log.Info().
+			Uint32("version", m.Version).
+			Bool("applied", m.Version <= version).
+			Msg(m.Description)
+	}
+}
+
+func runMigrations(*cobra.Command, []string) {
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	err := migration.MigrateTo(log.Logger, db, flagTarget)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not migrate database")
+	}
+
+	version, err := migration.Version(db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not get schema version")
+	}
+
+	log.Info().Uint32("version", version).Msg("migrated database")
+}
@@ -0,0 +1,117 @@
+package read_network_log
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/model/flow"
+	cborcodec "github.com/onflow/flow-go/network/codec/cbor"
+	"github.com/onflow/flow-go/network/recorder"
+)
+
+var (
+	flagLog       string
+	flagChannel   string
+	flagDirection string
+	flagPayload   bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "read-network-log",
+	Short: "Prints the messages of a network message log recorded by a node, one JSON object per line",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagLog, "log", "",
+		"network message log to read")
+	_ = Cmd.MarkFlagRequired("log")
+
+	Cmd.Flags().StringVar(&flagChannel, "channel", "",
+		"only print messages on the given channel")
+
+	Cmd.Flags().StringVar(&flagDirection, "direction", "",
+		"only print messages of the given direction (inbound or outbound)")
+
+	Cmd.Flags().BoolVar(&flagPayload, "payload", false,
+		"decode and print the payloads of the messages")
+}
+
+type loggedMessage struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Direction string            `json:"direction"`
+	Channel   string            `json:"channel"`
+	OriginID  flow.Identifier   `json:"origin_id"`
+	TargetIDs []flow.Identifier `json:"target_ids"`
+	EventID   string            `json:"event_id"`
+	Type      string            `json:"type"`
+	Size      int               `json:"size"`
+	Truncated bool              `json:"truncated,omitempty"`
+	Payload   interface{}       `json:"payload,omitempty"`
+}
+
+func run(*cobra.Command, []string) {
+
+	file, err := os.Open(flagLog)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not open network message log")
+	}
+	defer file.Close()
+
+	// nodes encode the payloads of their messages with CBOR
+	codec := cborcodec.NewCodec()
+	reader := recorder.NewReader(file)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not read network message log")
+		}
+
+		if flagChannel != "" && record.Channel().String() != flagChannel {
+			continue
+		}
+		if flagDirection != "" && record.Direction.String() != flagDirection {
+			continue
+		}
+
+		msg := loggedMessage{
+			Timestamp: record.Timestamp.UTC(),
+			Direction: record.Direction.String(),
+			Channel:   record.Channel().String(),
+			OriginID:  record.OriginID(),
+			TargetIDs: make([]flow.Identifier, 0, len(record.Message.TargetIDs)),
+			EventID:   flow.HashToID(record.Message.EventID).String(),
+			Type:      record.Message.Type,
+			Size:      record.Message.Size() + record.PayloadSize - len(record.Message.Payload),
+			Truncated: record.Truncated,
+		}
+		for _, targetID := range record.Message.TargetIDs {
+			msg.TargetIDs = append(msg.TargetIDs, flow.HashToID(targetID))
+		}
+
+		if flagPayload && !record.Truncated {
+			event, err := codec.Decode(record.Message.Payload)
+			if err != nil {
+				log.Error().Err(err).Str("event_id", msg.EventID).Msg("could not decode message payload")
+			} else {
+				msg.Payload = event
+			}
+		}
+
+		err = encoder.Encode(msg)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not print message")
+		}
+	}
+}
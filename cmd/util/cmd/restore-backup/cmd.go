@@ -0,0 +1,96 @@
+package restore_backup
+
+import (
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/module/backup"
+)
+
+var (
+	flagBackupDir        string
+	flagDatadir          string
+	flagTriedir          string
+	flagChunkDataPackDir string
+	flagVerifyOnly       bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "restore-backup",
+	Short: "Verifies a backup taken through the admin server of a node and restores it into empty directories",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagBackupDir, "backup-dir", "",
+		"directory of the backup")
+	_ = Cmd.MarkFlagRequired("backup-dir")
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"empty directory to restore the protocol state into")
+
+	Cmd.Flags().StringVar(&flagTriedir, "triedir", "",
+		"empty directory to restore the execution state into (execution nodes only)")
+
+	Cmd.Flags().StringVar(&flagChunkDataPackDir, "chunk-data-pack-dir", "",
+		"empty directory to restore the chunk data packs into (execution nodes with the pebble chunk data pack backend only)")
+
+	Cmd.Flags().BoolVar(&flagVerifyOnly, "verify-only", false,
+		"only verify the checksums of the backup")
+}
+
+func run(*cobra.Command, []string) {
+
+	if flagVerifyOnly {
+		manifest, err := backup.Verify(flagBackupDir)
+		if err != nil {
+			log.Fatal().Err(err).Msg("backup is invalid")
+		}
+		log.Info().
+			Str("chain_id", manifest.ChainID.String()).
+			Uint64("finalized_height", manifest.FinalizedHeight).
+			Hex("finalized_block_id", manifest.FinalizedBlockID[:]).
+			Int("files", len(manifest.Files)).
+			Msg("backup is valid")
+		return
+	}
+
+	if flagDatadir == "" {
+		log.Fatal().Msg("datadir is required to restore a backup")
+	}
+
+	targets := make(map[string]string)
+	if flagTriedir != "" {
+		targets[backup.SourceExecutionState] = flagTriedir
+	}
+	if flagChunkDataPackDir != "" {
+		targets[backup.SourceChunkDataPacks] = flagChunkDataPackDir
+	}
+
+	// use the options of the nodes, so that the largest values of execution
+	// nodes can be restored
+	opts := badger.
+		DefaultOptions(flagDatadir).
+		WithKeepL0InMemory(true).
+		WithLogger(nil).
+		WithValueLogFileSize(128 << 23).
+		WithValueLogMaxEntries(100000)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not open key-value store")
+	}
+	defer db.Close()
+
+	manifest, err := backup.Restore(log.Logger, flagBackupDir, db, targets)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not restore backup")
+	}
+
+	log.Info().
+		Uint64("finalized_height", manifest.FinalizedHeight).
+		Hex("finalized_block_id", manifest.FinalizedBlockID[:]).
+		Msg("backup restored")
+}
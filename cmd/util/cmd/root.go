@@ -9,12 +9,18 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	check_database "github.com/onflow/flow-go/cmd/util/cmd/check-database"
 	checkpoint_list_tries "github.com/onflow/flow-go/cmd/util/cmd/checkpoint-list-tries"
 	export "github.com/onflow/flow-go/cmd/util/cmd/exec-data-json-export"
 	extract "github.com/onflow/flow-go/cmd/util/cmd/execution-state-extract"
 	ledger_json_exporter "github.com/onflow/flow-go/cmd/util/cmd/export-json-execution-state"
+	hotstuff_simulator "github.com/onflow/flow-go/cmd/util/cmd/hotstuff-simulator"
+	light_follower "github.com/onflow/flow-go/cmd/util/cmd/light-follower"
+	migrate_database "github.com/onflow/flow-go/cmd/util/cmd/migrate-database"
 	read_badger "github.com/onflow/flow-go/cmd/util/cmd/read-badger/cmd"
+	read_network_log "github.com/onflow/flow-go/cmd/util/cmd/read-network-log"
 	read_protocol_state "github.com/onflow/flow-go/cmd/util/cmd/read-protocol-state/cmd"
+	restore_backup "github.com/onflow/flow-go/cmd/util/cmd/restore-backup"
 	truncate_database "github.com/onflow/flow-go/cmd/util/cmd/truncate-database"
 )
 
@@ -56,6 +62,12 @@ func addCommands() {
 	rootCmd.AddCommand(read_badger.RootCmd)
 	rootCmd.AddCommand(read_protocol_state.RootCmd)
 	rootCmd.AddCommand(ledger_json_exporter.Cmd)
+	rootCmd.AddCommand(hotstuff_simulator.Cmd)
+	rootCmd.AddCommand(read_network_log.Cmd)
+	rootCmd.AddCommand(migrate_database.Cmd)
+	rootCmd.AddCommand(restore_backup.Cmd)
+	rootCmd.AddCommand(check_database.Cmd)
+	rootCmd.AddCommand(light_follower.Cmd)
 }
 
 func initConfig() {
@@ -0,0 +1,85 @@
+package committees
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/consensus/hotstuff"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/filter"
+	"github.com/onflow/flow-go/state/protocol"
+)
+
+// Epoch represents the consensus committee of a single epoch, as defined by
+// the epoch's initial identities and DKG. It does not depend on a local
+// protocol state and can thus be used by parties which only know the epoch,
+// such as light clients verifying finality proofs. It does not support
+// leader selection.
+type Epoch struct {
+	participants flow.IdentityList
+	dkg          protocol.DKG
+	firstView    uint64
+	finalView    uint64
+}
+
+// NewEpochCommittee returns a new committee for the given epoch.
+func NewEpochCommittee(epoch protocol.Epoch) (*Epoch, error) {
+
+	identities, err := epoch.InitialIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("could not get initial identities: %w", err)
+	}
+	dkg, err := epoch.DKG()
+	if err != nil {
+		return nil, fmt.Errorf("could not get dkg: %w", err)
+	}
+	firstView, err := epoch.FirstView()
+	if err != nil {
+		return nil, fmt.Errorf("could not get first view: %w", err)
+	}
+	finalView, err := epoch.FinalView()
+	if err != nil {
+		return nil, fmt.Errorf("could not get final view: %w", err)
+	}
+
+	e := &Epoch{
+		participants: identities.Filter(filter.IsVotingConsensusCommitteeMember),
+		dkg:          dkg,
+		firstView:    firstView,
+		finalView:    finalView,
+	}
+	return e, nil
+}
+
+// FirstView returns the first view of the epoch.
+func (e *Epoch) FirstView() uint64 {
+	return e.firstView
+}
+
+// FinalView returns the final view of the epoch.
+func (e *Epoch) FinalView() uint64 {
+	return e.finalView
+}
+
+func (e *Epoch) Identities(_ flow.Identifier, selector flow.IdentityFilter) (flow.IdentityList, error) {
+	return e.participants.Filter(selector), nil
+}
+
+func (e *Epoch) Identity(_ flow.Identifier, participantID flow.Identifier) (*flow.Identity, error) {
+	identity, ok := e.participants.ByNodeID(participantID)
+	if !ok {
+		return nil, fmt.Errorf("unknown partipant")
+	}
+	return identity, nil
+}
+
+func (e *Epoch) LeaderForView(_ uint64) (flow.Identifier, error) {
+	return flow.ZeroID, fmt.Errorf("epoch committee does not support leader selection")
+}
+
+func (e *Epoch) Self() flow.Identifier {
+	return flow.ZeroID
+}
+
+func (e *Epoch) DKG(_ flow.Identifier) (hotstuff.DKG, error) {
+	return e.dkg, nil
+}
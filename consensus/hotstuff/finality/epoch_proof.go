@@ -0,0 +1,120 @@
+package finality
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ErrInvalidEpochProof is returned when an epoch proof does not prove the
+// transition into its epoch.
+var ErrInvalidEpochProof = errors.New("invalid epoch proof")
+
+// ErrUnknownTransition is returned when the transition into an epoch can not
+// be proven, because the epoch is not committed yet or the transition was not
+// observed by the prover.
+var ErrUnknownTransition = errors.New("unknown epoch transition")
+
+// EpochProof proves the service events of an epoch to a light client, which
+// only knows the finalized headers of the previous epoch. The protocol state
+// applies the service events emitted by an execution result once the result
+// is sealed, so the proof consists of the sealed results which emitted the
+// EpochSetup and EpochCommit events of the epoch, with the payloads and the
+// headers of the blocks that sealed them.
+type EpochProof struct {
+	Setup      *flow.EpochSetup
+	Commit     *flow.EpochCommit
+	SetupSeal  *SealProof
+	CommitSeal *SealProof
+}
+
+// SealProof proves that an execution result was sealed by a block.
+type SealProof struct {
+	Header  *flow.Header
+	Payload *flow.Payload
+	Result  *flow.ExecutionResult
+}
+
+// Verify checks that the proof proves the transition from the epoch with the
+// given setup into the next epoch. It does not check that the blocks sealing
+// the service events are finalized, which is up to the caller. It returns an
+// error wrapping ErrInvalidEpochProof if the proof is invalid.
+func (p *EpochProof) Verify(previous *flow.EpochSetup) error {
+
+	if p.Setup == nil || p.Commit == nil {
+		return fmt.Errorf("missing service events: %w", ErrInvalidEpochProof)
+	}
+	if p.Setup.Counter != previous.Counter+1 {
+		return fmt.Errorf("epoch setup has invalid counter (%d => %d): %w", previous.Counter, p.Setup.Counter, ErrInvalidEpochProof)
+	}
+	if p.Setup.FirstView != previous.FinalView+1 {
+		return fmt.Errorf("epoch setup has invalid first view (%d != %d+1): %w", p.Setup.FirstView, previous.FinalView, ErrInvalidEpochProof)
+	}
+
+	err := p.SetupSeal.verify(p.Setup.ID())
+	if err != nil {
+		return fmt.Errorf("invalid seal of epoch setup: %w", err)
+	}
+
+	return p.VerifyCommit(p.Setup)
+}
+
+// VerifyCommit checks that the proof proves the commit of the epoch with the
+// given setup. It is used on its own if the setup of the epoch is already
+// trusted.
+func (p *EpochProof) VerifyCommit(setup *flow.EpochSetup) error {
+
+	if p.Commit == nil {
+		return fmt.Errorf("missing epoch commit: %w", ErrInvalidEpochProof)
+	}
+	if p.Commit.Counter != setup.Counter {
+		return fmt.Errorf("epoch commit has invalid counter (%d != %d): %w", p.Commit.Counter, setup.Counter, ErrInvalidEpochProof)
+	}
+
+	err := p.CommitSeal.verify(p.Commit.ID())
+	if err != nil {
+		return fmt.Errorf("invalid seal of epoch commit: %w", err)
+	}
+
+	return nil
+}
+
+// verify checks that the result of the proof is sealed by the payload of its
+// block and emitted the service event with the given ID.
+func (p *SealProof) verify(eventID flow.Identifier) error {
+
+	if p == nil || p.Header == nil || p.Payload == nil || p.Result == nil {
+		return fmt.Errorf("incomplete seal proof: %w", ErrInvalidEpochProof)
+	}
+	if p.Payload.Hash() != p.Header.PayloadHash {
+		return fmt.Errorf("payload does not match header: %w", ErrInvalidEpochProof)
+	}
+
+	resultID := p.Result.ID()
+	sealed := false
+	for _, seal := range p.Payload.Seals {
+		if seal.ResultID == resultID {
+			sealed = true
+			break
+		}
+	}
+	if !sealed {
+		return fmt.Errorf("result (id=%x) is not sealed by block (id=%x): %w", resultID, p.Header.ID(), ErrInvalidEpochProof)
+	}
+
+	for _, event := range p.Result.ServiceEvents {
+		switch ev := event.Event.(type) {
+		case *flow.EpochSetup:
+			if ev.ID() == eventID {
+				return nil
+			}
+		case *flow.EpochCommit:
+			if ev.ID() == eventID {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("service event (id=%x) was not emitted by result (id=%x): %w", eventID, resultID, ErrInvalidEpochProof)
+}
@@ -0,0 +1,113 @@
+package finality
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+)
+
+// EpochProver generates epoch proofs from the epoch history and the finalized
+// blocks of the local protocol state, for light clients following the chain.
+type EpochProver struct {
+	state   protocol.State
+	blocks  storage.Blocks
+	results storage.ExecutionResults
+}
+
+// NewEpochProver creates a new prover for the epochs of the given protocol
+// state.
+func NewEpochProver(state protocol.State, blocks storage.Blocks, results storage.ExecutionResults) *EpochProver {
+	p := &EpochProver{
+		state:   state,
+		blocks:  blocks,
+		results: results,
+	}
+	return p
+}
+
+// Prove generates the proof for the transition into the epoch with the given
+// counter. It returns ErrUnknownTransition if the epoch is not committed yet,
+// or if the local node did not observe the phases in which it was set up and
+// committed, such as for epochs before its root block.
+func (p *EpochProver) Prove(counter uint64) (*EpochProof, error) {
+
+	if counter == 0 {
+		return nil, ErrUnknownTransition
+	}
+	epoch, err := p.state.History().Epoch(counter)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrUnknownTransition
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get epoch: %w", err)
+	}
+	if epoch.Commit == nil {
+		return nil, ErrUnknownTransition
+	}
+
+	// the heights at which the epoch was set up and committed are recorded
+	// with the previous epoch, in which the service events were sealed
+	previous, err := p.state.History().Epoch(counter - 1)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrUnknownTransition
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get previous epoch: %w", err)
+	}
+	if previous.SetupPhaseHeight == 0 || previous.CommittedPhaseHeight == 0 {
+		return nil, ErrUnknownTransition
+	}
+
+	// the service events are applied by the first block of each phase, once
+	// they were sealed by its parent
+	setupSeal, err := p.sealProof(previous.SetupPhaseHeight-1, epoch.Setup.ID())
+	if err != nil {
+		return nil, fmt.Errorf("could not prove epoch setup: %w", err)
+	}
+	commitSeal, err := p.sealProof(previous.CommittedPhaseHeight-1, epoch.Commit.ID())
+	if err != nil {
+		return nil, fmt.Errorf("could not prove epoch commit: %w", err)
+	}
+
+	proof := &EpochProof{
+		Setup:      epoch.Setup,
+		Commit:     epoch.Commit,
+		SetupSeal:  setupSeal,
+		CommitSeal: commitSeal,
+	}
+
+	return proof, nil
+}
+
+// sealProof generates the proof that the service event with the given ID was
+// sealed by the finalized block at the given height.
+func (p *EpochProver) sealProof(height uint64, eventID flow.Identifier) (*SealProof, error) {
+
+	block, err := p.blocks.ByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block at height %d: %w", height, err)
+	}
+
+	for _, seal := range block.Payload.Seals {
+		result, err := p.results.ByID(seal.ResultID)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get sealed result (id=%x): %w", seal.ResultID, err)
+		}
+		proof := &SealProof{
+			Header:  block.Header,
+			Payload: block.Payload,
+			Result:  result,
+		}
+		if proof.verify(eventID) == nil {
+			return proof, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find sealed result with service event (id=%x) at height %d", eventID, height)
+}
@@ -0,0 +1,110 @@
+package finality
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	mockprotocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	mockstorage "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// commitFixture creates an epoch commit with a DKG group key which can be
+// encoded without the BLS library.
+func commitFixture(counter uint64) *flow.EpochCommit {
+	return &flow.EpochCommit{
+		Counter:         counter,
+		DKGGroupKey:     unittest.KeyFixture(crypto.ECDSAP256).PublicKey(),
+		DKGParticipants: make(map[flow.Identifier]flow.DKGParticipant),
+	}
+}
+
+// epochProofFixture creates the proof for the given epoch, whose service
+// events are sealed by a single result, and returns the payload sealing it.
+func epochProofFixture(setup *flow.EpochSetup, commit *flow.EpochCommit) (*EpochProof, *flow.Payload) {
+	result := unittest.ExecutionResultFixture()
+	result.ServiceEvents = []flow.ServiceEvent{setup.ServiceEvent(), commit.ServiceEvent()}
+	payload := unittest.PayloadFixture(unittest.WithSeals(unittest.Seal.Fixture(unittest.Seal.WithResult(result))))
+	seal := &SealProof{
+		Payload: &payload,
+		Result:  result,
+	}
+	proof := &EpochProof{
+		Setup:      setup,
+		Commit:     commit,
+		SetupSeal:  seal,
+		CommitSeal: seal,
+	}
+	return proof, &payload
+}
+
+func TestEpochProver(t *testing.T) {
+
+	previous := unittest.EpochSetupFixture(unittest.SetupWithCounter(1), unittest.WithFirstView(0), unittest.WithFinalView(99))
+	setup := unittest.EpochSetupFixture(unittest.SetupWithCounter(2), unittest.WithFirstView(100), unittest.WithFinalView(199))
+	commit := commitFixture(2)
+
+	// the next epoch was set up at height 50 and committed at height 60, with
+	// the service events sealed by the blocks at heights 49 and 59
+	expected, payload := epochProofFixture(setup, commit)
+	result := expected.SetupSeal.Result
+	block := unittest.BlockFixture()
+	block.SetPayload(*payload)
+	empty := unittest.BlockFixture()
+
+	history := &mockprotocol.History{}
+	history.On("Epoch", uint64(1)).Return(&protocol.EpochInfo{
+		EpochRecord: flow.EpochRecord{Counter: 1, SetupPhaseHeight: 50, CommittedPhaseHeight: 60},
+		Setup:       previous,
+	}, nil)
+	history.On("Epoch", uint64(2)).Return(&protocol.EpochInfo{
+		EpochRecord: flow.EpochRecord{Counter: 2},
+		Setup:       setup,
+		Commit:      commit,
+	}, nil)
+	history.On("Epoch", uint64(3)).Return(nil, storage.ErrNotFound)
+	state := &mockprotocol.State{}
+	state.On("History").Return(history)
+
+	blocks := &mockstorage.Blocks{}
+	blocks.On("ByHeight", uint64(49)).Return(&block, nil)
+	blocks.On("ByHeight", uint64(59)).Return(&block, nil)
+	results := &mockstorage.ExecutionResults{}
+	results.On("ByID", result.ID()).Return(result, nil)
+
+	prover := NewEpochProver(state, blocks, results)
+
+	t.Run("committed epoch", func(t *testing.T) {
+		proof, err := prover.Prove(2)
+		require.NoError(t, err)
+		assert.Equal(t, block.Header, proof.SetupSeal.Header)
+		assert.Equal(t, block.Header, proof.CommitSeal.Header)
+		assert.Equal(t, result.ID(), proof.CommitSeal.Result.ID())
+		assert.NoError(t, proof.Verify(previous))
+	})
+
+	t.Run("unknown epoch", func(t *testing.T) {
+		_, err := prover.Prove(3)
+		assert.True(t, errors.Is(err, ErrUnknownTransition))
+	})
+
+	t.Run("uncommitted epoch", func(t *testing.T) {
+		_, err := prover.Prove(1)
+		assert.True(t, errors.Is(err, ErrUnknownTransition))
+	})
+
+	t.Run("missing seal", func(t *testing.T) {
+		blocks := &mockstorage.Blocks{}
+		blocks.On("ByHeight", uint64(49)).Return(&empty, nil)
+		_, err := NewEpochProver(state, blocks, results).Prove(2)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrUnknownTransition))
+	})
+}
@@ -0,0 +1,45 @@
+package finality
+
+import (
+	"errors"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ErrInvalidProof is returned when a finality proof does not prove the
+// finalization of its block.
+var ErrInvalidProof = errors.New("invalid finality proof")
+
+// Proof is a compact, self-contained proof that a block was finalized by the
+// main consensus committee. It consists of a chain of headers, each of which
+// is the parent of the next one, starting with the finalized block:
+//
+//   B <- ... <- b <- b' <- b'' <- b*
+//
+// where b' and b'' are in the views directly following the view of b. Each
+// header carries the quorum certificate for its parent, so b', b'' and b*
+// carry the QCs for b, b' and b'' respectively. This direct 3-chain finalizes
+// b and, as all blocks between B and b are ancestors of b, also B.
+type Proof struct {
+	Headers []*flow.Header
+}
+
+// Block returns the header of the block whose finalization is proven.
+func (p *Proof) Block() *flow.Header {
+	if len(p.Headers) == 0 {
+		return nil
+	}
+	return p.Headers[0]
+}
+
+// threeChain returns the index of the first header starting a direct 3-chain
+// that is followed by a header carrying the QC for the last block of the
+// 3-chain, or -1 if the headers contain no such 3-chain.
+func threeChain(headers []*flow.Header) int {
+	for i := 0; i+3 < len(headers); i++ {
+		if headers[i+1].View == headers[i].View+1 && headers[i+2].View == headers[i].View+2 {
+			return i
+		}
+	}
+	return -1
+}
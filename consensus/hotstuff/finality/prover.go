@@ -0,0 +1,115 @@
+package finality
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ErrNotFinalized is returned when a finality proof is requested for a block
+// which is not finalized.
+var ErrNotFinalized = errors.New("block is not finalized")
+
+// Prover generates finality proofs for finalized blocks from the local
+// protocol state.
+type Prover struct {
+	state   protocol.State
+	headers storage.Headers
+}
+
+// NewProver creates a new prover for blocks of the given protocol state.
+func NewProver(state protocol.State, headers storage.Headers) *Prover {
+	p := &Prover{
+		state:   state,
+		headers: headers,
+	}
+	return p
+}
+
+// Prove generates a finality proof for the finalized block with the given ID.
+// The proof consists of the shortest chain of headers, starting with the
+// block, that contains a direct 3-chain and the QC for its last block. As the
+// 3-chain which finalized the latest finalized blocks is usually not yet
+// finalized itself, the prover falls back to the pending blocks for the tail
+// of the chain.
+func (p *Prover) Prove(blockID flow.Identifier) (*Proof, error) {
+
+	header, err := p.headers.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block header: %w", err)
+	}
+
+	// check that the block is actually finalized
+	final, err := p.state.Final().Head()
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized header: %w", err)
+	}
+	if header.Height > final.Height {
+		return nil, ErrNotFinalized
+	}
+	finalized, err := p.headers.ByHeight(header.Height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized header at height %d: %w", header.Height, err)
+	}
+	if finalized.ID() != blockID {
+		return nil, ErrNotFinalized
+	}
+
+	// walk the finalized chain, until we find a 3-chain
+	headers := []*flow.Header{header}
+	for height := header.Height + 1; height <= final.Height; height++ {
+		if i := threeChain(headers); i >= 0 {
+			return &Proof{Headers: headers[:i+4]}, nil
+		}
+		next, err := p.headers.ByHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("could not get finalized header at height %d: %w", height, err)
+		}
+		headers = append(headers, next)
+	}
+	if i := threeChain(headers); i >= 0 {
+		return &Proof{Headers: headers[:i+4]}, nil
+	}
+
+	// the remaining 3-chain is part of the pending blocks; we search the
+	// forks descending from the latest finalized block for it
+	pending, err := p.state.Final().Pending()
+	if err != nil {
+		return nil, fmt.Errorf("could not get pending blocks: %w", err)
+	}
+	children := make(map[flow.Identifier][]*flow.Header)
+	for _, pendingID := range pending {
+		child, err := p.headers.ByBlockID(pendingID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get pending header (id=%x): %w", pendingID, err)
+		}
+		children[child.ParentID] = append(children[child.ParentID], child)
+	}
+	proof, ok := p.search(headers, children)
+	if !ok {
+		return nil, fmt.Errorf("could not find 3-chain for finalized block (id=%x)", blockID)
+	}
+
+	return proof, nil
+}
+
+// search performs a depth-first search through the pending blocks for a chain
+// extending the given headers with a 3-chain.
+func (p *Prover) search(headers []*flow.Header, children map[flow.Identifier][]*flow.Header) (*Proof, bool) {
+	if i := threeChain(headers); i >= 0 {
+		return &Proof{Headers: headers[:i+4]}, true
+	}
+	tip := headers[len(headers)-1]
+	for _, child := range children[tip.ID()] {
+		extended := make([]*flow.Header, len(headers), len(headers)+1)
+		copy(extended, headers)
+		proof, ok := p.search(append(extended, child), children)
+		if ok {
+			return proof, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,115 @@
+package finality
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/mocks"
+	"github.com/onflow/flow-go/model/flow"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	storage "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// proverFixture creates a prover for the given chain, of which the first
+// `finalized` headers are finalized and the rest are pending.
+func proverFixture(headers []*flow.Header, finalized int, extra ...*flow.Header) *Prover {
+
+	store := &storage.Headers{}
+	byHeight := make(map[uint64]*flow.Header)
+	for _, header := range headers[:finalized] {
+		byHeight[header.Height] = header
+	}
+	byID := make(map[flow.Identifier]*flow.Header)
+	var pending []flow.Identifier
+	all := append(append([]*flow.Header{}, headers...), extra...)
+	for i, header := range all {
+		byID[header.ID()] = header
+		if i >= finalized {
+			pending = append(pending, header.ID())
+		}
+	}
+	store.On("ByBlockID", mock.Anything).Return(
+		func(blockID flow.Identifier) *flow.Header { return byID[blockID] },
+		func(blockID flow.Identifier) error {
+			if _, ok := byID[blockID]; !ok {
+				return errors.New("not found")
+			}
+			return nil
+		},
+	)
+	store.On("ByHeight", mock.Anything).Return(
+		func(height uint64) *flow.Header { return byHeight[height] },
+		func(height uint64) error {
+			if _, ok := byHeight[height]; !ok {
+				return errors.New("not found")
+			}
+			return nil
+		},
+	)
+
+	final := &protocol.Snapshot{}
+	final.On("Head").Return(headers[finalized-1], nil)
+	final.On("Pending").Return(pending, nil)
+	state := &protocol.State{}
+	state.On("Final").Return(final)
+
+	return NewProver(state, store)
+}
+
+func TestProver(t *testing.T) {
+
+	participants := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+
+	t.Run("3-chain finalized", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 12, 13, 14, 15, 16, 17)
+		prover := proverFixture(headers, 6)
+		proof, err := prover.Prove(headers[0].ID())
+		require.NoError(t, err)
+		assert.Equal(t, headers[:5], proof.Headers)
+	})
+
+	t.Run("3-chain pending", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 12, 13, 14, 15)
+		prover := proverFixture(headers, 2)
+		proof, err := prover.Prove(headers[0].ID())
+		require.NoError(t, err)
+		assert.Equal(t, headers[:5], proof.Headers)
+	})
+
+	t.Run("3-chain on pending fork", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 12, 13, 14, 15)
+		// a conflicting fork without a 3-chain, which comes first in the
+		// pending blocks
+		fork := unittest.BlockHeaderWithParentFixture(headers[1])
+		fork.View = 20
+		prover := proverFixture(headers[:2], 2, append([]*flow.Header{&fork}, headers[2:]...)...)
+		proof, err := prover.Prove(headers[0].ID())
+		require.NoError(t, err)
+		assert.Equal(t, headers[:5], proof.Headers)
+	})
+
+	t.Run("not finalized", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 12, 13)
+		prover := proverFixture(headers, 1)
+		_, err := prover.Prove(headers[1].ID())
+		assert.True(t, errors.Is(err, ErrNotFinalized))
+	})
+
+	t.Run("proof verifies", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 13, 14, 15, 16)
+		prover := proverFixture(headers, 3)
+		proof, err := prover.Prove(headers[0].ID())
+		require.NoError(t, err)
+
+		verifier := &mocks.Verifier{}
+		verifier.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		header, err := NewVerifier(participants, 0, 100, verifier).Verify(proof)
+		require.NoError(t, err)
+		assert.Equal(t, headers[0], header)
+	})
+}
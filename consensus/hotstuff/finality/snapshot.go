@@ -0,0 +1,43 @@
+// +build relic
+
+package finality
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/committees"
+	"github.com/onflow/flow-go/consensus/hotstuff/verification"
+	"github.com/onflow/flow-go/model/encodable"
+	"github.com/onflow/flow-go/model/encoding"
+	"github.com/onflow/flow-go/module/signature"
+	"github.com/onflow/flow-go/state/protocol"
+)
+
+// NewSnapshotVerifier creates a new finality proof verifier for the current
+// epoch of the given snapshot, which verifies QCs with the epoch's staking
+// keys and random beacon DKG.
+func NewSnapshotVerifier(snapshot protocol.Snapshot) (*Verifier, error) {
+	return NewEpochVerifier(snapshot.Epochs().Current())
+}
+
+// NewEpochVerifier creates a new finality proof verifier for the given epoch.
+func NewEpochVerifier(epoch protocol.Epoch) (*Verifier, error) {
+
+	committee, err := committees.NewEpochCommittee(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("could not create epoch committee: %w", err)
+	}
+	participants, err := epoch.InitialIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("could not get initial identities: %w", err)
+	}
+
+	verifier := verification.NewCombinedVerifier(
+		committee,
+		signature.NewAggregationVerifier(encoding.ConsensusVoteTag),
+		signature.NewThresholdVerifier(encoding.RandomBeaconTag),
+		signature.NewCombiner(encodable.ConsensusVoteSigLen, encodable.RandomBeaconSigLen),
+	)
+
+	return NewVerifier(participants, committee.FirstView(), committee.FinalView(), verifier), nil
+}
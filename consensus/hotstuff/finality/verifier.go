@@ -0,0 +1,113 @@
+package finality
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/consensus/hotstuff"
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/filter"
+)
+
+// Verifier verifies finality proofs against the consensus committee of a
+// single epoch. It does not require any local protocol state beyond the
+// committee, so it can be used by light clients and bridges.
+type Verifier struct {
+	participants flow.IdentityList
+	threshold    uint64
+	firstView    uint64
+	finalView    uint64
+	verifier     hotstuff.Verifier
+}
+
+// NewVerifier creates a new finality proof verifier for the epoch with the
+// given consensus participants and view range, which uses the given verifier
+// to check the QC signatures.
+func NewVerifier(participants flow.IdentityList, firstView uint64, finalView uint64, verifier hotstuff.Verifier) *Verifier {
+	participants = participants.Filter(filter.IsVotingConsensusCommitteeMember)
+	v := &Verifier{
+		participants: participants,
+		threshold:    hotstuff.ComputeStakeThresholdForBuildingQC(participants.TotalStake()),
+		firstView:    firstView,
+		finalView:    finalView,
+		verifier:     verifier,
+	}
+	return v
+}
+
+// Verify checks that the given proof proves the finalization of its first
+// block and returns the header of that block. It returns an error wrapping
+// ErrInvalidProof if the proof is invalid.
+func (v *Verifier) Verify(proof *Proof) (*flow.Header, error) {
+
+	headers := proof.Headers
+	if len(headers) < 4 {
+		return nil, fmt.Errorf("proof contains %d headers, need at least 4: %w", len(headers), ErrInvalidProof)
+	}
+
+	// the headers must form a chain, so that finalizing the last block of
+	// the chain finalizes the first one
+	for i := 1; i < len(headers); i++ {
+		parentID := headers[i-1].ID()
+		if headers[i].ParentID != parentID {
+			return nil, fmt.Errorf("header %d does not descend from header %d: %w", i, i-1, ErrInvalidProof)
+		}
+		if headers[i].Height != headers[i-1].Height+1 {
+			return nil, fmt.Errorf("header %d has invalid height %d: %w", i, headers[i].Height, ErrInvalidProof)
+		}
+	}
+
+	// the chain must end in a direct 3-chain; we require the 3-chain at the
+	// end of the proof, so a proof can't carry unverified headers
+	i := threeChain(headers)
+	if i != len(headers)-4 {
+		return nil, fmt.Errorf("proof does not end in a direct 3-chain: %w", ErrInvalidProof)
+	}
+
+	// all blocks of the 3-chain must have been certified by the committee
+	for j := i; j < i+3; j++ {
+		err := v.VerifyQC(headers[j], headers[j+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid QC for header %d: %w", j, err)
+		}
+	}
+
+	return headers[0], nil
+}
+
+// VerifyQC verifies the QC for the given block, which is included in the
+// header of its child. The block must be within the view range of the epoch.
+// It returns an error wrapping ErrInvalidProof if the QC is invalid.
+func (v *Verifier) VerifyQC(header *flow.Header, child *flow.Header) error {
+
+	blockID := header.ID()
+	if child.ParentID != blockID {
+		return fmt.Errorf("child does not descend from block: %w", ErrInvalidProof)
+	}
+
+	if header.View < v.firstView || header.View > v.finalView {
+		return fmt.Errorf("view %d outside of epoch [%d, %d]: %w", header.View, v.firstView, v.finalView, ErrInvalidProof)
+	}
+
+	signers := v.participants.Filter(filter.HasNodeID(child.ParentVoterIDs...)) // resulting IdentityList contains no duplicates
+	if len(signers) != len(child.ParentVoterIDs) {
+		return fmt.Errorf("some qc signers are duplicated or invalid consensus participants: %w", ErrInvalidProof)
+	}
+	if signers.TotalStake() < v.threshold {
+		return fmt.Errorf("qc signers have insufficient stake of %d (required=%d): %w", signers.TotalStake(), v.threshold, ErrInvalidProof)
+	}
+
+	block := &model.Block{
+		View:    header.View,
+		BlockID: blockID,
+	}
+	valid, err := v.verifier.VerifyQC(signers, child.ParentVoterSig, block)
+	if err != nil {
+		return fmt.Errorf("could not verify qc signature: %w", err)
+	}
+	if !valid {
+		return fmt.Errorf("invalid qc signature: %w", ErrInvalidProof)
+	}
+
+	return nil
+}
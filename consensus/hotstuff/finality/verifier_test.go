@@ -0,0 +1,98 @@
+package finality
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/mocks"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// chainFixture creates a chain of headers with the given views, where each
+// header carries a QC for its parent signed by the given voters.
+func chainFixture(voters flow.IdentityList, views ...uint64) []*flow.Header {
+	headers := make([]*flow.Header, 0, len(views))
+	parent := unittest.BlockHeaderFixture()
+	for _, view := range views {
+		header := unittest.BlockHeaderWithParentFixture(&parent)
+		header.View = view
+		header.ParentVoterIDs = voters.NodeIDs()
+		headers = append(headers, &header)
+		parent = header
+	}
+	return headers
+}
+
+func TestVerifier(t *testing.T) {
+
+	participants := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+
+	verifier := &mocks.Verifier{}
+	verifier.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	v := NewVerifier(participants, 0, 100, verifier)
+
+	t.Run("valid proof", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 12, 13, 14, 16)
+		header, err := v.Verify(&Proof{Headers: headers})
+		require.NoError(t, err)
+		assert.Equal(t, headers[0], header)
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 12)
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("broken chain", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 12, 13)
+		headers[2].ParentID = unittest.IdentifierFixture()
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("no direct 3-chain", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 13, 14)
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("trailing headers", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 12, 13, 14)
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("outside of epoch", func(t *testing.T) {
+		headers := chainFixture(participants, 99, 100, 101, 102)
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("unknown signer", func(t *testing.T) {
+		headers := chainFixture(participants, 10, 11, 12, 13)
+		headers[3].ParentVoterIDs = append(headers[3].ParentVoterIDs, unittest.IdentifierFixture())
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("insufficient stake", func(t *testing.T) {
+		headers := chainFixture(participants[:2], 10, 11, 12, 13)
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		invalid := &mocks.Verifier{}
+		invalid.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		v := NewVerifier(participants, 0, 100, invalid)
+		headers := chainFixture(participants, 10, 11, 12, 13)
+		_, err := v.Verify(&Proof{Headers: headers})
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+}
@@ -0,0 +1,128 @@
+package simulation
+
+import (
+	"time"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/pacemaker/timeout"
+)
+
+// Behaviour describes how a byzantine replica deviates from the protocol.
+type Behaviour string
+
+const (
+	// Equivocate makes a replica propose two conflicting blocks for each of
+	// its views, each sent to one half of the other replicas.
+	Equivocate Behaviour = "equivocate"
+	// WithholdVotes makes a replica never send its votes.
+	WithholdVotes Behaviour = "withhold-votes"
+	// WithholdProposals makes a replica never broadcast its proposals.
+	WithholdProposals Behaviour = "withhold-proposals"
+)
+
+// Partition separates the replicas into groups that can not communicate with
+// each other for the given time window after the start of the simulation.
+// Replicas that are not part of any group can communicate with everyone.
+type Partition struct {
+	From   time.Duration
+	To     time.Duration
+	Groups [][]int
+}
+
+// Config is the configuration of a simulation.
+type Config struct {
+	// Stakes holds the stake of each replica; its length is the committee size.
+	Stakes []uint64
+	// Timeouts is the pacemaker timeout configuration of all replicas.
+	Timeouts timeout.Config
+	// Delay is the minimum delay for delivering a message.
+	Delay time.Duration
+	// Jitter is the maximum random delay added on top of Delay.
+	Jitter time.Duration
+	// DropRate is the probability for any message to be dropped.
+	DropRate float64
+	// Partitions are the network partitions occurring during the simulation.
+	Partitions []Partition
+	// Byzantine maps the index of byzantine replicas to their behaviour.
+	Byzantine map[int]Behaviour
+	// Duration is the maximum duration of the simulation.
+	Duration time.Duration
+	// FinalView stops the simulation early once all honest replicas finalized
+	// this view; zero means the simulation runs for its full duration.
+	FinalView uint64
+}
+
+// DefaultConfig returns the configuration for four honest replicas with equal
+// stake on a reliable network.
+func DefaultConfig() Config {
+	timeouts, err := timeout.NewConfig(
+		2*time.Second,
+		500*time.Millisecond,
+		0.5,
+		1.5,
+		0.85,
+		0,
+	)
+	if err != nil {
+		panic("default simulation config is not compliant with timeout config requirements")
+	}
+	return Config{
+		Stakes:    []uint64{1000, 1000, 1000, 1000},
+		Timeouts:  timeouts,
+		Delay:     10 * time.Millisecond,
+		Jitter:    10 * time.Millisecond,
+		DropRate:  0,
+		Byzantine: make(map[int]Behaviour),
+		Duration:  30 * time.Second,
+	}
+}
+
+type Option func(*Config)
+
+func WithStakes(stakes ...uint64) Option {
+	return func(cfg *Config) {
+		cfg.Stakes = stakes
+	}
+}
+
+func WithTimeouts(timeouts timeout.Config) Option {
+	return func(cfg *Config) {
+		cfg.Timeouts = timeouts
+	}
+}
+
+func WithDelay(delay time.Duration, jitter time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.Delay = delay
+		cfg.Jitter = jitter
+	}
+}
+
+func WithDropRate(rate float64) Option {
+	return func(cfg *Config) {
+		cfg.DropRate = rate
+	}
+}
+
+func WithPartition(partition Partition) Option {
+	return func(cfg *Config) {
+		cfg.Partitions = append(cfg.Partitions, partition)
+	}
+}
+
+func WithByzantine(index int, behaviour Behaviour) Option {
+	return func(cfg *Config) {
+		cfg.Byzantine[index] = behaviour
+	}
+}
+
+func WithDuration(duration time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.Duration = duration
+	}
+}
+
+func WithFinalView(view uint64) Option {
+	return func(cfg *Config) {
+		cfg.FinalView = view
+	}
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Network delivers messages between the replicas of a simulation, applying
+// the configured delays, drop rate and partitions.
+type Network struct {
+	sync.Mutex
+	rng        *rand.Rand
+	start      time.Time
+	delay      time.Duration
+	jitter     time.Duration
+	dropRate   float64
+	partitions []Partition
+	dropped    uint
+	delivered  uint
+	done       chan struct{}
+}
+
+// NewNetwork creates a new simulated network with the delays, drop rate and
+// partitions of the given configuration.
+func NewNetwork(cfg Config, seed int64) *Network {
+	n := &Network{
+		rng:        rand.New(rand.NewSource(seed)),
+		start:      time.Now(),
+		delay:      cfg.Delay,
+		jitter:     cfg.Jitter,
+		dropRate:   cfg.DropRate,
+		partitions: cfg.Partitions,
+		done:       make(chan struct{}),
+	}
+	return n
+}
+
+// Send delivers the message from the sender to the receiver after a random
+// delay, unless the message is dropped or the two replicas are partitioned.
+func (n *Network) Send(sender *Replica, receiver *Replica, msg interface{}) {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.partitioned(sender.index, receiver.index) || n.rng.Float64() < n.dropRate {
+		n.dropped++
+		return
+	}
+	n.delivered++
+
+	delay := n.delay
+	if n.jitter > 0 {
+		delay += time.Duration(n.rng.Int63n(int64(n.jitter)))
+	}
+	time.AfterFunc(delay, func() {
+		select {
+		case receiver.queue <- msg:
+		case <-n.done:
+		}
+	})
+}
+
+// Stop discards all messages that are still in transit.
+func (n *Network) Stop() {
+	close(n.done)
+}
+
+// partitioned checks whether the two replicas are in different groups of a
+// currently active partition.
+func (n *Network) partitioned(sender int, receiver int) bool {
+	elapsed := time.Since(n.start)
+	for _, partition := range n.partitions {
+		if elapsed < partition.From || elapsed >= partition.To {
+			continue
+		}
+		senderGroup, receiverGroup := -1, -1
+		for g, group := range partition.Groups {
+			for _, index := range group {
+				if index == sender {
+					senderGroup = g
+				}
+				if index == receiver {
+					receiverGroup = g
+				}
+			}
+		}
+		if senderGroup >= 0 && receiverGroup >= 0 && senderGroup != receiverGroup {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,460 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/consensus/hotstuff"
+	"github.com/onflow/flow-go/consensus/hotstuff/blockproducer"
+	"github.com/onflow/flow-go/consensus/hotstuff/eventhandler"
+	"github.com/onflow/flow-go/consensus/hotstuff/forks"
+	"github.com/onflow/flow-go/consensus/hotstuff/forks/finalizer"
+	"github.com/onflow/flow-go/consensus/hotstuff/forks/forkchoice"
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/consensus/hotstuff/notifications"
+	"github.com/onflow/flow-go/consensus/hotstuff/notifications/pubsub"
+	"github.com/onflow/flow-go/consensus/hotstuff/pacemaker"
+	"github.com/onflow/flow-go/consensus/hotstuff/pacemaker/timeout"
+	"github.com/onflow/flow-go/consensus/hotstuff/validator"
+	"github.com/onflow/flow-go/consensus/hotstuff/voteaggregator"
+	"github.com/onflow/flow-go/consensus/hotstuff/voter"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+var errStopped = errors.New("replica stopped")
+
+// Replica is a single HotStuff participant of a simulation. It runs the real
+// HotStuff event handler, with all dependencies outside of the consensus core
+// (block building, storage, signing, networking) replaced by simple in-memory
+// implementations.
+type Replica struct {
+	index     int
+	localID   flow.Identifier
+	behaviour Behaviour
+	sim       *Simulation
+	log       zerolog.Logger
+
+	// replica data; only accessed by the replica's own event loop while it
+	// is running
+	queue     chan interface{}
+	headers   map[flow.Identifier]*flow.Header
+	finalized map[uint64]flow.Identifier
+	lastFinal uint64
+	timeouts  uint
+	doubles   uint
+	started   uint64
+	voted     uint64
+
+	// finalView is the highest finalized view, which is read atomically while
+	// the replica runs
+	finalView uint64
+
+	// hotstuff components
+	pacemaker hotstuff.PaceMaker
+	forks     *forks.Forks
+	handler   *eventhandler.EventHandler
+}
+
+// NewReplica creates a new replica with the given index into the committee of
+// the simulation.
+func NewReplica(sim *Simulation, index int, log zerolog.Logger) (*Replica, error) {
+
+	localID := sim.participants[index].NodeID
+	r := &Replica{
+		index:     index,
+		localID:   localID,
+		behaviour: sim.cfg.Byzantine[index],
+		sim:       sim,
+		log:       log.With().Int("replica", index).Hex("local_id", localID[:]).Logger(),
+		queue:     make(chan interface{}, 1024),
+		headers:   make(map[flow.Identifier]*flow.Header),
+		finalized: make(map[uint64]flow.Identifier),
+	}
+
+	root := sim.root
+	r.headers[root.ID()] = root
+	r.finalized[root.Height] = root.ID()
+	r.lastFinal = root.Height
+
+	distributor := pubsub.NewDistributor()
+	distributor.AddConsumer(notifications.NewLogConsumer(r.log))
+	distributor.AddConsumer(&replicaConsumer{replica: r})
+
+	committee := &committee{participants: sim.participants, localID: localID}
+	signer := &signer{localID: localID}
+
+	var err error
+	controller := timeout.NewController(sim.cfg.Timeouts)
+	r.pacemaker, err = pacemaker.New(1, controller, distributor)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize pacemaker: %w", err)
+	}
+
+	producer, err := blockproducer.New(signer, committee, &builder{replica: r})
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize block producer: %w", err)
+	}
+
+	rootBlock := model.BlockFromFlow(root, 0)
+	rootQC := &flow.QuorumCertificate{
+		View:      rootBlock.View,
+		BlockID:   rootBlock.BlockID,
+		SignerIDs: sim.participants.NodeIDs(),
+	}
+	forkalizer, err := finalizer.New(&forks.BlockQC{Block: rootBlock, QC: rootQC}, r, distributor)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize finalizer: %w", err)
+	}
+	choice, err := forkchoice.NewNewestForkChoice(forkalizer, distributor)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize fork choice: %w", err)
+	}
+	r.forks = forks.New(forkalizer, choice)
+
+	valid := validator.New(committee, r.forks, signer)
+	aggregator := voteaggregator.New(distributor, 0, committee, valid, signer)
+	vote := voter.New(signer, r.forks, r, committee, 0)
+
+	r.handler, err = eventhandler.New(r.log, r.pacemaker, producer, r.forks, r, r, committee, aggregator, vote, valid, distributor)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize event handler: %w", err)
+	}
+
+	return r, nil
+}
+
+// Run processes timeouts and incoming messages until the given channel is
+// closed or processing an event fails.
+func (r *Replica) Run(done <-chan struct{}) error {
+
+	err := r.handler.Start()
+	if err != nil {
+		return fmt.Errorf("could not start event handler: %w", err)
+	}
+
+	for {
+
+		// we handle timeouts with priority
+		select {
+		case <-done:
+			return errStopped
+		case <-r.handler.TimeoutChannel():
+			err := r.handler.OnLocalTimeout()
+			if err != nil {
+				return fmt.Errorf("could not process timeout: %w", err)
+			}
+			continue
+		default:
+		}
+
+		// otherwise, process first received event
+		select {
+		case <-done:
+			return errStopped
+		case <-r.handler.TimeoutChannel():
+			err := r.handler.OnLocalTimeout()
+			if err != nil {
+				return fmt.Errorf("could not process timeout: %w", err)
+			}
+		case msg := <-r.queue:
+			switch m := msg.(type) {
+			case *flow.Header:
+				err := r.processProposal(m)
+				if err != nil {
+					return fmt.Errorf("could not process proposal: %w", err)
+				}
+			case *model.Vote:
+				err := r.handler.OnReceiveVote(m)
+				if err != nil {
+					return fmt.Errorf("could not process vote: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// processProposal hands the proposal to the event handler. Ancestors of the
+// proposal that never reached the replica are first retrieved from the blocks
+// known to the simulation, which stands in for the synchronization engine.
+func (r *Replica) processProposal(header *flow.Header) error {
+
+	var missing []*flow.Header
+	parentID := header.ParentID
+	for {
+		if _, known := r.headers[parentID]; known {
+			break
+		}
+		parent, ok := r.sim.block(parentID)
+		if !ok {
+			return fmt.Errorf("unknown parent (%x)", parentID)
+		}
+		if parent.View < r.forks.FinalizedView() {
+			break
+		}
+		missing = append(missing, parent)
+		parentID = parent.ParentID
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		err := r.submitProposal(missing[i])
+		if err != nil {
+			return fmt.Errorf("could not process ancestor (%x): %w", missing[i].ID(), err)
+		}
+	}
+
+	return r.submitProposal(header)
+}
+
+func (r *Replica) submitProposal(header *flow.Header) error {
+	blockID := header.ID()
+	if _, processed := r.forks.GetBlock(blockID); processed {
+		return nil
+	}
+	parent, ok := r.sim.block(header.ParentID)
+	if !ok {
+		return fmt.Errorf("unknown parent (%x)", header.ParentID)
+	}
+	r.headers[blockID] = header
+	return r.handler.OnReceiveProposal(model.ProposalFromFlow(header, parent.View))
+}
+
+// SendVote implements hotstuff.Communicator.
+func (r *Replica) SendVote(blockID flow.Identifier, view uint64, sigData []byte, recipientID flow.Identifier) error {
+	if r.behaviour == WithholdVotes {
+		return nil
+	}
+	recipient, ok := r.sim.replica(recipientID)
+	if !ok {
+		return fmt.Errorf("recipient doesn't exist (sender: %x, recipient: %x)", r.localID, recipientID)
+	}
+	if recipient == r {
+		return fmt.Errorf("can't send to self (sender: %x)", r.localID)
+	}
+	r.sim.network.Send(r, recipient, model.VoteFromFlow(r.localID, blockID, view, sigData))
+	return nil
+}
+
+// BroadcastProposal implements hotstuff.Communicator.
+func (r *Replica) BroadcastProposal(header *flow.Header) error {
+	return r.BroadcastProposalWithDelay(header, 0)
+}
+
+// BroadcastProposalWithDelay implements hotstuff.Communicator. The delay is
+// ignored, as the simulated network applies its own delays.
+func (r *Replica) BroadcastProposalWithDelay(header *flow.Header, _ time.Duration) error {
+
+	parent, ok := r.headers[header.ParentID]
+	if !ok {
+		return fmt.Errorf("parent for proposal not found (sender: %x, parent: %x)", r.localID, header.ParentID)
+	}
+	header.ChainID = parent.ChainID
+	header.Height = parent.Height + 1
+
+	// loop the proposal back into our own event loop
+	r.sim.addBlock(header)
+	r.loopback(header)
+
+	switch r.behaviour {
+	case WithholdProposals:
+		return nil
+	case Equivocate:
+		// send a conflicting proposal for the same view to every other replica
+		conflicting := *header
+		conflicting.PayloadHash = r.sim.randomIdentifier()
+		r.sim.addBlock(&conflicting)
+		for i, receiver := range r.sim.replicas {
+			if receiver == r {
+				continue
+			}
+			if i%2 == 0 {
+				r.sim.network.Send(r, receiver, header)
+			} else {
+				r.sim.network.Send(r, receiver, &conflicting)
+			}
+		}
+		return nil
+	}
+
+	for _, receiver := range r.sim.replicas {
+		if receiver == r {
+			continue
+		}
+		r.sim.network.Send(r, receiver, header)
+	}
+	return nil
+}
+
+// loopback queues our own proposal without blocking the event loop, which is
+// the only consumer of the queue.
+func (r *Replica) loopback(header *flow.Header) {
+	go func() {
+		select {
+		case r.queue <- header:
+		case <-r.sim.network.done:
+		}
+	}()
+}
+
+// MakeValid implements module.Finalizer.
+func (r *Replica) MakeValid(flow.Identifier) error {
+	return nil
+}
+
+// MakeFinal implements module.Finalizer. It records the heights of the newly
+// finalized blocks, which are compared between replicas to detect safety
+// violations.
+func (r *Replica) MakeFinal(blockID flow.Identifier) error {
+	header, ok := r.headers[blockID]
+	if !ok {
+		return fmt.Errorf("finalized block not found (%x)", blockID)
+	}
+	height := header.Height
+	for header.Height > r.lastFinal {
+		r.finalized[header.Height] = header.ID()
+		header, ok = r.headers[header.ParentID]
+		if !ok {
+			return fmt.Errorf("ancestor of finalized block not found (%x)", blockID)
+		}
+	}
+	if height > r.lastFinal {
+		r.lastFinal = height
+	}
+	atomic.StoreUint64(&r.finalView, r.forks.FinalizedView())
+	return nil
+}
+
+// GetStarted implements hotstuff.Persister.
+func (r *Replica) GetStarted() (uint64, error) {
+	return r.started, nil
+}
+
+// GetVoted implements hotstuff.Persister.
+func (r *Replica) GetVoted() (uint64, error) {
+	return r.voted, nil
+}
+
+// PutStarted implements hotstuff.Persister.
+func (r *Replica) PutStarted(view uint64) error {
+	r.started = view
+	return nil
+}
+
+// PutVoted implements hotstuff.Persister.
+func (r *Replica) PutVoted(view uint64) error {
+	r.voted = view
+	return nil
+}
+
+// replicaConsumer counts the timeouts and double proposals of a replica.
+type replicaConsumer struct {
+	notifications.NoopConsumer
+	replica *Replica
+}
+
+func (c *replicaConsumer) OnReachedTimeout(*model.TimerInfo) {
+	c.replica.timeouts++
+}
+
+func (c *replicaConsumer) OnDoubleProposeDetected(*model.Block, *model.Block) {
+	c.replica.doubles++
+}
+
+// committee is a static HotStuff committee with round-robin leader selection.
+type committee struct {
+	participants flow.IdentityList
+	localID      flow.Identifier
+}
+
+func (c *committee) Identities(_ flow.Identifier, selector flow.IdentityFilter) (flow.IdentityList, error) {
+	return c.participants.Filter(selector), nil
+}
+
+func (c *committee) Identity(_ flow.Identifier, participantID flow.Identifier) (*flow.Identity, error) {
+	identity, ok := c.participants.ByNodeID(participantID)
+	if !ok {
+		return nil, model.ErrInvalidSigner
+	}
+	return identity, nil
+}
+
+func (c *committee) LeaderForView(view uint64) (flow.Identifier, error) {
+	return c.participants[int(view%uint64(len(c.participants)))].NodeID, nil
+}
+
+func (c *committee) Self() flow.Identifier {
+	return c.localID
+}
+
+func (c *committee) DKG(flow.Identifier) (hotstuff.DKG, error) {
+	return nil, fmt.Errorf("simulation does not support random beacon")
+}
+
+// builder builds empty payloads on top of the replica's known blocks.
+type builder struct {
+	replica *Replica
+}
+
+func (b *builder) BuildOn(parentID flow.Identifier, setter func(*flow.Header) error) (*flow.Header, error) {
+	parent, ok := b.replica.headers[parentID]
+	if !ok {
+		return nil, fmt.Errorf("parent block not found (parent: %x)", parentID)
+	}
+	header := &flow.Header{
+		ChainID:     parent.ChainID,
+		ParentID:    parentID,
+		Height:      parent.Height + 1,
+		PayloadHash: b.replica.sim.randomIdentifier(),
+		Timestamp:   time.Now().UTC(),
+	}
+	err := setter(header)
+	if err != nil {
+		return nil, fmt.Errorf("could not apply setter: %w", err)
+	}
+	b.replica.headers[header.ID()] = header
+	return header, nil
+}
+
+// signer creates proposals, votes and QCs without signatures and accepts all
+// signatures as valid; signature verification is out of scope for the
+// simulation.
+type signer struct {
+	localID flow.Identifier
+}
+
+func (s *signer) CreateProposal(block *model.Block) (*model.Proposal, error) {
+	return &model.Proposal{Block: block}, nil
+}
+
+func (s *signer) CreateVote(block *model.Block) (*model.Vote, error) {
+	vote := &model.Vote{
+		View:     block.View,
+		BlockID:  block.BlockID,
+		SignerID: s.localID,
+	}
+	return vote, nil
+}
+
+func (s *signer) CreateQC(votes []*model.Vote) (*flow.QuorumCertificate, error) {
+	voterIDs := make([]flow.Identifier, 0, len(votes))
+	for _, vote := range votes {
+		voterIDs = append(voterIDs, vote.SignerID)
+	}
+	qc := &flow.QuorumCertificate{
+		View:      votes[0].View,
+		BlockID:   votes[0].BlockID,
+		SignerIDs: voterIDs,
+	}
+	return qc, nil
+}
+
+func (s *signer) VerifyVote(*flow.Identity, []byte, *model.Block) (bool, error) {
+	return true, nil
+}
+
+func (s *signer) VerifyQC(flow.IdentityList, []byte, *model.Block) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,168 @@
+package simulation
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Report summarizes the outcome of a simulation.
+type Report struct {
+	Duration   time.Duration
+	Delivered  uint
+	Dropped    uint
+	Replicas   []ReplicaReport
+	Violations []SafetyViolation
+}
+
+// ReplicaReport summarizes the outcome of a simulation for a single replica.
+type ReplicaReport struct {
+	Index           int
+	Behaviour       Behaviour
+	View            uint64
+	FinalizedView   uint64
+	FinalizedHeight uint64
+	Timeouts        uint
+	DoubleProposals uint
+	Err             error
+}
+
+// SafetyViolation is a pair of honest replicas that finalized different
+// blocks at the same height.
+type SafetyViolation struct {
+	Height  uint64
+	First   int
+	FirstID flow.Identifier
+	Other   int
+	OtherID flow.Identifier
+}
+
+// FinalizedBlocks returns the number of blocks finalized by all honest replicas.
+func (r *Report) FinalizedBlocks() uint64 {
+	var finalized uint64
+	first := true
+	for _, replica := range r.Replicas {
+		if replica.Behaviour != "" {
+			continue
+		}
+		if first || replica.FinalizedHeight < finalized {
+			finalized = replica.FinalizedHeight
+			first = false
+		}
+	}
+	return finalized
+}
+
+// FinalizationRate returns the number of blocks finalized by all honest
+// replicas per second.
+func (r *Report) FinalizationRate() float64 {
+	if r.Duration == 0 {
+		return 0
+	}
+	return float64(r.FinalizedBlocks()) / r.Duration.Seconds()
+}
+
+// Timeouts returns the total number of timeouts of all replicas.
+func (r *Report) Timeouts() uint {
+	var timeouts uint
+	for _, replica := range r.Replicas {
+		timeouts += replica.Timeouts
+	}
+	return timeouts
+}
+
+// Print writes a human readable version of the report.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "duration:            %s\n", r.Duration)
+	fmt.Fprintf(w, "messages delivered:  %d\n", r.Delivered)
+	fmt.Fprintf(w, "messages dropped:    %d\n", r.Dropped)
+	fmt.Fprintf(w, "finalized blocks:    %d\n", r.FinalizedBlocks())
+	fmt.Fprintf(w, "finalization rate:   %.2f blocks/s\n", r.FinalizationRate())
+	fmt.Fprintf(w, "timeouts:            %d\n", r.Timeouts())
+	fmt.Fprintf(w, "safety violations:   %d\n", len(r.Violations))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-8s %-20s %-8s %-15s %-17s %-9s %-16s %s\n",
+		"replica", "behaviour", "view", "finalized view", "finalized height", "timeouts", "double proposals", "error")
+	for _, replica := range r.Replicas {
+		behaviour := string(replica.Behaviour)
+		if behaviour == "" {
+			behaviour = "honest"
+		}
+		errMsg := ""
+		if replica.Err != nil {
+			errMsg = replica.Err.Error()
+		}
+		fmt.Fprintf(w, "%-8d %-20s %-8d %-15d %-17d %-9d %-16d %s\n",
+			replica.Index, behaviour, replica.View, replica.FinalizedView, replica.FinalizedHeight,
+			replica.Timeouts, replica.DoubleProposals, errMsg)
+	}
+	for _, violation := range r.Violations {
+		fmt.Fprintf(w, "safety violation at height %d: replica %d finalized %x, replica %d finalized %x\n",
+			violation.Height, violation.First, violation.FirstID, violation.Other, violation.OtherID)
+	}
+}
+
+// report assembles the report once all replicas have stopped.
+func (s *Simulation) report(duration time.Duration, errs []error) *Report {
+
+	s.network.Lock()
+	report := &Report{
+		Duration:  duration,
+		Delivered: s.network.delivered,
+		Dropped:   s.network.dropped,
+	}
+	s.network.Unlock()
+
+	for i, replica := range s.replicas {
+		report.Replicas = append(report.Replicas, ReplicaReport{
+			Index:           i,
+			Behaviour:       replica.behaviour,
+			View:            replica.pacemaker.CurView(),
+			FinalizedView:   replica.forks.FinalizedView(),
+			FinalizedHeight: replica.lastFinal,
+			Timeouts:        replica.timeouts,
+			DoubleProposals: replica.doubles,
+			Err:             errs[i],
+		})
+	}
+
+	// compare the finalized blocks of each pair of honest replicas; for each
+	// height, we keep the first honest replica that finalized it as reference
+	type reference struct {
+		replica int
+		blockID flow.Identifier
+	}
+	references := make(map[uint64]reference)
+	for i, replica := range s.replicas {
+		if replica.behaviour != "" {
+			continue
+		}
+		heights := make([]uint64, 0, len(replica.finalized))
+		for height := range replica.finalized {
+			heights = append(heights, height)
+		}
+		sort.Slice(heights, func(a, b int) bool { return heights[a] < heights[b] })
+		for _, height := range heights {
+			blockID := replica.finalized[height]
+			ref, ok := references[height]
+			if !ok {
+				references[height] = reference{replica: i, blockID: blockID}
+				continue
+			}
+			if ref.blockID != blockID {
+				report.Violations = append(report.Violations, SafetyViolation{
+					Height:  height,
+					First:   ref.replica,
+					FirstID: ref.blockID,
+					Other:   i,
+					OtherID: blockID,
+				})
+			}
+		}
+	}
+
+	return report
+}
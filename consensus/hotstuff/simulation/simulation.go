@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Simulation runs a committee of HotStuff replicas in-process, connected by a
+// simulated network, and reports on their liveness and safety.
+type Simulation struct {
+	log          zerolog.Logger
+	cfg          Config
+	root         *flow.Header
+	participants flow.IdentityList
+	replicas     []*Replica
+	lookup       map[flow.Identifier]*Replica
+	network      *Network
+
+	// rng draws the random identifiers of the simulation from its seed
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	// blocks holds every block proposed during the simulation; replicas use it
+	// to retrieve the ancestors of proposals they did not receive
+	blocksMu sync.RWMutex
+	blocks   map[flow.Identifier]*flow.Header
+}
+
+// New creates a new simulation with the default configuration, modified by
+// the given options.
+func New(log zerolog.Logger, seed int64, options ...Option) (*Simulation, error) {
+
+	cfg := DefaultConfig()
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	if len(cfg.Stakes) == 0 {
+		return nil, fmt.Errorf("committee must not be empty")
+	}
+	for index := range cfg.Byzantine {
+		if index < 0 || index >= len(cfg.Stakes) {
+			return nil, fmt.Errorf("byzantine replica %d is not part of the committee of size %d", index, len(cfg.Stakes))
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	sim := &Simulation{
+		log:     log,
+		cfg:     cfg,
+		lookup:  make(map[flow.Identifier]*Replica),
+		network: NewNetwork(cfg, seed),
+		rng:     rng,
+	}
+
+	participants := make(flow.IdentityList, 0, len(cfg.Stakes))
+	for _, stake := range cfg.Stakes {
+		var nodeID flow.Identifier
+		for nodeID == flow.ZeroID {
+			nodeID = sim.randomIdentifier()
+		}
+		participants = append(participants, &flow.Identity{
+			NodeID: nodeID,
+			Role:   flow.RoleConsensus,
+			Stake:  stake,
+		})
+	}
+
+	root := &flow.Header{
+		ChainID:     "simulation",
+		ParentID:    flow.ZeroID,
+		Height:      0,
+		PayloadHash: sim.randomIdentifier(),
+		Timestamp:   time.Now().UTC(),
+	}
+	sim.root = root
+	sim.participants = participants
+	sim.blocks = map[flow.Identifier]*flow.Header{root.ID(): root}
+
+	for index := range participants {
+		replica, err := NewReplica(sim, index, log)
+		if err != nil {
+			return nil, fmt.Errorf("could not create replica %d: %w", index, err)
+		}
+		sim.replicas = append(sim.replicas, replica)
+		sim.lookup[replica.localID] = replica
+	}
+
+	return sim, nil
+}
+
+// Run runs all replicas until the configured duration has elapsed or all
+// honest replicas finalized the configured final view, and returns the report.
+func (s *Simulation) Run() *Report {
+
+	start := time.Now()
+	done := make(chan struct{})
+	errs := make([]error, len(s.replicas))
+
+	var wg sync.WaitGroup
+	for i, replica := range s.replicas {
+		wg.Add(1)
+		go func(i int, replica *Replica) {
+			defer wg.Done()
+			err := replica.Run(done)
+			if !errors.Is(err, errStopped) {
+				s.log.Error().Err(err).Int("replica", i).Msg("replica failed")
+				errs[i] = err
+			}
+		}(i, replica)
+	}
+
+	deadline := time.After(s.cfg.Duration)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+Loop:
+	for {
+		select {
+		case <-deadline:
+			break Loop
+		case <-ticker.C:
+			if s.cfg.FinalView > 0 && s.reachedFinalView() {
+				break Loop
+			}
+		}
+	}
+
+	close(done)
+	s.network.Stop()
+	wg.Wait()
+
+	return s.report(time.Since(start), errs)
+}
+
+// reachedFinalView checks whether all honest replicas have finalized the final
+// view.
+func (s *Simulation) reachedFinalView() bool {
+	for _, replica := range s.replicas {
+		if replica.behaviour != "" {
+			continue
+		}
+		if atomic.LoadUint64(&replica.finalView) < s.cfg.FinalView {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Simulation) addBlock(header *flow.Header) {
+	s.blocksMu.Lock()
+	defer s.blocksMu.Unlock()
+	s.blocks[header.ID()] = header
+}
+
+func (s *Simulation) block(blockID flow.Identifier) (*flow.Header, bool) {
+	s.blocksMu.RLock()
+	defer s.blocksMu.RUnlock()
+	header, ok := s.blocks[blockID]
+	return header, ok
+}
+
+func (s *Simulation) replica(nodeID flow.Identifier) (*Replica, bool) {
+	replica, ok := s.lookup[nodeID]
+	return replica, ok
+}
+
+// randomIdentifier draws a random identifier from the seeded random source of
+// the simulation.
+func (s *Simulation) randomIdentifier() flow.Identifier {
+	s.rngMu.Lock()
+	defer s.rngMu.Unlock()
+	var id flow.Identifier
+	_, _ = s.rng.Read(id[:])
+	return id
+}
@@ -0,0 +1,72 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHonestReplicas(t *testing.T) {
+	sim, err := New(zerolog.Nop(), 1,
+		WithFinalView(20),
+		WithDuration(30*time.Second),
+	)
+	require.NoError(t, err)
+
+	report := sim.Run()
+	for _, replica := range report.Replicas {
+		assert.NoError(t, replica.Err)
+		assert.GreaterOrEqual(t, replica.FinalizedView, uint64(20))
+	}
+	assert.Empty(t, report.Violations)
+}
+
+func TestEquivocatingLeader(t *testing.T) {
+	sim, err := New(zerolog.Nop(), 1,
+		WithByzantine(1, Equivocate),
+		WithFinalView(20),
+		WithDuration(30*time.Second),
+	)
+	require.NoError(t, err)
+
+	report := sim.Run()
+	for _, replica := range report.Replicas {
+		assert.NoError(t, replica.Err)
+	}
+	assert.Greater(t, report.FinalizedBlocks(), uint64(0))
+	assert.Empty(t, report.Violations)
+}
+
+func TestPartitionedNetwork(t *testing.T) {
+	sim, err := New(zerolog.Nop(), 1,
+		WithPartition(Partition{From: 0, To: time.Second, Groups: [][]int{{0, 1}, {2, 3}}}),
+		WithDropRate(0.05),
+		WithFinalView(20),
+		WithDuration(30*time.Second),
+	)
+	require.NoError(t, err)
+
+	report := sim.Run()
+	for _, replica := range report.Replicas {
+		assert.NoError(t, replica.Err)
+		assert.GreaterOrEqual(t, replica.FinalizedView, uint64(20))
+	}
+	assert.Greater(t, report.Timeouts(), uint(0))
+	assert.Empty(t, report.Violations)
+}
+
+func TestSeed(t *testing.T) {
+	first, err := New(zerolog.Nop(), 1)
+	require.NoError(t, err)
+	second, err := New(zerolog.Nop(), 1)
+	require.NoError(t, err)
+	other, err := New(zerolog.Nop(), 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.participants.NodeIDs(), second.participants.NodeIDs())
+	assert.Equal(t, first.root.PayloadHash, second.root.PayloadHash)
+	assert.NotEqual(t, first.participants.NodeIDs(), other.participants.NodeIDs())
+}
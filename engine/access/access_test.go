@@ -107,6 +107,8 @@ func (suite *Suite) RunTest(
 			collections,
 			transactions,
 			receipts,
+			results,
+			nil,
 			suite.chainID,
 			suite.metrics,
 			nil,
@@ -283,6 +285,8 @@ func (suite *Suite) TestSendTransactionToRandomCollectionNode() {
 			collections,
 			transactions,
 			nil,
+			nil,
+			nil,
 			suite.chainID,
 			metrics,
 			connFactory, // passing in the connection factory
@@ -441,6 +445,7 @@ func (suite *Suite) TestGetSealedTransaction() {
 		metrics := metrics.NewNoopCollector()
 		transactions := storage.NewTransactions(metrics, db)
 		collections := storage.NewCollections(db, transactions)
+		transactionLocations := storage.NewTransactionLocations(db)
 		collectionsToMarkFinalized, err := stdmap.NewTimes(100)
 		require.NoError(suite.T(), err)
 		collectionsToMarkExecuted, err := stdmap.NewTimes(100)
@@ -449,11 +454,11 @@ func (suite *Suite) TestGetSealedTransaction() {
 		require.NoError(suite.T(), err)
 
 		rpcEng := rpc.New(suite.log, suite.state, rpc.Config{}, nil, nil, nil, blocks, headers, collections, transactions,
-			nil, suite.chainID, metrics, 0, 0, false, false, nil, nil)
+			nil, nil, transactionLocations, suite.chainID, metrics, 0, 0, false, false, nil, nil)
 
 		// create the ingest engine
 		ingestEng, err := ingestion.New(suite.log, suite.net, suite.state, suite.me, suite.request, blocks, headers, collections,
-			transactions, nil, metrics, collectionsToMarkFinalized, collectionsToMarkExecuted, blocksToMarkExecuted, rpcEng)
+			transactions, nil, transactionLocations, metrics, collectionsToMarkFinalized, collectionsToMarkExecuted, blocksToMarkExecuted, rpcEng)
 		require.NoError(suite.T(), err)
 
 		// 1. Assume that follower engine updated the block storage and the protocol state. The block is reported as sealed
@@ -485,6 +490,12 @@ func (suite *Suite) TestGetSealedTransaction() {
 		require.NoError(suite.T(), err)
 		// assert that the transaction is reported as Sealed
 		require.Equal(suite.T(), entitiesproto.TransactionStatus_SEALED, gResp.GetStatus())
+
+		// 6. the transaction is indexed in the finalized block which included it
+		location, err := transactionLocations.ByTransactionID(txID)
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), block.ID(), location.BlockID)
+		require.Equal(suite.T(), block.Header.Height, location.Height)
 	})
 }
 
@@ -516,6 +527,8 @@ func (suite *Suite) TestExecuteScript() {
 			collections,
 			transactions,
 			receipts,
+			results,
+			nil,
 			suite.chainID,
 			suite.metrics,
 			connFactory,
@@ -542,7 +555,7 @@ func (suite *Suite) TestExecuteScript() {
 			Once()
 		// create the ingest engine
 		ingestEng, err := ingestion.New(suite.log, suite.net, suite.state, suite.me, suite.request, blocks, headers, collections,
-			transactions, receipts, metrics, collectionsToMarkFinalized, collectionsToMarkExecuted, blocksToMarkExecuted, nil)
+			transactions, receipts, storage.NewTransactionLocations(db), metrics, collectionsToMarkFinalized, collectionsToMarkExecuted, blocksToMarkExecuted, nil)
 		require.NoError(suite.T(), err)
 
 		// create a block and a seal pointing to that block
@@ -50,11 +50,12 @@ type Engine struct {
 
 	// storage
 	// FIX: remove direct DB access by substituting indexer module
-	blocks            storage.Blocks
-	headers           storage.Headers
-	collections       storage.Collections
-	transactions      storage.Transactions
-	executionReceipts storage.ExecutionReceipts
+	blocks               storage.Blocks
+	headers              storage.Headers
+	collections          storage.Collections
+	transactions         storage.Transactions
+	executionReceipts    storage.ExecutionReceipts
+	transactionLocations storage.TransactionLocations
 
 	// metrics
 	transactionMetrics         module.TransactionMetrics
@@ -77,6 +78,7 @@ func New(
 	collections storage.Collections,
 	transactions storage.Transactions,
 	executionReceipts storage.ExecutionReceipts,
+	transactionLocations storage.TransactionLocations,
 	transactionMetrics module.TransactionMetrics,
 	collectionsToMarkFinalized *stdmap.Times,
 	collectionsToMarkExecuted *stdmap.Times,
@@ -96,6 +98,7 @@ func New(
 		collections:                collections,
 		transactions:               transactions,
 		executionReceipts:          executionReceipts,
+		transactionLocations:       transactionLocations,
 		transactionMetrics:         transactionMetrics,
 		collectionsToMarkFinalized: collectionsToMarkFinalized,
 		collectionsToMarkExecuted:  collectionsToMarkExecuted,
@@ -212,6 +215,22 @@ func (e *Engine) processFinalizedBlock(blockID flow.Identifier) error {
 		return fmt.Errorf("could not index block for collections: %w", err)
 	}
 
+	// index the location of the transactions of the collections we already
+	// received, the others are indexed once their collection is received
+	for _, g := range block.Payload.Guarantees {
+		collection, err := e.collections.ByID(g.CollectionID)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not retrieve collection (%x): %w", g.CollectionID, err)
+		}
+		err = e.transactionLocations.Index(block.Header, collection.Transactions)
+		if err != nil {
+			return fmt.Errorf("could not index transaction locations: %w", err)
+		}
+	}
+
 	// queue requesting each of the collections from the collection node
 	e.requestCollections(block.Payload.Guarantees)
 
@@ -351,6 +370,20 @@ func (e *Engine) handleCollection(originID flow.Identifier, entity flow.Entity)
 		}
 	}
 
+	// index the location of the transactions if the block which includes the
+	// collection is already finalized, otherwise they are indexed once it is
+	block, err := e.blocks.ByCollectionID(light.ID())
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up block for collection (%x): %w", light.ID(), err)
+	}
+	err = e.transactionLocations.Index(block.Header, collection.Transactions)
+	if err != nil {
+		return fmt.Errorf("could not index transaction locations: %w", err)
+	}
+
 	return nil
 }
 
@@ -38,14 +38,15 @@ type Suite struct {
 		params   *protocol.Params
 	}
 
-	me           *module.Local
-	request      *module.Requester
-	provider     *mocknetwork.Engine
-	blocks       *storage.Blocks
-	headers      *storage.Headers
-	collections  *storage.Collections
-	transactions *storage.Transactions
-	receipts     *storage.ExecutionReceipts
+	me                   *module.Local
+	request              *module.Requester
+	provider             *mocknetwork.Engine
+	blocks               *storage.Blocks
+	headers              *storage.Headers
+	collections          *storage.Collections
+	transactions         *storage.Transactions
+	receipts             *storage.ExecutionReceipts
+	transactionLocations *storage.TransactionLocations
 
 	eng *Engine
 }
@@ -82,6 +83,7 @@ func (suite *Suite) SetupTest() {
 	suite.headers = new(storage.Headers)
 	suite.collections = new(storage.Collections)
 	suite.transactions = new(storage.Transactions)
+	suite.transactionLocations = new(storage.TransactionLocations)
 	collectionsToMarkFinalized, err := stdmap.NewTimes(100)
 	require.NoError(suite.T(), err)
 	collectionsToMarkExecuted, err := stdmap.NewTimes(100)
@@ -90,10 +92,10 @@ func (suite *Suite) SetupTest() {
 	require.NoError(suite.T(), err)
 
 	rpcEng := rpc.New(log, suite.proto.state, rpc.Config{}, nil, nil, nil, suite.blocks, suite.headers, suite.collections,
-		suite.transactions, suite.receipts, flow.Testnet, metrics.NewNoopCollector(), 0, 0, false, false, nil, nil)
+		suite.transactions, suite.receipts, nil, suite.transactionLocations, flow.Testnet, metrics.NewNoopCollector(), 0, 0, false, false, nil, nil)
 
 	eng, err := New(log, net, suite.proto.state, suite.me, suite.request, suite.blocks, suite.headers, suite.collections,
-		suite.transactions, suite.receipts, metrics.NewNoopCollector(), collectionsToMarkFinalized, collectionsToMarkExecuted,
+		suite.transactions, suite.receipts, suite.transactionLocations, metrics.NewNoopCollector(), collectionsToMarkFinalized, collectionsToMarkExecuted,
 		blocksToMarkExecuted, rpcEng)
 	require.NoError(suite.T(), err)
 
@@ -123,6 +125,14 @@ func (suite *Suite) TestOnFinalizedBlock() {
 	// expect that the block storage is indexed with each of the collection guarantee
 	suite.blocks.On("IndexBlockForCollections", block.ID(), flow.GetIDs(block.Payload.Guarantees)).Return(nil).Once()
 
+	// expect that the transactions of the collections which were already received are indexed
+	received := unittest.CollectionFixture(2)
+	suite.collections.On("ByID", block.Payload.Guarantees[0].CollectionID).Return(&received, nil).Once()
+	for _, g := range block.Payload.Guarantees[1:] {
+		suite.collections.On("ByID", g.CollectionID).Return(nil, storerr.ErrNotFound).Once()
+	}
+	suite.transactionLocations.On("Index", block.Header, received.Transactions).Return(nil).Once()
+
 	// for each of the guarantees, we should request the corresponding collection once
 	needed := make(map[flow.Identifier]struct{})
 	for _, guarantee := range block.Payload.Guarantees {
@@ -153,6 +163,7 @@ func (suite *Suite) TestOnFinalizedBlock() {
 
 	// assert that the block was retrieved and all collections were requested
 	suite.headers.AssertExpectations(suite.T())
+	suite.transactionLocations.AssertExpectations(suite.T())
 	suite.request.AssertNumberOfCalls(suite.T(), "EntityByID", len(block.Payload.Guarantees))
 }
 
@@ -166,6 +177,12 @@ func (suite *Suite) TestOnCollection() {
 	// we should store the light collection and index its transactions
 	suite.collections.On("StoreLightAndIndexByTransaction", &light).Return(nil).Once()
 
+	// the block which includes the collection is finalized, so we should index
+	// the location of its transactions
+	block := unittest.BlockFixture()
+	suite.blocks.On("ByCollectionID", light.ID()).Return(&block, nil).Once()
+	suite.transactionLocations.On("Index", block.Header, collection.Transactions).Return(nil).Once()
+
 	// for each transaction in the collection, we should store it
 	needed := make(map[flow.Identifier]struct{})
 	for _, txID := range light.Transactions {
@@ -193,9 +210,10 @@ func (suite *Suite) TestOnCollection() {
 		}
 	}, time.Second, 20*time.Millisecond)
 
-	// check that the collection was stored and indexed, and we stored all transactions
+	// check that the collection was stored and indexed, and we stored and located all transactions
 	suite.collections.AssertExpectations(suite.T())
 	suite.transactions.AssertNumberOfCalls(suite.T(), "Store", len(collection.Transactions))
+	suite.transactionLocations.AssertExpectations(suite.T())
 }
 
 // TestOnCollection checks that when a duplicate collection is received, the node doesn't
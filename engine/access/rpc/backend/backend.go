@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
@@ -39,6 +40,8 @@ var fixedENIdentifiers flow.IdentifierList
 // Block details related calls are handled by backendBlockDetails.
 // Event related calls are handled by backendEvents.
 // Account related calls are handled by backendAccounts.
+// Finality proof related calls are handled by backendFinality.
+// Transaction location related calls are handled by backendTransactionLocations.
 //
 // All remaining calls are handled by the base Backend in this file.
 type Backend struct {
@@ -48,6 +51,8 @@ type Backend struct {
 	backendBlockHeaders
 	backendBlockDetails
 	backendAccounts
+	backendFinality
+	backendTransactionLocations
 
 	executionRPC      execproto.ExecutionAPIClient
 	state             protocol.State
@@ -67,6 +72,8 @@ func New(
 	collections storage.Collections,
 	transactions storage.Transactions,
 	executionReceipts storage.ExecutionReceipts,
+	executionResults storage.ExecutionResults,
+	transactionLocations storage.TransactionLocations,
 	chainID flow.ChainID,
 	transactionMetrics module.TransactionMetrics,
 	connFactory ConnectionFactory,
@@ -126,6 +133,13 @@ func New(
 			blocks: blocks,
 			state:  state,
 		},
+		backendFinality: backendFinality{
+			prover:      finality.NewProver(state, headers),
+			epochProver: finality.NewEpochProver(state, blocks, executionResults),
+		},
+		backendTransactionLocations: backendTransactionLocations{
+			transactionLocations: transactionLocations,
+		},
 		backendAccounts: backendAccounts{
 			staticExecutionRPC: executionRPC,
 			state:              state,
@@ -233,6 +247,70 @@ func (b *Backend) GetLatestProtocolStateSnapshot(_ context.Context) ([]byte, err
 	return data, nil
 }
 
+// GetProtocolStateSnapshotByHeight returns the serializable snapshot at the
+// finalized block with the given height. The snapshot is certified by the
+// quorum certificate of the block, and can be used to bootstrap a node.
+func (b *Backend) GetProtocolStateSnapshotByHeight(_ context.Context, height uint64) ([]byte, error) {
+	data, err := convert.SnapshotToBytes(b.state.AtHeight(height))
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return data, nil
+}
+
+// GetProtocolStateSnapshotByEpoch returns the serializable snapshot at the
+// first finalized block of the epoch with the given counter that a node can
+// bootstrap from.
+func (b *Backend) GetProtocolStateSnapshotByEpoch(ctx context.Context, counter uint64) ([]byte, error) {
+	height, err := protocol.EpochBootstrapHeight(b.state, counter)
+	if errors.Is(err, protocol.ErrNoBootstrapHeight) {
+		return nil, status.Errorf(codes.NotFound, "no snapshot to bootstrap from in epoch %d", counter)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not find snapshot of epoch %d: %v", counter, err)
+	}
+
+	return b.GetProtocolStateSnapshotByHeight(ctx, height)
+}
+
+// GetEpochs returns all epochs known to the node, with their height
+// boundaries, participants, cluster assignments and DKG keys.
+func (b *Backend) GetEpochs(_ context.Context) ([]*protocol.EpochInfo, error) {
+	epochs, err := b.state.History().Epochs()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not get epochs: %v", err)
+	}
+
+	return epochs, nil
+}
+
+// GetEpochAtHeight returns the epoch which the finalized block with the given
+// height belongs to.
+func (b *Backend) GetEpochAtHeight(_ context.Context, height uint64) (*protocol.EpochInfo, error) {
+	epoch, err := b.state.History().AtHeight(height)
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return epoch, nil
+}
+
+// GetNodeIdentityAtHeight returns the identity of the node with the given ID,
+// with its role and stake, in the epoch of the finalized block with the given
+// height.
+func (b *Backend) GetNodeIdentityAtHeight(_ context.Context, nodeID flow.Identifier, height uint64) (*flow.Identity, error) {
+	identity, err := b.state.History().Identity(nodeID, height)
+	if protocol.IsIdentityNotFound(err) {
+		return nil, status.Errorf(codes.NotFound, "node %x did not participate in the epoch at height %d", nodeID, height)
+	}
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return identity, nil
+}
+
 func convertStorageError(err error) error {
 	if err == nil {
 		return nil
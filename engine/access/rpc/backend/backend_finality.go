@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+type backendFinality struct {
+	prover      *finality.Prover
+	epochProver *finality.EpochProver
+}
+
+// GetFinalityProof returns a proof of the finalization of the block with the
+// given ID, which can be verified by light clients knowing the consensus
+// committee of the block's epoch.
+func (b *backendFinality) GetFinalityProof(_ context.Context, blockID flow.Identifier) (*finality.Proof, error) {
+	proof, err := b.prover.Prove(blockID)
+	if errors.Is(err, finality.ErrNotFinalized) {
+		return nil, status.Errorf(codes.FailedPrecondition, "block %x is not finalized", blockID)
+	}
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return proof, nil
+}
+
+// GetEpochProof returns a proof of the transition into the epoch with the
+// given counter, with which light clients can learn the consensus committee of
+// the epoch from the committee of the previous one.
+func (b *backendFinality) GetEpochProof(_ context.Context, counter uint64) (*finality.EpochProof, error) {
+	proof, err := b.epochProver.Prove(counter)
+	if errors.Is(err, finality.ErrUnknownTransition) {
+		return nil, status.Errorf(codes.NotFound, "transition into epoch %d is not known", counter)
+	}
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return proof, nil
+}
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"testing"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/metrics"
+	realprotocol "github.com/onflow/flow-go/state/protocol"
 	protocol "github.com/onflow/flow-go/state/protocol/mock"
 	"github.com/onflow/flow-go/storage"
 	storagemock "github.com/onflow/flow-go/storage/mock"
@@ -82,6 +84,8 @@ func (suite *Suite) TestPing() {
 		suite.execClient,
 		suite.colClient,
 		nil, nil, nil, nil, nil, nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -107,6 +111,8 @@ func (suite *Suite) TestGetLatestFinalizedBlockHeader() {
 		suite.state,
 		suite.execClient,
 		nil, nil, nil, nil, nil, nil, nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -139,6 +145,8 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot() {
 		suite.state,
 		nil, nil, nil, nil, nil,
 		nil, nil, nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -161,6 +169,117 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot() {
 	// suite.assertAllExpectations()
 }
 
+func (suite *Suite) TestGetProtocolStateSnapshotByHeight() {
+	// setup the snapshot mock
+	snap := unittest.RootSnapshotFixture(unittest.CompleteIdentitySet())
+	head, err := snap.Head()
+	suite.Require().NoError(err)
+	suite.state.On("AtHeight", head.Height).Return(snap).Once()
+
+	backend := New(
+		suite.state,
+		nil, nil, nil, nil, nil,
+		nil, nil, nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		100,
+		nil,
+		nil,
+		suite.log,
+	)
+
+	// query the handler for the snapshot at the height
+	bytes, err := backend.GetProtocolStateSnapshotByHeight(context.Background(), head.Height)
+	suite.Require().NoError(err)
+
+	// make sure the returned bytes is equal to the serialized snapshot
+	convertedSnapshot, err := convert.SnapshotToBytes(snap)
+	suite.Require().NoError(err)
+	suite.Require().Equal(bytes, convertedSnapshot)
+}
+
+// TestGetProtocolStateSnapshotByEpoch tests that snapshots of epochs without
+// a finalized block to bootstrap from are not found.
+func (suite *Suite) TestGetProtocolStateSnapshotByEpoch() {
+	root := unittest.BlockHeaderFixture()
+	params := new(protocol.Params)
+	params.On("Root").Return(&root, nil)
+	suite.state.On("Params").Return(params)
+	suite.snapshot.On("Head").Return(&root, nil)
+
+	// the root block is the only finalized block, in epoch 1
+	epoch := new(protocol.Epoch)
+	epoch.On("Counter").Return(uint64(1), nil)
+	epochs := new(protocol.EpochQuery)
+	epochs.On("Current").Return(epoch)
+	suite.snapshot.On("Epochs").Return(epochs)
+	suite.snapshot.On("SealedResult").Return(nil, &flow.Seal{BlockID: root.ID()}, nil)
+	suite.state.On("AtHeight", root.Height).Return(suite.snapshot)
+	suite.state.On("AtBlockID", root.ID()).Return(suite.snapshot)
+
+	backend := New(
+		suite.state,
+		nil, nil, nil, nil, nil,
+		nil, nil, nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		100,
+		nil,
+		nil,
+		suite.log,
+	)
+
+	_, err := backend.GetProtocolStateSnapshotByEpoch(context.Background(), 2)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+}
+
+func (suite *Suite) TestGetNodeIdentityAtHeight() {
+	identity := unittest.IdentityFixture()
+	unknown := unittest.IdentifierFixture()
+
+	history := new(protocol.History)
+	history.On("Identity", identity.NodeID, uint64(10)).Return(identity, nil)
+	history.On("Identity", unknown, uint64(10)).Return(nil, realprotocol.IdentityNotFoundError{NodeID: unknown})
+	history.On("Identity", identity.NodeID, uint64(1000)).Return(nil, fmt.Errorf("height above finalized: %w", storage.ErrNotFound))
+	suite.state.On("History").Return(history)
+
+	backend := New(
+		suite.state,
+		nil, nil, nil, nil, nil,
+		nil, nil, nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+	)
+
+	actual, err := backend.GetNodeIdentityAtHeight(context.Background(), identity.NodeID, 10)
+	suite.checkResponse(actual, err)
+	suite.Require().Equal(identity, actual)
+
+	_, err = backend.GetNodeIdentityAtHeight(context.Background(), unknown, 10)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	_, err = backend.GetNodeIdentityAtHeight(context.Background(), identity.NodeID, 1000)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	history.AssertExpectations(suite.T())
+}
+
 func (suite *Suite) TestGetLatestSealedBlockHeader() {
 	// setup the mocks
 	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
@@ -172,6 +291,8 @@ func (suite *Suite) TestGetLatestSealedBlockHeader() {
 		suite.state,
 		nil, nil, nil, nil, nil,
 		nil, nil, nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -210,6 +331,8 @@ func (suite *Suite) TestGetTransaction() {
 		nil, nil, nil, nil, nil, nil,
 		suite.transactions,
 		nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -244,6 +367,8 @@ func (suite *Suite) TestGetCollection() {
 		suite.collections,
 		suite.transactions,
 		nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -327,6 +452,8 @@ func (suite *Suite) TestTransactionStatusTransition() {
 		suite.collections,
 		suite.transactions,
 		suite.receipts,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		connFactory,
@@ -446,6 +573,8 @@ func (suite *Suite) TestTransactionExpiredStatusTransition() {
 		suite.collections,
 		suite.transactions,
 		nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -607,6 +736,8 @@ func (suite *Suite) TestTransactionPendingToFinalizedStatusTransition() {
 		suite.collections,
 		suite.transactions,
 		suite.receipts,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		connFactory,
@@ -661,6 +792,8 @@ func (suite *Suite) TestTransactionResultUnknown() {
 		nil,
 		suite.transactions,
 		nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -701,6 +834,8 @@ func (suite *Suite) TestGetLatestFinalizedBlock() {
 		nil, nil, nil,
 		suite.blocks,
 		nil, nil, nil, nil,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -824,6 +959,8 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			nil,
 			suite.headers, nil, nil,
 			receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			nil,
@@ -850,6 +987,8 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			nil,
 			suite.headers, nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			connFactory, // the connection factory should be used to get the execution node client
@@ -877,6 +1016,8 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			nil,
 			suite.headers, nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			connFactory, // the connection factory should be used to get the execution node client
@@ -999,6 +1140,8 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			suite.state,
 			nil, nil, nil, nil, suite.headers, nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			nil,
@@ -1033,6 +1176,8 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			suite.headers,
 			nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			nil,
@@ -1066,6 +1211,8 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			suite.headers,
 			nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			nil,
@@ -1098,6 +1245,8 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			suite.headers,
 			nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			nil,
@@ -1130,6 +1279,8 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			suite.headers,
 			nil, nil,
 			suite.receipts,
+			nil,
+			nil,
 			suite.chainID,
 			metrics.NewNoopCollector(),
 			nil,
@@ -1199,6 +1350,8 @@ func (suite *Suite) TestGetAccount() {
 		suite.headers,
 		nil, nil,
 		suite.receipts,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		connFactory,
@@ -1270,6 +1423,8 @@ func (suite *Suite) TestGetAccountAtBlockHeight() {
 		suite.headers,
 		nil, nil,
 		suite.receipts,
+		nil,
+		nil,
 		flow.Testnet,
 		metrics.NewNoopCollector(),
 		nil,
@@ -1298,6 +1453,8 @@ func (suite *Suite) TestGetNetworkParameters() {
 	backend := New(
 		nil, nil, nil, nil, nil, nil, nil, nil,
 		nil,
+		nil,
+		nil,
 		flow.Mainnet,
 		metrics.NewNoopCollector(),
 		nil,
@@ -1313,6 +1470,148 @@ func (suite *Suite) TestGetNetworkParameters() {
 	suite.Require().Equal(expectedChainID, params.ChainID)
 }
 
+// TestGetFinalityProof tests that finality proofs are served for finalized blocks only.
+func (suite *Suite) TestGetFinalityProof() {
+
+	headers := make([]*flow.Header, 0, 5)
+	parent := unittest.BlockHeaderFixture()
+	for _, view := range []uint64{10, 12, 13, 14, 15} {
+		header := unittest.BlockHeaderWithParentFixture(&parent)
+		header.View = view
+		headers = append(headers, &header)
+		parent = header
+	}
+	for _, header := range headers {
+		suite.headers.On("ByBlockID", header.ID()).Return(header, nil)
+		suite.headers.On("ByHeight", header.Height).Return(header, nil)
+	}
+	suite.snapshot.On("Head").Return(headers[4], nil)
+
+	backend := New(
+		suite.state,
+		nil, nil, nil, nil,
+		suite.headers,
+		nil, nil, nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+	)
+
+	suite.Run("finalized block", func() {
+		proof, err := backend.GetFinalityProof(context.Background(), headers[0].ID())
+		suite.checkResponse(proof, err)
+		suite.Require().Equal(headers[:5], proof.Headers)
+	})
+
+	suite.Run("unknown block", func() {
+		blockID := unittest.IdentifierFixture()
+		suite.headers.On("ByBlockID", blockID).Return(nil, storage.ErrNotFound)
+		_, err := backend.GetFinalityProof(context.Background(), blockID)
+		suite.Require().Equal(codes.NotFound, status.Code(err))
+	})
+}
+
+// TestGetEpochProof tests that epoch proofs are not found for epochs whose
+// transition is not known.
+func (suite *Suite) TestGetEpochProof() {
+
+	history := new(protocol.History)
+	history.On("Epoch", uint64(2)).Return(nil, storage.ErrNotFound)
+	suite.state.On("History").Return(history)
+
+	backend := New(
+		suite.state,
+		nil, nil, nil, nil,
+		suite.headers,
+		nil, nil, nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+	)
+
+	_, err := backend.GetEpochProof(context.Background(), 2)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+}
+
+// TestGetAccountTransactions tests the pagination of the transactions of an account.
+func (suite *Suite) TestGetAccountTransactions() {
+
+	address := unittest.RandomAddressFixture()
+	locations := make([]flow.TransactionLocation, 5)
+	for i := range locations {
+		locations[i] = flow.TransactionLocation{
+			TransactionID: unittest.IdentifierFixture(),
+			BlockID:       unittest.IdentifierFixture(),
+			Height:        uint64(100 - i),
+		}
+	}
+
+	transactionLocations := new(storagemock.TransactionLocations)
+	transactionLocations.On("ByAccount", address, (*flow.TransactionLocation)(nil), uint(3)).Return(locations[:3], nil)
+	transactionLocations.
+		On("ByAccount", address, mock.AnythingOfType("*flow.TransactionLocation"), uint(3)).
+		Return(func(_ flow.Address, start *flow.TransactionLocation, _ uint) []flow.TransactionLocation {
+			// the cursor of the first page points to its last transaction
+			suite.Require().Equal(locations[1].Height, start.Height)
+			suite.Require().Equal(locations[1].TransactionID, start.TransactionID)
+			return locations[2:]
+		}, nil)
+
+	backend := New(
+		suite.state,
+		nil, nil, nil, nil, nil, nil, nil, nil,
+		nil,
+		transactionLocations,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+	)
+
+	suite.Run("pages", func() {
+		page, err := backend.GetAccountTransactions(context.Background(), address, 2, "")
+		suite.checkResponse(page, err)
+		suite.Require().Equal(locations[:2], page.Transactions)
+		suite.Require().NotEmpty(page.NextCursor)
+
+		page, err = backend.GetAccountTransactions(context.Background(), address, 2, page.NextCursor)
+		suite.checkResponse(page, err)
+		suite.Require().Equal(locations[2:4], page.Transactions)
+		suite.Require().NotEmpty(page.NextCursor)
+	})
+
+	suite.Run("invalid cursor", func() {
+		_, err := backend.GetAccountTransactions(context.Background(), address, 2, "invalid")
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+		_, err = backend.GetAccountTransactions(context.Background(), address, 2, "00ff")
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	suite.Run("limit exceeded", func() {
+		_, err := backend.GetAccountTransactions(context.Background(), address, MaxAccountTransactionsLimit+1, "")
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
 // TestExecutionNodesForBlockID tests the common method backend.executionNodesForBlockID used for serving all API calls
 // that need to talk to an execution node.
 func (suite *Suite) TestExecutionNodesForBlockID() {
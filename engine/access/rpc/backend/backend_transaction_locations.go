@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// DefaultAccountTransactionsLimit is the number of transactions returned per
+// page of account transactions if no limit is requested.
+const DefaultAccountTransactionsLimit = 50
+
+// MaxAccountTransactionsLimit is the maximum number of transactions returned
+// per page of account transactions.
+const MaxAccountTransactionsLimit = 500
+
+type backendTransactionLocations struct {
+	transactionLocations storage.TransactionLocations
+}
+
+// GetTransactionLocation returns the finalized block which included the
+// transaction with the given ID.
+func (b *backendTransactionLocations) GetTransactionLocation(_ context.Context, id flow.Identifier) (*flow.TransactionLocation, error) {
+	location, err := b.transactionLocations.ByTransactionID(id)
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return location, nil
+}
+
+// GetAccountTransactions returns a page of the finalized transactions which
+// involve the account with the given address. The cursor is empty for the
+// first page, and the next cursor of the previous page otherwise.
+func (b *backendTransactionLocations) GetAccountTransactions(_ context.Context, address flow.Address, limit uint, cursor string) (*access.AccountTransactions, error) {
+	if limit == 0 {
+		limit = DefaultAccountTransactionsLimit
+	}
+	if limit > MaxAccountTransactionsLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit %d exceeds the maximum of %d", limit, MaxAccountTransactionsLimit)
+	}
+
+	var start *flow.TransactionLocation
+	if cursor != "" {
+		var err error
+		start, err = decodeTransactionCursor(cursor)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %v", err)
+		}
+	}
+
+	// look up one more transaction than requested, to know whether there is
+	// a next page
+	locations, err := b.transactionLocations.ByAccount(address, start, limit+1)
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	page := &access.AccountTransactions{
+		Transactions: locations,
+	}
+	if uint(len(locations)) > limit {
+		page.Transactions = locations[:limit]
+		page.NextCursor = encodeTransactionCursor(&locations[limit-1])
+	}
+
+	return page, nil
+}
+
+// encodeTransactionCursor encodes the position of the given transaction in
+// the account transactions as opaque hex string.
+func encodeTransactionCursor(location *flow.TransactionLocation) string {
+	cursor := make([]byte, 8+len(location.TransactionID))
+	binary.BigEndian.PutUint64(cursor, location.Height)
+	copy(cursor[8:], location.TransactionID[:])
+	return hex.EncodeToString(cursor)
+}
+
+// decodeTransactionCursor decodes a cursor encoded by encodeTransactionCursor.
+func decodeTransactionCursor(cursor string) (*flow.TransactionLocation, error) {
+	data, err := hex.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var location flow.TransactionLocation
+	if len(data) != 8+len(location.TransactionID) {
+		return nil, fmt.Errorf("invalid length %d", len(data))
+	}
+	location.Height = binary.BigEndian.Uint64(data)
+	copy(location.TransactionID[:], data[8:])
+	return &location, nil
+}
@@ -47,6 +47,8 @@ func (suite *Suite) TestHistoricalTransactionResult() {
 		suite.collections,
 		suite.transactions,
 		suite.receipts,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
@@ -104,6 +106,8 @@ func (suite *Suite) TestHistoricalTransaction() {
 		suite.collections,
 		suite.transactions,
 		suite.receipts,
+		nil,
+		nil,
 		suite.chainID,
 		metrics.NewNoopCollector(),
 		nil,
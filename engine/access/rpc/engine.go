@@ -64,6 +64,8 @@ func New(log zerolog.Logger,
 	collections storage.Collections,
 	transactions storage.Transactions,
 	executionReceipts storage.ExecutionReceipts,
+	executionResults storage.ExecutionResults,
+	transactionLocations storage.TransactionLocations,
 	chainID flow.ChainID,
 	transactionMetrics module.TransactionMetrics,
 	collectionGRPCPort uint,
@@ -110,9 +112,6 @@ func New(log zerolog.Logger,
 
 	grpcServer := grpc.NewServer(grpcOpts...)
 
-	// wrap the GRPC server with an HTTP proxy server to serve HTTP clients
-	httpServer := NewHTTPServer(grpcServer, config.HTTPListenAddr)
-
 	connectionFactory := &backend.ConnectionFactoryImpl{
 		CollectionGRPCPort:        collectionGRPCPort,
 		ExecutionGRPCPort:         executionGRPCPort,
@@ -130,6 +129,8 @@ func New(log zerolog.Logger,
 		collections,
 		transactions,
 		executionReceipts,
+		executionResults,
+		transactionLocations,
 		chainID,
 		transactionMetrics,
 		connectionFactory,
@@ -140,6 +141,9 @@ func New(log zerolog.Logger,
 		log,
 	)
 
+	// wrap the GRPC server with an HTTP proxy server to serve HTTP clients
+	httpServer := NewHTTPServer(log, grpcServer, backend, config.HTTPListenAddr)
+
 	eng := &Engine{
 		log:        log,
 		unit:       engine.NewUnit(),
@@ -0,0 +1,102 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// epochsPath is the HTTP path under which the epochs known to the node are
+// served. Without query parameters, all epochs are served, otherwise the epoch
+// of the finalized block at the given height, e.g. /v1/epochs?height=<height>.
+const epochsPath = "/v1/epochs"
+
+// identitiesPath is the HTTP path under which the identities of nodes are
+// served, followed by the hex-encoded node ID and the height of the finalized
+// block to resolve the identity at, e.g. /v1/identities/<node ID>?height=<height>.
+const identitiesPath = "/v1/identities/"
+
+// epochsHandler serves the JSON-encoded epochs known to the node, with their
+// height boundaries, participants, cluster assignments and DKG keys.
+func epochsHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var epochs interface{}
+		var err error
+		if height := req.URL.Query().Get("height"); height != "" {
+			h, perr := strconv.ParseUint(height, 10, 64)
+			if perr != nil {
+				http.Error(res, "invalid height", http.StatusBadRequest)
+				return
+			}
+			epochs, err = api.GetEpochAtHeight(req.Context(), h)
+		} else {
+			epochs, err = api.GetEpochs(req.Context())
+		}
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(epochs)
+		if err != nil {
+			log.Error().Err(err).Msg("could not encode epochs")
+		}
+	}
+}
+
+// identityHandler serves the JSON-encoded identity of a node, with its role
+// and stake, in the epoch of the finalized block at the given height.
+func identityHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		nodeID, err := flow.HexStringToIdentifier(strings.TrimPrefix(req.URL.Path, identitiesPath))
+		if err != nil {
+			http.Error(res, "invalid node ID", http.StatusBadRequest)
+			return
+		}
+		height, err := strconv.ParseUint(req.URL.Query().Get("height"), 10, 64)
+		if err != nil {
+			http.Error(res, "invalid height", http.StatusBadRequest)
+			return
+		}
+
+		identity, err := api.GetNodeIdentityAtHeight(req.Context(), nodeID, height)
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(identity)
+		if err != nil {
+			log.Error().Err(err).Hex("node_id", nodeID[:]).Msg("could not encode identity")
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// finalityProofPath is the HTTP path under which finality proofs are served,
+// followed by the hex-encoded block ID, e.g. /v1/finality_proofs/<block ID>.
+const finalityProofPath = "/v1/finality_proofs/"
+
+// headersPath is the HTTP path under which the headers of finalized blocks are
+// served, starting at the given height, e.g. /v1/headers?height=<height>&limit=<limit>.
+const headersPath = "/v1/headers"
+
+// epochProofPath is the HTTP path under which epoch proofs are served,
+// followed by the epoch counter, e.g. /v1/epoch_proofs/<counter>.
+const epochProofPath = "/v1/epoch_proofs/"
+
+// The default and the maximum number of headers served at once.
+const (
+	defaultHeadersLimit = 50
+	maxHeadersLimit     = 250
+)
+
+// finalityProofHandler serves JSON-encoded finality proofs for finalized
+// blocks, so that light clients can verify finalization without a gRPC client.
+func finalityProofHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		blockID, err := flow.HexStringToIdentifier(strings.TrimPrefix(req.URL.Path, finalityProofPath))
+		if err != nil {
+			http.Error(res, "invalid block ID", http.StatusBadRequest)
+			return
+		}
+
+		proof, err := api.GetFinalityProof(req.Context(), blockID)
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(proof)
+		if err != nil {
+			log.Error().Err(err).Hex("block_id", blockID[:]).Msg("could not encode finality proof")
+		}
+	}
+}
+
+// headersHandler serves the JSON-encoded headers of consecutive finalized
+// blocks, including the QCs for their parents, so that light clients can
+// follow the chain without downloading payloads. Fewer headers than requested
+// are served once the finalized height is reached.
+func headersHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := req.URL.Query()
+		height, err := strconv.ParseUint(query.Get("height"), 10, 64)
+		if err != nil {
+			http.Error(res, "invalid height", http.StatusBadRequest)
+			return
+		}
+		limit := uint64(defaultHeadersLimit)
+		if l := query.Get("limit"); l != "" {
+			limit, err = strconv.ParseUint(l, 10, 64)
+			if err != nil || limit == 0 {
+				http.Error(res, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+		if limit > maxHeadersLimit {
+			limit = maxHeadersLimit
+		}
+
+		headers := make([]*flow.Header, 0, limit)
+		for h := height; h < height+limit; h++ {
+			header, err := api.GetBlockHeaderByHeight(req.Context(), h)
+			if status.Code(err) == codes.NotFound {
+				break
+			}
+			if err != nil {
+				http.Error(res, status.Convert(err).Message(), httpStatus(err))
+				return
+			}
+			headers = append(headers, header)
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(headers)
+		if err != nil {
+			log.Error().Err(err).Uint64("height", height).Msg("could not encode headers")
+		}
+	}
+}
+
+// epochProofHandler serves JSON-encoded proofs of epoch transitions, so that
+// light clients can learn the consensus committee of each epoch.
+func epochProofHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		counter, err := strconv.ParseUint(strings.TrimPrefix(req.URL.Path, epochProofPath), 10, 64)
+		if err != nil {
+			http.Error(res, "invalid epoch counter", http.StatusBadRequest)
+			return
+		}
+
+		proof, err := api.GetEpochProof(req.Context(), counter)
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(proof)
+		if err != nil {
+			log.Error().Err(err).Uint64("counter", counter).Msg("could not encode epoch proof")
+		}
+	}
+}
+
+// httpStatus maps the gRPC status code of an Access API error to an HTTP
+// status code.
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+
+	"github.com/onflow/flow-go/access"
 )
 
 type HTTPHeader struct {
@@ -27,9 +30,12 @@ var defaultHTTPHeaders = []HTTPHeader{
 	},
 }
 
-// NewHTTPServer creates and intializes a new HTTP GRPC proxy server
+// NewHTTPServer creates and intializes a new HTTP GRPC proxy server, which
+// additionally serves the JSON endpoints of the given Access API
 func NewHTTPServer(
+	log zerolog.Logger,
 	grpcServer *grpc.Server,
+	api access.API,
 	address string,
 ) *http.Server {
 	wrappedServer := grpcweb.WrapServer(
@@ -42,6 +48,16 @@ func NewHTTPServer(
 	// register gRPC HTTP proxy
 	mux.Handle("/", wrappedHandler(wrappedServer, defaultHTTPHeaders))
 
+	// register JSON endpoints
+	mux.Handle(finalityProofPath, finalityProofHandler(log, api))
+	mux.Handle(headersPath, headersHandler(log, api))
+	mux.Handle(epochProofPath, epochProofHandler(log, api))
+	mux.Handle(transactionLocationPath, transactionLocationHandler(log, api))
+	mux.Handle(accountTransactionsPath, accountTransactionsHandler(log, api))
+	mux.Handle(protocolStateSnapshotPath, protocolStateSnapshotHandler(log, api))
+	mux.Handle(epochsPath, epochsHandler(log, api))
+	mux.Handle(identitiesPath, identityHandler(log, api))
+
 	httpServer := &http.Server{
 		Addr:    address,
 		Handler: mux,
@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+)
+
+// protocolStateSnapshotPath is the HTTP path under which protocol state
+// snapshots are served. Without query parameters, the latest sealed snapshot
+// is served, otherwise the snapshot at the given height or at the start of
+// the given epoch, e.g. /v1/protocol_state_snapshot?height=<height> or
+// /v1/protocol_state_snapshot?epoch=<counter>.
+const protocolStateSnapshotPath = "/v1/protocol_state_snapshot"
+
+// protocolStateSnapshotHandler serves JSON-encoded protocol state snapshots,
+// which new nodes download to bootstrap from the current state of the network.
+func protocolStateSnapshotHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := req.URL.Query()
+		height, epoch := query.Get("height"), query.Get("epoch")
+
+		var data []byte
+		var err error
+		switch {
+		case height != "" && epoch != "":
+			http.Error(res, "only one of height and epoch can be given", http.StatusBadRequest)
+			return
+		case height != "":
+			h, perr := strconv.ParseUint(height, 10, 64)
+			if perr != nil {
+				http.Error(res, "invalid height", http.StatusBadRequest)
+				return
+			}
+			data, err = api.GetProtocolStateSnapshotByHeight(req.Context(), h)
+		case epoch != "":
+			counter, perr := strconv.ParseUint(epoch, 10, 64)
+			if perr != nil {
+				http.Error(res, "invalid epoch", http.StatusBadRequest)
+				return
+			}
+			data, err = api.GetProtocolStateSnapshotByEpoch(req.Context(), counter)
+		default:
+			data, err = api.GetLatestProtocolStateSnapshot(req.Context())
+		}
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		_, err = res.Write(data)
+		if err != nil {
+			log.Error().Err(err).Msg("could not write protocol state snapshot")
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// transactionLocationPath is the HTTP path under which the locations of
+// transactions are served, followed by the hex-encoded transaction ID, e.g.
+// /v1/transaction_locations/<transaction ID>.
+const transactionLocationPath = "/v1/transaction_locations/"
+
+// accountTransactionsPath is the HTTP path under which the transactions of
+// accounts are served, e.g. /v1/accounts/<address>/transactions, with the
+// optional query parameters limit and cursor.
+const accountTransactionsPath = "/v1/accounts/"
+
+// transactionLocationHandler serves the JSON-encoded location of finalized
+// transactions.
+func transactionLocationHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		txID, err := flow.HexStringToIdentifier(strings.TrimPrefix(req.URL.Path, transactionLocationPath))
+		if err != nil {
+			http.Error(res, "invalid transaction ID", http.StatusBadRequest)
+			return
+		}
+
+		location, err := api.GetTransactionLocation(req.Context(), txID)
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(location)
+		if err != nil {
+			log.Error().Err(err).Hex("tx_id", txID[:]).Msg("could not encode transaction location")
+		}
+	}
+}
+
+// accountTransactionsHandler serves JSON-encoded pages of the finalized
+// transactions involving an account, from the newest to the oldest.
+func accountTransactionsHandler(log zerolog.Logger, api access.API) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		setResponseHeaders(res, defaultHTTPHeaders)
+
+		if req.Method == "OPTIONS" {
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		path := strings.TrimPrefix(req.URL.Path, accountTransactionsPath)
+		if !strings.HasSuffix(path, "/transactions") {
+			http.NotFound(res, req)
+			return
+		}
+		address, err := parseAddress(strings.TrimSuffix(path, "/transactions"))
+		if err != nil {
+			http.Error(res, "invalid address", http.StatusBadRequest)
+			return
+		}
+
+		var limit uint64
+		query := req.URL.Query()
+		if query.Get("limit") != "" {
+			limit, err = strconv.ParseUint(query.Get("limit"), 10, 32)
+			if err != nil {
+				http.Error(res, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		page, err := api.GetAccountTransactions(req.Context(), address, uint(limit), query.Get("cursor"))
+		if err != nil {
+			http.Error(res, status.Convert(err).Message(), httpStatus(err))
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(res).Encode(page)
+		if err != nil {
+			log.Error().Err(err).Str("address", address.Hex()).Msg("could not encode account transactions")
+		}
+	}
+}
+
+// parseAddress parses a hex-encoded account address, with or without the 0x
+// prefix.
+func parseAddress(s string) (flow.Address, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+	if len(b) == 0 || len(b) > flow.AddressLength {
+		return flow.EmptyAddress, fmt.Errorf("invalid address length %d", len(b))
+	}
+	return flow.BytesToAddress(b), nil
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network"
+)
+
+// senderRoleMap lists, for each channel, the roles which are authorized to
+// send each message type on it. The message types are given in the format of
+// the `Type` field of network messages.
+// Note: Please update this map, if a new message type is sent on a channel or
+// the roles sending a message type have changed.
+var senderRoleMap map[network.Channel]map[string]flow.RoleList
+
+// SenderRolesByChannel returns the roles which are authorized to send messages
+// of the given type on the given channel. Channels which don't restrict their
+// message types fall back to the roles participating in the channel. It
+// returns false if no role is authorized to send the message type on the
+// channel.
+func SenderRolesByChannel(channel network.Channel, messageType string) (flow.RoleList, bool) {
+	if clusterChannel, isCluster := ClusterChannel(channel); isCluster {
+		// replaces channel with the stripped-off prefix
+		channel = clusterChannel
+	}
+	senders, restricted := senderRoleMap[channel]
+	if !restricted {
+		return RolesByChannel(channel)
+	}
+	roles, ok := senders[messageType]
+	return roles, ok
+}
+
+// initializeSenderRoleMap initializes an instance of senderRoleMap and
+// populates it with the message types of each channel and the roles
+// authorized to send them.
+func initializeSenderRoleMap() {
+	all := flow.RoleList{flow.RoleCollection, flow.RoleConsensus, flow.RoleExecution, flow.RoleVerification,
+		flow.RoleAccess}
+
+	senderRoleMap = make(map[network.Channel]map[string]flow.RoleList)
+
+	// Channels for consensus protocols
+	senderRoleMap[ConsensusCommittee] = map[string]flow.RoleList{
+		"messages.BlockProposal": {flow.RoleConsensus},
+		"messages.BlockVote":     {flow.RoleConsensus},
+	}
+	senderRoleMap[consensusClusterPrefix] = map[string]flow.RoleList{
+		"messages.ClusterBlockProposal": {flow.RoleCollection},
+		"messages.ClusterBlockVote":     {flow.RoleCollection},
+	}
+
+	// Channels for protocols actively synchronizing state across nodes; all
+	// nodes follow the main chain, so they all request and serve blocks
+	senderRoleMap[SyncCommittee] = map[string]flow.RoleList{
+		"messages.SyncRequest":   all,
+		"messages.SyncResponse":  all,
+		"messages.RangeRequest":  all,
+		"messages.BatchRequest":  all,
+		"messages.BlockResponse": all,
+	}
+	senderRoleMap[syncClusterPrefix] = map[string]flow.RoleList{
+		"messages.SyncRequest":          {flow.RoleCollection},
+		"messages.SyncResponse":         {flow.RoleCollection},
+		"messages.RangeRequest":         {flow.RoleCollection},
+		"messages.BatchRequest":         {flow.RoleCollection},
+		"messages.ClusterBlockResponse": {flow.RoleCollection},
+	}
+	senderRoleMap[SyncExecution] = map[string]flow.RoleList{
+		"messages.ExecutionStateSyncRequest": {flow.RoleExecution},
+		"messages.ExecutionStateDelta":       {flow.RoleExecution},
+	}
+
+	// Channels for actively pushing entities to subscribers
+	senderRoleMap[PushTransactions] = map[string]flow.RoleList{
+		"flow.TransactionBody": {flow.RoleCollection},
+	}
+	senderRoleMap[PushGuarantees] = map[string]flow.RoleList{
+		"flow.CollectionGuarantee": {flow.RoleCollection, flow.RoleConsensus},
+	}
+	senderRoleMap[PushBlocks] = map[string]flow.RoleList{
+		"messages.BlockProposal": {flow.RoleConsensus},
+	}
+	senderRoleMap[PushReceipts] = map[string]flow.RoleList{
+		"flow.ExecutionReceipt": {flow.RoleExecution},
+	}
+	senderRoleMap[PushApprovals] = map[string]flow.RoleList{
+		"flow.ResultApproval": {flow.RoleVerification},
+	}
+
+	// Channels for actively requesting missing entities
+	senderRoleMap[RequestCollections] = map[string]flow.RoleList{
+		"messages.EntityRequest":  {flow.RoleExecution, flow.RoleAccess},
+		"messages.EntityResponse": {flow.RoleCollection},
+	}
+	senderRoleMap[RequestChunks] = map[string]flow.RoleList{
+		"messages.ChunkDataRequest":  {flow.RoleVerification},
+		"messages.ChunkDataResponse": {flow.RoleExecution},
+	}
+	senderRoleMap[RequestReceiptsByBlockID] = map[string]flow.RoleList{
+		"messages.EntityRequest":  {flow.RoleConsensus},
+		"messages.EntityResponse": {flow.RoleExecution},
+	}
+	senderRoleMap[RequestApprovalsByChunk] = map[string]flow.RoleList{
+		"messages.ApprovalRequest":  {flow.RoleConsensus},
+		"messages.ApprovalResponse": {flow.RoleVerification},
+	}
+}
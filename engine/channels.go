@@ -14,6 +14,7 @@ import (
 // It creates and initializes the channelRoleMap map.
 func init() {
 	initializeChannelRoleMap()
+	initializeSenderRoleMap()
 }
 
 // channelRoleMap keeps a map between channels and the list of flow roles involved in them.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/onflow/flow-go/engine/collection/epochmgr"
+	"github.com/onflow/flow-go/engine/collection/health"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/mempool/epochs"
 	chainsync "github.com/onflow/flow-go/module/synchronization"
@@ -21,6 +22,7 @@ type EpochComponentsFactory struct {
 	hotstuff *HotStuffFactory
 	proposal *ProposalEngineFactory
 	sync     *SyncEngineFactory
+	health   *health.Monitor
 }
 
 func NewEpochComponentsFactory(
@@ -31,6 +33,7 @@ func NewEpochComponentsFactory(
 	hotstuff *HotStuffFactory,
 	proposal *ProposalEngineFactory,
 	sync *SyncEngineFactory,
+	health *health.Monitor,
 ) *EpochComponentsFactory {
 
 	factory := &EpochComponentsFactory{
@@ -41,6 +44,7 @@ func NewEpochComponentsFactory(
 		hotstuff: hotstuff,
 		proposal: proposal,
 		sync:     sync,
+		health:   health,
 	}
 	return factory
 }
@@ -131,6 +135,9 @@ func (factory *EpochComponentsFactory) Create(
 		err = fmt.Errorf("could not create sync engine: %w", err)
 		return
 	}
+	// track the health of the cluster consensus
+	status := factory.health.Track(counter, cluster.ChainID(), cluster.Members(), state, pool)
+
 	hotstuff, err = factory.hotstuff.Create(
 		epoch,
 		cluster,
@@ -140,6 +147,7 @@ func (factory *EpochComponentsFactory) Create(
 		builder,
 		finalizer,
 		proposalEng,
+		status,
 	)
 	if err != nil {
 		err = fmt.Errorf("could not create hotstuff: %w", err)
@@ -60,6 +60,7 @@ func (f *HotStuffFactory) Create(
 	builder module.Builder,
 	updater module.Finalizer,
 	communicator hotstuff.Communicator,
+	consumers ...hotstuff.Consumer,
 ) (*hotstuff.EventLoop, error) {
 
 	// setup metrics/logging with the new chain ID
@@ -68,6 +69,9 @@ func (f *HotStuffFactory) Create(
 	notifier.AddConsumer(notifications.NewLogConsumer(f.log))
 	notifier.AddConsumer(hotmetrics.NewMetricsConsumer(metrics))
 	notifier.AddConsumer(notifications.NewTelemetryConsumer(f.log, cluster.ChainID()))
+	for _, consumer := range consumers {
+		notifier.AddConsumer(consumer)
+	}
 	builder = blockproducer.NewMetricsWrapper(builder, metrics) // wrapper for measuring time spent building block payload component
 
 	var committee hotstuff.Committee
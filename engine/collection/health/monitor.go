@@ -0,0 +1,95 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/mempool"
+	"github.com/onflow/flow-go/state/cluster"
+)
+
+// DefaultLivenessWindow is the default time after which we consider a cluster
+// member from which we didn't see any consensus messages as not live.
+const DefaultLivenessWindow = 2 * time.Minute
+
+// Monitor keeps track of the health of the cluster consensus of the epochs
+// this node participates in. As there is at most one cluster consensus running
+// for the current and one for the next epoch, it only keeps the statuses for
+// the two most recent epochs.
+type Monitor struct {
+	metrics  module.CollectionMetrics
+	liveness time.Duration
+
+	mu       sync.RWMutex
+	statuses map[uint64]*Status
+}
+
+// NewMonitor creates a new cluster health monitor, which considers members
+// live if they were seen within the given liveness window.
+func NewMonitor(metrics module.CollectionMetrics, liveness time.Duration) *Monitor {
+	m := &Monitor{
+		metrics:  metrics,
+		liveness: liveness,
+		statuses: make(map[uint64]*Status),
+	}
+	return m
+}
+
+// Track starts tracking the health of the cluster consensus for the epoch with
+// the given counter. The returned status must be registered as consumer of the
+// cluster's HotStuff notifications.
+func (m *Monitor) Track(
+	counter uint64,
+	chainID flow.ChainID,
+	members flow.IdentityList,
+	state cluster.State,
+	pool mempool.Transactions,
+) *Status {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := NewStatus(m.metrics, counter, chainID, members, state, pool, m.liveness)
+	m.statuses[counter] = status
+
+	// forget about the clusters of past epochs
+	for tracked := range m.statuses {
+		if tracked+1 < counter {
+			delete(m.statuses, tracked)
+		}
+	}
+
+	return status
+}
+
+// Reports returns the health reports for all tracked cluster consensus
+// instances, ordered by epoch.
+func (m *Monitor) Reports() ([]*Report, error) {
+
+	m.mu.RLock()
+	counters := make([]uint64, 0, len(m.statuses))
+	for counter := range m.statuses {
+		counters = append(counters, counter)
+	}
+	statuses := make(map[uint64]*Status, len(m.statuses))
+	for counter, status := range m.statuses {
+		statuses[counter] = status
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(counters, func(i, j int) bool { return counters[i] < counters[j] })
+	reports := make([]*Report, 0, len(counters))
+	for _, counter := range counters {
+		report, err := statuses[counter].Report()
+		if err != nil {
+			return nil, fmt.Errorf("could not get health report for epoch %d: %w", counter, err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/model/flow"
+	mempool "github.com/onflow/flow-go/module/mempool/mock"
+	module "github.com/onflow/flow-go/module/mock"
+	cluster "github.com/onflow/flow-go/state/cluster/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestMonitor(t *testing.T) {
+
+	members := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleCollection))
+	chainID := flow.ChainID("cluster")
+
+	final := unittest.BlockHeaderFixture()
+	snapshot := &cluster.Snapshot{}
+	snapshot.On("Head").Return(&final, nil)
+	state := &cluster.State{}
+	state.On("Final").Return(snapshot)
+
+	pool := &mempool.Transactions{}
+	pool.On("Size").Return(uint(7))
+
+	metrics := &module.CollectionMetrics{}
+	metrics.On("ClusterViewChanged", chainID, uint64(10)).Once()
+	metrics.On("ClusterPendingTransactions", chainID, uint(7)).Once()
+	metrics.On("ClusterLiveMembers", chainID, uint(3)).Once()
+
+	monitor := NewMonitor(metrics, time.Minute)
+	status := monitor.Track(1, chainID, members, state, pool)
+
+	// the proposer and QC signers of incorporated blocks are live
+	status.OnBlockIncorporated(&model.Block{
+		ProposerID: members[0].NodeID,
+		QC:         &flow.QuorumCertificate{SignerIDs: []flow.Identifier{members[0].NodeID, members[1].NodeID}},
+	})
+	status.OnReceiveVote(10, &model.Vote{SignerID: members[2].NodeID})
+	status.OnEnteringView(10, members[0].NodeID)
+	metrics.AssertExpectations(t)
+
+	// tracking later epochs drops the statuses of past epochs
+	monitor.Track(2, chainID, members, state, pool)
+	monitor.Track(3, chainID, members, state, pool)
+
+	reports, err := monitor.Reports()
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, uint64(2), reports[0].Epoch)
+	assert.Equal(t, uint64(3), reports[1].Epoch)
+
+	// the report of the tracked epoch reflects the cluster state
+	monitor = NewMonitor(metrics, time.Minute)
+	status = monitor.Track(1, chainID, members, state, pool)
+	status.OnReceiveProposal(10, &model.Proposal{Block: &model.Block{ProposerID: members[3].NodeID}})
+
+	reports, err = monitor.Reports()
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	report := reports[0]
+	assert.Equal(t, chainID, report.ChainID)
+	assert.Equal(t, final.Height, report.FinalizedHeight)
+	assert.Equal(t, final.View, report.FinalizedView)
+	assert.Equal(t, uint(7), report.PendingTransactions)
+	require.Len(t, report.Members, 4)
+	for i, member := range report.Members {
+		assert.Equal(t, members[i].NodeID, member.NodeID)
+		assert.Equal(t, i == 3, member.Live)
+	}
+}
@@ -0,0 +1,26 @@
+package health
+
+import (
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Report describes the health of the cluster consensus of one epoch, as seen
+// by this collection node.
+type Report struct {
+	Epoch               uint64         `json:"epoch"`
+	ChainID             flow.ChainID   `json:"chain_id"`
+	View                uint64         `json:"view"`
+	FinalizedHeight     uint64         `json:"finalized_height"`
+	FinalizedView       uint64         `json:"finalized_view"`
+	PendingTransactions uint           `json:"pending_transactions"`
+	Members             []MemberReport `json:"members"`
+}
+
+// MemberReport describes the liveness of a cluster member.
+type MemberReport struct {
+	NodeID   flow.Identifier `json:"node_id"`
+	LastSeen time.Time       `json:"last_seen"`
+	Live     bool            `json:"live"`
+}
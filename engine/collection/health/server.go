@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+)
+
+// ReportPath is the HTTP path under which the cluster health reports are served.
+const ReportPath = "/v1/cluster_health"
+
+// Server serves the cluster health reports of the monitor as JSON over HTTP.
+type Server struct {
+	unit    *engine.Unit
+	log     zerolog.Logger
+	monitor *Monitor
+	server  *http.Server
+}
+
+// NewServer creates a new cluster health server listening on the given address.
+func NewServer(log zerolog.Logger, address string, monitor *Monitor) *Server {
+
+	s := &Server{
+		unit:    engine.NewUnit(),
+		log:     log.With().Str("component", "cluster_health").Str("address", address).Logger(),
+		monitor: monitor,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReportPath, s.handleReports)
+	s.server = &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	return s
+}
+
+// Ready returns a ready channel that is closed once the server has started.
+func (s *Server) Ready() <-chan struct{} {
+	s.unit.Launch(s.serve)
+	return s.unit.Ready()
+}
+
+// Done returns a done channel that is closed once the server has stopped.
+func (s *Server) Done() <-chan struct{} {
+	return s.unit.Done(func() {
+		err := s.server.Shutdown(context.Background())
+		if err != nil {
+			s.log.Error().Err(err).Msg("error stopping cluster health server")
+		}
+	})
+}
+
+func (s *Server) serve() {
+	s.log.Info().Msg("starting cluster health server")
+
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return
+	}
+	if err != nil {
+		s.log.Err(err).Msg("failed to start the cluster health server")
+	}
+}
+
+func (s *Server) handleReports(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reports, err := s.monitor.Reports()
+	if err != nil {
+		s.log.Error().Err(err).Msg("could not get cluster health reports")
+		http.Error(res, "could not get cluster health reports", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(res).Encode(reports)
+	if err != nil {
+		s.log.Error().Err(err).Msg("could not encode cluster health reports")
+	}
+}
@@ -0,0 +1,148 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/consensus/hotstuff/notifications"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/mempool"
+	"github.com/onflow/flow-go/state/cluster"
+)
+
+// Status tracks the health of the cluster consensus of a single epoch. It is
+// registered as a HotStuff notification consumer with the cluster consensus,
+// from which it learns about the current view and the liveness of the other
+// cluster members. A member is considered live if we saw a proposal, vote or
+// QC signature of the member within the liveness window.
+type Status struct {
+	notifications.NoopConsumer
+
+	metrics  module.CollectionMetrics
+	counter  uint64
+	chainID  flow.ChainID
+	members  flow.IdentityList
+	state    cluster.State
+	pool     mempool.Transactions
+	liveness time.Duration
+
+	mu       sync.RWMutex
+	view     uint64
+	lastSeen map[flow.Identifier]time.Time
+}
+
+// NewStatus creates a new status tracker for the cluster with the given chain
+// ID and members, with the given cluster state and transaction pool.
+func NewStatus(
+	metrics module.CollectionMetrics,
+	counter uint64,
+	chainID flow.ChainID,
+	members flow.IdentityList,
+	state cluster.State,
+	pool mempool.Transactions,
+	liveness time.Duration,
+) *Status {
+
+	s := &Status{
+		metrics:  metrics,
+		counter:  counter,
+		chainID:  chainID,
+		members:  members,
+		state:    state,
+		pool:     pool,
+		liveness: liveness,
+		lastSeen: make(map[flow.Identifier]time.Time),
+	}
+	return s
+}
+
+// OnEnteringView updates the current view of the cluster consensus.
+func (s *Status) OnEnteringView(view uint64, _ flow.Identifier) {
+	s.mu.Lock()
+	s.view = view
+	live := s.liveMembers(time.Now())
+	s.mu.Unlock()
+
+	s.metrics.ClusterViewChanged(s.chainID, view)
+	s.metrics.ClusterPendingTransactions(s.chainID, s.pool.Size())
+	s.metrics.ClusterLiveMembers(s.chainID, live)
+}
+
+// OnBlockIncorporated marks the proposer of the block, as well as the signers
+// of the QC it contains, as live.
+func (s *Status) OnBlockIncorporated(block *model.Block) {
+	s.seen(block.ProposerID)
+	if block.QC != nil {
+		s.seen(block.QC.SignerIDs...)
+	}
+}
+
+// OnReceiveProposal marks the proposer of the proposal as live.
+func (s *Status) OnReceiveProposal(_ uint64, proposal *model.Proposal) {
+	s.seen(proposal.Block.ProposerID)
+}
+
+// OnReceiveVote marks the signer of the vote as live. Votes are only sent to
+// the leader of the next view, so we only receive votes when we are leader.
+func (s *Status) OnReceiveVote(_ uint64, vote *model.Vote) {
+	s.seen(vote.SignerID)
+}
+
+// Report returns a report of the current health of the cluster.
+func (s *Status) Report() (*Report, error) {
+
+	final, err := s.state.Final().Head()
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized cluster header: %w", err)
+	}
+
+	now := time.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := &Report{
+		Epoch:               s.counter,
+		ChainID:             s.chainID,
+		View:                s.view,
+		FinalizedHeight:     final.Height,
+		FinalizedView:       final.View,
+		PendingTransactions: s.pool.Size(),
+		Members:             make([]MemberReport, 0, len(s.members)),
+	}
+	for _, member := range s.members {
+		lastSeen, ok := s.lastSeen[member.NodeID]
+		report.Members = append(report.Members, MemberReport{
+			NodeID:   member.NodeID,
+			LastSeen: lastSeen,
+			Live:     ok && now.Sub(lastSeen) <= s.liveness,
+		})
+	}
+
+	return report, nil
+}
+
+// seen marks the given cluster members as live.
+func (s *Status) seen(nodeIDs ...flow.Identifier) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, nodeID := range nodeIDs {
+		s.lastSeen[nodeID] = now
+	}
+}
+
+// liveMembers returns the number of members seen within the liveness window;
+// must be called while holding the lock.
+func (s *Status) liveMembers(now time.Time) uint {
+	var live uint
+	for _, member := range s.members {
+		lastSeen, ok := s.lastSeen[member.NodeID]
+		if ok && now.Sub(lastSeen) <= s.liveness {
+			live++
+		}
+	}
+	return live
+}
@@ -0,0 +1,400 @@
+package light
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/checkpointsync"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// Config is the configuration of the light follower.
+type Config struct {
+	Interval  time.Duration // the interval between polls of the source
+	BatchSize uint          // the maximum number of headers requested at once
+}
+
+// DefaultConfig returns the default configuration of the light follower.
+func DefaultConfig() Config {
+	return Config{
+		Interval:  5 * time.Second,
+		BatchSize: 100,
+	}
+}
+
+// epoch is an epoch known to the light follower, along with the verifier of
+// its QCs, which is created on first use.
+type epoch struct {
+	setup    *flow.EpochSetup
+	commit   *flow.EpochCommit // nil if the epoch is only set up
+	verifier *finality.Verifier
+}
+
+// Follower follows the finalized chain in header-only mode, for light clients
+// such as bridges and wallets. It downloads the finalized headers, which carry
+// the QCs for their parents, from an untrusted source, verifies the QCs against
+// the consensus committee of their epoch and stores the headers once they are
+// finalized by a direct 3-chain. Payloads are never stored. The committees of
+// later epochs are learned from the sealed service events of each transition,
+// starting with the epochs of the trusted snapshot the database was
+// bootstrapped with.
+type Follower struct {
+	unit      *engine.Unit
+	log       zerolog.Logger
+	db        *badger.DB
+	source    Source
+	verifiers checkpointsync.VerifierFactory
+	config    Config
+	epochs    []*epoch // the known epochs, ordered by counter; only the last one might not be committed
+}
+
+// New creates a new light follower on a database initialized with Bootstrap.
+func New(log zerolog.Logger, db *badger.DB, source Source, verifiers checkpointsync.VerifierFactory, config Config) (*Follower, error) {
+
+	var epochs []*epoch
+	err := db.View(func(tx *badger.Txn) error {
+		var err error
+		epochs, err = retrieveEpochs(tx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve epochs: %w", err)
+	}
+	if len(epochs) == 0 || epochs[0].commit == nil {
+		return nil, fmt.Errorf("database is not bootstrapped")
+	}
+
+	f := &Follower{
+		unit:      engine.NewUnit(),
+		log:       log.With().Str("engine", "light_follower").Logger(),
+		db:        db,
+		source:    source,
+		verifiers: verifiers,
+		config:    config,
+		epochs:    epochs,
+	}
+
+	return f, nil
+}
+
+// Ready returns a ready channel that is closed once the follower has started
+// polling its source.
+func (f *Follower) Ready() <-chan struct{} {
+	f.unit.LaunchPeriodically(f.poll, f.config.Interval, 0)
+	return f.unit.Ready()
+}
+
+// Done returns a done channel that is closed once the follower has stopped.
+func (f *Follower) Done() <-chan struct{} {
+	return f.unit.Done()
+}
+
+// Head returns the header of the latest finalized block.
+func (f *Follower) Head() (*flow.Header, error) {
+	var header flow.Header
+	err := f.db.View(func(tx *badger.Txn) error {
+		var height uint64
+		err := operation.RetrieveFinalizedHeight(&height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized height: %w", err)
+		}
+		return retrieveFinalized(height, &header)(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// ByHeight returns the header of the finalized block with the given height.
+// It returns storage.ErrNotFound if no such block was finalized yet.
+func (f *Follower) ByHeight(height uint64) (*flow.Header, error) {
+	var header flow.Header
+	err := f.db.View(retrieveFinalized(height, &header))
+	if err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// poll synchronizes with the source until no more progress is made.
+func (f *Follower) poll() {
+	for {
+		select {
+		case <-f.unit.Quit():
+			return
+		default:
+		}
+
+		progress, err := f.Sync(f.unit.Ctx())
+		if err != nil {
+			f.log.Error().Err(err).Msg("could not sync finalized headers")
+			return
+		}
+		if !progress {
+			return
+		}
+	}
+}
+
+// Sync runs a single round of synchronization with the source. It returns
+// whether the follower made progress, by finalizing blocks or by learning
+// about the next epoch.
+func (f *Follower) Sync(ctx context.Context) (bool, error) {
+
+	head, err := f.Head()
+	if err != nil {
+		return false, fmt.Errorf("could not get head: %w", err)
+	}
+
+	headers, err := f.source.Headers(ctx, head.Height+1, f.config.BatchSize)
+	if err != nil {
+		return false, fmt.Errorf("could not get headers: %w", err)
+	}
+	if len(headers) == 0 {
+		return false, nil
+	}
+
+	// once the source finalized blocks beyond the committees we know about,
+	// the transition into the next epoch must have been sealed; we might not
+	// have finalized the sealing blocks yet, in which case we try again in
+	// the next round
+	learned := false
+	if headers[len(headers)-1].View > f.finalView() {
+		learned, err = f.extendEpochs(ctx, head)
+		if err != nil {
+			return false, fmt.Errorf("could not extend epochs: %w", err)
+		}
+	}
+
+	// headers beyond the known committees can't be verified yet
+	finalView := f.finalView()
+	n := 0
+	for n < len(headers) && headers[n].View <= finalView {
+		n++
+	}
+	if n == 0 {
+		return learned, nil
+	}
+	headers = headers[:n]
+
+	// extend the chain with the tail of the finality proof, so that we can
+	// finalize up to the last header requested
+	proof, err := f.source.FinalityProof(ctx, headers[n-1].ID())
+	if err != nil {
+		return learned, fmt.Errorf("could not get finality proof: %w", err)
+	}
+	if proof.Block() == nil || proof.Block().ID() != headers[n-1].ID() {
+		return learned, fmt.Errorf("finality proof for wrong block: %w", finality.ErrInvalidProof)
+	}
+	chain := append([]*flow.Header{head}, headers...)
+	chain = append(chain, proof.Headers[1:]...)
+
+	for i := 1; i < len(chain); i++ {
+		if chain[i].ParentID != chain[i-1].ID() || chain[i].Height != chain[i-1].Height+1 {
+			return learned, fmt.Errorf("header at height %d does not extend the finalized chain: %w", chain[i].Height, finality.ErrInvalidProof)
+		}
+	}
+
+	final, err := f.finalized(chain)
+	if err != nil {
+		return learned, fmt.Errorf("could not verify headers: %w", err)
+	}
+	if final > n {
+		final = n
+	}
+	if final == 0 {
+		return learned, nil
+	}
+
+	err = f.db.Update(func(tx *badger.Txn) error {
+		for _, header := range chain[1 : final+1] {
+			err := insertFinalized(header)(tx)
+			if err != nil {
+				return fmt.Errorf("could not insert header at height %d: %w", header.Height, err)
+			}
+		}
+		return operation.UpdateFinalizedHeight(chain[final].Height)(tx)
+	})
+	if err != nil {
+		return learned, fmt.Errorf("could not store finalized headers: %w", err)
+	}
+
+	f.log.Debug().
+		Uint64("height", chain[final].Height).
+		Hex("block_id", logging.Entity(chain[final])).
+		Int("headers", final).
+		Msg("finalized headers")
+
+	return true, nil
+}
+
+// finalized returns the index of the latest header of the chain which is
+// finalized by a direct 3-chain certified by the known committees, or zero if
+// no header after the first, already finalized, one is.
+func (f *Follower) finalized(chain []*flow.Header) (int, error) {
+
+	for i := len(chain) - 4; i >= 1; i-- {
+		if chain[i+1].View != chain[i].View+1 || chain[i+2].View != chain[i].View+2 {
+			continue
+		}
+
+		// each QC is verified against the committee of the epoch of the
+		// certified block, so a 3-chain can span an epoch transition
+		certified := true
+		for j := i; j < i+3; j++ {
+			verifier, err := f.verifier(chain[j].View)
+			if err != nil {
+				return 0, fmt.Errorf("could not get verifier for view %d: %w", chain[j].View, err)
+			}
+			if verifier == nil {
+				certified = false
+				break
+			}
+			err = verifier.VerifyQC(chain[j], chain[j+1])
+			if err != nil {
+				return 0, fmt.Errorf("invalid QC for header at height %d: %w", chain[j].Height, err)
+			}
+		}
+		if certified {
+			return i, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// verifier returns the verifier of the QCs of the committed epoch containing
+// the given view, or nil if there is no such epoch.
+func (f *Follower) verifier(view uint64) (*finality.Verifier, error) {
+	for _, e := range f.epochs {
+		if e.commit == nil || view < e.setup.FirstView || view > e.setup.FinalView {
+			continue
+		}
+		if e.verifier == nil {
+			verifier, err := f.verifiers(e.setup, e.commit)
+			if err != nil {
+				return nil, fmt.Errorf("could not create verifier for epoch %d: %w", e.setup.Counter, err)
+			}
+			e.verifier = verifier
+		}
+		return e.verifier, nil
+	}
+	return nil, nil
+}
+
+// finalView returns the final view of the latest committed epoch.
+func (f *Follower) finalView() uint64 {
+	for i := len(f.epochs) - 1; i >= 0; i-- {
+		if f.epochs[i].commit != nil {
+			return f.epochs[i].setup.FinalView
+		}
+	}
+	return 0
+}
+
+// extendEpochs learns about the epoch following the latest committed epoch,
+// from a proof of its transition whose sealing blocks are finalized up to the
+// given head. It returns whether the epoch was learned.
+func (f *Follower) extendEpochs(ctx context.Context, head *flow.Header) (bool, error) {
+
+	last := f.epochs[len(f.epochs)-1]
+	counter := last.setup.Counter
+	if last.commit != nil {
+		counter++
+	}
+
+	proof, err := f.source.EpochProof(ctx, counter)
+	if errors.Is(err, finality.ErrUnknownTransition) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not get proof for epoch %d: %w", counter, err)
+	}
+
+	// if the epoch is already set up, we only need to verify its commit
+	seals := []*finality.SealProof{proof.CommitSeal}
+	if last.commit == nil {
+		if proof.Setup == nil || proof.Setup.ID() != last.setup.ID() {
+			return false, fmt.Errorf("epoch %d has unexpected setup: %w", counter, finality.ErrInvalidEpochProof)
+		}
+	} else {
+		seals = append(seals, proof.SetupSeal)
+	}
+
+	// the service events are only valid if they were sealed on the finalized
+	// chain, so we can't verify the proof before we finalized the sealing
+	// blocks ourselves
+	for _, seal := range seals {
+		if seal == nil || seal.Header == nil {
+			return false, fmt.Errorf("incomplete proof for epoch %d: %w", counter, finality.ErrInvalidEpochProof)
+		}
+		if seal.Header.Height > head.Height {
+			return false, nil
+		}
+	}
+
+	if last.commit == nil {
+		err = proof.VerifyCommit(last.setup)
+	} else {
+		err = proof.Verify(last.setup)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid proof for epoch %d: %w", counter, err)
+	}
+
+	for _, seal := range seals {
+		var blockID flow.Identifier
+		err = f.db.View(operation.LookupBlockHeight(seal.Header.Height, &blockID))
+		if err != nil {
+			return false, fmt.Errorf("could not look up finalized block at height %d: %w", seal.Header.Height, err)
+		}
+		if blockID != seal.Header.ID() {
+			return false, fmt.Errorf("epoch %d sealed by unfinalized block at height %d: %w", counter, seal.Header.Height, finality.ErrInvalidEpochProof)
+		}
+	}
+
+	if last.commit == nil {
+		err = f.db.Update(commitEpoch(last.setup, proof.Commit))
+		if err != nil {
+			return false, fmt.Errorf("could not store commit of epoch %d: %w", counter, err)
+		}
+		last.commit = proof.Commit
+	} else {
+		err = f.db.Update(insertEpoch(proof.Setup, proof.Commit))
+		if err != nil {
+			return false, fmt.Errorf("could not store epoch %d: %w", counter, err)
+		}
+		f.epochs = append(f.epochs, &epoch{setup: proof.Setup, commit: proof.Commit})
+	}
+
+	f.log.Info().
+		Uint64("counter", counter).
+		Uint64("first_view", proof.Setup.FirstView).
+		Uint64("final_view", proof.Setup.FinalView).
+		Msg("verified transition into next epoch")
+
+	return true, nil
+}
+
+// retrieveFinalized retrieves the header of the finalized block with the given
+// height.
+func retrieveFinalized(height uint64, header *flow.Header) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		var blockID flow.Identifier
+		err := operation.LookupBlockHeight(height, &blockID)(tx)
+		if err != nil {
+			return fmt.Errorf("could not look up block at height %d: %w", height, err)
+		}
+		return operation.RetrieveHeader(blockID, header)(tx)
+	}
+}
@@ -0,0 +1,351 @@
+package light
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/consensus/hotstuff/mocks"
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// source is a source serving a chain of headers, of which the headers up to
+// the finalized height are finalized.
+type source struct {
+	chain     []*flow.Header
+	finalized uint64
+	proofs    map[uint64]*finality.EpochProof
+}
+
+func (s *source) Headers(_ context.Context, height uint64, limit uint) ([]*flow.Header, error) {
+	var headers []*flow.Header
+	for h := height; h <= s.finalized && h < height+uint64(limit); h++ {
+		headers = append(headers, s.chain[h])
+	}
+	return headers, nil
+}
+
+func (s *source) FinalityProof(_ context.Context, blockID flow.Identifier) (*finality.Proof, error) {
+	for i, header := range s.chain {
+		if header.ID() != blockID {
+			continue
+		}
+		for j := i; j+3 < len(s.chain); j++ {
+			if s.chain[j+1].View == s.chain[j].View+1 && s.chain[j+2].View == s.chain[j].View+2 {
+				return &finality.Proof{Headers: s.chain[i : j+4]}, nil
+			}
+		}
+	}
+	return nil, errors.New("no finality proof")
+}
+
+func (s *source) EpochProof(_ context.Context, counter uint64) (*finality.EpochProof, error) {
+	proof, ok := s.proofs[counter]
+	if !ok {
+		return nil, finality.ErrUnknownTransition
+	}
+	return proof, nil
+}
+
+// chainFixture creates a chain of headers, starting with a root header at
+// height and view zero, with one header for each of the given views. Each QC
+// is signed by the committee returned for the view of the certified block, and
+// the headers at the heights of the given payloads commit to these payloads.
+func chainFixture(committee func(view uint64) flow.IdentityList, payloads map[uint64]*flow.Payload, views ...uint64) []*flow.Header {
+	root := unittest.BlockHeaderFixture()
+	root.Height = 0
+	root.View = 0
+	chain := []*flow.Header{&root}
+	for _, view := range views {
+		header := unittest.BlockHeaderWithParentFixture(chain[len(chain)-1])
+		header.View = view
+		header.ParentVoterIDs = committee(chain[len(chain)-1].View).NodeIDs()
+		if payload, ok := payloads[header.Height]; ok {
+			header.PayloadHash = payload.Hash()
+		}
+		chain = append(chain, &header)
+	}
+	return chain
+}
+
+// commitFixture creates an epoch commit with a DKG group key which can be
+// encoded without the BLS library.
+func commitFixture(counter uint64) *flow.EpochCommit {
+	return &flow.EpochCommit{
+		Counter:         counter,
+		DKGGroupKey:     unittest.KeyFixture(crypto.ECDSAP256).PublicKey(),
+		DKGParticipants: make(map[flow.Identifier]flow.DKGParticipant),
+	}
+}
+
+// epochProofFixture creates the proof for the given epoch, whose service
+// events are sealed by a single result, and returns the payload sealing it.
+func epochProofFixture(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.EpochProof, *flow.Payload) {
+	result := unittest.ExecutionResultFixture()
+	result.ServiceEvents = []flow.ServiceEvent{setup.ServiceEvent(), commit.ServiceEvent()}
+	payload := unittest.PayloadFixture(unittest.WithSeals(unittest.Seal.Fixture(unittest.Seal.WithResult(result))))
+	seal := &finality.SealProof{
+		Payload: &payload,
+		Result:  result,
+	}
+	proof := &finality.EpochProof{
+		Setup:      setup,
+		Commit:     commit,
+		SetupSeal:  seal,
+		CommitSeal: seal,
+	}
+	return proof, &payload
+}
+
+// withFollower runs the given function with a follower of the given source,
+// whose database was bootstrapped with the first header of the source and
+// the given epochs.
+func withFollower(t *testing.T, src *source, verifier *mocks.Verifier, epochs []*epoch, f func(*Follower)) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+		root := src.chain[0]
+		err := db.Update(func(tx *badger.Txn) error {
+			err := insertFinalized(root)(tx)
+			require.NoError(t, err)
+			err = operation.InsertRootHeight(root.Height)(tx)
+			require.NoError(t, err)
+			err = operation.InsertFinalizedHeight(root.Height)(tx)
+			require.NoError(t, err)
+			for _, e := range epochs {
+				err = insertEpoch(e.setup, e.commit)(tx)
+				require.NoError(t, err)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		verifiers := func(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.Verifier, error) {
+			return finality.NewVerifier(setup.Participants, setup.FirstView, setup.FinalView, verifier), nil
+		}
+
+		// the epochs are not retrieved from the database, as their DKG keys
+		// can only be decoded with the BLS library
+		follower := &Follower{
+			unit:      engine.NewUnit(),
+			log:       zerolog.Nop(),
+			db:        db,
+			source:    src,
+			verifiers: verifiers,
+			config:    Config{BatchSize: 10},
+			epochs:    epochs,
+		}
+
+		f(follower)
+	})
+}
+
+func TestSync(t *testing.T) {
+
+	participants := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+	setup := unittest.EpochSetupFixture(unittest.SetupWithCounter(1), unittest.WithFirstView(0), unittest.WithFinalView(1000))
+	setup.Participants = participants
+	epochs := func() []*epoch {
+		return []*epoch{{setup: setup, commit: commitFixture(1)}}
+	}
+
+	committee := func(uint64) flow.IdentityList {
+		return participants
+	}
+
+	views := make([]uint64, 0, 20)
+	for view := uint64(1); view <= 20; view++ {
+		views = append(views, view)
+	}
+
+	t.Run("finalized headers", func(t *testing.T) {
+		src := &source{chain: chainFixture(committee, nil, views...), finalized: 15}
+		verifier := &mocks.Verifier{}
+		verifier.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+		withFollower(t, src, verifier, epochs(), func(follower *Follower) {
+
+			progress, err := follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.True(t, progress)
+			head, err := follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, src.chain[10].ID(), head.ID())
+
+			// the last headers are finalized with the tail of the finality proof
+			progress, err = follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.True(t, progress)
+			head, err = follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, src.chain[15].ID(), head.ID())
+
+			progress, err = follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.False(t, progress)
+
+			for height := uint64(0); height <= 15; height++ {
+				header, err := follower.ByHeight(height)
+				require.NoError(t, err)
+				assert.Equal(t, src.chain[height].ID(), header.ID())
+			}
+		})
+	})
+
+	t.Run("no direct 3-chain", func(t *testing.T) {
+		src := &source{chain: chainFixture(committee, nil, 1, 3, 5, 7, 8, 9, 10), finalized: 3}
+		verifier := &mocks.Verifier{}
+		verifier.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+		withFollower(t, src, verifier, epochs(), func(follower *Follower) {
+			progress, err := follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.True(t, progress)
+			head, err := follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, src.chain[3].ID(), head.ID())
+		})
+	})
+
+	t.Run("invalid QC", func(t *testing.T) {
+		src := &source{chain: chainFixture(committee, nil, views...), finalized: 15}
+		verifier := &mocks.Verifier{}
+		verifier.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+		withFollower(t, src, verifier, epochs(), func(follower *Follower) {
+			_, err := follower.Sync(context.Background())
+			assert.True(t, errors.Is(err, finality.ErrInvalidProof))
+			head, err := follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, src.chain[0].ID(), head.ID())
+		})
+	})
+
+	t.Run("forged header", func(t *testing.T) {
+		src := &source{chain: chainFixture(committee, nil, views...), finalized: 15}
+		src.chain[5].ParentID = unittest.IdentifierFixture()
+		verifier := &mocks.Verifier{}
+		verifier.On("VerifyQC", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+		withFollower(t, src, verifier, epochs(), func(follower *Follower) {
+			_, err := follower.Sync(context.Background())
+			assert.True(t, errors.Is(err, finality.ErrInvalidProof))
+		})
+	})
+}
+
+func TestSync_EpochTransition(t *testing.T) {
+
+	first := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+	second := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+
+	setup1 := unittest.EpochSetupFixture(unittest.SetupWithCounter(1), unittest.WithFirstView(0), unittest.WithFinalView(99))
+	setup1.Participants = first
+	setup2 := unittest.EpochSetupFixture(unittest.SetupWithCounter(2), unittest.WithFirstView(100), unittest.WithFinalView(199))
+	setup2.Participants = second
+	commit2 := commitFixture(2)
+
+	// the service events of the second epoch are sealed at height 50
+	proof, payload := epochProofFixture(setup2, commit2)
+
+	views := make([]uint64, 0, 150)
+	for view := uint64(1); view <= 150; view++ {
+		views = append(views, view)
+	}
+	committee := func(view uint64) flow.IdentityList {
+		if view <= setup1.FinalView {
+			return first
+		}
+		return second
+	}
+	chain := chainFixture(committee, map[uint64]*flow.Payload{50: payload}, views...)
+	proof.SetupSeal.Header = chain[50]
+
+	// the verifier can only verify QCs of the committee of their epoch
+	verifier := &mocks.Verifier{}
+	verifier.On("VerifyQC", first, mock.Anything, mock.Anything).Return(true, nil)
+	verifier.On("VerifyQC", second, mock.Anything, mock.Anything).Return(true, nil)
+
+	epochs := []*epoch{{setup: setup1, commit: commitFixture(1)}}
+
+	t.Run("valid transition", func(t *testing.T) {
+		src := &source{chain: chain, finalized: 140, proofs: map[uint64]*finality.EpochProof{}}
+
+		withFollower(t, src, verifier, epochs, func(follower *Follower) {
+			follower.config.BatchSize = 200
+
+			// without the next epoch, we can only finalize up to the last
+			// 3-chain of the first epoch
+			progress, err := follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.True(t, progress)
+			head, err := follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(97), head.Height)
+
+			src.proofs[2] = proof
+			progress, err = follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.True(t, progress)
+			head, err = follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(140), head.Height)
+
+			var record flow.EpochRecord
+			err = follower.db.View(operation.RetrieveEpochRecord(2, &record))
+			require.NoError(t, err)
+			assert.Equal(t, setup2.ID(), record.SetupID)
+			assert.Equal(t, commit2.ID(), record.CommitID)
+		})
+	})
+
+	t.Run("unfinalized sealing block", func(t *testing.T) {
+		src := &source{chain: chain, finalized: 140, proofs: map[uint64]*finality.EpochProof{2: proof}}
+
+		withFollower(t, src, verifier, epochs, func(follower *Follower) {
+
+			// the proof is only accepted once the sealing block is finalized
+			progress, err := follower.Sync(context.Background())
+			require.NoError(t, err)
+			assert.True(t, progress)
+			assert.Len(t, follower.epochs, 1)
+
+			for i := 0; i < 20; i++ {
+				_, err = follower.Sync(context.Background())
+				require.NoError(t, err)
+			}
+			assert.Len(t, follower.epochs, 2)
+			head, err := follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(140), head.Height)
+		})
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		forged, _ := epochProofFixture(setup2, commitFixture(2))
+		forged.SetupSeal.Header = chain[50]
+		src := &source{chain: chain, finalized: 140, proofs: map[uint64]*finality.EpochProof{2: forged}}
+
+		withFollower(t, src, verifier, epochs, func(follower *Follower) {
+			follower.config.BatchSize = 200
+
+			_, err := follower.Sync(context.Background())
+			require.NoError(t, err)
+			_, err = follower.Sync(context.Background())
+			assert.True(t, errors.Is(err, finality.ErrInvalidEpochProof))
+			head, err := follower.Head()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(97), head.Height)
+		})
+	})
+}
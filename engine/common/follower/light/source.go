@@ -0,0 +1,20 @@
+package light
+
+import (
+	"context"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/checkpointsync"
+)
+
+// Source provides a light client with the finalized headers of the chain and
+// the proofs to verify them, such as checkpointsync.HTTPSource. Nothing
+// provided by a source is trusted.
+type Source interface {
+	checkpointsync.ProofSource
+
+	// Headers returns up to limit finalized headers in order of height,
+	// starting at the given height. It returns fewer headers if the source
+	// did not finalize that many yet.
+	Headers(ctx context.Context, height uint64, limit uint) ([]*flow.Header, error)
+}
@@ -0,0 +1,181 @@
+package light
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// Bootstrap initializes the empty database of a light client with the head
+// and the epochs of the given snapshot. The snapshot is the root of trust of
+// the light client, so it must come from a trusted source, such as the root
+// snapshot of the spork.
+func Bootstrap(db *badger.DB, snapshot protocol.Snapshot) error {
+
+	head, err := snapshot.Head()
+	if err != nil {
+		return fmt.Errorf("could not get snapshot head: %w", err)
+	}
+	phase, err := snapshot.Phase()
+	if err != nil {
+		return fmt.Errorf("could not get epoch phase: %w", err)
+	}
+
+	currentSetup, err := protocol.ToEpochSetup(snapshot.Epochs().Current())
+	if err != nil {
+		return fmt.Errorf("could not get current epoch setup: %w", err)
+	}
+	currentCommit, err := protocol.ToEpochCommit(snapshot.Epochs().Current())
+	if err != nil {
+		return fmt.Errorf("could not get current epoch commit: %w", err)
+	}
+
+	// in the setup phase, the next epoch is trusted to be set up as in the
+	// snapshot, as its setup was sealed before the head
+	var nextSetup *flow.EpochSetup
+	var nextCommit *flow.EpochCommit
+	if phase == flow.EpochPhaseSetup || phase == flow.EpochPhaseCommitted {
+		nextSetup, err = protocol.ToEpochSetup(snapshot.Epochs().Next())
+		if err != nil {
+			return fmt.Errorf("could not get next epoch setup: %w", err)
+		}
+	}
+	if phase == flow.EpochPhaseCommitted {
+		nextCommit, err = protocol.ToEpochCommit(snapshot.Epochs().Next())
+		if err != nil {
+			return fmt.Errorf("could not get next epoch commit: %w", err)
+		}
+	}
+
+	return db.Update(func(tx *badger.Txn) error {
+
+		var root uint64
+		err := operation.RetrieveRootHeight(&root)(tx)
+		if err == nil {
+			return fmt.Errorf("database is already bootstrapped at height %d", root)
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not check root height: %w", err)
+		}
+
+		err = insertFinalized(head)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert head: %w", err)
+		}
+		err = operation.InsertRootHeight(head.Height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert root height: %w", err)
+		}
+		err = operation.InsertFinalizedHeight(head.Height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert finalized height: %w", err)
+		}
+
+		err = insertEpoch(currentSetup, currentCommit)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert current epoch: %w", err)
+		}
+		if nextSetup != nil {
+			err = insertEpoch(nextSetup, nextCommit)(tx)
+			if err != nil {
+				return fmt.Errorf("could not insert next epoch: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// insertFinalized inserts the header of a finalized block and indexes it by
+// its height.
+func insertFinalized(header *flow.Header) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		blockID := header.ID()
+		err := operation.InsertHeader(blockID, header)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert header: %w", err)
+		}
+		err = operation.IndexBlockHeight(header.Height, blockID)(tx)
+		if err != nil {
+			return fmt.Errorf("could not index height: %w", err)
+		}
+		return nil
+	}
+}
+
+// insertEpoch inserts the service events and the record of an epoch. The
+// commit is nil if the epoch is only set up.
+func insertEpoch(setup *flow.EpochSetup, commit *flow.EpochCommit) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		record := &flow.EpochRecord{
+			Counter: setup.Counter,
+			SetupID: setup.ID(),
+		}
+		err := operation.InsertEpochSetup(record.SetupID, setup)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert epoch setup: %w", err)
+		}
+		if commit != nil {
+			record.CommitID = commit.ID()
+			err = operation.InsertEpochCommit(record.CommitID, commit)(tx)
+			if err != nil {
+				return fmt.Errorf("could not insert epoch commit: %w", err)
+			}
+		}
+		return operation.InsertEpochRecord(record)(tx)
+	}
+}
+
+// commitEpoch inserts the commit of an epoch which was only set up so far.
+func commitEpoch(setup *flow.EpochSetup, commit *flow.EpochCommit) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		record := &flow.EpochRecord{
+			Counter:  setup.Counter,
+			SetupID:  setup.ID(),
+			CommitID: commit.ID(),
+		}
+		err := operation.InsertEpochCommit(record.CommitID, commit)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert epoch commit: %w", err)
+		}
+		return operation.UpdateEpochRecord(record)(tx)
+	}
+}
+
+// retrieveEpochs retrieves the service events of all known epochs, ordered by
+// counter. The commit of the last epoch is nil if it is only set up.
+func retrieveEpochs(tx *badger.Txn) ([]*epoch, error) {
+
+	var records []flow.EpochRecord
+	err := operation.LookupEpochRecords(&records)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up epoch records: %w", err)
+	}
+
+	epochs := make([]*epoch, 0, len(records))
+	for _, record := range records {
+		var setup flow.EpochSetup
+		err = operation.RetrieveEpochSetup(record.SetupID, &setup)(tx)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve setup of epoch %d: %w", record.Counter, err)
+		}
+		e := &epoch{setup: &setup}
+		if record.CommitID != flow.ZeroID {
+			var commit flow.EpochCommit
+			err = operation.RetrieveEpochCommit(record.CommitID, &commit)(tx)
+			if err != nil {
+				return nil, fmt.Errorf("could not retrieve commit of epoch %d: %w", record.Counter, err)
+			}
+			e.commit = &commit
+		}
+		epochs = append(epochs, e)
+	}
+
+	return epochs, nil
+}
@@ -36,6 +36,7 @@ type Engine struct {
 	me      module.Local            // used to access local node information
 	pool    mempool.Guarantees      // used to keep pending guarantees in pool
 	con     network.Conduit         // conduit to receive/send guarantees
+	stalls  *StallDetector          // used to detect stalled collection clusters
 }
 
 // New creates a new collection propagation engine.
@@ -76,6 +77,13 @@ func New(
 	return e, nil
 }
 
+// WithStallDetector adds a detector for stalled collection clusters, which is
+// notified of every valid collection guarantee.
+func (e *Engine) WithStallDetector(stalls *StallDetector) *Engine {
+	e.stalls = stalls
+	return e
+}
+
 // Ready returns a ready channel that is closed once the engine has fully
 // started. For the ingestion engine, we consider the engine up and running
 // upon initialization.
@@ -190,6 +198,14 @@ func (e *Engine) onGuarantee(originID flow.Identifier, guarantee *flow.Collectio
 
 	log.Info().Msg("collection guarantee added to pool")
 
+	// the cluster which produced the guarantee is alive
+	if e.stalls != nil {
+		err = e.stalls.OnGuarantee(guarantee)
+		if err != nil {
+			log.Error().Err(err).Msg("could not record guarantee for stall detection")
+		}
+	}
+
 	// if the collection guarantee stems from a collection node, we should propagate it
 	// to the other consensus nodes on the network; we no longer need to care about
 	// fan-out here, as libp2p will take care of the pub-sub pattern adequately
@@ -0,0 +1,214 @@
+package ingestion
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/state/protocol"
+)
+
+// DefaultStallThreshold is the default duration without any collection
+// guarantees from a cluster after which we consider the cluster stalled.
+const DefaultStallThreshold = 2 * time.Minute
+
+// StalledCluster is the alert raised when no collection guarantees have been
+// received from a collection cluster for longer than the stall threshold.
+type StalledCluster struct {
+	Epoch         uint64              // counter of the epoch of the cluster
+	Index         uint                // index of the cluster in the epoch's clustering
+	Members       flow.IdentifierList // node IDs of the cluster members
+	LastGuarantee time.Time           // time of the last guarantee, zero if none was received
+	StalledFor    time.Duration       // time since the last guarantee, or since we started watching
+}
+
+// StallDetector watches the collection guarantees received by the ingestion
+// engine and detects the clusters of the current epoch which have stopped
+// producing collections. For each cluster which becomes stalled, it logs a
+// structured alert and updates the stalled cluster metric; it logs again once
+// guarantees from the cluster arrive again.
+type StallDetector struct {
+	unit      *engine.Unit
+	log       zerolog.Logger
+	metrics   module.ConsensusMetrics
+	state     protocol.State
+	threshold time.Duration
+
+	mu            sync.Mutex
+	watching      map[uint64]time.Time          // per epoch, when we started watching its clusters
+	lastGuarantee map[uint64]map[uint]time.Time // per epoch and cluster, when we last received a guarantee
+	stalled       map[uint64]map[uint]bool      // per epoch and cluster, whether we raised an alert
+}
+
+// NewStallDetector creates a new detector for stalled collection clusters,
+// which considers a cluster stalled after no guarantees have been received
+// from it for the given threshold.
+func NewStallDetector(log zerolog.Logger, metrics module.ConsensusMetrics, state protocol.State, threshold time.Duration) *StallDetector {
+	d := &StallDetector{
+		unit:          engine.NewUnit(),
+		log:           log.With().Str("component", "stall_detector").Logger(),
+		metrics:       metrics,
+		state:         state,
+		threshold:     threshold,
+		watching:      make(map[uint64]time.Time),
+		lastGuarantee: make(map[uint64]map[uint]time.Time),
+		stalled:       make(map[uint64]map[uint]bool),
+	}
+	return d
+}
+
+// Ready returns a ready channel that is closed once the detector has started.
+// The detector checks for stalled clusters several times per threshold.
+func (d *StallDetector) Ready() <-chan struct{} {
+	d.unit.LaunchPeriodically(func() {
+		_, err := d.Check(time.Now())
+		if err != nil {
+			d.log.Error().Err(err).Msg("could not check for stalled clusters")
+		}
+	}, d.threshold/4, 0)
+	return d.unit.Ready()
+}
+
+// Done returns a done channel that is closed once the detector has stopped.
+func (d *StallDetector) Done() <-chan struct{} {
+	return d.unit.Done()
+}
+
+// OnGuarantee records the receipt of a valid collection guarantee for the
+// cluster which produced it. Guarantees for epochs which are not watched are
+// ignored.
+func (d *StallDetector) OnGuarantee(guarantee *flow.CollectionGuarantee) error {
+
+	if len(guarantee.SignerIDs) == 0 {
+		return fmt.Errorf("guarantee has no signers")
+	}
+
+	epoch := d.state.AtBlockID(guarantee.ReferenceBlockID).Epochs().Current()
+	counter, err := epoch.Counter()
+	if err != nil {
+		return fmt.Errorf("could not get epoch counter: %w", err)
+	}
+	clusters, err := epoch.Clustering()
+	if err != nil {
+		return fmt.Errorf("could not get clustering: %w", err)
+	}
+	_, index, ok := clusters.ByNodeID(guarantee.SignerIDs[0])
+	if !ok {
+		return fmt.Errorf("guarantor (id=%x) is not part of any cluster", guarantee.SignerIDs[0])
+	}
+
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// ignore guarantees for epochs we don't watch, in particular late
+	// guarantees for past epochs which we already forgot about
+	if _, ok := d.watching[counter]; !ok {
+		return nil
+	}
+
+	if d.lastGuarantee[counter] == nil {
+		d.lastGuarantee[counter] = make(map[uint]time.Time)
+	}
+	d.lastGuarantee[counter][index] = now
+
+	// if we raised an alert for the cluster, it has recovered
+	if d.stalled[counter][index] {
+		delete(d.stalled[counter], index)
+		d.metrics.ClusterStalled(index, false)
+		d.log.Info().
+			Str("alert", "stalled_cluster_recovered").
+			Uint64("epoch", counter).
+			Uint("cluster_index", index).
+			Msg("received collection guarantee from previously stalled cluster")
+	}
+
+	return nil
+}
+
+// Check checks which clusters of the current epoch have not produced any
+// guarantees for longer than the threshold, as of the given time, raising
+// an alert for each newly stalled cluster. It returns all stalled clusters.
+func (d *StallDetector) Check(now time.Time) ([]*StalledCluster, error) {
+
+	epoch := d.state.Final().Epochs().Current()
+	counter, err := epoch.Counter()
+	if err != nil {
+		return nil, fmt.Errorf("could not get epoch counter: %w", err)
+	}
+	clusters, err := epoch.Clustering()
+	if err != nil {
+		return nil, fmt.Errorf("could not get clustering: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// clusters of an epoch get the full threshold to produce their first
+	// guarantee from the time we start watching the epoch
+	watching, ok := d.watching[counter]
+	if !ok {
+		watching = now
+		d.watching[counter] = now
+	}
+
+	// forget about past epochs
+	for past := range d.watching {
+		if past < counter {
+			for index := range d.stalled[past] {
+				d.metrics.ClusterStalled(index, false)
+			}
+			delete(d.watching, past)
+			delete(d.lastGuarantee, past)
+			delete(d.stalled, past)
+		}
+	}
+
+	var stalled []*StalledCluster
+	for i, members := range clusters {
+		index := uint(i)
+
+		lastGuarantee, received := d.lastGuarantee[counter][index]
+		since := watching
+		if received {
+			since = lastGuarantee
+		}
+		if now.Sub(since) <= d.threshold {
+			continue
+		}
+
+		cluster := &StalledCluster{
+			Epoch:         counter,
+			Index:         index,
+			Members:       members.NodeIDs(),
+			LastGuarantee: lastGuarantee,
+			StalledFor:    now.Sub(since),
+		}
+		stalled = append(stalled, cluster)
+
+		if d.stalled[counter][index] {
+			continue
+		}
+		if d.stalled[counter] == nil {
+			d.stalled[counter] = make(map[uint]bool)
+		}
+		d.stalled[counter][index] = true
+		d.metrics.ClusterStalled(index, true)
+		d.log.Warn().
+			Str("alert", "stalled_cluster").
+			Uint64("epoch", cluster.Epoch).
+			Uint("cluster_index", cluster.Index).
+			Strs("members", cluster.Members.Strings()).
+			Time("last_guarantee", cluster.LastGuarantee).
+			Dur("stalled_for", cluster.StalledFor).
+			Msg("no collection guarantees received from cluster")
+	}
+
+	return stalled, nil
+}
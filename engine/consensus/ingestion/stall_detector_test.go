@@ -0,0 +1,123 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	mockmodule "github.com/onflow/flow-go/module/mock"
+	mockprotocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestStallDetector(t *testing.T) {
+
+	collectors := unittest.IdentityListFixture(6, unittest.WithRole(flow.RoleCollection))
+	clusters := unittest.ClusterList(2, collectors)
+
+	epoch := &mockprotocol.Epoch{}
+	epoch.On("Counter").Return(uint64(1), nil)
+	epoch.On("Clustering").Return(clusters, nil)
+	query := &mockprotocol.EpochQuery{}
+	query.On("Current").Return(epoch)
+	snapshot := &mockprotocol.Snapshot{}
+	snapshot.On("Epochs").Return(query)
+	state := &mockprotocol.State{}
+	state.On("Final").Return(snapshot)
+	state.On("AtBlockID", mock.Anything).Return(snapshot)
+
+	metrics := &mockmodule.ConsensusMetrics{}
+	metrics.On("ClusterStalled", mock.Anything, mock.Anything)
+
+	threshold := time.Minute
+	detector := NewStallDetector(zerolog.Nop(), metrics, state, threshold)
+
+	guarantee := unittest.CollectionGuaranteeFixture()
+	guarantee.SignerIDs = clusters[0].NodeIDs()
+
+	// start watching two thresholds in the past
+	start := time.Now().Add(-2 * threshold)
+
+	// no cluster is stalled within the threshold after we start watching
+	stalled, err := detector.Check(start)
+	require.NoError(t, err)
+	assert.Empty(t, stalled)
+
+	// after the threshold, the cluster without guarantees is stalled
+	err = detector.OnGuarantee(guarantee)
+	require.NoError(t, err)
+	stalled, err = detector.Check(time.Now())
+	require.NoError(t, err)
+	require.Len(t, stalled, 1)
+	assert.Equal(t, uint(1), stalled[0].Index)
+	assert.Equal(t, uint64(1), stalled[0].Epoch)
+	assert.ElementsMatch(t, clusters[1].NodeIDs(), stalled[0].Members)
+	assert.True(t, stalled[0].LastGuarantee.IsZero())
+	metrics.AssertCalled(t, "ClusterStalled", uint(1), true)
+
+	// the alert is only raised once
+	_, err = detector.Check(time.Now())
+	require.NoError(t, err)
+	metrics.AssertNumberOfCalls(t, "ClusterStalled", 1)
+
+	// once a guarantee arrives, the cluster recovers
+	guarantee.SignerIDs = clusters[1].NodeIDs()
+	err = detector.OnGuarantee(guarantee)
+	require.NoError(t, err)
+	metrics.AssertCalled(t, "ClusterStalled", uint(1), false)
+	stalled, err = detector.Check(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, stalled)
+}
+
+// TestStallDetectorPastEpoch tests that late guarantees for past epochs don't
+// recreate the state of the epochs which the detector forgot about.
+func TestStallDetectorPastEpoch(t *testing.T) {
+
+	collectors := unittest.IdentityListFixture(6, unittest.WithRole(flow.RoleCollection))
+	clusters := unittest.ClusterList(2, collectors)
+
+	snapshotOf := func(counter uint64) *mockprotocol.Snapshot {
+		epoch := &mockprotocol.Epoch{}
+		epoch.On("Counter").Return(counter, nil)
+		epoch.On("Clustering").Return(clusters, nil)
+		query := &mockprotocol.EpochQuery{}
+		query.On("Current").Return(epoch)
+		snapshot := &mockprotocol.Snapshot{}
+		snapshot.On("Epochs").Return(query)
+		return snapshot
+	}
+
+	// guarantees reference blocks of the first epoch, while the second epoch
+	// is finalized
+	state := &mockprotocol.State{}
+	state.On("Final").Return(snapshotOf(1)).Once()
+	state.On("Final").Return(snapshotOf(2))
+	state.On("AtBlockID", mock.Anything).Return(snapshotOf(1))
+
+	metrics := &mockmodule.ConsensusMetrics{}
+	metrics.On("ClusterStalled", mock.Anything, mock.Anything)
+
+	detector := NewStallDetector(zerolog.Nop(), metrics, state, time.Minute)
+	guarantee := unittest.CollectionGuaranteeFixture()
+	guarantee.SignerIDs = clusters[0].NodeIDs()
+
+	_, err := detector.Check(time.Now())
+	require.NoError(t, err)
+	require.NoError(t, detector.OnGuarantee(guarantee))
+	assert.Contains(t, detector.lastGuarantee, uint64(1))
+
+	// the first epoch is forgotten once the second epoch is watched
+	_, err = detector.Check(time.Now())
+	require.NoError(t, err)
+	assert.NotContains(t, detector.lastGuarantee, uint64(1))
+
+	// and late guarantees for it are ignored
+	require.NoError(t, detector.OnGuarantee(guarantee))
+	assert.NotContains(t, detector.lastGuarantee, uint64(1))
+}
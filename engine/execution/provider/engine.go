@@ -185,8 +185,9 @@ func (e *Engine) onChunkDataRequest(
 		Collection:    collection,
 	}
 
-	// sends requested chunk data pack to the requester
-	err = e.chunksConduit.Unicast(response, originID)
+	// streams requested chunk data pack to the requester, which may be too
+	// large to be held in memory as a single message
+	err = engine.SendTransfer(e.chunksConduit, response, originID)
 	if err != nil {
 		return fmt.Errorf("could not send requested chunk data pack to (%s): %w", origin, err)
 	}
@@ -0,0 +1,18 @@
+package engine
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network"
+)
+
+// SendTransfer sends the given event to the target as a transfer, so that the
+// target can receive it in frames rather than as a single large message. The
+// target processes the event like any other event sent to it. If the conduit
+// does not support transfers, the event is sent as a unicast message instead.
+func SendTransfer(con network.Conduit, event interface{}, targetID flow.Identifier) error {
+	tc, ok := con.(network.TransferConduit)
+	if !ok {
+		return con.Unicast(event, targetID)
+	}
+	return tc.TransferEvent(event, targetID)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/messages"
+	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// transferConduit records the events transferred on it.
+type transferConduit struct {
+	*mocknetwork.Conduit
+	events []interface{}
+}
+
+func (c *transferConduit) Transfer(targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error {
+	panic("not implemented")
+}
+
+func (c *transferConduit) TransferEvent(event interface{}, targetID flow.Identifier) error {
+	c.events = append(c.events, event)
+	return nil
+}
+
+func TestSendTransfer(t *testing.T) {
+	response := &messages.ChunkDataResponse{
+		ChunkDataPack: *unittest.ChunkDataPackFixture(unittest.IdentifierFixture()),
+		Collection:    unittest.CollectionFixture(3),
+		Nonce:         42,
+	}
+	targetID := unittest.IdentifierFixture()
+
+	t.Run("transfer conduit", func(t *testing.T) {
+		con := &transferConduit{Conduit: &mocknetwork.Conduit{}}
+		err := SendTransfer(con, response, targetID)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{response}, con.events)
+		con.AssertNotCalled(t, "Unicast")
+	})
+
+	t.Run("unicast fallback", func(t *testing.T) {
+		con := &mocknetwork.Conduit{}
+		con.On("Unicast", response, targetID).Return(nil).Once()
+		err := SendTransfer(con, response, targetID)
+		require.NoError(t, err)
+		con.AssertExpectations(t)
+	})
+}
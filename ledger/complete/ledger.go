@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -328,6 +329,28 @@ func (l *Ledger) ExportCheckpointAt(state ledger.State,
 	return newTrie.RootHash(), nil
 }
 
+// WriteCheckpointAt writes a checkpoint with the single trie at the given state
+// to the writer, which new execution nodes load to bootstrap at this state.
+// The state must still be in the forest of the ledger.
+func (l *Ledger) WriteCheckpointAt(state ledger.State, writer io.Writer) error {
+	t, err := l.forest.GetTrie(ledger.RootHash(state))
+	if err != nil {
+		return fmt.Errorf("cannot get trie at the given state commitment: %w", err)
+	}
+
+	flatTrie, err := flattener.FlattenTrie(t)
+	if err != nil {
+		return fmt.Errorf("failed to flatten the trie: %w", err)
+	}
+
+	err = wal.StoreCheckpoint(flatTrie.ToFlattenedForestWithASingleTrie(), writer)
+	if err != nil {
+		return fmt.Errorf("failed to store the checkpoint: %w", err)
+	}
+
+	return nil
+}
+
 // MostRecentTouchedState returns a state which is most recently touched.
 func (l *Ledger) MostRecentTouchedState() (ledger.State, error) {
 	root, err := l.forest.MostRecentTouchedRootHash()
@@ -20,6 +20,7 @@ import (
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/ledger/common/utils"
 	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
@@ -509,6 +510,36 @@ func Test_ExportCheckpointAt(t *testing.T) {
 	})
 }
 
+func Test_WriteCheckpointAt(t *testing.T) {
+	led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	u := utils.UpdateFixture()
+	u.SetState(led.InitialState())
+	state, err := led.Set(u)
+	require.NoError(t, err)
+
+	t.Run("known state", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := led.WriteCheckpointAt(state, &buf)
+		require.NoError(t, err)
+
+		// the checkpoint holds the single trie at the state
+		flatForest, err := wal.ReadCheckpoint(&buf)
+		require.NoError(t, err)
+		tries, err := flattener.RebuildTries(flatForest)
+		require.NoError(t, err)
+		require.Len(t, tries, 1)
+		assert.Equal(t, []byte(state), tries[0].RootHash())
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := led.WriteCheckpointAt(ledger.State(unittest.StateCommitmentFixture()), &buf)
+		assert.Error(t, err)
+	})
+}
+
 func TestWALUpdateIsRunInParallel(t *testing.T) {
 
 	// The idea of this test is - WAL update should be run in parallel
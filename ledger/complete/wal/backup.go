@@ -0,0 +1,125 @@
+package wal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	prometheusWAL "github.com/m4ksio/wal/wal"
+)
+
+// Backup copies the files needed to replay the current state of the WAL into
+// the given directory, while updates continue to be recorded: the latest
+// checkpoint, or the root checkpoint if there is none, and all segments after
+// it. Segments are not written to anymore once the WAL has moved on to the
+// next one, so updates are only held while the segments written to since the
+// start of the backup are copied. It returns the names of the copied files.
+func (w *DiskWAL) Backup(dir string) ([]string, error) {
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create backup directory: %w", err)
+	}
+
+	checkpointer, err := w.NewCheckpointer()
+	if err != nil {
+		return nil, fmt.Errorf("could not create checkpointer: %w", err)
+	}
+
+	var files []string
+
+	latest, err := checkpointer.LatestCheckpoint()
+	if err != nil {
+		return nil, fmt.Errorf("could not get latest checkpoint: %w", err)
+	}
+	if latest >= 0 {
+		files = append(files, NumberToFilename(latest))
+	} else {
+		hasRoot, err := checkpointer.HasRootCheckpoint()
+		if err != nil {
+			return nil, fmt.Errorf("could not check root checkpoint existence: %w", err)
+		}
+		if hasRoot {
+			files = append(files, RootCheckpointFilename)
+		}
+	}
+	for _, name := range files {
+		err = copyFile(filepath.Join(w.dir, name), filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("could not copy checkpoint %s: %w", name, err)
+		}
+	}
+
+	// segments covered by the checkpoint are not needed for the replay
+	first, last, err := w.Segments()
+	if err != nil {
+		return nil, fmt.Errorf("could not get segments: %w", err)
+	}
+	from := first
+	if latest+1 > from {
+		from = latest + 1
+	}
+
+	// copy the segments which are not written to anymore
+	for i := from; i < last; i++ {
+		name := NumberToFilenamePart(i)
+		err = copyFile(prometheusWAL.SegmentName(w.dir, i), filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("could not copy segment %s: %w", name, err)
+		}
+		files = append(files, name)
+	}
+
+	// hold the updates while copying the segments written to in the meantime,
+	// so that no record is copied partially
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, current, err := w.Segments()
+	if err != nil {
+		return nil, fmt.Errorf("could not get segments: %w", err)
+	}
+	if last > from {
+		from = last
+	}
+	for i := from; i <= current; i++ {
+		name := NumberToFilenamePart(i)
+		err = copyFile(prometheusWAL.SegmentName(w.dir, i), filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("could not copy segment %s: %w", name, err)
+		}
+		files = append(files, name)
+	}
+
+	return files, nil
+}
+
+// copyFile copies the file at the source path to the target path and syncs it
+// to disk.
+func copyFile(source string, target string) error {
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("could not open source file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create target file: %w", err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("could not copy file: %w", err)
+	}
+
+	err = out.Sync()
+	if err != nil {
+		return fmt.Errorf("could not sync file: %w", err)
+	}
+
+	return nil
+}
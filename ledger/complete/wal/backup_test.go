@@ -0,0 +1,96 @@
+package wal
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestDiskWAL_Backup(t *testing.T) {
+
+	pathByteSize := 32
+	size := 10
+	collector := metrics.NewNoopCollector()
+
+	unittest.RunWithTempDir(t, func(dir string) {
+
+		f, err := mtrie.NewForest(pathByteSize, size*10, collector, func(tree *trie.MTrie) error { return nil })
+		require.NoError(t, err)
+		rootHash := f.GetEmptyRootHash()
+
+		wal, err := NewDiskWAL(zerolog.Nop(), nil, collector, filepath.Join(dir, "wal"), size*10, pathByteSize, 32*1024)
+		require.NoError(t, err)
+
+		checkpointer, err := wal.NewCheckpointer()
+		require.NoError(t, err)
+
+		// each update exceeds a segment, so that every update starts a new one
+		update := func() {
+			paths := utils.RandomPaths(2, pathByteSize)
+			payloads := utils.RandomPayloads(2, 2<<15, 2<<16)
+			update := &ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads}
+			require.NoError(t, wal.RecordUpdate(update))
+			rootHash, err = f.Update(update)
+			require.NoError(t, err)
+		}
+
+		for i := 0; i < size; i++ {
+			update()
+		}
+
+		// checkpoint the first segments, then record some more updates
+		err = checkpointer.Checkpoint(4, func() (io.WriteCloser, error) {
+			return checkpointer.CheckpointWriter(4)
+		})
+		require.NoError(t, err)
+		for i := 0; i < size; i++ {
+			update()
+		}
+
+		backupDir := filepath.Join(dir, "backup")
+		files, err := wal.Backup(backupDir)
+		require.NoError(t, err)
+		<-wal.Done()
+
+		// the segments covered by the checkpoint are not backed up
+		assert.Equal(t, NumberToFilename(4), files[0])
+		assert.Equal(t, NumberToFilenamePart(5), files[1])
+		assert.NoFileExists(t, filepath.Join(backupDir, NumberToFilenamePart(4)))
+
+		// replaying the backup results in the same state
+		restored, err := mtrie.NewForest(pathByteSize, size*10, collector, func(tree *trie.MTrie) error { return nil })
+		require.NoError(t, err)
+		backup, err := NewDiskWAL(zerolog.Nop(), nil, collector, backupDir, size*10, pathByteSize, 32*1024)
+		require.NoError(t, err)
+		err = backup.Replay(
+			func(forestSequencing *flattener.FlattenedForest) error {
+				return loadIntoForest(restored, forestSequencing)
+			},
+			func(update *ledger.TrieUpdate) error {
+				_, err := restored.Update(update)
+				return err
+			},
+			func(rootHash ledger.RootHash) error {
+				return fmt.Errorf("no deletion expected")
+			},
+		)
+		require.NoError(t, err)
+		<-backup.Done()
+
+		_, err = restored.GetTrie(rootHash)
+		assert.NoError(t, err)
+	})
+}
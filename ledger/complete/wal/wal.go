@@ -3,6 +3,7 @@ package wal
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	prometheusWAL "github.com/m4ksio/wal/wal"
@@ -20,6 +21,7 @@ const SegmentSize = 32 * 1024 * 1024
 
 type DiskWAL struct {
 	wal            *prometheusWAL.WAL
+	mu             sync.RWMutex // held exclusively while the last segment is backed up
 	paused         bool
 	forestCapacity int
 	pathByteSize   int
@@ -63,7 +65,9 @@ func (w *DiskWAL) RecordUpdate(update *ledger.TrieUpdate) error {
 
 	bytes := EncodeUpdate(update)
 
+	w.mu.RLock()
 	_, err := w.wal.Log(bytes)
+	w.mu.RUnlock()
 
 	if err != nil {
 		return fmt.Errorf("error while recording update in LedgerWAL: %w", err)
@@ -95,7 +99,9 @@ func (w *DiskWAL) RecordDelete(rootHash ledger.RootHash) error {
 
 	bytes := EncodeDelete(rootHash)
 
+	w.mu.RLock()
 	_, err := w.wal.Log(bytes)
+	w.mu.RUnlock()
 
 	if err != nil {
 		return fmt.Errorf("error while recording delete in LedgerWAL: %w", err)
@@ -29,6 +29,10 @@ var (
 	PathRootSeal                  = filepath.Join(DirnamePublicBootstrap, "root-block-seal.json")
 	PathRootProtocolStateSnapshot = filepath.Join(DirnamePublicBootstrap, "root-protocol-state-snapshot.json")
 
+	// recent protocol state snapshot, verified against the root snapshot, which
+	// is only available on a node bootstrapped with checkpoint sync
+	PathCheckpointProtocolStateSnapshot = filepath.Join(DirnamePublicBootstrap, "checkpoint-protocol-state-snapshot.json")
+
 	PathRootCheckpoint = filepath.Join(DirnameExecutionState, wal.RootCheckpointFilename) // only available on an execution node
 
 	// private genesis information
@@ -7,6 +7,7 @@ import (
 	"io"
 
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/vmihailenco/msgpack"
 
 	"github.com/onflow/flow-go/crypto"
@@ -190,6 +191,27 @@ func (pub *RandomBeaconPubKey) UnmarshalMsgpack(b []byte) error {
 	return err
 }
 
+func (pub RandomBeaconPubKey) MarshalCBOR() ([]byte, error) {
+	if pub.PublicKey == nil {
+		return cbor.Marshal(nil)
+	}
+	return cbor.Marshal(pub.PublicKey.Encode())
+}
+
+func (pub *RandomBeaconPubKey) UnmarshalCBOR(b []byte) error {
+	var bz []byte
+	err := cbor.Unmarshal(b, &bz)
+	if err != nil {
+		return err
+	}
+
+	if len(bz) == 0 {
+		return nil
+	}
+	pub.PublicKey, err = crypto.DecodePublicKey(crypto.BLSBLS12381, bz)
+	return err
+}
+
 func (pub *RandomBeaconPubKey) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, pub.PublicKey.Encode())
 }
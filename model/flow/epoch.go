@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/vmihailenco/msgpack/v4"
 
 	"github.com/onflow/flow-go/crypto"
@@ -137,6 +138,20 @@ func (commit *EpochCommit) UnmarshalMsgpack(b []byte) error {
 	return nil
 }
 
+func (commit *EpochCommit) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(encodableFromCommit(commit))
+}
+
+func (commit *EpochCommit) UnmarshalCBOR(b []byte) error {
+	var enc encodableCommit
+	err := cbor.Unmarshal(b, &enc)
+	if err != nil {
+		return err
+	}
+	*commit = commitFromEncodable(enc)
+	return nil
+}
+
 // EncodeRLP encodes the commit as RLP. The RLP encoding needs to be handled
 // differently from JSON/msgpack, because it does not handle custom encoders
 // within map types.
@@ -236,6 +251,20 @@ func (part *DKGParticipant) UnmarshalMsgpack(b []byte) error {
 	return nil
 }
 
+func (part DKGParticipant) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(encodableFromDKGParticipant(part))
+}
+
+func (part *DKGParticipant) UnmarshalCBOR(b []byte) error {
+	var enc encodableDKGParticipant
+	err := cbor.Unmarshal(b, &enc)
+	if err != nil {
+		return err
+	}
+	*part = dkgParticipantFromEncodable(enc)
+	return nil
+}
+
 func (part DKGParticipant) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, encodableFromDKGParticipant(part))
 }
@@ -250,6 +279,22 @@ type EpochStatus struct {
 	NextEpoch     EventIDs // EpochSetup and EpochCommit events for the next epoch
 }
 
+// EpochRecord records the service events and the height boundaries of an
+// epoch, as observed by the finalized blocks of the local node. It is kept
+// for all epochs the node knows of, even after the blocks of the epoch were
+// pruned. Heights which were not observed, such as those of phases that
+// started before the root block, or of phases that did not start yet, are
+// zero.
+type EpochRecord struct {
+	Counter              uint64     // the number of the epoch
+	SetupID              Identifier // the ID of the EpochSetup event for the epoch
+	CommitID             Identifier // the ID of the EpochCommit event for the epoch, ZeroID if not committed yet
+	FirstHeight          uint64     // the height of the first block of the epoch
+	SetupPhaseHeight     uint64     // the height of the first block of the setup phase of the epoch
+	CommittedPhaseHeight uint64     // the height of the first block of the committed phase of the epoch
+	FinalHeight          uint64     // the height of the final block of the epoch
+}
+
 // EventIDs is a container for IDs of epoch service events.
 type EventIDs struct {
 	// SetupID is the ID of the EpochSetup event for the respective Epoch
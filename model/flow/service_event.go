@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/vmihailenco/msgpack/v4"
 )
 
@@ -133,3 +134,50 @@ func (se *ServiceEvent) UnmarshalMsgpack(b []byte) error {
 	}
 	return nil
 }
+
+func (se *ServiceEvent) UnmarshalCBOR(b []byte) error {
+
+	// keep the event raw, as CBOR can not decode maps keyed by identifiers,
+	// such as the DKG participants, into generic values
+	var enc struct {
+		Type  string
+		Event cbor.RawMessage
+	}
+	err := cbor.Unmarshal(b, &enc)
+	if err != nil {
+		return err
+	}
+
+	if enc.Type == "" {
+		return fmt.Errorf("missing type key")
+	}
+	if len(enc.Event) == 0 {
+		return fmt.Errorf("missing event key")
+	}
+
+	var event interface{}
+	switch enc.Type {
+	case ServiceEventSetup:
+		setup := new(EpochSetup)
+		err = cbor.Unmarshal(enc.Event, setup)
+		if err != nil {
+			return err
+		}
+		event = setup
+	case ServiceEventCommit:
+		commit := new(EpochCommit)
+		err = cbor.Unmarshal(enc.Event, commit)
+		if err != nil {
+			return err
+		}
+		event = commit
+	default:
+		return fmt.Errorf("invalid type: %s", enc.Type)
+	}
+
+	*se = ServiceEvent{
+		Type:  enc.Type,
+		Event: event,
+	}
+	return nil
+}
@@ -216,6 +216,12 @@ func (tb *TransactionBody) signerList() []Address {
 	return signers
 }
 
+// Accounts returns the accounts involved in the transaction, which are the
+// proposer, the payer and the authorizers, each listed once.
+func (tb *TransactionBody) Accounts() []Address {
+	return tb.signerList()
+}
+
 // signerMap returns a mapping from address to signer index.
 func (tb *TransactionBody) signerMap() map[Address]int {
 	signers := make(map[Address]int)
@@ -0,0 +1,9 @@
+package flow
+
+// TransactionLocation locates a transaction in the finalized block which
+// included it.
+type TransactionLocation struct {
+	TransactionID Identifier
+	BlockID       Identifier
+	Height        uint64
+}
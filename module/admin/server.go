@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Server is the http server that serves the admin endpoints of a node, which
+// allow operators to inspect its internal state. As the endpoints expose
+// details about the node, it should only listen on a local address.
+type Server struct {
+	server *http.Server
+	mux    *http.ServeMux
+	log    zerolog.Logger
+}
+
+// NewServer creates a new admin server that will listen on the given address.
+// Endpoints are added with Handle before the server is started.
+func NewServer(log zerolog.Logger, addr string) *Server {
+	mux := http.NewServeMux()
+
+	s := &Server{
+		server: &http.Server{Addr: addr, Handler: mux},
+		mux:    mux,
+		log:    log.With().Str("component", "admin_server").Logger(),
+	}
+
+	return s
+}
+
+// Handle registers the handler for the given endpoint.
+func (s *Server) Handle(pattern string, handler http.Handler) *Server {
+	s.mux.Handle(pattern, handler)
+	return s
+}
+
+// Ready returns a channel that will close when the server is started.
+func (s *Server) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil {
+			// http.ErrServerClosed is returned when Close or Shutdown is called
+			// we don't consider this an error, so print this with debug level instead
+			if errors.Is(err, http.ErrServerClosed) {
+				s.log.Debug().Err(err).Msg("admin server shutdown")
+			} else {
+				s.log.Err(err).Msg("error shutting down admin server")
+			}
+		}
+	}()
+	go func() {
+		close(ready)
+	}()
+	return ready
+}
+
+// Done returns a channel that will close when shutdown is complete.
+func (s *Server) Done() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = s.server.Shutdown(ctx)
+		cancel()
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,254 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/pb"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// protocolFilename is the name of the backup of the protocol state database
+// within a backup directory. It uses the format of badger's own backups.
+const protocolFilename = "protocol.bak"
+
+// SourceExecutionState is the name of the execution state ledger within the
+// backups of execution nodes.
+const SourceExecutionState = "execution_state"
+
+// SourceChunkDataPacks is the name of the chunk data pack database within the
+// backups of execution nodes which keep chunk data packs out of the protocol
+// state database.
+const SourceChunkDataPacks = "chunk_data_packs"
+
+// protocolBatchSize is the number of bytes of key-value pairs after which the
+// pairs read from the protocol state database are written to the backup.
+const protocolBatchSize = 4 << 20
+
+// Source is a store of the node which is backed up along with its protocol
+// state database, such as the execution state ledger.
+type Source interface {
+	// Backup copies a consistent state of the store into the given empty
+	// directory and returns the names of the copied files.
+	Backup(dir string) ([]string, error)
+}
+
+// Backuper backs up the databases of a running node. The protocol state
+// database is backed up within a single read transaction, so that it is
+// consistent in itself and with the heights of the manifest. The other sources
+// are backed up afterwards, so their state is at least as recent as the one of
+// the protocol state, which is what a node expects on startup.
+type Backuper struct {
+	log     zerolog.Logger
+	db      *badger.DB
+	mu      sync.Mutex // only one backup is taken at a time
+	sources map[string]Source
+}
+
+// New creates a new backuper for the given protocol state database.
+func New(log zerolog.Logger, db *badger.DB) *Backuper {
+	b := &Backuper{
+		log:     log.With().Str("component", "backup").Logger(),
+		db:      db,
+		sources: make(map[string]Source),
+	}
+	return b
+}
+
+// AddSource adds a store to back up along with the protocol state database,
+// into the subdirectory of the given name.
+func (b *Backuper) AddSource(name string, source Source) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sources[name] = source
+}
+
+// Backup backs up the node into the given directory, which must not exist
+// yet, and returns the manifest of the backup.
+func (b *Backuper) Backup(dir string) (*Manifest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := os.MkdirAll(filepath.Dir(dir), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create parent directory: %w", err)
+	}
+	err = os.Mkdir(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create backup directory: %w", err)
+	}
+
+	log := b.log.With().Str("dir", dir).Logger()
+	start := time.Now()
+
+	manifest := &Manifest{
+		Version:   ManifestVersion,
+		CreatedAt: start.UTC(),
+		Sources:   make([]string, 0, len(b.sources)),
+	}
+	files := []string{protocolFilename}
+
+	err = b.backupProtocolState(filepath.Join(dir, protocolFilename), manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not back up protocol state: %w", err)
+	}
+
+	log.Info().
+		Uint64("finalized_height", manifest.FinalizedHeight).
+		Uint64("entries", manifest.ProtocolEntries).
+		Dur("duration", time.Since(start)).
+		Msg("protocol state backed up")
+
+	for name := range b.sources {
+		manifest.Sources = append(manifest.Sources, name)
+	}
+	sort.Strings(manifest.Sources)
+
+	for _, name := range manifest.Sources {
+		err = os.Mkdir(filepath.Join(dir, name), 0700)
+		if err != nil {
+			return nil, fmt.Errorf("could not create directory for %s: %w", name, err)
+		}
+		names, err := b.sources[name].Backup(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("could not back up %s: %w", name, err)
+		}
+		for _, file := range names {
+			files = append(files, path.Join(name, file))
+		}
+		log.Info().Str("source", name).Int("files", len(names)).Msg("source backed up")
+	}
+
+	for _, file := range files {
+		f, err := checksum(dir, file)
+		if err != nil {
+			return nil, fmt.Errorf("could not checksum file %s: %w", file, err)
+		}
+		manifest.Files = append(manifest.Files, f)
+	}
+
+	err = writeManifest(dir, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	log.Info().Dur("duration", time.Since(start)).Msg("backup completed")
+
+	return manifest, nil
+}
+
+// backupProtocolState writes the latest version of all key-value pairs of the
+// protocol state database into the file at the given path, in the format of
+// badger's backups, and records the state of the protocol in the manifest.
+func (b *Backuper) backupProtocolState(filename string, manifest *Manifest) error {
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create backup file: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+
+	err = b.db.View(func(tx *badger.Txn) error {
+
+		err := operation.RetrieveRootHeight(&manifest.RootHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve root height: %w", err)
+		}
+		err = operation.RetrieveSealedHeight(&manifest.SealedHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve sealed height: %w", err)
+		}
+		err = operation.RetrieveFinalizedHeight(&manifest.FinalizedHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized height: %w", err)
+		}
+		err = operation.LookupBlockHeight(manifest.FinalizedHeight, &manifest.FinalizedBlockID)(tx)
+		if err != nil {
+			return fmt.Errorf("could not look up finalized block: %w", err)
+		}
+		var final flow.Header
+		err = operation.RetrieveHeader(manifest.FinalizedBlockID, &final)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized header: %w", err)
+		}
+		manifest.ChainID = final.ChainID
+
+		it := tx.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		list := &pb.KVList{}
+		size := 0
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return fmt.Errorf("could not read value of key %x: %w", item.Key(), err)
+			}
+			list.Kv = append(list.Kv, &pb.KV{
+				Key:       item.KeyCopy(nil),
+				Value:     val,
+				UserMeta:  []byte{item.UserMeta()},
+				Version:   item.Version(),
+				ExpiresAt: item.ExpiresAt(),
+			})
+			manifest.ProtocolEntries++
+			size += len(item.Key()) + len(val)
+			if size < protocolBatchSize {
+				continue
+			}
+			err = writeList(w, list)
+			if err != nil {
+				return err
+			}
+			list = &pb.KVList{}
+			size = 0
+		}
+
+		if len(list.Kv) > 0 {
+			return writeList(w, list)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush backup file: %w", err)
+	}
+
+	return file.Sync()
+}
+
+// writeList writes a list of key-value pairs prefixed with its length, as
+// expected by badger when loading a backup.
+func writeList(w io.Writer, list *pb.KVList) error {
+	data, err := list.Marshal()
+	if err != nil {
+		return fmt.Errorf("could not encode key-value pairs: %w", err)
+	}
+	err = binary.Write(w, binary.LittleEndian, uint64(len(data)))
+	if err != nil {
+		return fmt.Errorf("could not write length: %w", err)
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write key-value pairs: %w", err)
+	}
+	return nil
+}
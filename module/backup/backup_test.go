@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// fileSource is a source backing up a single file with fixed content.
+type fileSource struct {
+	content []byte
+}
+
+func (s *fileSource) Backup(dir string) ([]string, error) {
+	err := ioutil.WriteFile(filepath.Join(dir, "data"), s.content, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"data"}, nil
+}
+
+// withBackup runs the given function with a backup of a database holding a
+// finalized block and some other entries.
+func withBackup(t *testing.T, f func(dir string, manifest *Manifest, final *flow.Header)) {
+	unittest.RunWithTempDir(t, func(tmp string) {
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+			final := unittest.BlockHeaderFixture()
+			final.Height = 42
+			err := db.Update(func(tx *badger.Txn) error {
+				err := operation.InsertRootHeight(10)(tx)
+				require.NoError(t, err)
+				err = operation.InsertSealedHeight(40)(tx)
+				require.NoError(t, err)
+				err = operation.InsertFinalizedHeight(final.Height)(tx)
+				require.NoError(t, err)
+				err = operation.InsertHeader(final.ID(), &final)(tx)
+				require.NoError(t, err)
+				return operation.IndexBlockHeight(final.Height, final.ID())(tx)
+			})
+			require.NoError(t, err)
+
+			backuper := New(zerolog.Nop(), db)
+			backuper.AddSource("ledger", &fileSource{content: []byte("ledger state")})
+
+			dir := filepath.Join(tmp, "backup")
+			manifest, err := backuper.Backup(dir)
+			require.NoError(t, err)
+
+			f(dir, manifest, &final)
+		})
+	})
+}
+
+func TestBackupRestore(t *testing.T) {
+	withBackup(t, func(dir string, manifest *Manifest, final *flow.Header) {
+
+		assert.Equal(t, uint64(10), manifest.RootHeight)
+		assert.Equal(t, uint64(40), manifest.SealedHeight)
+		assert.Equal(t, final.Height, manifest.FinalizedHeight)
+		assert.Equal(t, final.ID(), manifest.FinalizedBlockID)
+		assert.Equal(t, final.ChainID, manifest.ChainID)
+		assert.Equal(t, uint64(5), manifest.ProtocolEntries)
+		assert.Equal(t, []string{"ledger"}, manifest.Sources)
+		require.Len(t, manifest.Files, 2)
+		assert.Equal(t, protocolFilename, manifest.Files[0].Path)
+		assert.Equal(t, "ledger/data", manifest.Files[1].Path)
+
+		read, err := ReadManifest(dir)
+		require.NoError(t, err)
+		assert.Equal(t, manifest.Files, read.Files)
+		assert.True(t, manifest.CreatedAt.Equal(read.CreatedAt))
+
+		unittest.RunWithTempDir(t, func(ledgerDir string) {
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+				restored, err := Restore(zerolog.Nop(), dir, db, map[string]string{"ledger": ledgerDir})
+				require.NoError(t, err)
+				assert.Equal(t, manifest.FinalizedBlockID, restored.FinalizedBlockID)
+
+				var header flow.Header
+				err = db.View(operation.RetrieveHeader(final.ID(), &header))
+				require.NoError(t, err)
+				assert.Equal(t, final.ID(), header.ID())
+
+				content, err := ioutil.ReadFile(filepath.Join(ledgerDir, "data"))
+				require.NoError(t, err)
+				assert.Equal(t, []byte("ledger state"), content)
+			})
+		})
+	})
+}
+
+func TestBackup_ExistingDir(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			_, err := New(zerolog.Nop(), db).Backup(dir)
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestRestore_Corrupted(t *testing.T) {
+	withBackup(t, func(dir string, manifest *Manifest, final *flow.Header) {
+
+		err := ioutil.WriteFile(filepath.Join(dir, "ledger", "data"), []byte("ledger stat3"), 0600)
+		require.NoError(t, err)
+
+		_, err = Verify(dir)
+		assert.Error(t, err)
+
+		unittest.RunWithTempDir(t, func(ledgerDir string) {
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+				_, err := Restore(zerolog.Nop(), dir, db, map[string]string{"ledger": ledgerDir})
+				assert.Error(t, err)
+			})
+		})
+	})
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	withBackup(t, func(dir string, manifest *Manifest, final *flow.Header) {
+
+		// the ledger needs a directory to be restored into
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			_, err := Restore(zerolog.Nop(), dir, db, nil)
+			assert.Error(t, err)
+		})
+
+		// the database needs to be empty
+		unittest.RunWithTempDir(t, func(ledgerDir string) {
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+				err := db.Update(operation.InsertRootHeight(1))
+				require.NoError(t, err)
+				_, err = Restore(zerolog.Nop(), dir, db, map[string]string{"ledger": ledgerDir})
+				assert.Error(t, err)
+			})
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// Handler takes backups of the node on request of its operators. A POST
+// request backs up the node into the directory given by the `dir` query
+// parameter, on the file system of the node, and is answered with the JSON
+// manifest of the backup once it is completed.
+type Handler struct {
+	log      zerolog.Logger
+	backuper *Backuper
+}
+
+// NewHandler returns a handler taking backups with the given backuper.
+func NewHandler(log zerolog.Logger, backuper *Backuper) *Handler {
+	return &Handler{
+		log:      log.With().Str("component", "backup_handler").Logger(),
+		backuper: backuper,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dir := r.URL.Query().Get("dir")
+	if dir == "" {
+		http.Error(w, "missing dir parameter", http.StatusBadRequest)
+		return
+	}
+
+	manifest, err := h.backuper.Backup(dir)
+	if err != nil {
+		h.log.Error().Err(err).Str("dir", dir).Msg("could not back up node")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(manifest)
+	if err != nil {
+		h.log.Error().Err(err).Msg("could not write backup manifest")
+	}
+}
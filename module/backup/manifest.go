@@ -0,0 +1,130 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ManifestFilename is the name of the manifest within a backup directory. It
+// is written last, so a backup directory without a manifest is incomplete.
+const ManifestFilename = "manifest.json"
+
+// ManifestVersion is the version of the backup format described by manifests
+// written by this node software.
+const ManifestVersion = 1
+
+// Manifest describes a backup of a node: the state of the protocol at the time
+// of the backup and the files it consists of, with their checksums.
+type Manifest struct {
+	Version          uint            `json:"version"`
+	CreatedAt        time.Time       `json:"created_at"`
+	ChainID          flow.ChainID    `json:"chain_id"`
+	RootHeight       uint64          `json:"root_height"`
+	SealedHeight     uint64          `json:"sealed_height"`
+	FinalizedHeight  uint64          `json:"finalized_height"`
+	FinalizedBlockID flow.Identifier `json:"finalized_block_id"`
+	ProtocolEntries  uint64          `json:"protocol_entries"` // key-value pairs in the protocol state backup
+	Sources          []string        `json:"sources"`          // names of the stores backed up along with the protocol state
+	Files            []File          `json:"files"`
+}
+
+// File is a file of a backup.
+type File struct {
+	Path   string `json:"path"` // slash-separated, relative to the backup directory
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"` // hex-encoded
+}
+
+// ReadManifest reads the manifest of the backup in the given directory.
+func ReadManifest(dir string) (*Manifest, error) {
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFilename))
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode manifest: %w", err)
+	}
+
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported backup version (%d != %d)", manifest.Version, ManifestVersion)
+	}
+
+	return &manifest, nil
+}
+
+// Verify checks that the files of the backup in the given directory match the
+// sizes and checksums of its manifest, and returns the manifest.
+func Verify(dir string) (*Manifest, error) {
+
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, expected := range manifest.Files {
+		actual, err := checksum(dir, expected.Path)
+		if err != nil {
+			return nil, fmt.Errorf("could not checksum file %s: %w", expected.Path, err)
+		}
+		if actual != expected {
+			return nil, fmt.Errorf("file %s is corrupted (size %d, checksum %s, expected size %d, checksum %s)",
+				expected.Path, actual.Size, actual.SHA256, expected.Size, expected.SHA256)
+		}
+	}
+
+	return manifest, nil
+}
+
+// writeManifest writes the manifest into the given backup directory.
+func writeManifest(dir string, manifest *Manifest) error {
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode manifest: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, ManifestFilename), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create manifest: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	return file.Sync()
+}
+
+// checksum returns the size and checksum of the file with the given
+// slash-separated path within the backup directory.
+func checksum(dir string, path string) (File, error) {
+
+	file, err := os.Open(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		return File{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{Path: path, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
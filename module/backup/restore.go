@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// restoreMaxPendingWrites is the number of pending writes while loading the
+// protocol state backup into the database.
+const restoreMaxPendingWrites = 256
+
+// Restore verifies the backup in the given directory and restores it: the
+// protocol state into the given database, which must be empty, and the files
+// of each other source into the directory given for it, which must be empty or
+// not exist yet. Afterwards, the restored protocol state is checked against the
+// manifest, which is returned.
+func Restore(log zerolog.Logger, dir string, db *badger.DB, targets map[string]string) (*Manifest, error) {
+
+	log = log.With().Str("component", "backup").Str("dir", dir).Logger()
+
+	manifest, err := Verify(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify backup: %w", err)
+	}
+
+	log.Info().
+		Uint64("finalized_height", manifest.FinalizedHeight).
+		Time("created_at", manifest.CreatedAt).
+		Msg("backup verified")
+
+	for _, name := range manifest.Sources {
+		target, ok := targets[name]
+		if !ok {
+			return nil, fmt.Errorf("no directory to restore %s into", name)
+		}
+		err = requireEmptyDir(target)
+		if err != nil {
+			return nil, fmt.Errorf("could not restore %s: %w", name, err)
+		}
+	}
+
+	var entries uint64
+	err = db.View(countEntries(&entries))
+	if err != nil {
+		return nil, fmt.Errorf("could not count database entries: %w", err)
+	}
+	if entries > 0 {
+		return nil, fmt.Errorf("database is not empty (%d entries)", entries)
+	}
+
+	err = loadProtocolState(db, filepath.Join(dir, protocolFilename))
+	if err != nil {
+		return nil, fmt.Errorf("could not restore protocol state: %w", err)
+	}
+
+	for _, file := range manifest.Files {
+		parts := strings.SplitN(file.Path, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		target, ok := targets[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("file %s does not belong to a backed up source", file.Path)
+		}
+		err = copyFile(filepath.Join(dir, filepath.FromSlash(file.Path)), filepath.Join(target, filepath.FromSlash(parts[1])))
+		if err != nil {
+			return nil, fmt.Errorf("could not restore file %s: %w", file.Path, err)
+		}
+	}
+
+	err = checkProtocolState(db, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("restored protocol state does not match manifest: %w", err)
+	}
+
+	log.Info().Uint64("entries", manifest.ProtocolEntries).Msg("backup restored")
+
+	return manifest, nil
+}
+
+// loadProtocolState loads the protocol state backup at the given path into the
+// database.
+func loadProtocolState(db *badger.DB, filename string) error {
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("could not open backup file: %w", err)
+	}
+	defer file.Close()
+
+	err = db.Load(file, restoreMaxPendingWrites)
+	if err != nil {
+		return fmt.Errorf("could not load backup file: %w", err)
+	}
+
+	return nil
+}
+
+// checkProtocolState checks that the database holds the number of entries and
+// the finalized block of the manifest.
+func checkProtocolState(db *badger.DB, manifest *Manifest) error {
+	return db.View(func(tx *badger.Txn) error {
+
+		var entries uint64
+		err := countEntries(&entries)(tx)
+		if err != nil {
+			return fmt.Errorf("could not count entries: %w", err)
+		}
+		if entries != manifest.ProtocolEntries {
+			return fmt.Errorf("wrong number of entries (%d != %d)", entries, manifest.ProtocolEntries)
+		}
+
+		var height uint64
+		err = operation.RetrieveFinalizedHeight(&height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized height: %w", err)
+		}
+		if height != manifest.FinalizedHeight {
+			return fmt.Errorf("wrong finalized height (%d != %d)", height, manifest.FinalizedHeight)
+		}
+
+		var blockID flow.Identifier
+		err = operation.LookupBlockHeight(height, &blockID)(tx)
+		if err != nil {
+			return fmt.Errorf("could not look up finalized block: %w", err)
+		}
+		if blockID != manifest.FinalizedBlockID {
+			return fmt.Errorf("wrong finalized block (%x != %x)", blockID, manifest.FinalizedBlockID)
+		}
+
+		return nil
+	})
+}
+
+// countEntries counts the key-value pairs of the database.
+func countEntries(entries *uint64) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := tx.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			*entries++
+		}
+		return nil
+	}
+}
+
+// requireEmptyDir creates the given directory if it does not exist yet and
+// returns an error if it contains any files.
+func requireEmptyDir(dir string) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("could not read directory %s: %w", dir, err)
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("directory %s is not empty", dir)
+	}
+	return nil
+}
+
+// copyFile copies the file at the source path to the target path, which must
+// not exist yet, and syncs it to disk.
+func copyFile(source string, target string) error {
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("could not open source file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create target file: %w", err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("could not copy file: %w", err)
+	}
+
+	return out.Sync()
+}
@@ -197,6 +197,8 @@ func (b *Builder) BuildOn(parentID flow.Identifier, setter func(*flow.Header) er
 		var transactions []*flow.TransactionBody
 		var totalByteSize uint64
 		var totalGas uint64
+		// PRIORITY: we consider transactions in the order of the mempool,
+		// which is by priority for priority mempools
 		for _, tx := range b.transactions.All() {
 
 			// if we have reached maximum number of transactions, stop
@@ -786,6 +786,97 @@ func (suite *BuilderSuite) TestBuildOn_UnlimitedPayer() {
 	}
 }
 
+func (suite *BuilderSuite) TestBuildOn_PriorityPayers() {
+
+	// create builder with max 10 tx/collection and no rate limiting, on a
+	// mempool which orders transactions by two priority payers
+	first := unittest.RandomAddressFixture()
+	second := unittest.RandomAddressFixture()
+	pool := stdmap.NewPriorityTransactions(1000, builder.PayerPriority(first, second))
+	suite.builder = builder.NewBuilder(suite.db, trace.NewNoopTracer(), suite.headers, suite.headers, suite.payloads, pool,
+		builder.WithMaxCollectionSize(10),
+	)
+
+	create := func(payer flow.Address) *flow.TransactionBody {
+		tx := unittest.TransactionBodyFixture()
+		tx.ReferenceBlockID = suite.ProtoStateRoot().ID()
+		tx.Payer = payer
+		return &tx
+	}
+
+	// fill the pool with spam transactions from other payers, as well as 6
+	// transactions of each priority payer
+	for i := 0; i < 100; i++ {
+		pool.Add(create(unittest.RandomAddressFixture()))
+	}
+	var firstIDs, secondIDs []flow.Identifier
+	for i := 0; i < 6; i++ {
+		tx := create(second)
+		suite.Require().True(pool.Add(tx))
+		secondIDs = append(secondIDs, tx.ID())
+		tx = create(first)
+		suite.Require().True(pool.Add(tx))
+		firstIDs = append(firstIDs, tx.ID())
+	}
+
+	header, err := suite.builder.BuildOn(suite.genesis.ID(), noopSetter)
+	suite.Require().Nil(err)
+
+	// the collection should contain all transactions of the first priority
+	// payer, followed by the first transactions of the second priority payer,
+	// each in the order they were added to the pool
+	var built model.Block
+	err = suite.db.View(procedure.RetrieveClusterBlock(header.ID(), &built))
+	suite.Require().Nil(err)
+	suite.Require().Len(built.Payload.Collection.Transactions, 10)
+	suite.Assert().Equal(append(firstIDs, secondIDs[:4]...), flow.GetIDs(built.Payload.Collection.Transactions))
+}
+
+func (suite *BuilderSuite) TestBuildOn_PriorityPayersRateLimit() {
+
+	// create builder with max 10 tx/collection and 1 tx/payer/collection, on
+	// a mempool which orders transactions by a priority payer
+	priority := unittest.RandomAddressFixture()
+	pool := stdmap.NewPriorityTransactions(1000, builder.PayerPriority(priority))
+	suite.builder = builder.NewBuilder(suite.db, trace.NewNoopTracer(), suite.headers, suite.headers, suite.payloads, pool,
+		builder.WithMaxCollectionSize(10),
+		builder.WithMaxPayerTransactionRate(1),
+	)
+
+	create := func(payer flow.Address) *flow.TransactionBody {
+		tx := unittest.TransactionBodyFixture()
+		tx.ReferenceBlockID = suite.ProtoStateRoot().ID()
+		tx.Payer = payer
+		return &tx
+	}
+
+	// fill the pool with transactions from other payers, and with more
+	// transactions of the priority payer than fit into a collection
+	for i := 0; i < 100; i++ {
+		pool.Add(create(unittest.RandomAddressFixture()))
+	}
+	for i := 0; i < 10; i++ {
+		pool.Add(create(priority))
+	}
+
+	header, err := suite.builder.BuildOn(suite.genesis.ID(), noopSetter)
+	suite.Require().Nil(err)
+
+	// the priority payer is still rate limited, so only its first transaction
+	// is included, followed by transactions of other payers
+	var built model.Block
+	err = suite.db.View(procedure.RetrieveClusterBlock(header.ID(), &built))
+	suite.Require().Nil(err)
+	suite.Require().Len(built.Payload.Collection.Transactions, 10)
+	for i, tx := range built.Payload.Collection.Transactions {
+		if i == 0 {
+			suite.Assert().Equal(priority, tx.Payer)
+		} else {
+			suite.Assert().NotEqual(priority, tx.Payer)
+		}
+	}
+}
+
 // helper to check whether a collection contains each of the given transactions.
 func collectionContains(collection flow.Collection, txIDs ...flow.Identifier) bool {
 
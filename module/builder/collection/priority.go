@@ -0,0 +1,24 @@
+package collection
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/mempool"
+)
+
+// PayerPriority returns a transaction priority based on a priority list of
+// payers. Transactions paid by the first payer in the list have the highest
+// priority, transactions paid by the second payer the second highest and so
+// on. Transactions by payers which are not in the list have the lowest
+// priority, zero.
+func PayerPriority(payers ...flow.Address) mempool.TransactionPriority {
+	lookup := make(map[flow.Address]uint64, len(payers))
+	for i, payer := range payers {
+		if _, ok := lookup[payer]; ok {
+			continue
+		}
+		lookup[payer] = uint64(len(payers) - i)
+	}
+	return func(tx *flow.TransactionBody) uint64 {
+		return lookup[tx.Payer]
+	}
+}
@@ -4,10 +4,12 @@ package consensus
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/dgraph-io/badger/v2"
 
+	"github.com/onflow/flow-go/model/encoding"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter/id"
 	"github.com/onflow/flow-go/module"
@@ -18,10 +20,22 @@ import (
 	"github.com/onflow/flow-go/storage/badger/operation"
 )
 
+// resource types and reasons reported to the builder metrics; the resource
+// types match the resource labels of the other metrics
+const (
+	resourceGuarantee = "guarantee"
+	resourceSeal      = "seal"
+	resourceReceipt   = "receipt"
+
+	reasonCountLimit = "count_limit"
+	reasonByteLimit  = "byte_limit"
+)
+
 // Builder is the builder for consensus block payloads. Upon providing a payload
 // hash, it also memorizes which entities were included into the payload.
 type Builder struct {
 	metrics   module.MempoolMetrics
+	decisions module.BuilderMetrics
 	tracer    module.Tracer
 	db        *badger.DB
 	state     protocol.MutableState
@@ -39,6 +53,7 @@ type Builder struct {
 // NewBuilder creates a new block builder.
 func NewBuilder(
 	metrics module.MempoolMetrics,
+	decisions module.BuilderMetrics,
 	db *badger.DB,
 	state protocol.MutableState,
 	headers storage.Headers,
@@ -54,13 +69,19 @@ func NewBuilder(
 ) *Builder {
 
 	// initialize default config
+	guaranteeOrdering, receiptOrdering := MempoolOrder()
 	cfg := Config{
-		minInterval:       500 * time.Millisecond,
-		maxInterval:       10 * time.Second,
-		maxSealCount:      100,
-		maxGuaranteeCount: 100,
-		maxReceiptCount:   200,
-		expiry:            flow.DefaultTransactionExpiry,
+		minInterval:          500 * time.Millisecond,
+		maxInterval:          10 * time.Second,
+		maxSealCount:         100,
+		maxGuaranteeCount:    100,
+		maxReceiptCount:      200,
+		maxSealByteSize:      2_000_000,
+		maxGuaranteeByteSize: 500_000,
+		maxReceiptByteSize:   4_000_000,
+		guaranteeOrdering:    guaranteeOrdering,
+		receiptOrdering:      receiptOrdering,
+		expiry:               flow.DefaultTransactionExpiry,
 	}
 
 	// apply option parameters
@@ -70,6 +91,7 @@ func NewBuilder(
 
 	b := &Builder{
 		metrics:   metrics,
+		decisions: decisions,
 		db:        db,
 		tracer:    tracer,
 		state:     state,
@@ -143,7 +165,10 @@ func (b *Builder) BuildOn(parentID flow.Identifier, setter func(*flow.Header) er
 //
 // 3) If the referenced block has an expired height, skip.
 //
-// 4) Otherwise, this guarantee can be included in the payload.
+// 4) Otherwise, this guarantee is eligible for the payload.
+//
+// The eligible guarantees are ordered by the configured GuaranteeOrdering and
+// included in that order, as long as they fit within the count and byte limits.
 func (b *Builder) getInsertableGuarantees(parentID flow.Identifier) ([]*flow.CollectionGuarantee, error) {
 	b.tracer.StartSpan(parentID, trace.CONBuildOnCreatePayloadGuarantees)
 	defer b.tracer.FinishSpan(parentID, trace.CONBuildOnCreatePayloadGuarantees)
@@ -172,8 +197,8 @@ func (b *Builder) getInsertableGuarantees(parentID flow.Identifier) ([]*flow.Col
 		limit = rootHeight
 	}
 
-	// blockLookup keeps track of the blocks from limit to parent
-	blockLookup := make(map[flow.Identifier]struct{})
+	// blockLookup keeps track of the heights of the blocks from limit to parent
+	blockLookup := make(map[flow.Identifier]uint64)
 
 	// receiptLookup keeps track of the receipts contained in blocks between
 	// limit and parent
@@ -189,7 +214,7 @@ func (b *Builder) getInsertableGuarantees(parentID flow.Identifier) ([]*flow.Col
 			return nil, fmt.Errorf("could not get ancestor header (%x): %w", ancestorID, err)
 		}
 
-		blockLookup[ancestorID] = struct{}{}
+		blockLookup[ancestorID] = ancestor.Height
 
 		index, err := b.index.ByBlockID(ancestorID)
 		if err != nil {
@@ -208,14 +233,8 @@ func (b *Builder) getInsertableGuarantees(parentID flow.Identifier) ([]*flow.Col
 	}
 
 	// go through mempool and collect valid collections
-	var guarantees []*flow.CollectionGuarantee
+	var candidates []*GuaranteeCandidate
 	for _, guarantee := range b.guarPool.All() {
-		// add at most <maxGuaranteeCount> number of collection guarantees in a new block proposal
-		// in order to prevent the block payload from being too big or computationally heavy for the
-		// execution nodes
-		if uint(len(guarantees)) >= b.cfg.maxGuaranteeCount {
-			break
-		}
 
 		collID := guarantee.ID()
 
@@ -226,12 +245,44 @@ func (b *Builder) getInsertableGuarantees(parentID flow.Identifier) ([]*flow.Col
 		}
 
 		// skip collections for blocks that are not within the limit
-		_, ok := blockLookup[guarantee.ReferenceBlockID]
+		referenceHeight, ok := blockLookup[guarantee.ReferenceBlockID]
 		if !ok {
 			continue
 		}
 
-		guarantees = append(guarantees, guarantee)
+		candidates = append(candidates, &GuaranteeCandidate{
+			Guarantee:       guarantee,
+			ReferenceHeight: referenceHeight,
+		})
+	}
+
+	b.cfg.guaranteeOrdering(candidates)
+
+	var guarantees []*flow.CollectionGuarantee
+	var byteSize uint
+	for _, candidate := range candidates {
+		// add at most <maxGuaranteeCount> number of collection guarantees in a new block proposal
+		// in order to prevent the block payload from being too big or computationally heavy for the
+		// execution nodes
+		if uint(len(guarantees)) >= b.cfg.maxGuaranteeCount {
+			b.decisions.PayloadItemExcluded(resourceGuarantee, reasonCountLimit)
+			continue
+		}
+
+		// guarantees are independent of each other, so we skip the ones which
+		// exceed the byte limit and still try to fit in smaller ones
+		size, err := encodedSize(candidate.Guarantee)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine guarantee size (%x): %w", candidate.Guarantee.ID(), err)
+		}
+		if byteSize+size > b.cfg.maxGuaranteeByteSize {
+			b.decisions.PayloadItemExcluded(resourceGuarantee, reasonByteLimit)
+			continue
+		}
+
+		byteSize += size
+		guarantees = append(guarantees, candidate.Guarantee)
+		b.decisions.PayloadItemIncluded(resourceGuarantee, size)
 	}
 
 	return guarantees, nil
@@ -241,11 +292,14 @@ func (b *Builder) getInsertableGuarantees(parentID flow.Identifier) ([]*flow.Col
 // inserted in the next payload. It looks in the seal mempool and applies the
 // following filters:
 //
-// 1) Do not collect more than maxSealCount items.
+// 1) Do not collect more than maxSealCount items or maxSealByteSize bytes.
 //
 // 2) The seals should form a valid chain.
 //
 // 3) The seals should correspond to an incorporated result on this fork.
+//
+// As the seals have to form a chain on top of the last seal, their order is
+// given; we include them in that order until we hit one of the limits.
 func (b *Builder) getInsertableSeals(parentID flow.Identifier) ([]*flow.Seal, error) {
 
 	b.tracer.StartSpan(parentID, trace.CONBuildOnCreatePayloadSeals)
@@ -336,9 +390,21 @@ func (b *Builder) getInsertableSeals(parentID flow.Identifier) ([]*flow.Seal, er
 	nextSeal, ok := filteredSeals[nextSealHeight]
 
 	var count uint = 0
+	var byteSize uint = 0
 	for ok {
 		// don't include more than maxSealCount seals
 		if count >= b.cfg.maxSealCount {
+			b.excludeSeals(filteredSeals, nextSealHeight, reasonCountLimit)
+			break
+		}
+
+		// don't include more than maxSealByteSize bytes of seals
+		size, err := encodedSize(nextSeal.Seal)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine seal size (%x): %w", nextSeal.Seal.ID(), err)
+		}
+		if byteSize+size > b.cfg.maxSealByteSize {
+			b.excludeSeals(filteredSeals, nextSealHeight, reasonByteLimit)
 			break
 		}
 
@@ -358,14 +424,25 @@ func (b *Builder) getInsertableSeals(parentID flow.Identifier) ([]*flow.Seal, er
 
 		last = nextSeal.Seal
 		chain = append(chain, nextSeal.Seal)
+		b.decisions.PayloadItemIncluded(resourceSeal, size)
 		nextSealHeight++
 		count++
+		byteSize += size
 		nextSeal, ok = filteredSeals[nextSealHeight]
 	}
 
 	return chain, nil
 }
 
+// excludeSeals reports the seals continuing the chain from the given height,
+// which are left out of the payload for the given reason.
+func (b *Builder) excludeSeals(filteredSeals map[uint64]*flow.IncorporatedResultSeal, height uint64, reason string) {
+	for _, ok := filteredSeals[height]; ok; _, ok = filteredSeals[height] {
+		b.decisions.PayloadItemExcluded(resourceSeal, reason)
+		height++
+	}
+}
+
 type InsertableReceipts struct {
 	receipts []*flow.ExecutionReceiptMeta
 	results  []*flow.ExecutionResult
@@ -382,9 +459,11 @@ type InsertableReceipts struct {
 //
 // 2) If it was already included in the fork, skip it.
 //
-// 3) Otherwise, this receipt can be included in the payload.
+// 3) Otherwise, this receipt is eligible for the payload.
 //
-// Receipts have to be ordered by block height.
+// Receipts have to be ordered by block height. Among the receipts for blocks of
+// the same height, the configured ReceiptOrdering decides which are included
+// first.
 func (b *Builder) getInsertableReceipts(parentID flow.Identifier) (*InsertableReceipts, error) {
 	b.tracer.StartSpan(parentID, trace.CONBuildOnCreatePayloadReceipts)
 	defer b.tracer.FinishSpan(parentID, trace.CONBuildOnCreatePayloadReceipts)
@@ -461,40 +540,95 @@ func (b *Builder) getInsertableReceipts(parentID flow.Identifier) (*InsertableRe
 		return nil, fmt.Errorf("failed to retrieve reachable receipts from memool: %w", err)
 	}
 
-	insertables := toInsertables(receipts, includedResults, b.cfg.maxReceiptCount)
+	candidates := make([]*ReceiptCandidate, 0, len(receipts))
+	for _, receipt := range receipts {
+		executed, err := b.headers.ByBlockID(receipt.ExecutionResult.BlockID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get executed block (%x): %w", receipt.ExecutionResult.BlockID, err)
+		}
+		candidates = append(candidates, &ReceiptCandidate{
+			Receipt:     receipt,
+			BlockHeight: executed.Height,
+		})
+	}
+
+	// apply the configured ordering, but restore the parent-first order of the
+	// results afterwards; a result is always for a block of greater height
+	// than its parent result
+	b.cfg.receiptOrdering(candidates)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].BlockHeight < candidates[j].BlockHeight
+	})
+
+	insertables, err := b.toInsertables(candidates, includedResults)
+	if err != nil {
+		return nil, fmt.Errorf("could not select receipts: %w", err)
+	}
 
 	return insertables, nil
 }
 
 // toInsertables separates the provided receipts into ExecutionReceiptMeta and
 // ExecutionResult. Results that are in includedResults are skipped.
-// We also limit the number of receipts to maxReceiptCount.
-func toInsertables(receipts []*flow.ExecutionReceipt, includedResults map[flow.Identifier]struct{}, maxReceiptCount uint) *InsertableReceipts {
+// We also limit the number of receipts to maxReceiptCount and their byte size,
+// including the results they introduce, to maxReceiptByteSize. As a receipt's
+// result might be the parent of results further down the list, we stop at the
+// first receipt exceeding a limit.
+func (b *Builder) toInsertables(candidates []*ReceiptCandidate, includedResults map[flow.Identifier]struct{}) (*InsertableReceipts, error) {
 	results := make([]*flow.ExecutionResult, 0)
+	filteredReceipts := make([]*flow.ExecutionReceiptMeta, 0, len(candidates))
 
-	count := uint(len(receipts))
-	// don't collect more than maxReceiptCount receipts
-	if count > maxReceiptCount {
-		count = maxReceiptCount
-	}
-
-	filteredReceipts := make([]*flow.ExecutionReceiptMeta, 0, count)
+	var byteSize uint
+	for i, candidate := range candidates {
+		// don't collect more than maxReceiptCount receipts
+		if uint(len(filteredReceipts)) >= b.cfg.maxReceiptCount {
+			b.excludeReceipts(candidates[i:], reasonCountLimit)
+			break
+		}
 
-	for i := uint(0); i < count; i++ {
-		receipt := receipts[i]
+		receipt := candidate.Receipt
 		meta := receipt.Meta()
+		size, err := encodedSize(meta)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine receipt size (%x): %w", receipt.ID(), err)
+		}
 		resultID := meta.ResultID
-		if _, inserted := includedResults[resultID]; !inserted {
+		_, inserted := includedResults[resultID]
+		if !inserted {
+			resultSize, err := encodedSize(&receipt.ExecutionResult)
+			if err != nil {
+				return nil, fmt.Errorf("could not determine result size (%x): %w", resultID, err)
+			}
+			size += resultSize
+		}
+
+		// don't collect more than maxReceiptByteSize bytes of receipts and results
+		if byteSize+size > b.cfg.maxReceiptByteSize {
+			b.excludeReceipts(candidates[i:], reasonByteLimit)
+			break
+		}
+
+		if !inserted {
 			results = append(results, &receipt.ExecutionResult)
 			includedResults[resultID] = struct{}{}
 		}
 
+		byteSize += size
 		filteredReceipts = append(filteredReceipts, meta)
+		b.decisions.PayloadItemIncluded(resourceReceipt, size)
 	}
 
 	return &InsertableReceipts{
 		receipts: filteredReceipts,
 		results:  results,
+	}, nil
+}
+
+// excludeReceipts reports the given receipts as left out of the payload for
+// the given reason.
+func (b *Builder) excludeReceipts(candidates []*ReceiptCandidate, reason string) {
+	for range candidates {
+		b.decisions.PayloadItemExcluded(resourceReceipt, reason)
 	}
 }
 
@@ -589,3 +723,13 @@ func isNoDupAndNotSealed(includedReceipts map[flow.Identifier]struct{}, sealedBl
 		return true
 	}
 }
+
+// encodedSize returns the byte size of the given payload item in the default
+// encoding, which is the encoding blocks are propagated with.
+func encodedSize(item interface{}) (uint, error) {
+	data, err := encoding.DefaultEncoder.Encode(item)
+	if err != nil {
+		return 0, err
+	}
+	return uint(len(data)), nil
+}
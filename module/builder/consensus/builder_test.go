@@ -379,6 +379,7 @@ func (bs *BuilderSuite) SetupTest() {
 
 	// initialize the builder
 	bs.build = NewBuilder(
+		noopMetrics,
 		noopMetrics,
 		bs.db,
 		bs.state,
@@ -478,6 +479,50 @@ func (bs *BuilderSuite) TestPayloadGuaranteeReferenceExpired() {
 	bs.Assert().ElementsMatch(valid, bs.assembled.Guarantees, "should have valid from mempool in payload")
 }
 
+// Test maxGuaranteeByteSize is enforced
+func (bs *BuilderSuite) TestPayloadGuaranteeByteLimit() {
+
+	// add sixteen guarantees to the pool
+	bs.pendingGuarantees = unittest.CollectionGuaranteesFixture(16, unittest.WithCollRef(bs.finalID))
+
+	// change maxGuaranteeByteSize to the size of the first four guarantees
+	var limit uint
+	for _, guarantee := range bs.pendingGuarantees[:4] {
+		size, err := encodedSize(guarantee)
+		bs.Require().NoError(err)
+		limit += size
+	}
+	bs.build.cfg.maxGuaranteeByteSize = limit
+
+	_, err := bs.build.BuildOn(bs.parentID, bs.setter)
+	bs.Require().NoError(err)
+	bs.Assert().NotEmpty(bs.assembled.Guarantees, "should have guarantees within maxGuaranteeByteSize")
+	var total uint
+	for _, guarantee := range bs.assembled.Guarantees {
+		size, err := encodedSize(guarantee)
+		bs.Require().NoError(err)
+		total += size
+	}
+	bs.Assert().LessOrEqual(total, limit, "should have excluded guarantees above maxGuaranteeByteSize")
+}
+
+// Test the guarantee ordering decides which guarantees are included first
+func (bs *BuilderSuite) TestPayloadGuaranteeOldestFirst() {
+
+	// create 4 guarantees referencing the first block and 12 referencing the
+	// last finalized block
+	oldest := unittest.CollectionGuaranteesFixture(4, unittest.WithCollRef(bs.firstID))
+	recent := unittest.CollectionGuaranteesFixture(12, unittest.WithCollRef(bs.finalID))
+	bs.pendingGuarantees = append(recent, oldest...)
+
+	bs.build.cfg.maxGuaranteeCount = uint(len(oldest))
+	bs.build.cfg.guaranteeOrdering = OldestGuaranteesFirst()
+
+	_, err := bs.build.BuildOn(bs.parentID, bs.setter)
+	bs.Require().NoError(err)
+	bs.Assert().ElementsMatch(oldest, bs.assembled.Guarantees, "should have included oldest guarantees first")
+}
+
 func (bs *BuilderSuite) TestPayloadSealAllValid() {
 
 	// use valid chain of seals in mempool
@@ -505,6 +550,26 @@ func (bs *BuilderSuite) TestPayloadSealLimit() {
 	bs.Assert().Equal(bs.chain[:limit], bs.assembled.Seals, "should have excluded seals above maxSealCount")
 }
 
+// Test maxSealByteSize is enforced
+func (bs *BuilderSuite) TestPayloadSealByteLimit() {
+
+	// use valid chain of seals in mempool
+	bs.pendingSeals = bs.irsMap
+
+	// change maxSealByteSize to the size of the first two seals of the chain
+	var limit uint
+	for _, seal := range bs.chain[:2] {
+		size, err := encodedSize(seal)
+		bs.Require().NoError(err)
+		limit += size
+	}
+	bs.build.cfg.maxSealByteSize = limit
+
+	_, err := bs.build.BuildOn(bs.parentID, bs.setter)
+	bs.Require().NoError(err)
+	bs.Assert().Equal(bs.chain[:2], bs.assembled.Seals, "should have excluded seals above maxSealByteSize")
+}
+
 // TestPayloadSealOnlyFork checks that the builder only includes seals corresponding
 // to blocks on the current fork (and _not_ seals for sealable blocks on other forks)
 func (bs *BuilderSuite) TestPayloadSealOnlyFork() {
@@ -793,7 +858,10 @@ func (bs *BuilderSuite) TestPayloadReceipts_AsProvidedByReceiptForest() {
 	var expectedMetas []*flow.ExecutionReceiptMeta
 	var expectedResults []*flow.ExecutionResult
 	for i := 0; i < 10; i++ {
-		expectedReceipts = append(expectedReceipts, unittest.ExecutionReceiptFixture())
+		// the receipts are for unsealed blocks on the fork, as guaranteed by the ExecutionTree's block filter
+		result := unittest.ExecutionResultFixture()
+		result.BlockID = bs.pendingBlockIDs[i%len(bs.pendingBlockIDs)]
+		expectedReceipts = append(expectedReceipts, unittest.ExecutionReceiptFixture(unittest.WithResult(result)))
 		expectedMetas = append(expectedMetas, expectedReceipts[i].Meta())
 		expectedResults = append(expectedResults, &expectedReceipts[i].ExecutionResult)
 	}
@@ -13,6 +13,14 @@ type Config struct {
 	maxSealCount      uint
 	maxGuaranteeCount uint
 	maxReceiptCount   uint
+	// the max encoded byte size of the seals, guarantees and receipts (including
+	// the results they commit to) to be included in a block proposal
+	maxSealByteSize      uint
+	maxGuaranteeByteSize uint
+	maxReceiptByteSize   uint
+	// the orderings deciding which of the eligible items are included first
+	guaranteeOrdering GuaranteeOrdering
+	receiptOrdering   ReceiptOrdering
 	expiry            uint
 }
 
@@ -45,3 +53,33 @@ func WithMaxReceiptCount(maxReceiptCount uint) func(*Config) {
 		cfg.maxReceiptCount = maxReceiptCount
 	}
 }
+
+func WithMaxSealByteSize(maxSealByteSize uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.maxSealByteSize = maxSealByteSize
+	}
+}
+
+func WithMaxGuaranteeByteSize(maxGuaranteeByteSize uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.maxGuaranteeByteSize = maxGuaranteeByteSize
+	}
+}
+
+func WithMaxReceiptByteSize(maxReceiptByteSize uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.maxReceiptByteSize = maxReceiptByteSize
+	}
+}
+
+func WithGuaranteeOrdering(ordering GuaranteeOrdering) func(*Config) {
+	return func(cfg *Config) {
+		cfg.guaranteeOrdering = ordering
+	}
+}
+
+func WithReceiptOrdering(ordering ReceiptOrdering) func(*Config) {
+	return func(cfg *Config) {
+		cfg.receiptOrdering = ordering
+	}
+}
@@ -0,0 +1,66 @@
+package consensus
+
+import (
+	"sort"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// GuaranteeCandidate is a collection guarantee that is eligible for inclusion
+// in the payload under construction, together with the height of the block it
+// references.
+type GuaranteeCandidate struct {
+	Guarantee       *flow.CollectionGuarantee
+	ReferenceHeight uint64
+}
+
+// ReceiptCandidate is an execution receipt that is eligible for inclusion in
+// the payload under construction, together with the height of the block the
+// receipt's result is for.
+type ReceiptCandidate struct {
+	Receipt     *flow.ExecutionReceipt
+	BlockHeight uint64
+}
+
+// GuaranteeOrdering re-orders the eligible guarantees in place, so that the
+// guarantees which should be included first come first. The builder includes
+// guarantees in the resulting order until it hits the count or byte limit.
+type GuaranteeOrdering func(candidates []*GuaranteeCandidate)
+
+// ReceiptOrdering re-orders the eligible receipts in place, so that the
+// receipts which should be included first come first. As a receipt's result
+// can only be incorporated after its parent result, the builder afterwards
+// restores the order by block height; hence, the ordering only decides the
+// precedence among receipts for blocks of the same height.
+type ReceiptOrdering func(candidates []*ReceiptCandidate)
+
+// MempoolOrder keeps the order in which the candidates were retrieved from the
+// mempools. It is the default ordering for guarantees and receipts.
+func MempoolOrder() (GuaranteeOrdering, ReceiptOrdering) {
+	return func([]*GuaranteeCandidate) {}, func([]*ReceiptCandidate) {}
+}
+
+// OldestGuaranteesFirst orders guarantees by the height of their reference
+// block, so that the guarantees which are closest to expiry are included first.
+func OldestGuaranteesFirst() GuaranteeOrdering {
+	return func(candidates []*GuaranteeCandidate) {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].ReferenceHeight < candidates[j].ReferenceHeight
+		})
+	}
+}
+
+// UnderrepresentedExecutorsFirst orders receipts by the number of candidate
+// receipts from the same executor, so that receipts from executors with few
+// pending receipts are not crowded out by executors with many.
+func UnderrepresentedExecutorsFirst() ReceiptOrdering {
+	return func(candidates []*ReceiptCandidate) {
+		counts := make(map[flow.Identifier]int)
+		for _, candidate := range candidates {
+			counts[candidate.Receipt.ExecutorID]++
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return counts[candidates[i].Receipt.ExecutorID] < counts[candidates[j].Receipt.ExecutorID]
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestOldestGuaranteesFirst(t *testing.T) {
+	candidates := []*GuaranteeCandidate{
+		{Guarantee: unittest.CollectionGuaranteeFixture(), ReferenceHeight: 12},
+		{Guarantee: unittest.CollectionGuaranteeFixture(), ReferenceHeight: 10},
+		{Guarantee: unittest.CollectionGuaranteeFixture(), ReferenceHeight: 11},
+	}
+	expected := []*GuaranteeCandidate{candidates[1], candidates[2], candidates[0]}
+
+	OldestGuaranteesFirst()(candidates)
+	assert.Equal(t, expected, candidates)
+}
+
+func TestUnderrepresentedExecutorsFirst(t *testing.T) {
+	busy := unittest.IdentifierFixture()
+	quiet := unittest.IdentifierFixture()
+	receiptFrom := func(executorID flow.Identifier) *ReceiptCandidate {
+		return &ReceiptCandidate{
+			Receipt: unittest.ExecutionReceiptFixture(unittest.WithExecutorID(executorID)),
+		}
+	}
+	candidates := []*ReceiptCandidate{receiptFrom(busy), receiptFrom(busy), receiptFrom(quiet), receiptFrom(busy)}
+	expected := []*ReceiptCandidate{candidates[2], candidates[0], candidates[1], candidates[3]}
+
+	UnderrepresentedExecutorsFirst()(candidates)
+	assert.Equal(t, expected, candidates)
+}
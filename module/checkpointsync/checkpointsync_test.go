@@ -0,0 +1,179 @@
+package checkpointsync_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/module/checkpointsync"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestSnapshot(t *testing.T) {
+	snapshot := unittest.RootSnapshotFixture(unittest.IdentityListFixture(5, unittest.WithAllRoles()))
+	data, err := convert.SnapshotToBytes(snapshot)
+	require.NoError(t, err)
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, checkpointsync.SnapshotPath, r.URL.Path)
+		query = r.URL.RawQuery
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	client := checkpointsync.NewClient(zerolog.Nop(), server.Client())
+
+	t.Run("latest", func(t *testing.T) {
+		downloaded, decoded, err := client.Snapshot(context.Background(), server.URL, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, data, downloaded)
+		assert.Empty(t, query)
+
+		head, err := decoded.Head()
+		require.NoError(t, err)
+		expected, err := snapshot.Head()
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID(), head.ID())
+	})
+
+	t.Run("at height", func(t *testing.T) {
+		_, _, err := client.Snapshot(context.Background(), server.URL, 42, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "height=42", query)
+	})
+
+	t.Run("at epoch", func(t *testing.T) {
+		_, _, err := client.Snapshot(context.Background(), server.URL, 0, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "epoch=3", query)
+	})
+
+	t.Run("height and epoch", func(t *testing.T) {
+		_, _, err := client.Snapshot(context.Background(), server.URL, 42, 3)
+		assert.Error(t, err)
+	})
+}
+
+// countingLedger counts the checkpoints written by the ledger, and blocks
+// writing them while the gate is closed.
+type countingLedger struct {
+	*complete.Ledger
+	written int32
+	gate    chan struct{}
+}
+
+func (l *countingLedger) WriteCheckpointAt(state ledger.State, writer io.Writer) error {
+	atomic.AddInt32(&l.written, 1)
+	<-l.gate
+	return l.Ledger.WriteCheckpointAt(state, writer)
+}
+
+func TestCheckpoint(t *testing.T) {
+	led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+	update := utils.UpdateFixture()
+	update.SetState(led.InitialState())
+	state, err := led.Set(update)
+	require.NoError(t, err)
+
+	gate := make(chan struct{})
+	close(gate)
+	counting := &countingLedger{Ledger: led, gate: gate}
+
+	unittest.RunWithTempDir(t, func(cache string) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			mux := http.NewServeMux()
+			mux.Handle(checkpointsync.CheckpointPath, checkpointsync.NewCheckpointHandler(zerolog.Nop(), counting, cache, 1))
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := checkpointsync.NewClient(zerolog.Nop(), server.Client())
+			path := filepath.Join(dir, "root.checkpoint")
+
+			t.Run("known state", func(t *testing.T) {
+				err := client.Checkpoint(context.Background(), server.URL, state, path)
+				require.NoError(t, err)
+				assert.NoError(t, checkpointsync.VerifyCheckpoint(path, state))
+
+				// the checkpoint does not hold other states
+				err = checkpointsync.VerifyCheckpoint(path, unittest.StateCommitmentFixture())
+				assert.Error(t, err)
+			})
+
+			t.Run("cached state", func(t *testing.T) {
+				err := os.Remove(path)
+				require.NoError(t, err)
+
+				err = client.Checkpoint(context.Background(), server.URL, state, path)
+				require.NoError(t, err)
+				assert.NoError(t, checkpointsync.VerifyCheckpoint(path, state))
+
+				// the checkpoint was only generated once
+				assert.Equal(t, int32(1), atomic.LoadInt32(&counting.written))
+			})
+
+			t.Run("unknown state", func(t *testing.T) {
+				err := os.Remove(path)
+				require.NoError(t, err)
+
+				err = client.Checkpoint(context.Background(), server.URL, unittest.StateCommitmentFixture(), path)
+				assert.Error(t, err)
+
+				// no partial checkpoint is left behind
+				files, err := ioutil.ReadDir(dir)
+				require.NoError(t, err)
+				assert.Empty(t, files)
+				files, err = ioutil.ReadDir(cache)
+				require.NoError(t, err)
+				assert.Len(t, files, 1)
+			})
+
+			t.Run("busy", func(t *testing.T) {
+				counting.gate = make(chan struct{})
+
+				// the first generation blocks the only slot
+				unknown := unittest.StateCommitmentFixture()
+				done := make(chan error)
+				go func() {
+					done <- client.Checkpoint(context.Background(), server.URL, unknown, path)
+				}()
+				require.Eventually(t, func() bool {
+					return atomic.LoadInt32(&counting.written) == 3
+				}, time.Second, 10*time.Millisecond)
+
+				req, err := http.NewRequest(http.MethodGet, server.URL+checkpointsync.CheckpointPath+fmt.Sprintf("%x", unittest.StateCommitmentFixture()), nil)
+				require.NoError(t, err)
+				res, err := server.Client().Do(req)
+				require.NoError(t, err)
+				_ = res.Body.Close()
+				assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+				// cached checkpoints are still served
+				err = client.Checkpoint(context.Background(), server.URL, state, path)
+				require.NoError(t, err)
+
+				close(counting.gate)
+				assert.Error(t, <-done)
+			})
+		})
+	})
+}
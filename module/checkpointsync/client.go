@@ -0,0 +1,187 @@
+package checkpointsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol/inmem"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// SnapshotPath is the HTTP path under which access nodes serve protocol state
+// snapshots.
+const SnapshotPath = "/v1/protocol_state_snapshot"
+
+// Client downloads the data to bootstrap a node at a recent state of the
+// network from its peers, rather than from the spork root: the protocol state
+// snapshot from an access node, and for execution nodes, the checkpoint of
+// the execution state sealed in the snapshot from an execution node.
+type Client struct {
+	log    zerolog.Logger
+	client *http.Client
+}
+
+// NewClient creates a new client using the given HTTP client.
+func NewClient(log zerolog.Logger, client *http.Client) *Client {
+	return &Client{
+		log:    log.With().Str("component", "checkpoint_sync").Logger(),
+		client: client,
+	}
+}
+
+// Snapshot downloads the protocol state snapshot at the given height, or at
+// the start of the given epoch, from the access node with the given address.
+// If neither is given, it downloads the latest sealed snapshot. It returns
+// the encoded snapshot along with the decoded one.
+func (c *Client) Snapshot(ctx context.Context, accessAddr string, height uint64, epoch uint64) ([]byte, *inmem.Snapshot, error) {
+	if height != 0 && epoch != 0 {
+		return nil, nil, fmt.Errorf("only one of height and epoch can be given")
+	}
+
+	query := url.Values{}
+	if height != 0 {
+		query.Set("height", strconv.FormatUint(height, 10))
+	}
+	if epoch != 0 {
+		query.Set("epoch", strconv.FormatUint(epoch, 10))
+	}
+
+	body, err := c.get(ctx, endpoint(accessAddr, SnapshotPath, query))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not request snapshot: %w", err)
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read snapshot: %w", err)
+	}
+	snapshot, err := convert.BytesToInmemSnapshot(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not decode snapshot: %w", err)
+	}
+
+	head, err := snapshot.Head()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get snapshot head: %w", err)
+	}
+	c.log.Info().
+		Uint64("height", head.Height).
+		Hex("block_id", logging.Entity(head)).
+		Msg("downloaded protocol state snapshot")
+
+	return data, snapshot, nil
+}
+
+// Checkpoint downloads the checkpoint of the execution state with the given
+// commitment from the execution node with the given address, and writes it to
+// the given file once it is verified to hold the execution state.
+func (c *Client) Checkpoint(ctx context.Context, executionAddr string, commit flow.StateCommitment, path string) error {
+	body, err := c.get(ctx, endpoint(executionAddr, CheckpointPath+fmt.Sprintf("%x", commit), nil))
+	if err != nil {
+		return fmt.Errorf("could not request checkpoint: %w", err)
+	}
+	defer body.Close()
+
+	// download to a temporary file first, so that we never leave a partial
+	// or invalid checkpoint behind
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("could not create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	n, err := io.Copy(file, body)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("could not download checkpoint: %w", err)
+	}
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("could not write checkpoint file: %w", err)
+	}
+
+	err = VerifyCheckpoint(tmp, commit)
+	if err != nil {
+		return fmt.Errorf("invalid checkpoint: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("could not move checkpoint file: %w", err)
+	}
+
+	c.log.Info().
+		Hex("state_commitment", commit).
+		Int64("size", n).
+		Str("path", path).
+		Msg("downloaded execution state checkpoint")
+
+	return nil
+}
+
+// get requests the given URL and returns the response body, if the request
+// succeeded.
+func (c *Client) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return res.Body, nil
+}
+
+// VerifyCheckpoint verifies that the checkpoint file with the given path holds
+// the execution state with the given commitment.
+func VerifyCheckpoint(path string, commit flow.StateCommitment) error {
+	flatForest, err := wal.LoadCheckpoint(path)
+	if err != nil {
+		return fmt.Errorf("could not load checkpoint: %w", err)
+	}
+	tries, err := flattener.RebuildTries(flatForest)
+	if err != nil {
+		return fmt.Errorf("could not rebuild tries: %w", err)
+	}
+	for _, trie := range tries {
+		if bytes.Equal(trie.RootHash(), commit) {
+			return nil
+		}
+	}
+	return fmt.Errorf("checkpoint does not hold state commitment %x", commit)
+}
+
+// endpoint returns the URL of the given path on the node with the given
+// address, which defaults to the http scheme.
+func endpoint(addr string, path string, query url.Values) string {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	u := strings.TrimSuffix(addr, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
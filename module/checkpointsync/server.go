@@ -0,0 +1,295 @@
+package checkpointsync
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+)
+
+// CheckpointPath is the HTTP path under which execution nodes serve execution
+// state checkpoints, followed by the hex-encoded state commitment, e.g.
+// /v1/checkpoints/<state commitment>.
+const CheckpointPath = "/v1/checkpoints/"
+
+// tempSuffix marks checkpoint files in the cache which are still written.
+const tempSuffix = ".tmp"
+
+// DefaultCheckpointLimit is the default number of checkpoints an execution
+// node generates concurrently. Further requests for checkpoints which are not
+// cached yet are rejected until a generation completes.
+const DefaultCheckpointLimit = 2
+
+// cachedCheckpoints is the number of recently requested checkpoints which are
+// kept on disk, so that they are generated only once for all clients
+// bootstrapping from the same state commitment.
+const cachedCheckpoints = 3
+
+// CheckpointWriter writes checkpoints of the execution state, which is
+// implemented by the ledger.
+type CheckpointWriter interface {
+	WriteCheckpointAt(state ledger.State, writer io.Writer) error
+}
+
+// errBusy is returned when the limit of concurrent checkpoint generations is
+// reached.
+var errBusy = errors.New("too many checkpoints in progress")
+
+// CheckpointHandler serves checkpoints of the recent execution states of an
+// execution node, so that new execution nodes can bootstrap from them. Each
+// checkpoint is written to a cache directory once per state commitment, and
+// served from there, while the number of concurrent generations is limited.
+type CheckpointHandler struct {
+	log     zerolog.Logger
+	ledger  CheckpointWriter
+	dir     string
+	slots   chan struct{}
+	mu      sync.Mutex
+	pending map[string]chan struct{} // in-progress generations by cache file path
+}
+
+// NewCheckpointHandler creates a handler serving checkpoints of the given
+// ledger, which caches them in the given directory and generates at most
+// limit checkpoints concurrently.
+func NewCheckpointHandler(log zerolog.Logger, ledger CheckpointWriter, dir string, limit int) *CheckpointHandler {
+	return &CheckpointHandler{
+		log:     log.With().Str("component", "checkpoint_handler").Logger(),
+		ledger:  ledger,
+		dir:     dir,
+		slots:   make(chan struct{}, limit),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+func (h *CheckpointHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	commit, err := hex.DecodeString(strings.TrimPrefix(r.URL.Path, CheckpointPath))
+	if err != nil || len(commit) == 0 {
+		http.Error(w, "invalid state commitment", http.StatusBadRequest)
+		return
+	}
+
+	h.log.Info().Hex("state_commitment", commit).Msg("serving execution state checkpoint")
+
+	path := filepath.Join(h.dir, hex.EncodeToString(commit))
+	err = h.cache(ledger.State(commit), path)
+	if errors.Is(err, errBusy) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		// the state is no longer, or not yet, in the forest of the ledger
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// the file may be evicted after opening it, which doesn't affect reading it
+	file, err := os.Open(path)
+	if err != nil {
+		h.log.Error().Err(err).Hex("state_commitment", commit).Msg("could not open cached checkpoint")
+		http.Error(w, "checkpoint not available", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		h.log.Error().Err(err).Hex("state_commitment", commit).Msg("could not stat cached checkpoint")
+		http.Error(w, "checkpoint not available", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+// cache makes sure the checkpoint of the given state is cached at the given
+// path. Concurrent requests for the same state wait for a single generation.
+// It returns errBusy if the checkpoint needs to be generated, but the limit of
+// concurrent generations is reached.
+func (h *CheckpointHandler) cache(state ledger.State, path string) error {
+
+	h.mu.Lock()
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			h.mu.Unlock()
+			// mark the checkpoint as recently used, so that it is evicted last
+			now := time.Now()
+			_ = os.Chtimes(path, now, now)
+			return nil
+		}
+		done, ok := h.pending[path]
+		if !ok {
+			break
+		}
+		h.mu.Unlock()
+		<-done
+		h.mu.Lock()
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+	default:
+		h.mu.Unlock()
+		return errBusy
+	}
+	done := make(chan struct{})
+	h.pending[path] = done
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.pending, path)
+		close(done)
+		h.mu.Unlock()
+		<-h.slots
+	}()
+
+	err := h.write(state, path)
+	if err != nil {
+		return err
+	}
+
+	err = h.evict()
+	if err != nil {
+		h.log.Warn().Err(err).Msg("could not evict cached checkpoints")
+	}
+
+	return nil
+}
+
+// write writes the checkpoint of the given state to a temporary file, which is
+// moved to the given path once it is complete.
+func (h *CheckpointHandler) write(state ledger.State, path string) error {
+
+	err := os.MkdirAll(h.dir, 0700)
+	if err != nil {
+		return fmt.Errorf("could not create checkpoint cache: %w", err)
+	}
+	file, err := ioutil.TempFile(h.dir, filepath.Base(path)+tempSuffix)
+	if err != nil {
+		return fmt.Errorf("could not create checkpoint file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	err = h.ledger.WriteCheckpointAt(state, file)
+	if err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	err = file.Sync()
+	if err != nil {
+		return fmt.Errorf("could not sync checkpoint: %w", err)
+	}
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("could not close checkpoint: %w", err)
+	}
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("could not move checkpoint into cache: %w", err)
+	}
+
+	return nil
+}
+
+// evict removes the least recently used checkpoints from the cache.
+func (h *CheckpointHandler) evict() error {
+
+	infos, err := ioutil.ReadDir(h.dir)
+	if err != nil {
+		return fmt.Errorf("could not read checkpoint cache: %w", err)
+	}
+	var cached []os.FileInfo
+	for _, info := range infos {
+		if !info.IsDir() && !strings.Contains(info.Name(), tempSuffix) {
+			cached = append(cached, info)
+		}
+	}
+	if len(cached) <= cachedCheckpoints {
+		return nil
+	}
+
+	sort.Slice(cached, func(i, j int) bool {
+		return cached[i].ModTime().After(cached[j].ModTime())
+	})
+	for _, info := range cached[cachedCheckpoints:] {
+		err = os.Remove(filepath.Join(h.dir, info.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove cached checkpoint %s: %w", info.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Server is the HTTP server of an execution node which serves checkpoints to
+// new execution nodes. Unlike the admin server, it listens on a public address,
+// so the checkpoints are cached and their generation is limited.
+type Server struct {
+	server *http.Server
+	log    zerolog.Logger
+}
+
+// NewServer creates a new server that will listen on the given address and
+// serve checkpoints of the given ledger, which are cached in the given
+// directory.
+func NewServer(log zerolog.Logger, addr string, ledger CheckpointWriter, dir string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle(CheckpointPath, NewCheckpointHandler(log, ledger, dir, DefaultCheckpointLimit))
+
+	return &Server{
+		server: &http.Server{Addr: addr, Handler: mux},
+		log:    log.With().Str("component", "checkpoint_server").Logger(),
+	}
+}
+
+// Ready returns a channel that will close when the server is started.
+func (s *Server) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil {
+			if errors.Is(err, http.ErrServerClosed) {
+				s.log.Debug().Err(err).Msg("checkpoint server shutdown")
+			} else {
+				s.log.Err(err).Msg("error shutting down checkpoint server")
+			}
+		}
+	}()
+	go func() {
+		close(ready)
+	}()
+	return ready
+}
+
+// Done returns a channel that will close when shutdown is complete.
+func (s *Server) Done() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = s.server.Shutdown(ctx)
+		cancel()
+		close(done)
+	}()
+	return done
+}
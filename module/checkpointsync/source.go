@@ -0,0 +1,104 @@
+package checkpointsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// The HTTP paths under which access nodes serve the finalized headers and
+// their proofs, for light clients and to verify downloaded snapshots.
+const (
+	HeadersPath       = "/v1/headers"
+	FinalityProofPath = "/v1/finality_proofs/"
+	EpochProofPath    = "/v1/epoch_proofs/"
+)
+
+// HTTPSource is a proof source requesting the proofs, along with the finalized
+// headers for light clients, from the HTTP API of an access node.
+type HTTPSource struct {
+	addr   string
+	client *http.Client
+}
+
+// NewHTTPSource creates a new source for the access node with the given HTTP
+// address, using the given HTTP client.
+func NewHTTPSource(addr string, client *http.Client) *HTTPSource {
+	s := &HTTPSource{
+		addr:   addr,
+		client: client,
+	}
+	return s
+}
+
+func (s *HTTPSource) Headers(ctx context.Context, height uint64, limit uint) ([]*flow.Header, error) {
+	query := url.Values{}
+	query.Set("height", strconv.FormatUint(height, 10))
+	query.Set("limit", strconv.FormatUint(uint64(limit), 10))
+
+	var headers []*flow.Header
+	_, err := s.get(ctx, HeadersPath, query, &headers)
+	if err != nil {
+		return nil, fmt.Errorf("could not request headers: %w", err)
+	}
+
+	return headers, nil
+}
+
+func (s *HTTPSource) FinalityProof(ctx context.Context, blockID flow.Identifier) (*finality.Proof, error) {
+	var proof finality.Proof
+	_, err := s.get(ctx, FinalityProofPath+blockID.String(), nil, &proof)
+	if err != nil {
+		return nil, fmt.Errorf("could not request finality proof: %w", err)
+	}
+
+	return &proof, nil
+}
+
+func (s *HTTPSource) EpochProof(ctx context.Context, counter uint64) (*finality.EpochProof, error) {
+	var proof finality.EpochProof
+	status, err := s.get(ctx, EpochProofPath+strconv.FormatUint(counter, 10), nil, &proof)
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("epoch %d not available: %w", counter, finality.ErrUnknownTransition)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not request epoch proof: %w", err)
+	}
+
+	return &proof, nil
+}
+
+// get requests the given path and decodes the JSON response into the given
+// target. It returns the status code of the response along with any error.
+func (s *HTTPSource) get(ctx context.Context, path string, query url.Values, target interface{}) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint(s.addr, path, query), nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return res.StatusCode, fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	err = json.NewDecoder(res.Body).Decode(target)
+	if err != nil {
+		return res.StatusCode, fmt.Errorf("could not decode response: %w", err)
+	}
+
+	return res.StatusCode, nil
+}
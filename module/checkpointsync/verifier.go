@@ -0,0 +1,26 @@
+// +build relic
+
+package checkpointsync
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol/inmem"
+)
+
+// VerificationSupported is true if the build can verify the QCs of epochs,
+// which is required to verify snapshots against the root snapshot.
+const VerificationSupported = true
+
+// NewEpochVerifier creates the verifier of the QCs of the epoch with the given
+// service events, which checks the signatures against the staking keys of the
+// consensus participants and the random beacon keys of the epoch.
+func NewEpochVerifier(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.Verifier, error) {
+	epoch, err := inmem.NewCommittedEpoch(setup, commit)
+	if err != nil {
+		return nil, fmt.Errorf("could not create epoch: %w", err)
+	}
+	return finality.NewEpochVerifier(epoch)
+}
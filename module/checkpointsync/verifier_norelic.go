@@ -0,0 +1,20 @@
+// +build !relic
+
+package checkpointsync
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// VerificationSupported is false, as the QCs of epochs can not be verified
+// without the relic build tag.
+const VerificationSupported = false
+
+// NewEpochVerifier is not available without the relic build tag, as the QCs
+// of the epoch are verified against BLS signatures.
+func NewEpochVerifier(_ *flow.EpochSetup, _ *flow.EpochCommit) (*finality.Verifier, error) {
+	return nil, fmt.Errorf("verifying QCs requires the relic build tag")
+}
@@ -0,0 +1,328 @@
+package checkpointsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/state/protocol/inmem"
+)
+
+// ErrInvalidSnapshot is returned when a protocol state snapshot can not be
+// verified against the trusted root.
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// ProofSource provides the proofs to verify a protocol state snapshot against
+// the root snapshot of the spork. Nothing provided by a source is trusted.
+type ProofSource interface {
+
+	// FinalityProof returns the finality proof for the finalized block with
+	// the given ID.
+	FinalityProof(ctx context.Context, blockID flow.Identifier) (*finality.Proof, error)
+
+	// EpochProof returns the proof for the transition into the epoch with the
+	// given counter. It returns an error wrapping finality.ErrUnknownTransition if the
+	// source can not prove the transition, at least not yet.
+	EpochProof(ctx context.Context, counter uint64) (*finality.EpochProof, error)
+}
+
+// VerifierFactory creates the verifier of the QCs of the epoch with the given
+// service events, such as NewEpochVerifier.
+type VerifierFactory func(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.Verifier, error)
+
+// epoch holds the service events of an epoch.
+type epoch struct {
+	setup  *flow.EpochSetup
+	commit *flow.EpochCommit // nil if the epoch is only set up
+}
+
+// VerifySnapshot verifies a protocol state snapshot from an untrusted source,
+// such as a recent snapshot downloaded from an access node, against the root
+// snapshot of the spork, which is trusted. Starting with the epochs of the
+// root, it follows the proven epoch transitions up to the epochs of the
+// snapshot, and checks that the head of the snapshot was finalized by the
+// committee of its epoch. It returns an error wrapping ErrInvalidSnapshot if
+// the snapshot is invalid.
+func VerifySnapshot(ctx context.Context, source ProofSource, root protocol.Snapshot, snapshot protocol.Snapshot, verifiers VerifierFactory) error {
+
+	rootHead, err := root.Head()
+	if err != nil {
+		return fmt.Errorf("could not get root head: %w", err)
+	}
+	head, err := snapshot.Head()
+	if err != nil {
+		return fmt.Errorf("could not get snapshot head: %w", err)
+	}
+	if head.Height < rootHead.Height {
+		return fmt.Errorf("snapshot head is below root (%d < %d): %w", head.Height, rootHead.Height, ErrInvalidSnapshot)
+	}
+
+	err = verifySealingSegment(snapshot, root, head)
+	if err != nil {
+		return err
+	}
+
+	// the committed epochs of the root snapshot are trusted
+	trusted, err := snapshotEpochs(root)
+	if err != nil {
+		return fmt.Errorf("could not get root epochs: %w", err)
+	}
+	epochs := make(map[uint64]*epoch)
+	var last *epoch
+	for _, e := range trusted {
+		if e.commit == nil {
+			continue
+		}
+		epochs[e.setup.Counter] = e
+		last = e
+	}
+
+	claimed, err := snapshotEpochs(snapshot)
+	if err != nil {
+		return fmt.Errorf("could not get snapshot epochs: %w", err)
+	}
+	current, err := snapshot.Epochs().Current().Counter()
+	if err != nil {
+		return fmt.Errorf("could not get current epoch: %w", err)
+	}
+
+	// follow the epoch transitions up to the current epoch of the snapshot
+	for last.setup.Counter < current {
+		proof, err := provenEpoch(ctx, source, last, verifiers)
+		if err != nil {
+			return err
+		}
+		last = &epoch{setup: proof.Setup, commit: proof.Commit}
+		epochs[last.setup.Counter] = last
+	}
+
+	// the root may already know the epoch following the current one
+	last, ok := epochs[current]
+	if !ok {
+		return fmt.Errorf("current epoch %d precedes root epochs: %w", current, ErrInvalidSnapshot)
+	}
+	verifier, err := verifiers(last.setup, last.commit)
+	if err != nil {
+		return fmt.Errorf("could not create verifier for epoch %d: %w", current, err)
+	}
+	err = verifyFinalized(ctx, source, head, verifier)
+	if err != nil {
+		return fmt.Errorf("could not verify snapshot head: %w", err)
+	}
+
+	// the next epoch must be in the phase claimed by the snapshot as of its
+	// head, so that the node doesn't miss the service events sealed before
+	next, err := provenEpoch(ctx, source, last, verifiers)
+	if err != nil && !errors.Is(err, finality.ErrUnknownTransition) {
+		return err
+	}
+	phase, err := snapshot.Phase()
+	if err != nil {
+		return fmt.Errorf("could not get epoch phase: %w", err)
+	}
+	provenPhase := flow.EpochPhaseStaking
+	if next != nil && next.SetupSeal.Header.Height <= head.Height {
+		provenPhase = flow.EpochPhaseSetup
+		if next.CommitSeal.Header.Height <= head.Height {
+			provenPhase = flow.EpochPhaseCommitted
+			epochs[next.Setup.Counter] = &epoch{setup: next.Setup, commit: next.Commit}
+		} else {
+			epochs[next.Setup.Counter] = &epoch{setup: next.Setup}
+		}
+	}
+	if next == nil && phase == flow.EpochPhaseSetup {
+		return fmt.Errorf("setup of epoch %d can not be verified before the epoch is committed: %w", current+1, finality.ErrUnknownTransition)
+	}
+	if phase != provenPhase {
+		return fmt.Errorf("snapshot is in phase %s, but the proven phase is %s: %w", phase, provenPhase, ErrInvalidSnapshot)
+	}
+
+	// all epochs of the snapshot must match the proven ones
+	for _, e := range claimed {
+		proven, ok := epochs[e.setup.Counter]
+		if !ok {
+			return fmt.Errorf("epoch %d of snapshot can not be verified: %w", e.setup.Counter, ErrInvalidSnapshot)
+		}
+		err = matchEpoch(e, proven)
+		if err != nil {
+			return fmt.Errorf("epoch %d of snapshot does not match proven epoch: %w", e.setup.Counter, err)
+		}
+	}
+
+	return nil
+}
+
+// provenEpoch returns the verified proof of the transition into the epoch
+// following the given committed epoch. The blocks sealing the service events
+// of the next epoch must be finalized by the committee of the given epoch. It
+// returns an error wrapping finality.ErrUnknownTransition if the source can not prove
+// the transition yet.
+func provenEpoch(ctx context.Context, source ProofSource, last *epoch, verifiers VerifierFactory) (*finality.EpochProof, error) {
+
+	counter := last.setup.Counter + 1
+	proof, err := source.EpochProof(ctx, counter)
+	if err != nil {
+		return nil, fmt.Errorf("could not get proof for epoch %d: %w", counter, err)
+	}
+	err = proof.Verify(last.setup)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proof for epoch %d: %w", counter, err)
+	}
+
+	verifier, err := verifiers(last.setup, last.commit)
+	if err != nil {
+		return nil, fmt.Errorf("could not create verifier for epoch %d: %w", last.setup.Counter, err)
+	}
+	for _, seal := range []*finality.SealProof{proof.SetupSeal, proof.CommitSeal} {
+		err = verifyFinalized(ctx, source, seal.Header, verifier)
+		if err != nil {
+			return nil, fmt.Errorf("could not verify block sealing service event of epoch %d: %w", counter, err)
+		}
+	}
+
+	return proof, nil
+}
+
+// verifyFinalized verifies that the block with the given header was finalized
+// by the committee of the given verifier.
+func verifyFinalized(ctx context.Context, source ProofSource, header *flow.Header, verifier *finality.Verifier) error {
+	proof, err := source.FinalityProof(ctx, header.ID())
+	if err != nil {
+		return fmt.Errorf("could not get finality proof: %w", err)
+	}
+	finalized, err := verifier.Verify(proof)
+	if err != nil {
+		return fmt.Errorf("invalid finality proof (%s): %w", err, ErrInvalidSnapshot)
+	}
+	if finalized.ID() != header.ID() {
+		return fmt.Errorf("finality proof is for wrong block (%x != %x): %w", finalized.ID(), header.ID(), ErrInvalidSnapshot)
+	}
+	return nil
+}
+
+// verifySealingSegment verifies that the sealing segment of the snapshot is a
+// chain of blocks ending with the given head, which includes the seal of the
+// sealed result of the snapshot, so that the sealed result is covered by the
+// verification of the head. Only the seal of the root is not included in any
+// block.
+func verifySealingSegment(snapshot protocol.Snapshot, root protocol.Snapshot, head *flow.Header) error {
+	segment, err := snapshot.SealingSegment()
+	if err != nil {
+		return fmt.Errorf("could not get sealing segment: %w", err)
+	}
+	if len(segment) == 0 {
+		return fmt.Errorf("empty sealing segment: %w", ErrInvalidSnapshot)
+	}
+	if segment[len(segment)-1].ID() != head.ID() {
+		return fmt.Errorf("sealing segment does not end with head: %w", ErrInvalidSnapshot)
+	}
+
+	result, seal, err := snapshot.SealedResult()
+	if err != nil {
+		return fmt.Errorf("could not get sealed result: %w", err)
+	}
+	if seal.ResultID != result.ID() {
+		return fmt.Errorf("seal is for wrong result: %w", ErrInvalidSnapshot)
+	}
+	_, rootSeal, err := root.SealedResult()
+	if err != nil {
+		return fmt.Errorf("could not get root seal: %w", err)
+	}
+
+	sealID := seal.ID()
+	sealed := sealID == rootSeal.ID()
+	for i, block := range segment {
+		if block.Payload.Hash() != block.Header.PayloadHash {
+			return fmt.Errorf("block %d of sealing segment has invalid payload: %w", i, ErrInvalidSnapshot)
+		}
+		if i > 0 && block.Header.ParentID != segment[i-1].ID() {
+			return fmt.Errorf("block %d of sealing segment does not descend from block %d: %w", i, i-1, ErrInvalidSnapshot)
+		}
+		for _, included := range block.Payload.Seals {
+			if included.ID() == sealID {
+				sealed = true
+			}
+		}
+	}
+	if !sealed {
+		return fmt.Errorf("seal is not included in sealing segment: %w", ErrInvalidSnapshot)
+	}
+
+	return nil
+}
+
+// snapshotEpochs returns the service events of the previous, current and next
+// epochs of the given snapshot, as far as they exist. The commit of the next
+// epoch is nil if it is only set up.
+func snapshotEpochs(snapshot protocol.Snapshot) ([]*epoch, error) {
+
+	var epochs []*epoch
+	queries := []protocol.Epoch{snapshot.Epochs().Previous(), snapshot.Epochs().Current(), snapshot.Epochs().Next()}
+	for _, query := range queries {
+		_, err := query.Counter()
+		if errors.Is(err, protocol.ErrNoPreviousEpoch) || errors.Is(err, protocol.ErrNextEpochNotSetup) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get epoch counter: %w", err)
+		}
+
+		setup, err := protocol.ToEpochSetup(query)
+		if err != nil {
+			return nil, fmt.Errorf("could not get epoch setup: %w", err)
+		}
+		e := &epoch{setup: setup}
+		commit, err := protocol.ToEpochCommit(query)
+		if err != nil && !errors.Is(err, protocol.ErrEpochNotCommitted) {
+			return nil, fmt.Errorf("could not get epoch commit: %w", err)
+		}
+		if err == nil {
+			e.commit = commit
+		}
+		epochs = append(epochs, e)
+	}
+
+	return epochs, nil
+}
+
+// matchEpoch checks that the epoch of a snapshot has the proven service
+// events. As the snapshot holds the epoch in the form of the protocol state,
+// the proven events are converted into that form before they are compared.
+func matchEpoch(claimed *epoch, proven *epoch) error {
+
+	var converted *inmem.Epoch
+	var err error
+	if claimed.commit == nil {
+		converted, err = inmem.NewSetupEpoch(proven.setup)
+	} else if proven.commit != nil {
+		converted, err = inmem.NewCommittedEpoch(proven.setup, proven.commit)
+	} else {
+		return fmt.Errorf("epoch is not committed yet: %w", ErrInvalidSnapshot)
+	}
+	if err != nil {
+		return fmt.Errorf("could not convert proven epoch: %w", err)
+	}
+
+	setup, err := protocol.ToEpochSetup(converted)
+	if err != nil {
+		return fmt.Errorf("could not get proven setup: %w", err)
+	}
+	if setup.ID() != claimed.setup.ID() {
+		return fmt.Errorf("epoch setup does not match: %w", ErrInvalidSnapshot)
+	}
+	if claimed.commit == nil {
+		return nil
+	}
+	commit, err := protocol.ToEpochCommit(converted)
+	if err != nil {
+		return fmt.Errorf("could not get proven commit: %w", err)
+	}
+	if commit.ID() != claimed.commit.ID() {
+		return fmt.Errorf("epoch commit does not match: %w", ErrInvalidSnapshot)
+	}
+
+	return nil
+}
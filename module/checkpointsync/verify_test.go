@@ -0,0 +1,262 @@
+package checkpointsync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/finality"
+	"github.com/onflow/flow-go/consensus/hotstuff/mocks"
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/filter"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/state/protocol/inmem"
+	"github.com/onflow/flow-go/state/protocol/invalid"
+	mockprotocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// source is a proof source for a chain of headers, proving the finality of
+// any header followed by a direct 3-chain.
+type source struct {
+	chain  []*flow.Header
+	proofs map[uint64]*finality.EpochProof
+}
+
+func (s *source) FinalityProof(_ context.Context, blockID flow.Identifier) (*finality.Proof, error) {
+	for i, header := range s.chain {
+		if header.ID() != blockID {
+			continue
+		}
+		for j := i; j+3 < len(s.chain); j++ {
+			if s.chain[j+1].View == s.chain[j].View+1 && s.chain[j+2].View == s.chain[j].View+2 {
+				return &finality.Proof{Headers: s.chain[i : j+4]}, nil
+			}
+		}
+	}
+	return nil, errors.New("no finality proof")
+}
+
+func (s *source) EpochProof(_ context.Context, counter uint64) (*finality.EpochProof, error) {
+	proof, ok := s.proofs[counter]
+	if !ok {
+		return nil, finality.ErrUnknownTransition
+	}
+	return proof, nil
+}
+
+// chainFixture creates a chain of headers, starting with a root header at
+// height and view zero, with one header for each of the given views. Each QC
+// is signed by the committee returned for the view of the certified block, and
+// the headers at the heights of the given payloads commit to these payloads.
+func chainFixture(committee func(view uint64) flow.IdentityList, payloads map[uint64]*flow.Payload, views ...uint64) []*flow.Header {
+	root := unittest.BlockHeaderFixture()
+	root.Height = 0
+	root.View = 0
+	chain := []*flow.Header{&root}
+	for _, view := range views {
+		header := unittest.BlockHeaderWithParentFixture(chain[len(chain)-1])
+		header.View = view
+		header.ParentVoterIDs = committee(chain[len(chain)-1].View).NodeIDs()
+		if payload, ok := payloads[header.Height]; ok {
+			header.PayloadHash = payload.Hash()
+		}
+		chain = append(chain, &header)
+	}
+	return chain
+}
+
+// commitFixture creates an epoch commit with a DKG group key which can be
+// encoded without the BLS library.
+func commitFixture(counter uint64) *flow.EpochCommit {
+	return &flow.EpochCommit{
+		Counter:         counter,
+		DKGGroupKey:     unittest.KeyFixture(crypto.ECDSAP256).PublicKey(),
+		DKGParticipants: make(map[flow.Identifier]flow.DKGParticipant),
+	}
+}
+
+// epochProofFixture creates the proof for the given epoch, whose service
+// events are sealed by a single result, and returns the payload sealing it.
+func epochProofFixture(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.EpochProof, *flow.Payload) {
+	result := unittest.ExecutionResultFixture()
+	result.ServiceEvents = []flow.ServiceEvent{setup.ServiceEvent(), commit.ServiceEvent()}
+	payload := unittest.PayloadFixture(unittest.WithSeals(unittest.Seal.Fixture(unittest.Seal.WithResult(result))))
+	seal := &finality.SealProof{
+		Payload: &payload,
+		Result:  result,
+	}
+	proof := &finality.EpochProof{
+		Setup:      setup,
+		Commit:     commit,
+		SetupSeal:  seal,
+		CommitSeal: seal,
+	}
+	return proof, &payload
+}
+
+// committedEpochFixture creates the service events of a committed epoch with
+// the given consensus committee, which can be used without the BLS library.
+func committedEpochFixture(counter uint64, firstView uint64, finalView uint64, committee flow.IdentityList) (*flow.EpochSetup, *flow.EpochCommit) {
+	participants := append(unittest.IdentityListFixture(1, unittest.WithRole(flow.RoleCollection)), committee...)
+	setup := unittest.EpochSetupFixture(
+		unittest.SetupWithCounter(counter),
+		unittest.WithFirstView(firstView),
+		unittest.WithFinalView(finalView),
+		unittest.WithParticipants(participants),
+	)
+	commit := commitFixture(counter)
+	commit.ClusterQCs = []*flow.QuorumCertificate{unittest.QuorumCertificateFixture()}
+	for i, node := range committee {
+		commit.DKGParticipants[node.NodeID] = flow.DKGParticipant{
+			Index:    uint(i),
+			KeyShare: unittest.KeyFixture(crypto.ECDSAP256).PublicKey(),
+		}
+	}
+	return setup, commit
+}
+
+// snapshotFixture creates a snapshot with the given head, sealing segment,
+// sealed result and epochs. The next epoch is only set up if its commit is nil.
+func snapshotFixture(t *testing.T, head *flow.Header, segment []*flow.Block, result *flow.ExecutionResult, seal *flow.Seal, phase flow.EpochPhase, previous *epoch, current *epoch, next *epoch) *mockprotocol.Snapshot {
+
+	toEpoch := func(e *epoch) protocol.Epoch {
+		if e.commit == nil {
+			converted, err := inmem.NewSetupEpoch(e.setup)
+			require.NoError(t, err)
+			return converted
+		}
+		converted, err := inmem.NewCommittedEpoch(e.setup, e.commit)
+		require.NoError(t, err)
+		return converted
+	}
+
+	epochs := &mockprotocol.EpochQuery{}
+	if previous != nil {
+		epochs.On("Previous").Return(toEpoch(previous))
+	} else {
+		epochs.On("Previous").Return(invalid.NewEpoch(protocol.ErrNoPreviousEpoch))
+	}
+	epochs.On("Current").Return(toEpoch(current))
+	if next != nil {
+		epochs.On("Next").Return(toEpoch(next))
+	} else {
+		epochs.On("Next").Return(invalid.NewEpoch(protocol.ErrNextEpochNotSetup))
+	}
+
+	snapshot := &mockprotocol.Snapshot{}
+	snapshot.On("Head").Return(head, nil)
+	snapshot.On("SealingSegment").Return(segment, nil)
+	snapshot.On("SealedResult").Return(result, seal, nil)
+	snapshot.On("Phase").Return(phase, nil)
+	snapshot.On("Epochs").Return(epochs)
+	return snapshot
+}
+
+func TestVerifySnapshot(t *testing.T) {
+
+	first := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+	second := unittest.IdentityListFixture(4, unittest.WithRole(flow.RoleConsensus))
+	setup1, commit1 := committedEpochFixture(1, 0, 99, first)
+	setup2, commit2 := committedEpochFixture(2, 100, 199, second)
+	epoch1 := &epoch{setup: setup1, commit: commit1}
+	epoch2 := &epoch{setup: setup2, commit: commit2}
+
+	// the service events of the second epoch are sealed at height 50, and the
+	// snapshot is taken at height 110 in the second epoch
+	proof, payload := epochProofFixture(setup2, commit2)
+	result := unittest.ExecutionResultFixture()
+	seal := unittest.Seal.Fixture(unittest.Seal.WithResult(result))
+	headPayload := unittest.PayloadFixture(unittest.WithSeals(seal))
+
+	views := make([]uint64, 0, 130)
+	for view := uint64(1); view <= 130; view++ {
+		views = append(views, view)
+	}
+	committee := func(view uint64) flow.IdentityList {
+		if view <= setup1.FinalView {
+			return first
+		}
+		return second
+	}
+	chain := chainFixture(committee, map[uint64]*flow.Payload{50: payload, 110: &headPayload}, views...)
+	proof.SetupSeal.Header = chain[50]
+	src := &source{chain: chain, proofs: map[uint64]*finality.EpochProof{2: proof}}
+
+	head := chain[110]
+	segment := []*flow.Block{{Header: head, Payload: &headPayload}}
+	rootResult := unittest.ExecutionResultFixture()
+	root := snapshotFixture(t, chain[0], nil, rootResult, unittest.Seal.Fixture(unittest.Seal.WithResult(rootResult)), flow.EpochPhaseStaking, nil, epoch1, nil)
+
+	// the signers are passed in the canonical order of the epoch setup
+	signedBy := func(committee flow.IdentityList) interface{} {
+		return mock.MatchedBy(func(signers flow.IdentityList) bool {
+			return len(signers.Filter(filter.HasNodeID(committee.NodeIDs()...))) == len(signers)
+		})
+	}
+	verifier := &mocks.Verifier{}
+	verifier.On("VerifyQC", signedBy(first), mock.Anything, mock.Anything).Return(true, nil)
+	verifier.On("VerifyQC", signedBy(second), mock.Anything, mock.Anything).Return(true, nil)
+	verifiers := func(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.Verifier, error) {
+		return finality.NewVerifier(setup.Participants, setup.FirstView, setup.FinalView, verifier), nil
+	}
+
+	t.Run("valid snapshot", func(t *testing.T) {
+		snapshot := snapshotFixture(t, head, segment, result, seal, flow.EpochPhaseStaking, epoch1, epoch2, nil)
+		err := VerifySnapshot(context.Background(), src, root, snapshot, verifiers)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unfinalized head", func(t *testing.T) {
+		rejecting := &mocks.Verifier{}
+		rejecting.On("VerifyQC", signedBy(first), mock.Anything, mock.Anything).Return(true, nil)
+		rejecting.On("VerifyQC", signedBy(second), mock.Anything, mock.Anything).Return(false, nil)
+		verifiers := func(setup *flow.EpochSetup, commit *flow.EpochCommit) (*finality.Verifier, error) {
+			return finality.NewVerifier(setup.Participants, setup.FirstView, setup.FinalView, rejecting), nil
+		}
+
+		snapshot := snapshotFixture(t, head, segment, result, seal, flow.EpochPhaseStaking, epoch1, epoch2, nil)
+		err := VerifySnapshot(context.Background(), src, root, snapshot, verifiers)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+
+	t.Run("forged epoch", func(t *testing.T) {
+		forged, _ := committedEpochFixture(2, 100, 199, second)
+		snapshot := snapshotFixture(t, head, segment, result, seal, flow.EpochPhaseStaking, epoch1, &epoch{setup: forged, commit: commit2}, nil)
+		err := VerifySnapshot(context.Background(), src, root, snapshot, verifiers)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+
+	t.Run("forged sealed result", func(t *testing.T) {
+		forged := unittest.ExecutionResultFixture()
+		snapshot := snapshotFixture(t, head, segment, forged, unittest.Seal.Fixture(unittest.Seal.WithResult(forged)), flow.EpochPhaseStaking, epoch1, epoch2, nil)
+		err := VerifySnapshot(context.Background(), src, root, snapshot, verifiers)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+
+	t.Run("omitted next epoch", func(t *testing.T) {
+		setup3, commit3 := committedEpochFixture(3, 200, 299, second)
+		next, nextPayload := epochProofFixture(setup3, commit3)
+		chain := chainFixture(committee, map[uint64]*flow.Payload{50: payload, 105: nextPayload, 110: &headPayload}, views...)
+		next.SetupSeal.Header = chain[105]
+		src := &source{chain: chain, proofs: map[uint64]*finality.EpochProof{2: proof, 3: next}}
+		proof.SetupSeal.Header = chain[50]
+
+		// the next epoch was committed before the head, but the snapshot
+		// claims to be in the staking phase
+		head := chain[110]
+		segment := []*flow.Block{{Header: head, Payload: &headPayload}}
+		snapshot := snapshotFixture(t, head, segment, result, seal, flow.EpochPhaseStaking, epoch1, epoch2, nil)
+		err := VerifySnapshot(context.Background(), src, root, snapshot, verifiers)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+
+		snapshot = snapshotFixture(t, head, segment, result, seal, flow.EpochPhaseCommitted, epoch1, epoch2, &epoch{setup: setup3, commit: commit3})
+		err = VerifySnapshot(context.Background(), src, root, snapshot, verifiers)
+		assert.NoError(t, err)
+	})
+}
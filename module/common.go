@@ -5,3 +5,19 @@ type ReadyDoneAware interface {
 	Ready() <-chan struct{}
 	Done() <-chan struct{}
 }
+
+// NoopReadyDoneAware is a ReadyDoneAware for optional components which are
+// disabled, and which is ready and done immediately.
+type NoopReadyDoneAware struct{}
+
+func (n *NoopReadyDoneAware) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+func (n *NoopReadyDoneAware) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
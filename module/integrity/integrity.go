@@ -0,0 +1,529 @@
+package integrity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/pruner"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/storage/badger/procedure"
+)
+
+// Check identifies a part of the integrity check of a database.
+type Check string
+
+const (
+	CheckHeightIndex    Check = "height_index"     // finalized heights are indexed to the blocks of the finalized chain
+	CheckParentLinks    Check = "parent_links"     // the finalized chain is linked by parent IDs down to the lowest height
+	CheckPayload        Check = "payload"          // payload indexes reference stored guarantees, seals, receipts and results
+	CheckCommitments    Check = "commitments"      // seals and results match the state commitments of executed blocks
+	CheckExecutedState  Check = "executed_state"   // the state of the latest executed block is stored
+	CheckChunkDataPacks Check = "chunk_data_packs" // the chunk data packs of the latest executed block are stored
+)
+
+// Config configures the integrity check of a database.
+type Config struct {
+	// Repair rebuilds the broken indexes which can be derived from other data,
+	// instead of only reporting them.
+	Repair bool
+
+	// HasState reports whether the execution state ledger holds the state with
+	// the given commitment. If it is nil, the ledger is not checked.
+	HasState func(commit flow.StateCommitment) bool
+
+	// ChunkDataPacks is the store of the chunk data packs, if they are not
+	// kept in the protocol state database.
+	ChunkDataPacks storage.Reader
+}
+
+// Issue is an inconsistency found in a database.
+type Issue struct {
+	Check    Check
+	Height   uint64
+	BlockID  flow.Identifier
+	Message  string
+	Repaired bool
+}
+
+// Report is the result of the integrity check of a database.
+type Report struct {
+	RootHeight      uint64
+	LowestHeight    uint64 // lowest height above the root whose block was not pruned
+	FinalizedHeight uint64
+	Blocks          uint64 // number of finalized blocks checked
+	Issues          []Issue
+}
+
+// Unrepaired returns the number of issues which were not repaired.
+func (r *Report) Unrepaired() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// checker checks the integrity of a database.
+type checker struct {
+	log    zerolog.Logger
+	db     *badger.DB
+	config Config
+	report *Report
+}
+
+// Run checks the integrity of the given protocol state database and returns
+// the issues it found. The finalized chain is the source of truth: it is
+// walked down from the finalized block along the parent links, and the
+// indexes of the finalized heights are checked, and repaired, against it.
+// An error is only returned if the database could not be read.
+func Run(log zerolog.Logger, db *badger.DB, config Config) (*Report, error) {
+
+	if config.ChunkDataPacks == nil {
+		config.ChunkDataPacks = bstorage.NewKVStore(db)
+	}
+
+	c := &checker{
+		log:    log.With().Str("component", "integrity").Logger(),
+		db:     db,
+		config: config,
+		report: &Report{},
+	}
+
+	err := c.run()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.report, nil
+}
+
+func (c *checker) run() error {
+
+	r := c.report
+	err := c.db.View(func(tx *badger.Txn) error {
+		err := operation.RetrieveRootHeight(&r.RootHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve root height: %w", err)
+		}
+		err = operation.RetrieveFinalizedHeight(&r.FinalizedHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized height: %w", err)
+		}
+
+		// blocks between the root and the pruned height were removed
+		var pruned uint64
+		err = operation.RetrievePrunedHeight(string(pruner.DataBlocks), &pruned)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			pruned = r.RootHeight
+		} else if err != nil {
+			return fmt.Errorf("could not retrieve pruned height of blocks: %w", err)
+		}
+		r.LowestHeight = r.RootHeight
+		if pruned > r.RootHeight {
+			r.LowestHeight = pruned + 1
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.log.Info().
+		Uint64("root_height", r.RootHeight).
+		Uint64("lowest_height", r.LowestHeight).
+		Uint64("finalized_height", r.FinalizedHeight).
+		Msg("checking database integrity")
+
+	err = c.checkIndexedHeights()
+	if err != nil {
+		return fmt.Errorf("could not check indexed heights: %w", err)
+	}
+
+	err = c.checkFinalizedChain()
+	if err != nil {
+		return fmt.Errorf("could not check finalized chain: %w", err)
+	}
+
+	err = c.checkExecutedState()
+	if err != nil {
+		return fmt.Errorf("could not check executed state: %w", err)
+	}
+
+	c.log.Info().
+		Uint64("blocks", r.Blocks).
+		Int("issues", len(r.Issues)).
+		Int("unrepaired", r.Unrepaired()).
+		Msg("database integrity checked")
+
+	return nil
+}
+
+// issue records an issue of the given check.
+func (c *checker) issue(check Check, height uint64, blockID flow.Identifier, repaired bool, msg string, args ...interface{}) {
+	issue := Issue{
+		Check:    check,
+		Height:   height,
+		BlockID:  blockID,
+		Message:  fmt.Sprintf(msg, args...),
+		Repaired: repaired,
+	}
+	c.report.Issues = append(c.report.Issues, issue)
+	c.log.Warn().
+		Str("check", string(check)).
+		Uint64("height", height).
+		Hex("block_id", blockID[:]).
+		Bool("repaired", repaired).
+		Msg(issue.Message)
+}
+
+// checkIndexedHeights checks that heights are only indexed between the lowest
+// and the finalized height, or at the root height. Heights indexed beyond
+// those are left over from crashes and removed when repairing.
+func (c *checker) checkIndexedHeights() error {
+
+	r := c.report
+	stale := make(map[uint64]flow.Identifier)
+	err := c.db.View(operation.TraverseBlockHeights(func(height uint64, blockID flow.Identifier) error {
+		if height > r.FinalizedHeight || (height < r.LowestHeight && height != r.RootHeight) {
+			stale[height] = blockID
+		}
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("could not traverse heights: %w", err)
+	}
+
+	for height, blockID := range stale {
+		repaired := false
+		if c.config.Repair {
+			err = c.db.Update(operation.RemoveBlockHeight(height))
+			if err != nil {
+				return fmt.Errorf("could not remove index of height %d: %w", height, err)
+			}
+			repaired = true
+		}
+		c.issue(CheckHeightIndex, height, blockID, repaired, "height outside of the finalized range (%d-%d) is indexed", r.LowestHeight, r.FinalizedHeight)
+	}
+
+	return nil
+}
+
+// checkFinalizedChain walks the finalized chain down from the finalized block
+// and checks the height index, the parent links and the payload of each block.
+// Heights indexed to blocks other than the parents of the blocks above are
+// re-indexed when repairing.
+func (c *checker) checkFinalizedChain() error {
+
+	r := c.report
+
+	// only the root block is left if all finalized blocks above it were pruned
+	if r.FinalizedHeight < r.LowestHeight {
+		return c.checkRoot()
+	}
+
+	var blockID flow.Identifier
+	err := c.db.View(operation.LookupBlockHeight(r.FinalizedHeight, &blockID))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.issue(CheckHeightIndex, r.FinalizedHeight, flow.ZeroID, false, "finalized height is not indexed")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up finalized block: %w", err)
+	}
+
+	for height := r.FinalizedHeight; ; height-- {
+
+		ok, err := c.checkHeight(height, blockID)
+		if err != nil {
+			return fmt.Errorf("could not check height %d: %w", height, err)
+		}
+		if !ok || height == r.LowestHeight {
+			break
+		}
+
+		var header flow.Header
+		err = c.db.View(operation.RetrieveHeader(blockID, &header))
+		if err != nil {
+			return fmt.Errorf("could not retrieve header: %w", err)
+		}
+		blockID = header.ParentID
+	}
+
+	// the root block is kept when the blocks above it are pruned
+	if r.LowestHeight == r.RootHeight {
+		return nil
+	}
+	return c.checkRoot()
+}
+
+// checkRoot checks the root block, which is not linked to the lowest block
+// after the blocks above it were pruned.
+func (c *checker) checkRoot() error {
+
+	r := c.report
+
+	var blockID flow.Identifier
+	err := c.db.View(operation.LookupBlockHeight(r.RootHeight, &blockID))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.issue(CheckHeightIndex, r.RootHeight, flow.ZeroID, false, "root height is not indexed")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up root block: %w", err)
+	}
+	_, err = c.checkHeight(r.RootHeight, blockID)
+	if err != nil {
+		return fmt.Errorf("could not check root height: %w", err)
+	}
+
+	return nil
+}
+
+// checkHeight checks the finalized block with the given ID, expected at the
+// given height. It returns false if the block can't be checked, in which case
+// the chain can't be followed below it.
+func (c *checker) checkHeight(height uint64, blockID flow.Identifier) (bool, error) {
+
+	var header flow.Header
+	err := c.db.View(operation.RetrieveHeader(blockID, &header))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.issue(CheckParentLinks, height, blockID, false, "finalized block is missing")
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve header: %w", err)
+	}
+	if header.Height != height {
+		c.issue(CheckParentLinks, height, blockID, false, "finalized block has height %d", header.Height)
+		return false, nil
+	}
+
+	var indexed flow.Identifier
+	err = c.db.View(operation.LookupBlockHeight(height, &indexed))
+	missing := errors.Is(err, storage.ErrNotFound)
+	if err != nil && !missing {
+		return false, fmt.Errorf("could not look up height: %w", err)
+	}
+	if missing || indexed != blockID {
+		repaired := false
+		if c.config.Repair {
+			err = operation.RetryOnConflict(c.db.Update, func(tx *badger.Txn) error {
+				err := operation.RemoveBlockHeight(height)(tx)
+				if err != nil && !errors.Is(err, storage.ErrNotFound) {
+					return err
+				}
+				return operation.IndexBlockHeight(height, blockID)(tx)
+			})
+			if err != nil {
+				return false, fmt.Errorf("could not re-index height: %w", err)
+			}
+			repaired = true
+		}
+		if missing {
+			c.issue(CheckHeightIndex, height, blockID, repaired, "height is not indexed")
+		} else {
+			c.issue(CheckHeightIndex, height, blockID, repaired, "height is indexed to %x instead of the finalized block", indexed)
+		}
+	}
+
+	err = c.checkPayload(height, blockID)
+	if err != nil {
+		return false, fmt.Errorf("could not check payload: %w", err)
+	}
+
+	c.report.Blocks++
+	return true, nil
+}
+
+// checkPayload checks that the payload index of the given block references
+// stored entities, and that its seals and results match the state commitments
+// of the blocks executed by the node.
+func (c *checker) checkPayload(height uint64, blockID flow.Identifier) error {
+	return c.db.View(func(tx *badger.Txn) error {
+
+		var index flow.Index
+		err := procedure.RetrieveIndex(blockID, &index)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			c.issue(CheckPayload, height, blockID, false, "payload index is missing")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not retrieve payload index: %w", err)
+		}
+
+		for _, guaranteeID := range index.CollectionIDs {
+			var guarantee flow.CollectionGuarantee
+			err = operation.RetrieveGuarantee(guaranteeID, &guarantee)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				c.issue(CheckPayload, height, blockID, false, "guarantee %x is missing", guaranteeID)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not retrieve guarantee: %w", err)
+			}
+		}
+
+		for _, receiptID := range index.ReceiptIDs {
+			var meta flow.ExecutionReceiptMeta
+			err = operation.RetrieveExecutionReceiptMeta(receiptID, &meta)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				c.issue(CheckPayload, height, blockID, false, "receipt %x is missing", receiptID)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not retrieve receipt: %w", err)
+			}
+		}
+
+		for _, sealID := range index.SealIDs {
+			var seal flow.Seal
+			err = operation.RetrieveSeal(sealID, &seal)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				c.issue(CheckPayload, height, blockID, false, "seal %x is missing", sealID)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not retrieve seal: %w", err)
+			}
+			err = c.checkCommitment(tx, height, blockID, "seal", sealID, seal.BlockID, seal.FinalState)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, resultID := range index.ResultIDs {
+			var result flow.ExecutionResult
+			err = operation.RetrieveExecutionResult(resultID, &result)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				c.issue(CheckPayload, height, blockID, false, "result %x is missing", resultID)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not retrieve result: %w", err)
+			}
+			commit, ok := result.FinalStateCommitment()
+			if !ok {
+				c.issue(CheckCommitments, height, blockID, false, "result %x has no final state commitment", resultID)
+				continue
+			}
+			err = c.checkCommitment(tx, height, blockID, "result", resultID, result.BlockID, commit)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// checkCommitment checks that the commitment of the given seal or result
+// matches the commitment of the executed block, if the node executed it.
+func (c *checker) checkCommitment(tx *badger.Txn, height uint64, blockID flow.Identifier, entity string, entityID flow.Identifier, executedID flow.Identifier, commit flow.StateCommitment) error {
+
+	var executed flow.StateCommitment
+	err := operation.LookupStateCommitment(executedID, &executed)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up state commitment: %w", err)
+	}
+
+	if !bytes.Equal(executed, commit) {
+		c.issue(CheckCommitments, height, blockID, false, "%s %x commits to %x, while block %x was executed to %x",
+			entity, entityID, commit, executedID, executed)
+	}
+
+	return nil
+}
+
+// checkExecutedState checks that the state commitment of the latest executed
+// block is stored, as well as the state itself if the ledger is checked. It
+// only applies to execution nodes.
+func (c *checker) checkExecutedState() error {
+
+	var blockID flow.Identifier
+	err := c.db.View(operation.RetrieveExecutedBlock(&blockID))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not retrieve executed block: %w", err)
+	}
+
+	var header flow.Header
+	err = c.db.View(operation.RetrieveHeader(blockID, &header))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.issue(CheckExecutedState, 0, blockID, false, "latest executed block is missing")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not retrieve executed header: %w", err)
+	}
+
+	var commit flow.StateCommitment
+	err = c.db.View(operation.LookupStateCommitment(blockID, &commit))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.issue(CheckExecutedState, header.Height, blockID, false, "state commitment of latest executed block is missing")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up state commitment: %w", err)
+	}
+
+	if c.config.HasState != nil && !c.config.HasState(commit) {
+		c.issue(CheckExecutedState, header.Height, blockID, false, "state %x of latest executed block is missing in the ledger", commit)
+	}
+
+	// the chunks of the root block were not executed by the node
+	if header.Height == c.report.RootHeight {
+		return nil
+	}
+
+	return c.checkChunkDataPacks(header.Height, blockID)
+}
+
+// checkChunkDataPacks checks that the chunk data packs of the chunks of the
+// execution result of the given executed block are stored.
+func (c *checker) checkChunkDataPacks(height uint64, blockID flow.Identifier) error {
+
+	var resultID flow.Identifier
+	err := c.db.View(operation.LookupExecutionResult(blockID, &resultID))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.issue(CheckChunkDataPacks, height, blockID, false, "execution result of latest executed block is missing")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up execution result: %w", err)
+	}
+
+	var result flow.ExecutionResult
+	err = c.db.View(operation.RetrieveExecutionResult(resultID, &result))
+	if err != nil {
+		return fmt.Errorf("could not retrieve execution result: %w", err)
+	}
+
+	for _, chunk := range result.Chunks {
+		var pack flow.ChunkDataPack
+		err = operation.ReadChunkDataPack(chunk.ID(), &pack)(c.config.ChunkDataPacks)
+		if errors.Is(err, storage.ErrNotFound) {
+			c.issue(CheckChunkDataPacks, height, blockID, false, "chunk data pack of chunk %d is missing", chunk.Index)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not read chunk data pack: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package integrity
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/pruner"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/storage/pebble"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// storeChain stores a finalized chain of blocks from height zero up to the
+// given height, with a guarantee, a seal and a receipt in each payload.
+func storeChain(t *testing.T, db *badger.DB, finalized uint64) []*flow.Block {
+
+	blocks := bstorage.InitAll(metrics.NewNoopCollector(), db).Blocks
+
+	root := unittest.BlockFixture()
+	root.Header.Height = 0
+	chain := []*flow.Block{&root}
+	for height := uint64(1); height <= finalized; height++ {
+		block := unittest.BlockWithParentFixture(chain[len(chain)-1].Header)
+		block.SetPayload(unittest.PayloadFixture(
+			unittest.WithGuarantees(unittest.CollectionGuaranteeFixture()),
+			unittest.WithSeals(unittest.Seal.Fixture()),
+			unittest.WithReceipts(unittest.ExecutionReceiptFixture()),
+		))
+		chain = append(chain, &block)
+	}
+
+	for _, block := range chain {
+		require.NoError(t, blocks.Store(block))
+		require.NoError(t, db.Update(operation.IndexBlockHeight(block.Header.Height, block.ID())))
+	}
+	require.NoError(t, db.Update(operation.InsertRootHeight(0)))
+	require.NoError(t, db.Update(operation.InsertFinalizedHeight(finalized)))
+
+	return chain
+}
+
+// storeExecution stores the execution of the given block as the latest
+// executed block, with its result, and the chunk data packs of the result in
+// the given store.
+func storeExecution(t *testing.T, db *badger.DB, blockID flow.Identifier, packs storage.KVStore) *flow.ExecutionResult {
+	result := unittest.ExecutionResultFixture()
+	result.BlockID = blockID
+	commit, ok := result.FinalStateCommitment()
+	require.True(t, ok)
+
+	err := db.Update(operation.InsertExecutedBlock(blockID))
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		err = db.Update(operation.UpdateExecutedBlock(blockID))
+	}
+	require.NoError(t, err)
+	require.NoError(t, db.Update(operation.IndexStateCommitment(blockID, commit)))
+	require.NoError(t, db.Update(operation.InsertExecutionResult(result)))
+	require.NoError(t, db.Update(operation.IndexExecutionResult(blockID, result.ID())))
+
+	chunkDataPacks := bstorage.NewChunkDataPacks(packs)
+	for _, chunk := range result.Chunks {
+		require.NoError(t, chunkDataPacks.Store(unittest.ChunkDataPackFixture(chunk.ID())))
+	}
+	return result
+}
+
+// run checks the integrity of the database and returns the found issues.
+func run(t *testing.T, db *badger.DB, config Config) *Report {
+	report, err := Run(zerolog.Nop(), db, config)
+	require.NoError(t, err)
+	return report
+}
+
+func TestRun_Consistent(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		storeChain(t, db, 9)
+
+		report := run(t, db, Config{})
+		assert.Empty(t, report.Issues)
+		assert.Equal(t, uint64(10), report.Blocks)
+		assert.Equal(t, uint64(9), report.FinalizedHeight)
+	})
+}
+
+func TestRun_HeightIndex(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		// height 5 is indexed to another block, height 3 is not indexed and
+		// height 12 is indexed beyond the finalized height
+		require.NoError(t, db.Update(operation.RemoveBlockHeight(5)))
+		require.NoError(t, db.Update(operation.IndexBlockHeight(5, unittest.IdentifierFixture())))
+		require.NoError(t, db.Update(operation.RemoveBlockHeight(3)))
+		require.NoError(t, db.Update(operation.IndexBlockHeight(12, unittest.IdentifierFixture())))
+
+		report := run(t, db, Config{})
+		require.Len(t, report.Issues, 3)
+		assert.Equal(t, 3, report.Unrepaired())
+		for _, issue := range report.Issues {
+			assert.Equal(t, CheckHeightIndex, issue.Check)
+		}
+
+		report = run(t, db, Config{Repair: true})
+		assert.Len(t, report.Issues, 3)
+		assert.Equal(t, 0, report.Unrepaired())
+
+		for _, height := range []uint64{3, 5} {
+			var blockID flow.Identifier
+			require.NoError(t, db.View(operation.LookupBlockHeight(height, &blockID)))
+			assert.Equal(t, chain[height].ID(), blockID)
+		}
+
+		report = run(t, db, Config{})
+		assert.Empty(t, report.Issues)
+	})
+}
+
+func TestRun_ParentLinks(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		// the chain can't be followed below a missing block
+		require.NoError(t, db.Update(operation.RemoveHeader(chain[6].ID())))
+
+		report := run(t, db, Config{Repair: true})
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, CheckParentLinks, report.Issues[0].Check)
+		assert.Equal(t, uint64(6), report.Issues[0].Height)
+		assert.False(t, report.Issues[0].Repaired)
+		assert.Equal(t, uint64(3), report.Blocks)
+	})
+}
+
+func TestRun_Payload(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		guarantee := chain[4].Payload.Guarantees[0]
+		require.NoError(t, db.Update(operation.RemoveGuarantee(guarantee.ID())))
+
+		report := run(t, db, Config{})
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, CheckPayload, report.Issues[0].Check)
+		assert.Equal(t, chain[4].ID(), report.Issues[0].BlockID)
+	})
+}
+
+func TestRun_Commitments(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		// the node executed the block sealed in block 2 to a different state
+		// than the seal commits to
+		seal := chain[2].Payload.Seals[0]
+		require.NoError(t, db.Update(operation.IndexStateCommitment(seal.BlockID, unittest.StateCommitmentFixture())))
+
+		// the node executed the block of the result in block 3 to its state
+		result := chain[3].Payload.Results[0]
+		commit, ok := result.FinalStateCommitment()
+		require.True(t, ok)
+		require.NoError(t, db.Update(operation.IndexStateCommitment(result.BlockID, commit)))
+
+		report := run(t, db, Config{})
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, CheckCommitments, report.Issues[0].Check)
+		assert.Equal(t, chain[2].ID(), report.Issues[0].BlockID)
+	})
+}
+
+func TestRun_ExecutedState(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		executed := chain[8].ID()
+		require.NoError(t, db.Update(operation.InsertExecutedBlock(executed)))
+
+		report := run(t, db, Config{})
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, CheckExecutedState, report.Issues[0].Check)
+
+		result := storeExecution(t, db, executed, bstorage.NewKVStore(db))
+		commit, _ := result.FinalStateCommitment()
+
+		report = run(t, db, Config{})
+		assert.Empty(t, report.Issues)
+
+		hasState := func(state flow.StateCommitment) bool {
+			return !bytes.Equal(state, commit)
+		}
+		report = run(t, db, Config{HasState: hasState})
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, CheckExecutedState, report.Issues[0].Check)
+	})
+}
+
+func TestRun_ChunkDataPacks(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		t.Run("node database", func(t *testing.T) {
+			result := storeExecution(t, db, chain[8].ID(), bstorage.NewKVStore(db))
+
+			report := run(t, db, Config{})
+			assert.Empty(t, report.Issues)
+
+			require.NoError(t, bstorage.NewChunkDataPacks(bstorage.NewKVStore(db)).Remove(result.Chunks[0].ID()))
+			report = run(t, db, Config{})
+			require.Len(t, report.Issues, 1)
+			assert.Equal(t, CheckChunkDataPacks, report.Issues[0].Check)
+		})
+
+		t.Run("separate database", func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(dir string) {
+				packs, err := pebble.Open(dir, nil)
+				require.NoError(t, err)
+				defer packs.Close()
+
+				storeExecution(t, db, chain[9].ID(), packs)
+
+				report := run(t, db, Config{ChunkDataPacks: packs})
+				assert.Empty(t, report.Issues)
+
+				// the chunk data packs are not in the node database
+				report = run(t, db, Config{})
+				assert.NotEmpty(t, report.Issues)
+				for _, issue := range report.Issues {
+					assert.Equal(t, CheckChunkDataPacks, issue.Check)
+				}
+			})
+		})
+	})
+}
+
+func TestRun_Pruned(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		chain := storeChain(t, db, 9)
+
+		// the blocks above the root up to height 4 were pruned, but the index
+		// of height 2 was left behind
+		for height := uint64(1); height <= 4; height++ {
+			require.NoError(t, db.Update(operation.RemoveHeader(chain[height].ID())))
+			if height != 2 {
+				require.NoError(t, db.Update(operation.RemoveBlockHeight(height)))
+			}
+		}
+		require.NoError(t, db.Update(operation.InsertPrunedHeight(string(pruner.DataBlocks), 4)))
+
+		report := run(t, db, Config{Repair: true})
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, uint64(2), report.Issues[0].Height)
+		assert.True(t, report.Issues[0].Repaired)
+		assert.Equal(t, uint64(5), report.LowestHeight)
+		assert.Equal(t, uint64(6), report.Blocks)
+
+		report = run(t, db, Config{})
+		assert.Empty(t, report.Issues)
+	})
+}
@@ -0,0 +1,179 @@
+package stdmap
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/mempool"
+)
+
+// PriorityTransactions implements a transactions memory pool which returns the
+// transactions ordered by priority. Within a priority band, transactions are
+// returned in the order in which they were added. When the pool overflows, the
+// most recently added transaction of the lowest priority band is ejected.
+type PriorityTransactions struct {
+	*Backend
+	priority mempool.TransactionPriority
+	// the queue of transactions of each non-empty priority band, in the order
+	// in which they were added; only accessed while holding the backend lock
+	bands    map[uint64]*list.List
+	elements map[flow.Identifier]*list.Element
+}
+
+// prioritizedTransaction wraps a transaction with its priority.
+type prioritizedTransaction struct {
+	*flow.TransactionBody
+	priority uint64
+}
+
+// NewPriorityTransactions creates a new memory pool for transactions, which
+// orders them by the given priority function.
+func NewPriorityTransactions(limit uint, priority mempool.TransactionPriority) *PriorityTransactions {
+	t := &PriorityTransactions{
+		priority: priority,
+		bands:    make(map[uint64]*list.List),
+		elements: make(map[flow.Identifier]*list.Element),
+	}
+	t.Backend = NewBackend(WithLimit(limit), WithEject(t.ejectLowestPriority))
+
+	return t
+}
+
+// Add adds a transaction to the mempool.
+func (t *PriorityTransactions) Add(tx *flow.TransactionBody) bool {
+	added := false
+	_ = t.Backend.Run(func(entities map[flow.Identifier]flow.Entity) error {
+		txID := tx.ID()
+		if _, exists := entities[txID]; exists {
+			return nil
+		}
+		prioritized := &prioritizedTransaction{
+			TransactionBody: tx,
+			priority:        t.priority(tx),
+		}
+		entities[txID] = prioritized
+		t.enqueue(txID, prioritized)
+		added = true
+		return nil
+	})
+	return added
+}
+
+// Rem removes the transaction with the given ID from the mempool.
+func (t *PriorityTransactions) Rem(txID flow.Identifier) bool {
+	removed := false
+	_ = t.Backend.Run(func(entities map[flow.Identifier]flow.Entity) error {
+		if _, exists := entities[txID]; !exists {
+			return nil
+		}
+		delete(entities, txID)
+		t.dequeue(txID)
+		removed = true
+		return nil
+	})
+	return removed
+}
+
+// ByID returns the transaction with the given ID from the mempool.
+func (t *PriorityTransactions) ByID(txID flow.Identifier) (*flow.TransactionBody, bool) {
+	entity, exists := t.Backend.ByID(txID)
+	if !exists {
+		return nil, false
+	}
+	tx, ok := entity.(*prioritizedTransaction)
+	if !ok {
+		panic(fmt.Sprintf("invalid entity in transaction pool (%T)", entity))
+	}
+	return tx.TransactionBody, true
+}
+
+// All returns all transactions from the mempool, ordered by descending
+// priority and, within the same priority, by the order they were added.
+func (t *PriorityTransactions) All() []*flow.TransactionBody {
+	t.Backend.RLock()
+	defer t.Backend.RUnlock()
+
+	txs := make([]*flow.TransactionBody, 0, len(t.elements))
+	for _, priority := range t.priorities() {
+		for e := t.bands[priority].Front(); e != nil; e = e.Next() {
+			txs = append(txs, e.Value.(*prioritizedTransaction).TransactionBody)
+		}
+	}
+	return txs
+}
+
+// Clear removes all transactions from the mempool.
+func (t *PriorityTransactions) Clear() {
+	_ = t.Backend.Run(func(entities map[flow.Identifier]flow.Entity) error {
+		for txID := range entities {
+			delete(entities, txID)
+		}
+		t.bands = make(map[uint64]*list.List)
+		t.elements = make(map[flow.Identifier]*list.Element)
+		return nil
+	})
+}
+
+// enqueue appends the transaction to the queue of its priority band.
+func (t *PriorityTransactions) enqueue(txID flow.Identifier, tx *prioritizedTransaction) {
+	band, ok := t.bands[tx.priority]
+	if !ok {
+		band = list.New()
+		t.bands[tx.priority] = band
+	}
+	t.elements[txID] = band.PushBack(tx)
+}
+
+// dequeue removes the transaction from the queue of its priority band, and
+// drops the band once it is empty.
+func (t *PriorityTransactions) dequeue(txID flow.Identifier) {
+	e, ok := t.elements[txID]
+	if !ok {
+		return
+	}
+	delete(t.elements, txID)
+	priority := e.Value.(*prioritizedTransaction).priority
+	band := t.bands[priority]
+	band.Remove(e)
+	if band.Len() == 0 {
+		delete(t.bands, priority)
+	}
+}
+
+// priorities returns the priorities of the non-empty bands, from highest to
+// lowest.
+func (t *PriorityTransactions) priorities() []uint64 {
+	priorities := make([]uint64, 0, len(t.bands))
+	for priority := range t.bands {
+		priorities = append(priorities, priority)
+	}
+	sort.Slice(priorities, func(i, j int) bool {
+		return priorities[i] > priorities[j]
+	})
+	return priorities
+}
+
+// ejectLowestPriority picks the transaction that would be included last, so
+// that an overflowing pool never ejects transactions of a higher priority in
+// favour of lower priority ones. It only looks at the non-empty bands, of
+// which there are few, and is called by the backend while holding its lock.
+func (t *PriorityTransactions) ejectLowestPriority(entities map[flow.Identifier]flow.Entity) (flow.Identifier, flow.Entity) {
+	var lowest *list.List
+	var lowestPriority uint64
+	for priority, band := range t.bands {
+		if lowest == nil || priority < lowestPriority {
+			lowest = band
+			lowestPriority = priority
+		}
+	}
+	if lowest == nil {
+		return EjectFakeRandom(entities)
+	}
+
+	tx := lowest.Back().Value.(*prioritizedTransaction)
+	txID := tx.ID()
+	t.dequeue(txID)
+	return txID, tx
+}
@@ -0,0 +1,88 @@
+package stdmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/mempool/stdmap"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestPriorityTransactionPool(t *testing.T) {
+
+	// priority is the gas limit, so we can easily create transactions of a
+	// given priority band
+	priority := func(tx *flow.TransactionBody) uint64 { return tx.GasLimit }
+	create := func(band uint64) *flow.TransactionBody {
+		tx := unittest.TransactionBodyFixture()
+		tx.GasLimit = band
+		return &tx
+	}
+
+	t.Run("should order by priority and FIFO within band", func(t *testing.T) {
+		pool := stdmap.NewPriorityTransactions(1000, priority)
+
+		var low, high []*flow.TransactionBody
+		for i := 0; i < 10; i++ {
+			tx := create(1)
+			low = append(low, tx)
+			require.True(t, pool.Add(tx))
+			tx = create(2)
+			high = append(high, tx)
+			require.True(t, pool.Add(tx))
+		}
+
+		assert.Equal(t, append(high, low...), pool.All())
+	})
+
+	t.Run("should retrieve by ID", func(t *testing.T) {
+		pool := stdmap.NewPriorityTransactions(1000, priority)
+		tx := create(1)
+		require.True(t, pool.Add(tx))
+		require.False(t, pool.Add(tx))
+
+		got, exists := pool.ByID(tx.ID())
+		assert.True(t, exists)
+		assert.Equal(t, tx, got)
+		assert.True(t, pool.Rem(tx.ID()))
+		assert.EqualValues(t, 0, pool.Size())
+	})
+
+	t.Run("should eject lowest priority on overflow", func(t *testing.T) {
+		pool := stdmap.NewPriorityTransactions(2, priority)
+		first := create(1)
+		high := create(2)
+		last := create(1)
+		require.True(t, pool.Add(first))
+		require.True(t, pool.Add(high))
+		pool.Add(last)
+
+		assert.Equal(t, []*flow.TransactionBody{high, first}, pool.All())
+	})
+
+	t.Run("should keep bands consistent after removal", func(t *testing.T) {
+		pool := stdmap.NewPriorityTransactions(2, priority)
+		low := create(1)
+		high := create(2)
+		require.True(t, pool.Add(low))
+		require.True(t, pool.Add(high))
+		require.True(t, pool.Rem(low.ID()))
+		require.False(t, pool.Rem(low.ID()))
+
+		// with the low priority band gone, the most recent transaction of the
+		// high priority band is ejected
+		next := create(2)
+		last := create(2)
+		pool.Add(next)
+		pool.Add(last)
+		assert.Equal(t, []*flow.TransactionBody{high, next}, pool.All())
+
+		pool.Clear()
+		assert.Empty(t, pool.All())
+		require.True(t, pool.Add(low))
+		assert.Equal(t, []*flow.TransactionBody{low}, pool.All())
+	})
+}
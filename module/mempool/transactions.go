@@ -39,3 +39,9 @@ type Transactions interface {
 	// entire memory pool.
 	Hash() flow.Identifier
 }
+
+// TransactionPriority returns the priority of the given transaction; a
+// transaction with a higher priority should be included before transactions
+// with lower priority. Transactions with the same priority are in the same
+// priority band and should be included in the order they were received.
+type TransactionPriority func(tx *flow.TransactionBody) uint64
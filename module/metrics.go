@@ -20,6 +20,18 @@ type NetworkMetrics interface {
 	// NetworkDuplicateMessagesDropped counts number of messages dropped due to duplicate detection
 	NetworkDuplicateMessagesDropped(topic string, messageType string)
 
+	// NetworkUnauthorizedMessagesDropped counts number of messages dropped because the role of their origin
+	// is not authorized to send them on the topic
+	NetworkUnauthorizedMessagesDropped(topic string, messageType string, role string)
+
+	// NetworkRateLimitedMessagesDropped counts number of inbound messages dropped because they exceed
+	// the rate limit of their sender or topic, for the given mode of communication (unicast or pubsub)
+	NetworkRateLimitedMessagesDropped(topic string, mode string, reason string)
+
+	// NetworkMessageCompressed counts the size in bytes of a compressed message payload before and after
+	// compression, for the given direction (inbound or outbound)
+	NetworkMessageCompressed(topic string, direction string, rawBytes int, compressedBytes int)
+
 	// Message receive queue metrics
 	// MessageAdded increments the metric tracking the number of messages in the queue with the given priority
 	MessageAdded(priority int)
@@ -58,6 +70,14 @@ type CleanerMetrics interface {
 	RanGC(took time.Duration)
 }
 
+type PrunerMetrics interface {
+	// PrunedHeight reports the height up to which the given type of data was pruned
+	PrunedHeight(data string, height uint64)
+
+	// PruningDuration reports the time spent pruning a batch of heights of the given type of data
+	PruningDuration(data string, duration time.Duration)
+}
+
 type CacheMetrics interface {
 	// report the total number of cached items
 	CacheEntries(resource string, entries uint)
@@ -67,6 +87,12 @@ type CacheMetrics interface {
 	CacheNotFound(resource string)
 	// report the number of items the queried item is not found in the cache, but found in the database
 	CacheMiss(resource string)
+	// report the number of items evicted from the cache to make room for new ones
+	CacheEviction(resource string)
+	// report the maximum number of items the cache can hold
+	CacheLimit(resource string, limit uint)
+	// report the estimated memory size of the cached items, in bytes
+	CacheSize(resource string, bytes uint64)
 }
 
 type MempoolMetrics interface {
@@ -74,6 +100,18 @@ type MempoolMetrics interface {
 	Register(resource string, entriesFunc EntriesFunc) error
 }
 
+// BuilderMetrics reports the decisions the consensus block builder takes when
+// assembling block payloads.
+type BuilderMetrics interface {
+	// PayloadItemIncluded is called for every item of the given resource type
+	// the builder included in a payload, with the item's encoded byte size.
+	PayloadItemIncluded(resource string, sizeBytes uint)
+
+	// PayloadItemExcluded is called for every eligible item of the given
+	// resource type the builder left out of a payload, with the reason why.
+	PayloadItemExcluded(resource string, reason string)
+}
+
 type HotstuffMetrics interface {
 	// HotStuffBusyDuration reports Metrics C6 HotStuff Busy Duration
 	HotStuffBusyDuration(duration time.Duration, event string)
@@ -130,6 +168,18 @@ type CollectionMetrics interface {
 
 	// ClusterBlockFinalized is called when a collection is finalized.
 	ClusterBlockFinalized(block *cluster.Block)
+
+	// ClusterViewChanged is called when the consensus of the cluster with the
+	// given chain ID enters a new view.
+	ClusterViewChanged(chainID flow.ChainID, view uint64)
+
+	// ClusterPendingTransactions reports the number of transactions pending
+	// inclusion in a collection of the cluster with the given chain ID.
+	ClusterPendingTransactions(chainID flow.ChainID, count uint)
+
+	// ClusterLiveMembers reports the number of members of the cluster with the
+	// given chain ID from which we recently received consensus messages.
+	ClusterLiveMembers(chainID flow.ChainID, count uint)
 }
 
 type ConsensusMetrics interface {
@@ -156,6 +206,10 @@ type ConsensusMetrics interface {
 
 	// CheckSealingDuration records absolute time for the full sealing check by the consensus match engine
 	CheckSealingDuration(duration time.Duration)
+
+	// ClusterStalled records whether no collection guarantees have been
+	// received from the collection cluster with the given index for too long.
+	ClusterStalled(clusterIndex uint, stalled bool)
 }
 
 type VerificationMetrics interface {
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type BuilderCollector struct {
+	included      *prometheus.CounterVec
+	includedBytes *prometheus.CounterVec
+	excluded      *prometheus.CounterVec
+}
+
+func NewBuilderCollector() *BuilderCollector {
+	bc := &BuilderCollector{
+		included: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "payload_items_included_total",
+			Namespace: namespaceConsensus,
+			Subsystem: subsystemBuilder,
+			Help:      "the number of items included in block payloads by the builder",
+		}, []string{LabelResource}),
+		includedBytes: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "payload_bytes_included_total",
+			Namespace: namespaceConsensus,
+			Subsystem: subsystemBuilder,
+			Help:      "the encoded byte size of items included in block payloads by the builder",
+		}, []string{LabelResource}),
+		excluded: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "payload_items_excluded_total",
+			Namespace: namespaceConsensus,
+			Subsystem: subsystemBuilder,
+			Help:      "the number of eligible items left out of block payloads by the builder",
+		}, []string{LabelResource, LabelReason}),
+	}
+	return bc
+}
+
+// PayloadItemIncluded records an item, and its byte size, included in a block payload.
+func (bc *BuilderCollector) PayloadItemIncluded(resource string, sizeBytes uint) {
+	bc.included.With(prometheus.Labels{LabelResource: resource}).Inc()
+	bc.includedBytes.With(prometheus.Labels{LabelResource: resource}).Add(float64(sizeBytes))
+}
+
+// PayloadItemExcluded records an eligible item left out of a block payload.
+func (bc *BuilderCollector) PayloadItemExcluded(resource string, reason string) {
+	bc.excluded.With(prometheus.Labels{LabelResource: resource, LabelReason: reason}).Inc()
+}
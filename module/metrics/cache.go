@@ -12,6 +12,9 @@ type CacheCollector struct {
 	hits      *prometheus.CounterVec
 	notfounds *prometheus.CounterVec
 	misses    *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	limits    *prometheus.GaugeVec
+	sizes     *prometheus.GaugeVec
 }
 
 func NewCacheCollector(chain flow.ChainID) *CacheCollector {
@@ -49,6 +52,30 @@ func NewCacheCollector(chain flow.ChainID) *CacheCollector {
 			Help:        "the number of times the queried item was not found in cache, but found in database",
 			ConstLabels: prometheus.Labels{LabelChain: chain.String()},
 		}, []string{LabelResource}),
+
+		evictions: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:        "evictions_total",
+			Namespace:   namespaceStorage,
+			Subsystem:   subsystemCache,
+			Help:        "the number of items evicted from the cache to make room for new ones",
+			ConstLabels: prometheus.Labels{LabelChain: chain.String()},
+		}, []string{LabelResource}),
+
+		limits: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "limit_entries",
+			Namespace:   namespaceStorage,
+			Subsystem:   subsystemCache,
+			Help:        "the maximum number of entries the cache can hold",
+			ConstLabels: prometheus.Labels{LabelChain: chain.String()},
+		}, []string{LabelResource}),
+
+		sizes: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "size_bytes",
+			Namespace:   namespaceStorage,
+			Subsystem:   subsystemCache,
+			Help:        "the estimated memory size of the entries in the cache",
+			ConstLabels: prometheus.Labels{LabelChain: chain.String()},
+		}, []string{LabelResource}),
 	}
 
 	return cm
@@ -75,3 +102,20 @@ func (cc *CacheCollector) CacheNotFound(resource string) {
 func (cc *CacheCollector) CacheMiss(resource string) {
 	cc.misses.With(prometheus.Labels{LabelResource: resource}).Inc()
 }
+
+// CacheEviction records the number of items evicted from the cache to make
+// room for new ones.
+func (cc *CacheCollector) CacheEviction(resource string) {
+	cc.evictions.With(prometheus.Labels{LabelResource: resource}).Inc()
+}
+
+// CacheLimit records the maximum number of items the cache can hold, which
+// changes when caches are resized.
+func (cc *CacheCollector) CacheLimit(resource string, limit uint) {
+	cc.limits.With(prometheus.Labels{LabelResource: resource}).Set(float64(limit))
+}
+
+// CacheSize records the estimated memory size of the items in the cache.
+func (cc *CacheCollector) CacheSize(resource string, bytes uint64) {
+	cc.sizes.With(prometheus.Labels{LabelResource: resource}).Set(float64(bytes))
+}
@@ -26,6 +26,9 @@ type CollectionCollector struct {
 	finalizedHeight      *prometheus.GaugeVec     // tracks the finalized height
 	proposals            *prometheus.HistogramVec // tracks the number/size of PROPOSED collections
 	guarantees           *prometheus.HistogramVec // counts the number/size of FINALIZED collections
+	view                 *prometheus.GaugeVec     // tracks the current view of the cluster consensus
+	pendingTransactions  *prometheus.GaugeVec     // tracks the number of transactions pending inclusion
+	liveMembers          *prometheus.GaugeVec     // tracks the number of cluster members recently heard from
 }
 
 func NewCollectionCollector(tracer module.Tracer) *CollectionCollector {
@@ -61,6 +64,27 @@ func NewCollectionCollector(tracer module.Tracer) *CollectionCollector {
 			Name:      "guarantees_size_transactions",
 			Help:      "size/number of guaranteed/finalized collections",
 		}, []string{LabelChain, LabelProposer}),
+
+		view: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespaceCollection,
+			Subsystem: subsystemProposal,
+			Name:      "cur_view",
+			Help:      "tracks the current view of the cluster consensus",
+		}, []string{LabelChain}),
+
+		pendingTransactions: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespaceCollection,
+			Subsystem: subsystemProposal,
+			Name:      "pending_transactions",
+			Help:      "number of transactions pending inclusion in a collection",
+		}, []string{LabelChain}),
+
+		liveMembers: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespaceCollection,
+			Subsystem: subsystemProposal,
+			Name:      "live_members",
+			Help:      "number of cluster members from which consensus messages were recently received",
+		}, []string{LabelChain}),
 	}
 
 	return cc
@@ -117,3 +141,20 @@ func (cc *CollectionCollector) ClusterBlockFinalized(block *cluster.Block) {
 	}
 	cc.tracer.FinishSpan(collection.ID(), spanCollectionToGuarantee)
 }
+
+// ClusterViewChanged sets the current view of the cluster consensus.
+func (cc *CollectionCollector) ClusterViewChanged(chainID flow.ChainID, view uint64) {
+	cc.view.With(prometheus.Labels{LabelChain: chainID.String()}).Set(float64(view))
+}
+
+// ClusterPendingTransactions sets the number of transactions pending inclusion
+// in a collection of the cluster.
+func (cc *CollectionCollector) ClusterPendingTransactions(chainID flow.ChainID, count uint) {
+	cc.pendingTransactions.With(prometheus.Labels{LabelChain: chainID.String()}).Set(float64(count))
+}
+
+// ClusterLiveMembers sets the number of cluster members from which we recently
+// received consensus messages.
+func (cc *CollectionCollector) ClusterLiveMembers(chainID flow.ChainID, count uint) {
+	cc.liveMembers.With(prometheus.Labels{LabelChain: chainID.String()}).Set(float64(count))
+}
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,6 +38,9 @@ type ConsensusCollector struct {
 
 	// The number of emergency seals
 	emergencySealedBlocks prometheus.Counter
+
+	// Whether the guarantees of a collection cluster have stalled
+	stalledClusters *prometheus.GaugeVec
 }
 
 // NewConsensusCollector created a new consensus collector
@@ -65,11 +69,18 @@ func NewConsensusCollector(tracer module.Tracer, registerer prometheus.Registere
 		Subsystem: subsystemCompliance,
 		Help:      "the number of blocks sealed in emergency mode",
 	})
+	stalledClusters := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "stalled_clusters",
+		Namespace: namespaceConsensus,
+		Subsystem: subsystemIngestion,
+		Help:      "whether no collection guarantees have been received from a collection cluster for too long",
+	}, []string{LabelCluster})
 	registerer.MustRegister(
 		onReceiptDuration,
 		onApprovalDuration,
 		checkSealingDuration,
 		emergencySealedBlocks,
+		stalledClusters,
 	)
 	cc := &ConsensusCollector{
 		tracer:                tracer,
@@ -77,6 +88,7 @@ func NewConsensusCollector(tracer module.Tracer, registerer prometheus.Registere
 		onApprovalDuration:    onApprovalDuration,
 		checkSealingDuration:  checkSealingDuration,
 		emergencySealedBlocks: emergencySealedBlocks,
+		stalledClusters:       stalledClusters,
 	}
 	return cc
 }
@@ -120,3 +132,13 @@ func (cc *ConsensusCollector) OnApprovalProcessingDuration(duration time.Duratio
 func (cc *ConsensusCollector) CheckSealingDuration(duration time.Duration) {
 	cc.checkSealingDuration.Add(duration.Seconds())
 }
+
+// ClusterStalled records whether the guarantees of the collection cluster with
+// the given index have stalled.
+func (cc *ConsensusCollector) ClusterStalled(clusterIndex uint, stalled bool) {
+	value := 0.0
+	if stalled {
+		value = 1.0
+	}
+	cc.stalledClusters.With(prometheus.Labels{LabelCluster: strconv.FormatUint(uint64(clusterIndex), 10)}).Set(value)
+}
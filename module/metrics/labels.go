@@ -12,12 +12,21 @@ const (
 	LabelNodeRole    = "noderole"
 	LabelNodeInfo    = "nodeinfo"
 	LabelPriority    = "priority"
+	LabelReason      = "reason"
+	LabelCluster     = "cluster"
+	LabelMode        = "mode"
+	LabelDirection   = "direction"
 )
 
 const (
 	ChannelOneToOne = "OneToOne"
 )
 
+const (
+	DirectionInbound  = "inbound"
+	DirectionOutbound = "outbound"
+)
+
 const (
 	// collection
 	EngineProposal               = "proposal"
@@ -42,6 +42,7 @@ const (
 const (
 	subsystemCompliance = "compliance"
 	subsystemHotstuff   = "hotstuff"
+	subsystemBuilder    = "builder"
 )
 
 // Execution Subsystems
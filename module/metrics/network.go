@@ -19,6 +19,10 @@ type NetworkCollector struct {
 	outboundMessageSize      *prometheus.HistogramVec
 	inboundMessageSize       *prometheus.HistogramVec
 	duplicateMessagesDropped *prometheus.CounterVec
+	unauthorizedDropped      *prometheus.CounterVec
+	rateLimitedDropped       *prometheus.CounterVec
+	compressionRawBytes      *prometheus.CounterVec
+	compressionBytes         *prometheus.CounterVec
 	queueSize                *prometheus.GaugeVec
 	queueDuration            *prometheus.HistogramVec
 	inboundProcessTime       *prometheus.CounterVec
@@ -53,6 +57,34 @@ func NewNetworkCollector() *NetworkCollector {
 			Help:      "number of duplicate messages dropped",
 		}, []string{LabelChannel, LabelMessage}),
 
+		unauthorizedDropped: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceNetwork,
+			Subsystem: subsystemGossip,
+			Name:      "unauthorized_messages_dropped",
+			Help:      "number of messages dropped because their origin is not authorized to send them",
+		}, []string{LabelChannel, LabelMessage, LabelNodeRole}),
+
+		rateLimitedDropped: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceNetwork,
+			Subsystem: subsystemGossip,
+			Name:      "rate_limited_messages_dropped",
+			Help:      "number of inbound messages dropped because they exceed a rate limit",
+		}, []string{LabelChannel, LabelMode, LabelReason}),
+
+		compressionRawBytes: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceNetwork,
+			Subsystem: subsystemGossip,
+			Name:      "compression_raw_bytes",
+			Help:      "size of compressed message payloads before compression",
+		}, []string{LabelChannel, LabelDirection}),
+
+		compressionBytes: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceNetwork,
+			Subsystem: subsystemGossip,
+			Name:      "compression_compressed_bytes",
+			Help:      "size of compressed message payloads after compression",
+		}, []string{LabelChannel, LabelDirection}),
+
 		queueSize: promauto.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: namespaceNetwork,
 			Subsystem: subsystemQueue,
@@ -110,6 +142,25 @@ func (nc *NetworkCollector) NetworkDuplicateMessagesDropped(topic, messageType s
 	nc.duplicateMessagesDropped.WithLabelValues(topic, messageType).Add(1)
 }
 
+// NetworkUnauthorizedMessagesDropped tracks the number of messages dropped by the network layer because the
+// role of their origin is not authorized to send them on the topic
+func (nc *NetworkCollector) NetworkUnauthorizedMessagesDropped(topic string, messageType string, role string) {
+	nc.unauthorizedDropped.WithLabelValues(topic, messageType, role).Inc()
+}
+
+// NetworkRateLimitedMessagesDropped tracks the number of inbound messages dropped by the network layer because
+// they exceed the rate limit of their sender or topic
+func (nc *NetworkCollector) NetworkRateLimitedMessagesDropped(topic string, mode string, reason string) {
+	nc.rateLimitedDropped.WithLabelValues(topic, mode, reason).Inc()
+}
+
+// NetworkMessageCompressed tracks the size of compressed message payloads before and after compression, so that
+// the compression ratio can be derived for each topic and direction
+func (nc *NetworkCollector) NetworkMessageCompressed(topic string, direction string, rawBytes int, compressedBytes int) {
+	nc.compressionRawBytes.WithLabelValues(topic, direction).Add(float64(rawBytes))
+	nc.compressionBytes.WithLabelValues(topic, direction).Add(float64(compressedBytes))
+}
+
 func (nc *NetworkCollector) MessageAdded(priority int) {
 	nc.queueSize.WithLabelValues(strconv.Itoa(priority)).Inc()
 }
@@ -18,6 +18,9 @@ func NewNoopCollector() *NoopCollector {
 func (nc *NoopCollector) NetworkMessageSent(sizeBytes int, topic string, messageType string)     {}
 func (nc *NoopCollector) NetworkMessageReceived(sizeBytes int, topic string, messageType string) {}
 func (nc *NoopCollector) NetworkDuplicateMessagesDropped(topic string, messageType string)       {}
+func (nc *NoopCollector) NetworkUnauthorizedMessagesDropped(topic, messageType, role string)     {}
+func (nc *NoopCollector) NetworkRateLimitedMessagesDropped(topic, mode, reason string)           {}
+func (nc *NoopCollector) NetworkMessageCompressed(topic, direction string, raw, compressed int)  {}
 func (nc *NoopCollector) MessageAdded(priority int)                                              {}
 func (nc *NoopCollector) MessageRemoved(priority int)                                            {}
 func (nc *NoopCollector) QueueDuration(duration time.Duration, priority int)                     {}
@@ -28,6 +31,8 @@ func (nc *NoopCollector) MessageHandled(engine string, message string)
 func (nc *NoopCollector) OutboundConnections(_ uint)                                             {}
 func (nc *NoopCollector) InboundConnections(_ uint)                                              {}
 func (nc *NoopCollector) RanGC(duration time.Duration)                                           {}
+func (nc *NoopCollector) PrunedHeight(data string, height uint64)                                {}
+func (nc *NoopCollector) PruningDuration(data string, duration time.Duration)                    {}
 func (nc *NoopCollector) BadgerLSMSize(sizeBytes int64)                                          {}
 func (nc *NoopCollector) BadgerVLogSize(sizeBytes int64)                                         {}
 func (nc *NoopCollector) BadgerNumReads(n int64)                                                 {}
@@ -48,8 +53,13 @@ func (nc *NoopCollector) CacheEntries(resource string, entries uint)
 func (nc *NoopCollector) CacheHit(resource string)                                               {}
 func (nc *NoopCollector) CacheNotFound(resource string)                                          {}
 func (nc *NoopCollector) CacheMiss(resource string)                                              {}
+func (nc *NoopCollector) CacheEviction(resource string)                                          {}
+func (nc *NoopCollector) CacheLimit(resource string, limit uint)                                 {}
+func (nc *NoopCollector) CacheSize(resource string, bytes uint64)                                {}
 func (nc *NoopCollector) MempoolEntries(resource string, entries uint)                           {}
 func (nc *NoopCollector) Register(resource string, entriesFunc module.EntriesFunc) error         { return nil }
+func (nc *NoopCollector) PayloadItemIncluded(resource string, sizeBytes uint)                    {}
+func (nc *NoopCollector) PayloadItemExcluded(resource string, reason string)                     {}
 func (nc *NoopCollector) HotStuffBusyDuration(duration time.Duration, event string)              {}
 func (nc *NoopCollector) HotStuffIdleDuration(duration time.Duration)                            {}
 func (nc *NoopCollector) HotStuffWaitDuration(duration time.Duration, event string)              {}
@@ -65,6 +75,9 @@ func (nc *NoopCollector) PayloadProductionDuration(duration time.Duration)
 func (nc *NoopCollector) TransactionIngested(txID flow.Identifier)                               {}
 func (nc *NoopCollector) ClusterBlockProposed(*cluster.Block)                                    {}
 func (nc *NoopCollector) ClusterBlockFinalized(*cluster.Block)                                   {}
+func (nc *NoopCollector) ClusterViewChanged(chainID flow.ChainID, view uint64)                   {}
+func (nc *NoopCollector) ClusterPendingTransactions(chainID flow.ChainID, count uint)            {}
+func (nc *NoopCollector) ClusterLiveMembers(chainID flow.ChainID, count uint)                    {}
 func (nc *NoopCollector) StartCollectionToFinalized(collectionID flow.Identifier)                {}
 func (nc *NoopCollector) FinishCollectionToFinalized(collectionID flow.Identifier)               {}
 func (nc *NoopCollector) StartBlockToSeal(blockID flow.Identifier)                               {}
@@ -73,6 +86,7 @@ func (nc *NoopCollector) EmergencySeal()
 func (nc *NoopCollector) OnReceiptProcessingDuration(duration time.Duration)                     {}
 func (nc *NoopCollector) OnApprovalProcessingDuration(duration time.Duration)                    {}
 func (nc *NoopCollector) CheckSealingDuration(duration time.Duration)                            {}
+func (nc *NoopCollector) ClusterStalled(clusterIndex uint, stalled bool)                         {}
 func (nc *NoopCollector) OnExecutionReceiptReceived()                                            {}
 func (nc *NoopCollector) OnExecutionResultSent()                                                 {}
 func (nc *NoopCollector) OnExecutionResultReceived()                                             {}
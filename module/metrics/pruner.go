@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type PrunerCollector struct {
+	prunedHeight    *prometheus.GaugeVec
+	pruningDuration *prometheus.HistogramVec
+}
+
+func NewPrunerCollector() *PrunerCollector {
+	pc := &PrunerCollector{
+		prunedHeight: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespaceStorage,
+			Subsystem: subsystemBadger,
+			Name:      "pruned_height",
+			Help:      "the height up to which the data was pruned",
+		}, []string{LabelResource}),
+		pruningDuration: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespaceStorage,
+			Subsystem: subsystemBadger,
+			Name:      "pruning_runtime_s",
+			Buckets:   []float64{0.1, 1, 10, 60, 60 * 5},
+			Help:      "the time spent on pruning a batch of heights",
+		}, []string{LabelResource}),
+	}
+	return pc
+}
+
+// PrunedHeight records the height up to which the given type of data was pruned.
+func (pc *PrunerCollector) PrunedHeight(data string, height uint64) {
+	pc.prunedHeight.WithLabelValues(data).Set(float64(height))
+}
+
+// PruningDuration records the time spent pruning a batch of heights of the given type of data.
+func (pc *PrunerCollector) PruningDuration(data string, duration time.Duration) {
+	pc.pruningDuration.WithLabelValues(data).Observe(duration.Seconds())
+}
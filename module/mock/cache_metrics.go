@@ -14,11 +14,21 @@ func (_m *CacheMetrics) CacheEntries(resource string, entries uint) {
 	_m.Called(resource, entries)
 }
 
+// CacheEviction provides a mock function with given fields: resource
+func (_m *CacheMetrics) CacheEviction(resource string) {
+	_m.Called(resource)
+}
+
 // CacheHit provides a mock function with given fields: resource
 func (_m *CacheMetrics) CacheHit(resource string) {
 	_m.Called(resource)
 }
 
+// CacheLimit provides a mock function with given fields: resource, limit
+func (_m *CacheMetrics) CacheLimit(resource string, limit uint) {
+	_m.Called(resource, limit)
+}
+
 // CacheMiss provides a mock function with given fields: resource
 func (_m *CacheMetrics) CacheMiss(resource string) {
 	_m.Called(resource)
@@ -28,3 +38,8 @@ func (_m *CacheMetrics) CacheMiss(resource string) {
 func (_m *CacheMetrics) CacheNotFound(resource string) {
 	_m.Called(resource)
 }
+
+// CacheSize provides a mock function with given fields: resource, bytes
+func (_m *CacheMetrics) CacheSize(resource string, bytes uint64) {
+	_m.Called(resource, bytes)
+}
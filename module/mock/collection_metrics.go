@@ -24,6 +24,21 @@ func (_m *CollectionMetrics) ClusterBlockProposed(block *cluster.Block) {
 	_m.Called(block)
 }
 
+// ClusterLiveMembers provides a mock function with given fields: chainID, count
+func (_m *CollectionMetrics) ClusterLiveMembers(chainID flow.ChainID, count uint) {
+	_m.Called(chainID, count)
+}
+
+// ClusterPendingTransactions provides a mock function with given fields: chainID, count
+func (_m *CollectionMetrics) ClusterPendingTransactions(chainID flow.ChainID, count uint) {
+	_m.Called(chainID, count)
+}
+
+// ClusterViewChanged provides a mock function with given fields: chainID, view
+func (_m *CollectionMetrics) ClusterViewChanged(chainID flow.ChainID, view uint64) {
+	_m.Called(chainID, view)
+}
+
 // TransactionIngested provides a mock function with given fields: txID
 func (_m *CollectionMetrics) TransactionIngested(txID flow.Identifier) {
 	_m.Called(txID)
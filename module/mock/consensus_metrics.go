@@ -19,6 +19,11 @@ func (_m *ConsensusMetrics) CheckSealingDuration(duration time.Duration) {
 	_m.Called(duration)
 }
 
+// ClusterStalled provides a mock function with given fields: clusterIndex, stalled
+func (_m *ConsensusMetrics) ClusterStalled(clusterIndex uint, stalled bool) {
+	_m.Called(clusterIndex, stalled)
+}
+
 // EmergencySeal provides a mock function with given fields:
 func (_m *ConsensusMetrics) EmergencySeal() {
 	_m.Called()
@@ -38,6 +38,21 @@ func (_m *NetworkMetrics) NetworkDuplicateMessagesDropped(topic string, messageT
 	_m.Called(topic, messageType)
 }
 
+// NetworkUnauthorizedMessagesDropped provides a mock function with given fields: topic, messageType, role
+func (_m *NetworkMetrics) NetworkUnauthorizedMessagesDropped(topic string, messageType string, role string) {
+	_m.Called(topic, messageType, role)
+}
+
+// NetworkMessageCompressed provides a mock function with given fields: topic, direction, rawBytes, compressedBytes
+func (_m *NetworkMetrics) NetworkMessageCompressed(topic string, direction string, rawBytes int, compressedBytes int) {
+	_m.Called(topic, direction, rawBytes, compressedBytes)
+}
+
+// NetworkRateLimitedMessagesDropped provides a mock function with given fields: topic, mode, reason
+func (_m *NetworkMetrics) NetworkRateLimitedMessagesDropped(topic string, mode string, reason string) {
+	_m.Called(topic, mode, reason)
+}
+
 // NetworkMessageReceived provides a mock function with given fields: sizeBytes, topic, messageType
 func (_m *NetworkMetrics) NetworkMessageReceived(sizeBytes int, topic string, messageType string) {
 	_m.Called(sizeBytes, topic, messageType)
@@ -0,0 +1,682 @@
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// Data identifies a type of historical data which can be pruned.
+type Data string
+
+const (
+	DataEvents               Data = "events"
+	DataTransactionResults   Data = "transaction_results"
+	DataStateInteractions    Data = "state_interactions"
+	DataChunkDataPacks       Data = "chunk_data_packs"
+	DataTransactionLocations Data = "transaction_locations"
+	DataCollections          Data = "collections"
+	DataBlocks               Data = "blocks"
+)
+
+// pruningOrder is the order in which the types of data are pruned. Blocks come
+// last, as the other data is looked up through the height index and the
+// payload of the blocks. Transaction locations come before collections, as the
+// accounts indexed for each transaction are looked up through its collection.
+var pruningOrder = []Data{
+	DataEvents,
+	DataTransactionResults,
+	DataStateInteractions,
+	DataChunkDataPacks,
+	DataTransactionLocations,
+	DataCollections,
+	DataBlocks,
+}
+
+const (
+	DefaultInterval  = time.Minute
+	DefaultBatchSize = 1000
+)
+
+// Retention is the number of sealed heights for which each type of data is
+// kept. Zero keeps the data forever.
+type Retention struct {
+	Blocks               uint64
+	Collections          uint64
+	Events               uint64
+	TransactionResults   uint64
+	ChunkDataPacks       uint64
+	StateInteractions    uint64
+	TransactionLocations uint64
+}
+
+// of returns the retention of the given type of data.
+func (r Retention) of(data Data) uint64 {
+	switch data {
+	case DataBlocks:
+		return r.Blocks
+	case DataCollections:
+		return r.Collections
+	case DataEvents:
+		return r.Events
+	case DataTransactionResults:
+		return r.TransactionResults
+	case DataChunkDataPacks:
+		return r.ChunkDataPacks
+	case DataStateInteractions:
+		return r.StateInteractions
+	case DataTransactionLocations:
+		return r.TransactionLocations
+	default:
+		return 0
+	}
+}
+
+// Config configures the pruner.
+type Config struct {
+	Retention Retention
+	Interval  time.Duration // interval between pruning rounds
+	BatchSize uint64        // maximum number of heights pruned per type of data in a round
+	DryRun    bool          // whether to only log what would be pruned, without deleting anything
+}
+
+// DefaultConfig returns the default config, which keeps all data.
+func DefaultConfig() Config {
+	return Config{
+		Interval:  DefaultInterval,
+		BatchSize: DefaultBatchSize,
+	}
+}
+
+// Pruner periodically deletes the historical data of finalized blocks which
+// are older than the retention of the data, starting from the lowest height
+// above the root block. Each height is pruned in a single transaction, which
+// also persists the pruned height, so that the indexes stay consistent if the
+// node is stopped while pruning. The forks orphaned by the finalized block at
+// a height are pruned along with it.
+//
+// Pruning bypasses the caches of the storage layer, so that pruned entities
+// may still be served from the caches until they are evicted.
+type Pruner struct {
+	unit    *engine.Unit
+	log     zerolog.Logger
+	db      *badger.DB
+	metrics module.PrunerMetrics
+	config  Config
+	dryRun  map[Data]uint64 // heights pruned in a dry run, which are not persisted
+}
+
+// New creates a new pruner for the given database.
+func New(log zerolog.Logger, db *badger.DB, metrics module.PrunerMetrics, config Config) (*Pruner, error) {
+
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("pruning interval must be positive (%s)", config.Interval)
+	}
+	if config.BatchSize == 0 {
+		return nil, fmt.Errorf("pruning batch size must be positive")
+	}
+
+	// blocks are needed to look up the other data, so they have to be kept at
+	// least as long, and at least as long as transactions may reference them
+	blocks := config.Retention.Blocks
+	if blocks > 0 {
+		if blocks < flow.DefaultTransactionExpiry {
+			return nil, fmt.Errorf("block retention (%d) must be at least the transaction expiry (%d)", blocks, flow.DefaultTransactionExpiry)
+		}
+		for _, data := range pruningOrder {
+			retention := config.Retention.of(data)
+			if retention > blocks {
+				return nil, fmt.Errorf("block retention (%d) must be at least the retention of %s (%d)", blocks, data, retention)
+			}
+		}
+	}
+
+	// the accounts of the transactions whose locations are pruned are looked
+	// up through their collections, so they have to be kept at least as long
+	collections := config.Retention.Collections
+	if collections > 0 && config.Retention.TransactionLocations > collections {
+		return nil, fmt.Errorf("collection retention (%d) must be at least the retention of %s (%d)", collections, DataTransactionLocations, config.Retention.TransactionLocations)
+	}
+
+	p := &Pruner{
+		unit:    engine.NewUnit(),
+		log:     log.With().Str("component", "pruner").Bool("dry_run", config.DryRun).Logger(),
+		db:      db,
+		metrics: metrics,
+		config:  config,
+		dryRun:  make(map[Data]uint64),
+	}
+
+	return p, nil
+}
+
+// Ready returns a channel that is closed once the pruner has started. The
+// pruner idles if no data has a retention.
+func (p *Pruner) Ready() <-chan struct{} {
+	if p.enabled() {
+		p.unit.LaunchPeriodically(p.run, p.config.Interval, 0)
+	}
+	return p.unit.Ready()
+}
+
+// Done returns a channel that is closed once the running pruning round has
+// finished.
+func (p *Pruner) Done() <-chan struct{} {
+	return p.unit.Done()
+}
+
+// enabled returns whether any type of data has a retention.
+func (p *Pruner) enabled() bool {
+	for _, data := range pruningOrder {
+		if p.config.Retention.of(data) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pruner) run() {
+	err := p.Prune()
+	if err != nil {
+		p.log.Error().Err(err).Msg("could not prune data")
+	}
+}
+
+// Prune prunes the next batch of heights of each type of data which has a
+// retention, up to the height which is older than its retention.
+func (p *Pruner) Prune() error {
+
+	limit, err := p.limit()
+	if err != nil {
+		return fmt.Errorf("could not get pruning limit: %w", err)
+	}
+
+	for _, data := range pruningOrder {
+		retention := p.config.Retention.of(data)
+		if retention == 0 || limit <= retention {
+			continue
+		}
+		err = p.pruneData(data, limit-retention)
+		if err != nil {
+			return fmt.Errorf("could not prune %s: %w", data, err)
+		}
+	}
+
+	return nil
+}
+
+// limit returns the height to which the retentions are relative, which is the
+// latest sealed height. On execution nodes, it is bounded by the height of the
+// last executed block, so that data is not pruned before it is executed.
+func (p *Pruner) limit() (uint64, error) {
+
+	var sealed uint64
+	err := p.db.View(operation.RetrieveSealedHeight(&sealed))
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve sealed height: %w", err)
+	}
+
+	var executedID flow.Identifier
+	err = p.db.View(operation.RetrieveExecutedBlock(&executedID))
+	if errors.Is(err, storage.ErrNotFound) {
+		return sealed, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve executed block: %w", err)
+	}
+
+	var executed flow.Header
+	err = p.db.View(operation.RetrieveHeader(executedID, &executed))
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve executed header: %w", err)
+	}
+	if executed.Height < sealed {
+		return executed.Height, nil
+	}
+
+	return sealed, nil
+}
+
+// prunedHeight returns the height up to which the given type of data was
+// pruned. As the data of the root block is never pruned, it defaults to the
+// root height.
+func (p *Pruner) prunedHeight(data Data) (uint64, error) {
+
+	if height, ok := p.dryRun[data]; ok {
+		return height, nil
+	}
+
+	var height uint64
+	err := p.db.View(operation.RetrievePrunedHeight(string(data), &height))
+	if errors.Is(err, storage.ErrNotFound) {
+		err = p.db.View(operation.RetrieveRootHeight(&height))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return height, nil
+}
+
+// pruneData prunes the next batch of heights of the given type of data, up to
+// the given height.
+func (p *Pruner) pruneData(data Data, height uint64) error {
+
+	pruned, err := p.prunedHeight(data)
+	if err != nil {
+		return fmt.Errorf("could not get pruned height: %w", err)
+	}
+
+	blocks, err := p.prunedHeight(DataBlocks)
+	if err != nil {
+		return fmt.Errorf("could not get pruned height of blocks: %w", err)
+	}
+
+	if data == DataBlocks {
+		// blocks are only pruned once the data looked up through them is
+		for _, other := range pruningOrder {
+			if other == DataBlocks || p.config.Retention.of(other) == 0 {
+				continue
+			}
+			otherPruned, err := p.prunedHeight(other)
+			if err != nil {
+				return fmt.Errorf("could not get pruned height of %s: %w", other, err)
+			}
+			if otherPruned < height {
+				height = otherPruned
+			}
+		}
+	} else if pruned < blocks {
+		// the data of pruned blocks can't be looked up anymore, which happens
+		// if a retention is configured after blocks were pruned
+		p.log.Warn().
+			Str("data", string(data)).
+			Uint64("pruned_height", pruned).
+			Uint64("blocks_pruned_height", blocks).
+			Msg("skipping data of pruned blocks")
+		pruned = blocks
+	}
+
+	if height <= pruned {
+		return nil
+	}
+	if height-pruned > p.config.BatchSize {
+		height = pruned + p.config.BatchSize
+	}
+
+	start := time.Now()
+	for h := pruned + 1; h <= height; h++ {
+		err = p.pruneHeight(data, h)
+		if err != nil {
+			return fmt.Errorf("could not prune height %d: %w", h, err)
+		}
+	}
+	duration := time.Since(start)
+
+	p.log.Info().
+		Str("data", string(data)).
+		Uint64("from_height", pruned+1).
+		Uint64("to_height", height).
+		Dur("duration", duration).
+		Msg("pruned data")
+
+	if !p.config.DryRun {
+		p.metrics.PrunedHeight(string(data), height)
+		p.metrics.PruningDuration(string(data), duration)
+	}
+
+	return nil
+}
+
+// pruneHeight removes the given type of data of the finalized block at the
+// given height, and of the forks it orphaned, and persists the height as
+// pruned, in a single transaction. In a dry run, the transaction is discarded.
+func (p *Pruner) pruneHeight(data Data, height uint64) error {
+
+	tx := p.db.NewTransaction(true)
+	defer tx.Discard()
+
+	var blockID flow.Identifier
+	err := operation.LookupBlockHeight(height, &blockID)(tx)
+	if err != nil {
+		return fmt.Errorf("could not look up block: %w", err)
+	}
+
+	orphanIDs, err := orphanedBlocks(tx, blockID)
+	if err != nil {
+		return fmt.Errorf("could not look up orphaned blocks: %w", err)
+	}
+
+	err = removeFinalized(tx, data, blockID, height)
+	if err != nil {
+		return fmt.Errorf("could not remove %s of block %x: %w", data, blockID, err)
+	}
+	for _, orphanID := range orphanIDs {
+		err = removeOrphaned(tx, data, orphanID)
+		if err != nil {
+			return fmt.Errorf("could not remove %s of orphaned block %x: %w", data, orphanID, err)
+		}
+	}
+
+	if p.config.DryRun {
+		p.dryRun[data] = height
+		return nil
+	}
+
+	err = operation.UpdatePrunedHeight(string(data), height)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		err = operation.InsertPrunedHeight(string(data), height)(tx)
+	}
+	if err != nil {
+		return fmt.Errorf("could not persist pruned height: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// orphanedBlocks returns the blocks orphaned by the finalization of the given
+// block, which are its siblings and all their descendants. Every orphaned
+// block descends from exactly one sibling of a finalized block, so each one
+// is pruned along with exactly one height. The children index of a finalized
+// block is kept until its children are pruned, so that it can be looked up
+// for every type of data.
+func orphanedBlocks(tx *badger.Txn, blockID flow.Identifier) ([]flow.Identifier, error) {
+
+	var header flow.Header
+	err := operation.RetrieveHeader(blockID, &header)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve header: %w", err)
+	}
+
+	var siblingIDs []flow.Identifier
+	err = operation.RetrieveBlockChildren(header.ParentID, &siblingIDs)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve siblings: %w", err)
+	}
+
+	var orphanIDs []flow.Identifier
+	for _, siblingID := range siblingIDs {
+		if siblingID != blockID {
+			orphanIDs = append(orphanIDs, siblingID)
+		}
+	}
+	for i := 0; i < len(orphanIDs); i++ {
+		var childrenIDs []flow.Identifier
+		err = operation.RetrieveBlockChildren(orphanIDs[i], &childrenIDs)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve children: %w", err)
+		}
+		orphanIDs = append(orphanIDs, childrenIDs...)
+	}
+
+	return orphanIDs, nil
+}
+
+// removeFinalized removes the given type of data of the finalized block at the
+// given height.
+func removeFinalized(tx *badger.Txn, data Data, blockID flow.Identifier, height uint64) error {
+	var err error
+	switch data {
+	case DataEvents:
+		err = removeAll(tx,
+			operation.RemoveEventsByBlockID(blockID),
+			operation.RemoveServiceEventsByBlockID(blockID),
+		)
+	case DataTransactionResults:
+		err = removeAll(tx, operation.RemoveTransactionResultsByBlockID(blockID))
+	case DataStateInteractions:
+		err = removeAll(tx, operation.RemoveExecutionStateInteractions(blockID))
+	case DataChunkDataPacks:
+		err = removeChunkDataPacks(tx, blockID)
+	case DataTransactionLocations:
+		err = removeTransactionLocations(tx, blockID, height)
+	case DataCollections:
+		err = removeCollections(tx, blockID)
+	case DataBlocks:
+		err = removeBlock(tx, blockID, height)
+	default:
+		err = fmt.Errorf("unknown data: %s", data)
+	}
+	return err
+}
+
+// removeOrphaned removes the given type of data of the given orphaned block.
+// Collections and transactions are kept, as they may be included in finalized
+// blocks as well, and transaction locations are only indexed for finalized
+// blocks.
+func removeOrphaned(tx *badger.Txn, data Data, blockID flow.Identifier) error {
+	var err error
+	switch data {
+	case DataEvents:
+		err = removeAll(tx,
+			operation.RemoveEventsByBlockID(blockID),
+			operation.RemoveServiceEventsByBlockID(blockID),
+		)
+	case DataTransactionResults:
+		err = removeAll(tx, operation.RemoveTransactionResultsByBlockID(blockID))
+	case DataStateInteractions:
+		err = removeAll(tx, operation.RemoveExecutionStateInteractions(blockID))
+	case DataChunkDataPacks:
+		err = removeChunkDataPacks(tx, blockID)
+	case DataTransactionLocations, DataCollections:
+	case DataBlocks:
+		err = removeOrphanedBlock(tx, blockID)
+	default:
+		err = fmt.Errorf("unknown data: %s", data)
+	}
+	return err
+}
+
+// removeChunkDataPacks removes the chunk data packs of the chunks of the
+// execution result for the given block, along with their block index.
+func removeChunkDataPacks(tx *badger.Txn, blockID flow.Identifier) error {
+
+	var resultID flow.Identifier
+	err := operation.LookupExecutionResult(blockID, &resultID)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up execution result: %w", err)
+	}
+
+	var result flow.ExecutionResult
+	err = operation.RetrieveExecutionResult(resultID, &result)(tx)
+	if err != nil {
+		return fmt.Errorf("could not retrieve execution result: %w", err)
+	}
+
+	for _, chunk := range result.Chunks {
+		err = removeAll(tx,
+			operation.RemoveChunkDataPack(chunk.ID()),
+			operation.RemoveBlockIDByChunkID(chunk.ID()),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeTransactionLocations removes the location of the transactions of the
+// collections guaranteed in the payload of the given block, along with their
+// index by account.
+func removeTransactionLocations(tx *badger.Txn, blockID flow.Identifier, height uint64) error {
+
+	var guarIDs []flow.Identifier
+	err := operation.LookupPayloadGuarantees(blockID, &guarIDs)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up guarantees: %w", err)
+	}
+
+	for _, collID := range guarIDs {
+		var collection flow.LightCollection
+		err = operation.RetrieveCollection(collID, &collection)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			// nodes only store the collections they need
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not retrieve collection: %w", err)
+		}
+
+		for _, txID := range collection.Transactions {
+			var transaction flow.TransactionBody
+			err = operation.RetrieveTransaction(txID, &transaction)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				// the transaction, and thus its location, was pruned already
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not retrieve transaction: %w", err)
+			}
+
+			for _, address := range transaction.Accounts() {
+				err = removeAll(tx, operation.RemoveAccountTransaction(address, height, txID))
+				if err != nil {
+					return err
+				}
+			}
+			err = removeAll(tx, operation.RemoveTransactionLocation(txID))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeCollections removes the collections guaranteed in the payload of the
+// given block, along with their transactions and transaction index.
+func removeCollections(tx *badger.Txn, blockID flow.Identifier) error {
+
+	var guarIDs []flow.Identifier
+	err := operation.LookupPayloadGuarantees(blockID, &guarIDs)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up guarantees: %w", err)
+	}
+
+	for _, collID := range guarIDs {
+		var collection flow.LightCollection
+		err = operation.RetrieveCollection(collID, &collection)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			// nodes only store the collections they need
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not retrieve collection: %w", err)
+		}
+
+		for _, txID := range collection.Transactions {
+			err = removeAll(tx,
+				operation.RemoveTransaction(txID),
+				operation.RemoveCollectionByTransaction(txID),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = removeAll(tx, operation.RemoveCollection(collID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeBlock removes the header and payload indexes of the given block, along
+// with its guarantees and the indexes by height and collection. Seals, receipts
+// and results are kept, as they may be referenced by blocks at other heights.
+// The children index of the parent is removed rather than the one of the block,
+// which is needed to look up the blocks orphaned at the next height.
+func removeBlock(tx *badger.Txn, blockID flow.Identifier, height uint64) error {
+
+	var header flow.Header
+	err := operation.RetrieveHeader(blockID, &header)(tx)
+	if err != nil {
+		return fmt.Errorf("could not retrieve header: %w", err)
+	}
+
+	var guarIDs []flow.Identifier
+	err = operation.LookupPayloadGuarantees(blockID, &guarIDs)(tx)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not look up guarantees: %w", err)
+	}
+
+	for _, collID := range guarIDs {
+		err = removeAll(tx,
+			operation.RemoveGuarantee(collID),
+			operation.RemoveCollectionBlock(collID),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return removeAll(tx,
+		operation.RemovePayloadGuarantees(blockID),
+		operation.RemovePayloadSeals(blockID),
+		operation.RemovePayloadReceipts(blockID),
+		operation.RemovePayloadResults(blockID),
+		operation.RemoveBlockSeal(blockID),
+		operation.RemoveEpochStatus(blockID),
+		operation.RemoveBlockChildren(header.ParentID),
+		operation.RemoveBlockValidity(blockID),
+		operation.RemoveHeader(blockID),
+		operation.RemoveBlockHeight(height),
+	)
+}
+
+// removeOrphanedBlock removes the header and payload indexes of the given
+// orphaned block. Its guarantees are kept, as the guaranteed collections may
+// be included in finalized blocks as well.
+func removeOrphanedBlock(tx *badger.Txn, blockID flow.Identifier) error {
+	return removeAll(tx,
+		operation.RemovePayloadGuarantees(blockID),
+		operation.RemovePayloadSeals(blockID),
+		operation.RemovePayloadReceipts(blockID),
+		operation.RemovePayloadResults(blockID),
+		operation.RemoveBlockSeal(blockID),
+		operation.RemoveEpochStatus(blockID),
+		operation.RemoveBlockChildren(blockID),
+		operation.RemoveBlockValidity(blockID),
+		operation.RemoveHeader(blockID),
+	)
+}
+
+// removeAll applies the given removals in the transaction, ignoring entries
+// which don't exist, as not every node stores all data.
+func removeAll(tx *badger.Txn, removals ...func(*badger.Txn) error) error {
+	for _, remove := range removals {
+		err := remove(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
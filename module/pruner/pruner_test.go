@@ -0,0 +1,453 @@
+package pruner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	module "github.com/onflow/flow-go/module/mock"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// chain is a finalized chain of blocks with a collection and an event each.
+type chain struct {
+	blockIDs []flow.Identifier
+	collIDs  []flow.Identifier
+	txIDs    []flow.Identifier
+}
+
+// storeChain stores a finalized chain of blocks from the root height up to the
+// sealed height.
+func storeChain(t *testing.T, db *badger.DB, root uint64, sealed uint64) *chain {
+	c := &chain{}
+	for height := root; height <= sealed; height++ {
+		header := unittest.BlockHeaderFixture()
+		header.Height = height
+		blockID := header.ID()
+
+		collection := unittest.CollectionFixture(1)
+		light := collection.Light()
+		guarantee := unittest.CollectionGuaranteeFixture(func(cg *flow.CollectionGuarantee) {
+			cg.CollectionID = light.ID()
+		})
+		txID := light.Transactions[0]
+		event := unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID)
+		location := &flow.TransactionLocation{TransactionID: txID, BlockID: blockID, Height: height}
+
+		ops := []func(*badger.Txn) error{
+			operation.InsertHeader(blockID, &header),
+			operation.IndexBlockHeight(height, blockID),
+			operation.InsertGuarantee(guarantee.ID(), guarantee),
+			operation.IndexPayloadGuarantees(blockID, []flow.Identifier{guarantee.ID()}),
+			operation.InsertCollection(&light),
+			operation.InsertTransaction(txID, collection.Transactions[0]),
+			operation.IndexCollectionByTransaction(txID, light.ID()),
+			operation.InsertEvent(blockID, event),
+			operation.IndexTransactionLocation(location),
+		}
+		for _, address := range collection.Transactions[0].Accounts() {
+			ops = append(ops, operation.IndexAccountTransaction(address, location))
+		}
+
+		err := db.Update(func(tx *badger.Txn) error {
+			for _, op := range ops {
+				err := op(tx)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		c.blockIDs = append(c.blockIDs, blockID)
+		c.collIDs = append(c.collIDs, light.ID())
+		c.txIDs = append(c.txIDs, txID)
+	}
+
+	err := db.Update(func(tx *badger.Txn) error {
+		err := operation.InsertRootHeight(root)(tx)
+		if err != nil {
+			return err
+		}
+		return operation.InsertSealedHeight(sealed)(tx)
+	})
+	require.NoError(t, err)
+
+	return c
+}
+
+func hasEvents(t *testing.T, db *badger.DB, blockID flow.Identifier) bool {
+	var events []flow.Event
+	err := db.View(operation.LookupEventsByBlockID(blockID, &events))
+	require.NoError(t, err)
+	return len(events) > 0
+}
+
+func hasHeader(t *testing.T, db *badger.DB, blockID flow.Identifier) bool {
+	var header flow.Header
+	err := db.View(operation.RetrieveHeader(blockID, &header))
+	if errors.Is(err, storage.ErrNotFound) {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func hasCollection(t *testing.T, db *badger.DB, collID flow.Identifier, txID flow.Identifier) bool {
+	var collection flow.LightCollection
+	err := db.View(operation.RetrieveCollection(collID, &collection))
+	if errors.Is(err, storage.ErrNotFound) {
+		var tx flow.TransactionBody
+		err = db.View(operation.RetrieveTransaction(txID, &tx))
+		assert.True(t, errors.Is(err, storage.ErrNotFound), "transaction of pruned collection should be pruned")
+		var id flow.Identifier
+		err = db.View(operation.RetrieveCollectionID(txID, &id))
+		assert.True(t, errors.Is(err, storage.ErrNotFound), "transaction index of pruned collection should be pruned")
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func hasTransactionLocation(t *testing.T, db *badger.DB, txID flow.Identifier) bool {
+	var location flow.TransactionLocation
+	err := db.View(operation.LookupTransactionLocation(txID, &location))
+	if errors.Is(err, storage.ErrNotFound) {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func accountTransactions(t *testing.T, db *badger.DB, address flow.Address) []flow.TransactionLocation {
+	var locations []flow.TransactionLocation
+	err := db.View(operation.LookupAccountTransactions(address, nil, 1000, &locations))
+	require.NoError(t, err)
+	return locations
+}
+
+func prunedHeight(t *testing.T, db *badger.DB, data Data) uint64 {
+	var height uint64
+	err := db.View(operation.RetrievePrunedHeight(string(data), &height))
+	require.NoError(t, err)
+	return height
+}
+
+func TestPrune(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		c := storeChain(t, db, 0, 650)
+
+		metrics := &module.PrunerMetrics{}
+		metrics.On("PrunedHeight", string(DataEvents), uint64(640)).Once()
+		metrics.On("PrunedHeight", string(DataCollections), uint64(640)).Once()
+		metrics.On("PrunedHeight", string(DataBlocks), uint64(50)).Once()
+		metrics.On("PruningDuration", mock.Anything, mock.Anything).Times(3)
+
+		config := DefaultConfig()
+		config.Retention = Retention{
+			Events:      10,
+			Collections: 10,
+			Blocks:      600,
+		}
+		p, err := New(zerolog.Nop(), db, metrics, config)
+		require.NoError(t, err)
+
+		err = p.Prune()
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+
+		assert.Equal(t, uint64(640), prunedHeight(t, db, DataEvents))
+		assert.Equal(t, uint64(640), prunedHeight(t, db, DataCollections))
+		assert.Equal(t, uint64(50), prunedHeight(t, db, DataBlocks))
+
+		// the root block is never pruned
+		assert.True(t, hasEvents(t, db, c.blockIDs[0]))
+		assert.True(t, hasCollection(t, db, c.collIDs[0], c.txIDs[0]))
+		assert.True(t, hasHeader(t, db, c.blockIDs[0]))
+
+		assert.False(t, hasEvents(t, db, c.blockIDs[640]))
+		assert.True(t, hasEvents(t, db, c.blockIDs[641]))
+		assert.False(t, hasCollection(t, db, c.collIDs[640], c.txIDs[640]))
+		assert.True(t, hasCollection(t, db, c.collIDs[641], c.txIDs[641]))
+		assert.False(t, hasHeader(t, db, c.blockIDs[50]))
+		assert.True(t, hasHeader(t, db, c.blockIDs[51]))
+
+		// the indexes of pruned blocks are removed as well
+		var blockID flow.Identifier
+		err = db.View(operation.LookupBlockHeight(50, &blockID))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+		var guarIDs []flow.Identifier
+		err = db.View(operation.LookupPayloadGuarantees(c.blockIDs[50], &guarIDs))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		// nothing is left to prune until more blocks are sealed
+		err = p.Prune()
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+	})
+}
+
+func TestPrune_Batch(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		c := storeChain(t, db, 0, 250)
+
+		metrics := &module.PrunerMetrics{}
+		metrics.On("PrunedHeight", string(DataEvents), uint64(100)).Once()
+		metrics.On("PrunedHeight", string(DataEvents), uint64(200)).Once()
+		metrics.On("PrunedHeight", string(DataEvents), uint64(240)).Once()
+		metrics.On("PruningDuration", string(DataEvents), mock.Anything).Times(3)
+
+		config := DefaultConfig()
+		config.Retention.Events = 10
+		config.BatchSize = 100
+		p, err := New(zerolog.Nop(), db, metrics, config)
+		require.NoError(t, err)
+
+		err = p.Prune()
+		require.NoError(t, err)
+		assert.False(t, hasEvents(t, db, c.blockIDs[100]))
+		assert.True(t, hasEvents(t, db, c.blockIDs[101]))
+
+		err = p.Prune()
+		require.NoError(t, err)
+		err = p.Prune()
+		require.NoError(t, err)
+		assert.False(t, hasEvents(t, db, c.blockIDs[240]))
+		assert.True(t, hasEvents(t, db, c.blockIDs[241]))
+
+		metrics.AssertExpectations(t)
+	})
+}
+
+func TestPrune_NonZeroRoot(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		c := storeChain(t, db, 100, 150)
+
+		metrics := &module.PrunerMetrics{}
+		metrics.On("PrunedHeight", string(DataEvents), uint64(140)).Once()
+		metrics.On("PruningDuration", string(DataEvents), mock.Anything).Once()
+
+		config := DefaultConfig()
+		config.Retention.Events = 10
+		p, err := New(zerolog.Nop(), db, metrics, config)
+		require.NoError(t, err)
+
+		err = p.Prune()
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+
+		assert.True(t, hasEvents(t, db, c.blockIDs[0]))
+		assert.False(t, hasEvents(t, db, c.blockIDs[1]))
+		assert.False(t, hasEvents(t, db, c.blockIDs[40]))
+		assert.True(t, hasEvents(t, db, c.blockIDs[41]))
+	})
+}
+
+func TestPrune_DryRun(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		c := storeChain(t, db, 0, 650)
+
+		// no metrics are reported in a dry run
+		metrics := &module.PrunerMetrics{}
+
+		config := DefaultConfig()
+		config.Retention = Retention{
+			Events:      10,
+			Collections: 10,
+			Blocks:      600,
+		}
+		config.DryRun = true
+		p, err := New(zerolog.Nop(), db, metrics, config)
+		require.NoError(t, err)
+
+		err = p.Prune()
+		require.NoError(t, err)
+
+		for _, height := range []int{1, 50, 640} {
+			assert.True(t, hasEvents(t, db, c.blockIDs[height]))
+			assert.True(t, hasCollection(t, db, c.collIDs[height], c.txIDs[height]))
+			assert.True(t, hasHeader(t, db, c.blockIDs[height]))
+		}
+
+		var height uint64
+		err = db.View(operation.RetrievePrunedHeight(string(DataEvents), &height))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		// progress is kept in memory, so the same heights are not pruned again
+		assert.Equal(t, uint64(640), p.dryRun[DataEvents])
+		assert.Equal(t, uint64(50), p.dryRun[DataBlocks])
+	})
+}
+
+func TestPrune_TransactionLocations(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		c := storeChain(t, db, 0, 650)
+
+		// a transaction which is missing is considered pruned already, along
+		// with its location
+		var missing flow.TransactionBody
+		err := db.View(operation.RetrieveTransaction(c.txIDs[100], &missing))
+		require.NoError(t, err)
+		err = db.Update(func(tx *badger.Txn) error {
+			for _, address := range missing.Accounts() {
+				err := operation.RemoveAccountTransaction(address, 100, c.txIDs[100])(tx)
+				if err != nil {
+					return err
+				}
+			}
+			err := operation.RemoveTransactionLocation(c.txIDs[100])(tx)
+			if err != nil {
+				return err
+			}
+			return operation.RemoveTransaction(c.txIDs[100])(tx)
+		})
+		require.NoError(t, err)
+
+		metrics := &module.PrunerMetrics{}
+		metrics.On("PrunedHeight", string(DataTransactionLocations), uint64(640)).Once()
+		metrics.On("PrunedHeight", string(DataCollections), uint64(640)).Once()
+		metrics.On("PruningDuration", mock.Anything, mock.Anything).Times(2)
+
+		config := DefaultConfig()
+		config.Retention = Retention{
+			TransactionLocations: 10,
+			Collections:          10,
+		}
+		p, err := New(zerolog.Nop(), db, metrics, config)
+		require.NoError(t, err)
+
+		err = p.Prune()
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+
+		// the locations are pruned along with the collections of their transactions
+		assert.False(t, hasTransactionLocation(t, db, c.txIDs[640]))
+		assert.True(t, hasTransactionLocation(t, db, c.txIDs[641]))
+		assert.False(t, hasCollection(t, db, c.collIDs[640], c.txIDs[640]))
+
+		// the transactions of pruned heights are removed from the account
+		// index, except for the transactions of the root block
+		var transaction flow.TransactionBody
+		err = db.View(operation.RetrieveTransaction(c.txIDs[641], &transaction))
+		require.NoError(t, err)
+		for _, address := range transaction.Accounts() {
+			locations := accountTransactions(t, db, address)
+			require.Len(t, locations, 11)
+			for _, location := range locations {
+				if location.Height != 0 {
+					assert.Greater(t, location.Height, uint64(640))
+				}
+			}
+		}
+
+		// the root block is never pruned
+		assert.True(t, hasTransactionLocation(t, db, c.txIDs[0]))
+	})
+}
+
+// TestPrune_OrphanedForks tests that the forks orphaned by a finalized block
+// are pruned along with its height.
+func TestPrune_OrphanedForks(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		c := storeChain(t, db, 0, 650)
+
+		// a fork of two blocks, whose first block is a sibling of the
+		// finalized block at the given height
+		fork := func(height uint64) []flow.Identifier {
+			var finalized flow.Header
+			err := db.View(operation.RetrieveHeader(c.blockIDs[height], &finalized))
+			require.NoError(t, err)
+
+			first := unittest.BlockHeaderWithParentFixture(&finalized)
+			first.ParentID = finalized.ParentID
+			first.Height = height
+			second := unittest.BlockHeaderWithParentFixture(&first)
+
+			err = db.Update(func(tx *badger.Txn) error {
+				for _, op := range []func(*badger.Txn) error{
+					operation.InsertHeader(first.ID(), &first),
+					operation.InsertHeader(second.ID(), &second),
+					operation.InsertBlockChildren(finalized.ParentID, []flow.Identifier{finalized.ID(), first.ID()}),
+					operation.InsertBlockChildren(first.ID(), []flow.Identifier{second.ID()}),
+					operation.InsertEvent(first.ID(), unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture())),
+					operation.InsertEvent(second.ID(), unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture())),
+				} {
+					err := op(tx)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			return []flow.Identifier{first.ID(), second.ID()}
+		}
+		pruned := fork(30)
+		kept := fork(45)
+
+		metrics := &module.PrunerMetrics{}
+		metrics.On("PrunedHeight", string(DataEvents), uint64(40)).Once()
+		metrics.On("PrunedHeight", string(DataBlocks), uint64(40)).Once()
+		metrics.On("PruningDuration", mock.Anything, mock.Anything).Times(2)
+
+		config := DefaultConfig()
+		config.Retention = Retention{
+			Events: 610,
+			Blocks: 610,
+		}
+		p, err := New(zerolog.Nop(), db, metrics, config)
+		require.NoError(t, err)
+
+		err = p.Prune()
+		require.NoError(t, err)
+		metrics.AssertExpectations(t)
+
+		for _, blockID := range pruned {
+			assert.False(t, hasEvents(t, db, blockID))
+			assert.False(t, hasHeader(t, db, blockID))
+		}
+		for _, blockID := range kept {
+			assert.True(t, hasEvents(t, db, blockID))
+			assert.True(t, hasHeader(t, db, blockID))
+		}
+	})
+}
+
+func TestNew_InvalidRetention(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		metrics := &module.PrunerMetrics{}
+
+		config := DefaultConfig()
+		config.Retention.Blocks = flow.DefaultTransactionExpiry - 1
+		_, err := New(zerolog.Nop(), db, metrics, config)
+		assert.Error(t, err)
+
+		config = DefaultConfig()
+		config.Retention.Blocks = 1000
+		config.Retention.Events = 1001
+		_, err = New(zerolog.Nop(), db, metrics, config)
+		assert.Error(t, err)
+
+		// the transactions of the locations are looked up through their collections
+		config = DefaultConfig()
+		config.Retention.Collections = 100
+		config.Retention.TransactionLocations = 101
+		_, err = New(zerolog.Nop(), db, metrics, config)
+		assert.Error(t, err)
+
+		config = DefaultConfig()
+		config.Retention.Blocks = 1000
+		config.Retention.Events = 1000
+		_, err = New(zerolog.Nop(), db, metrics, config)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,98 @@
+package cbor
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/flow-go/network"
+)
+
+// encMode is the CBOR encoding mode used for all network messages. Timestamps
+// are encoded with nanosecond precision, so that entity IDs are preserved.
+var encMode = func() cbor.EncMode {
+	options := cbor.CoreDetEncOptions()
+	options.Time = cbor.TimeRFC3339Nano
+	mode, err := options.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+// decMode is the CBOR decoding mode used for all network messages. The size of
+// messages is already bounded by the networking layer, so we lift the default
+// limits on the number of array elements and map pairs.
+var decMode = func() cbor.DecMode {
+	options := cbor.DecOptions{
+		DupMapKey:        cbor.DupMapKeyEnforcedAPF,
+		MaxArrayElements: math.MaxInt32,
+		MaxMapPairs:      math.MaxInt32,
+	}
+	mode, err := options.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+// Codec represents a CBOR codec for our network.
+type Codec struct {
+}
+
+// NewCodec creates a new CBOR codec.
+func NewCodec() *Codec {
+	c := &Codec{}
+	return c
+}
+
+// NewEncoder creates a new CBOR encoder with the given underlying writer.
+func (c *Codec) NewEncoder(w io.Writer) network.Encoder {
+	enc := encMode.NewEncoder(w)
+	return &Encoder{enc: enc}
+}
+
+// NewDecoder creates a new CBOR decoder with the given underlying reader.
+func (c *Codec) NewDecoder(r io.Reader) network.Decoder {
+	dec := decMode.NewDecoder(r)
+	return &Decoder{dec: dec}
+}
+
+// Encode will encode the given entity and return the bytes.
+func (c *Codec) Encode(v interface{}) ([]byte, error) {
+
+	// encode the value
+	env, err := encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode envelope: %w", err)
+	}
+
+	// encode the envelope
+	data, err := encMode.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode value: %w", err)
+	}
+
+	return data, nil
+}
+
+// Decode will attempt to decode the given entity from bytes.
+func (c *Codec) Decode(data []byte) (interface{}, error) {
+
+	// decode the envelope
+	var env Envelope
+	err := decMode.Unmarshal(data, &env)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode envelope: %w", err)
+	}
+
+	// decode the value
+	v, err := decode(env)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode value: %w", err)
+	}
+
+	return v, nil
+}
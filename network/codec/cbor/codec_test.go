@@ -0,0 +1,127 @@
+package cbor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/messages"
+	"github.com/onflow/flow-go/network/codec/json"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func messageFixtures() []interface{} {
+	block := unittest.BlockFixture()
+	tx := unittest.TransactionBodyFixture()
+	collection := unittest.CollectionFixture(3)
+	return []interface{}{
+		&messages.BlockProposal{Header: block.Header, Payload: block.Payload},
+		&messages.BlockResponse{Nonce: 1, Blocks: []*flow.Block{&block}},
+		&tx,
+		unittest.CollectionGuaranteeFixture(),
+		unittest.ExecutionReceiptFixture(),
+		unittest.ResultApprovalFixture(),
+		&messages.ChunkDataResponse{
+			ChunkDataPack: *unittest.ChunkDataPackFixture(unittest.IdentifierFixture()),
+			Collection:    collection,
+			Nonce:         2,
+		},
+		&messages.EntityResponse{
+			Nonce:     3,
+			EntityIDs: []flow.Identifier{unittest.IdentifierFixture()},
+			Blobs:     [][]byte{unittest.RandomBytes(128)},
+		},
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec()
+	for _, msg := range messageFixtures() {
+		data, err := codec.Encode(msg)
+		require.NoError(t, err)
+		decoded, err := codec.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+	}
+}
+
+func TestCodec_Stream(t *testing.T) {
+	codec := NewCodec()
+	msgs := messageFixtures()
+
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf)
+	for _, msg := range msgs {
+		require.NoError(t, enc.Encode(msg))
+	}
+
+	dec := codec.NewDecoder(&buf)
+	for _, msg := range msgs {
+		decoded, err := dec.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+	}
+}
+
+func TestCodec_PreservesIDs(t *testing.T) {
+	codec := NewCodec()
+	block := unittest.BlockFixture()
+
+	data, err := codec.Encode(&messages.BlockProposal{Header: block.Header, Payload: block.Payload})
+	require.NoError(t, err)
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+
+	proposal := decoded.(*messages.BlockProposal)
+	assert.Equal(t, block.Header.ID(), proposal.Header.ID())
+	assert.Equal(t, block.Payload.Hash(), proposal.Payload.Hash())
+}
+
+func TestCodec_ServiceEvents(t *testing.T) {
+	codec := NewCodec()
+	setup := unittest.EpochSetupFixture(unittest.WithParticipants(unittest.IdentityListFixture(5, unittest.WithAllRoles(), unittest.WithRandomPublicKeys())))
+	commit := unittest.EpochCommitFixture()
+	commit.DKGParticipants[unittest.IdentifierFixture()] = flow.DKGParticipant{
+		Index:    1,
+		KeyShare: unittest.KeyFixture(crypto.BLSBLS12381).PublicKey(),
+	}
+	result := unittest.ExecutionResultFixture(func(result *flow.ExecutionResult) {
+		result.ServiceEvents = []flow.ServiceEvent{setup.ServiceEvent(), commit.ServiceEvent()}
+	})
+	receipt := unittest.ExecutionReceiptFixture(unittest.WithResult(result))
+
+	data, err := codec.Encode(receipt)
+	require.NoError(t, err)
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+
+	decodedReceipt := decoded.(*flow.ExecutionReceipt)
+	assert.Equal(t, receipt.ID(), decodedReceipt.ID())
+	assert.Equal(t, result.ID(), decodedReceipt.ExecutionResult.ID())
+	require.Len(t, decodedReceipt.ExecutionResult.ServiceEvents, 2)
+	assert.Equal(t, setup.ID(), decodedReceipt.ExecutionResult.ServiceEvents[0].Event.(*flow.EpochSetup).ID())
+	assert.Equal(t, commit.ID(), decodedReceipt.ExecutionResult.ServiceEvents[1].Event.(*flow.EpochCommit).ID())
+}
+
+func TestCodec_SmallerThanJSON(t *testing.T) {
+	codec := NewCodec()
+	legacy := json.NewCodec()
+	for _, msg := range messageFixtures() {
+		data, err := codec.Encode(msg)
+		require.NoError(t, err)
+		jsonData, err := legacy.Encode(msg)
+		require.NoError(t, err)
+		assert.Less(t, len(data), len(jsonData), "%T", msg)
+	}
+}
+
+func TestCodec_InvalidCode(t *testing.T) {
+	data, err := encMode.Marshal(Envelope{Code: 255, Data: []byte{0xf6}})
+	require.NoError(t, err)
+	_, err = NewCodec().Decode(data)
+	assert.Error(t, err)
+}
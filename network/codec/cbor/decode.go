@@ -0,0 +1,25 @@
+package cbor
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/network/codec"
+)
+
+// decode will decode the envelope into an entity.
+func decode(env Envelope) (interface{}, error) {
+
+	// create the desired message
+	v, err := codec.InterfaceFromMessageCode(env.Code)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine message type: %w", err)
+	}
+
+	// unmarshal the payload
+	err = decMode.Unmarshal(env.Data, v)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode payload: %w", err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,29 @@
+package cbor
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/network/codec"
+)
+
+func encode(v interface{}) (*Envelope, error) {
+
+	// determine the message type
+	code, err := codec.MessageCodeFromInterface(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine message code: %w", err)
+	}
+
+	// encode the payload
+	data, err := encMode.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode payload: %w", err)
+	}
+
+	env := Envelope{
+		Code: code,
+		Data: data,
+	}
+
+	return &env, nil
+}
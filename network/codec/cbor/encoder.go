@@ -0,0 +1,31 @@
+package cbor
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Encoder is an encoder to write serialized CBOR to a writer.
+type Encoder struct {
+	enc *cbor.Encoder
+}
+
+// Encode will convert the given message into CBOR and write it to the
+// underlying encoder.
+func (e *Encoder) Encode(v interface{}) error {
+
+	// encode the value
+	env, err := encode(v)
+	if err != nil {
+		return fmt.Errorf("could not encode value: %w", err)
+	}
+
+	// write the envelope to network
+	err = e.enc.Encode(env)
+	if err != nil {
+		return fmt.Errorf("could not encode envelope: %w", err)
+	}
+
+	return nil
+}
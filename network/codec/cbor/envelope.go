@@ -0,0 +1,15 @@
+package cbor
+
+import (
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Envelope is a wrapper to convey type information with CBOR encoding. It is
+// encoded as a two-element array rather than a map, so the framing only adds a
+// few bytes to each message. The code is taken from the message code registry
+// shared by all network codecs.
+type Envelope struct {
+	_    struct{} `cbor:",toarray"`
+	Code uint8
+	Data cbor.RawMessage
+}
@@ -6,89 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/pkg/errors"
-
-	"github.com/onflow/flow-go/model/flow"
-	"github.com/onflow/flow-go/model/libp2p/message"
-	"github.com/onflow/flow-go/model/messages"
+	"github.com/onflow/flow-go/network/codec"
 )
 
 func encode(v interface{}) (*Envelope, error) {
 
 	// determine the message type
-	var code uint8
-	switch v.(type) {
-
-	// consensus
-	case *messages.BlockProposal:
-		code = CodeBlockProposal
-	case *messages.BlockVote:
-		code = CodeBlockVote
-
-	// protocol state sync
-	case *messages.SyncRequest:
-		code = CodeSyncRequest
-	case *messages.SyncResponse:
-		code = CodeSyncResponse
-	case *messages.RangeRequest:
-		code = CodeRangeRequest
-	case *messages.BatchRequest:
-		code = CodeBatchRequest
-	case *messages.BlockResponse:
-		code = CodeBlockResponse
-
-	// cluster consensus
-	case *messages.ClusterBlockProposal:
-		code = CodeClusterBlockProposal
-	case *messages.ClusterBlockVote:
-		code = CodeClusterBlockVote
-	case *messages.ClusterBlockResponse:
-		code = CodeClusterBlockResponse
-
-	// collections, guarantees & transactions
-	case *flow.CollectionGuarantee:
-		code = CodeCollectionGuarantee
-	case *flow.TransactionBody:
-		code = CodeTransactionBody
-	case *flow.Transaction:
-		code = CodeTransaction
-
-	// core messages for execution & verification
-	case *flow.ExecutionReceipt:
-		code = CodeExecutionReceipt
-	case *flow.ResultApproval:
-		code = CodeResultApproval
-
-	// execution state synchronization
-	case *messages.ExecutionStateSyncRequest:
-		code = CodeExecutionStateSyncRequest
-	case *messages.ExecutionStateDelta:
-		code = CodeExecutionStateDelta
-
-	// data exchange for execution of blocks
-	case *messages.ChunkDataRequest:
-		code = CodeChunkDataRequest
-	case *messages.ChunkDataResponse:
-		code = CodeChunkDataResponse
-
-	// result approvals
-	case *messages.ApprovalRequest:
-		code = CodeApprovalRequest
-	case *messages.ApprovalResponse:
-		code = CodeApprovalResponse
-
-	// generic entity exchange engines
-	case *messages.EntityRequest:
-		code = CodeEntityRequest
-	case *messages.EntityResponse:
-		code = CodeEntityResponse
-
-	// testing
-	case *message.TestMessage:
-		code = CodeEcho
-
-	default:
-		return nil, errors.Errorf("invalid encode type (%T)", v)
+	code, err := codec.MessageCodeFromInterface(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine message code: %w", err)
 	}
 
 	// encode the payload
@@ -6,55 +6,9 @@ import (
 	"encoding/json"
 )
 
-const (
-
-	// consensus
-	CodeBlockProposal = iota + 1
-	CodeBlockVote
-
-	// protocol state sync
-	CodeSyncRequest
-	CodeSyncResponse
-	CodeRangeRequest
-	CodeBatchRequest
-	CodeBlockResponse
-
-	// cluster consensus
-	CodeClusterBlockProposal
-	CodeClusterBlockVote
-	CodeClusterBlockResponse
-
-	// collections, guarantees & transactions
-	CodeCollectionGuarantee
-	CodeTransaction
-	CodeTransactionBody
-
-	// core messages for execution & verification
-	CodeExecutionReceipt
-	CodeResultApproval
-
-	// execution state synchronization
-	CodeExecutionStateSyncRequest
-	CodeExecutionStateDelta
-
-	// data exchange for execution of blocks
-	CodeChunkDataRequest
-	CodeChunkDataResponse
-
-	// result approvals
-	CodeApprovalRequest
-	CodeApprovalResponse
-
-	// generic entity exchange engines
-	CodeEntityRequest
-	CodeEntityResponse
-
-	// testing
-	CodeEcho
-)
-
 // Envelope is a wrapper to convey type information with JSON encoding without
-// writing custom bytes to the wire.
+// writing custom bytes to the wire. The code is taken from the message code
+// registry shared by all network codecs.
 type Envelope struct {
 	Code uint8
 	Data json.RawMessage
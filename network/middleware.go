@@ -3,6 +3,7 @@
 package network
 
 import (
+	"io"
 	"time"
 
 	"github.com/onflow/flow-go/model/flow"
@@ -49,6 +50,10 @@ type Middleware interface {
 	// effort.
 	Publish(msg *message.Message, channel Channel) error
 
+	// SendTransfer reliably streams the payload of the given size and type to the target ID on the channel, in
+	// frames which the target receives as a Transfer.
+	SendTransfer(channel Channel, targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error
+
 	// Subscribe subscribes the middleware to a channel.
 	Subscribe(channel Channel) error
 
@@ -71,6 +76,8 @@ type Overlay interface {
 	// Identity returns a map of all identifier to flow identity
 	Identity() (map[flow.Identifier]flow.Identity, error)
 	Receive(nodeID flow.Identifier, msg *message.Message) error
+	// ReceiveTransfer hands an inbound transfer to the engine of its channel, which consumes its frames.
+	ReceiveTransfer(nodeID flow.Identifier, transfer Transfer) error
 }
 
 // Connection represents an interface to read from & write to a connection.
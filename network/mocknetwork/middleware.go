@@ -8,6 +8,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	io "io"
+
 	network "github.com/onflow/flow-go/network"
 
 	time "time"
@@ -88,6 +90,20 @@ func (_m *Middleware) SendDirect(msg *message.Message, targetID flow.Identifier)
 	return r0
 }
 
+// SendTransfer provides a mock function with given fields: channel, targetID, payloadType, payload, size
+func (_m *Middleware) SendTransfer(channel network.Channel, targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error {
+	ret := _m.Called(channel, targetID, payloadType, payload, size)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(network.Channel, flow.Identifier, string, io.ReaderAt, uint64) error); ok {
+		r0 = rf(channel, targetID, payloadType, payload, size)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Start provides a mock function with given fields: overlay
 func (_m *Middleware) Start(overlay network.Overlay) error {
 	ret := _m.Called(overlay)
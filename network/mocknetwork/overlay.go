@@ -7,6 +7,8 @@ import (
 	message "github.com/onflow/flow-go/network/message"
 
 	mock "github.com/stretchr/testify/mock"
+
+	network "github.com/onflow/flow-go/network"
 )
 
 // Overlay is an autogenerated mock type for the Overlay type
@@ -51,6 +53,20 @@ func (_m *Overlay) Receive(nodeID flow.Identifier, msg *message.Message) error {
 	return r0
 }
 
+// ReceiveTransfer provides a mock function with given fields: nodeID, transfer
+func (_m *Overlay) ReceiveTransfer(nodeID flow.Identifier, transfer network.Transfer) error {
+	ret := _m.Called(nodeID, transfer)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(flow.Identifier, network.Transfer) error); ok {
+		r0 = rf(nodeID, transfer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Topology provides a mock function with given fields:
 func (_m *Overlay) Topology() (flow.IdentityList, error) {
 	ret := _m.Called()
@@ -0,0 +1,72 @@
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/message"
+)
+
+// CodecVersion identifies the codec used to encode the message payloads on a
+// unicast stream. It is negotiated when the stream is set up, as a suffix of
+// the Flow libp2p protocol ID.
+type CodecVersion string
+
+const (
+	// CodecVersionJSON is the legacy JSON codec. It is negotiated with the bare
+	// Flow protocol ID, so that nodes which predate codec negotiation remain
+	// reachable.
+	CodecVersionJSON CodecVersion = ""
+
+	// CodecVersionCBOR is the binary CBOR codec.
+	CodecVersionCBOR CodecVersion = "cbor/1"
+)
+
+// VersionedCodec is a codec together with the version it is negotiated under.
+type VersionedCodec struct {
+	Version CodecVersion
+	Codec   network.Codec
+}
+
+// codecProtocolID returns the libp2p protocol ID under which the given codec
+// version is negotiated.
+func codecProtocolID(pid protocol.ID, version CodecVersion) protocol.ID {
+	if version == CodecVersionJSON {
+		return pid
+	}
+	return protocol.ID(fmt.Sprintf("%s/%s", pid, version))
+}
+
+// codecTopic returns the pubsub topic on which payloads encoded with the given
+// codec version are published. Like the protocol ID, the topic of the legacy
+// JSON codec is left unversioned, so that nodes which predate codec
+// negotiation receive them.
+func codecTopic(topic network.Topic, version CodecVersion) network.Topic {
+	if version == CodecVersionJSON {
+		return topic
+	}
+	return network.Topic(fmt.Sprintf("%s/%s", topic, version))
+}
+
+// transcode converts the payload of the given message from one codec to
+// another. The message itself is left untouched, so that the event ID, which
+// is computed over the original payload, still deduplicates the event across
+// differently encoded copies.
+func transcode(msg *message.Message, from VersionedCodec, to VersionedCodec) (*message.Message, error) {
+	if from.Version == to.Version {
+		return msg, nil
+	}
+
+	event, err := from.Codec.Decode(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode payload (version: %q): %w", from.Version, err)
+	}
+	payload, err := to.Codec.Encode(event)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode payload (version: %q): %w", to.Version, err)
+	}
+
+	return withPayload(msg, payload), nil
+}
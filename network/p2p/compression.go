@@ -0,0 +1,135 @@
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/onflow/flow-go/network/message"
+)
+
+// Compression identifies the algorithm used to compress message payloads. On
+// unicast streams, it is negotiated as a suffix of the protocol ID of the
+// codec, so that peers which don't support it keep receiving uncompressed
+// payloads.
+type Compression string
+
+const (
+	// CompressionNone disables compression.
+	CompressionNone Compression = ""
+
+	// CompressionSnappy compresses payloads with snappy, which trades a lower
+	// compression ratio for a high throughput.
+	CompressionSnappy Compression = "snappy"
+)
+
+// DefaultCompressionThreshold is the payload size in bytes from which payloads
+// are compressed, as smaller payloads hardly shrink.
+const DefaultCompressionThreshold = 4 << 10 // 4 kb
+
+const (
+	// compressedPayloadMarker is the first byte of compressed payloads, followed
+	// by the identifier of the algorithm. It is the CBOR break code, which just
+	// like in JSON is invalid as the first byte of an encoded payload, so that
+	// compressed payloads can be told apart from the payloads of any codec.
+	compressedPayloadMarker = 0xff
+
+	// snappyAlgorithmID identifies snappy compressed payloads.
+	snappyAlgorithmID = 0x01
+)
+
+// CompressionConfig configures the compression of outbound messages.
+// Compressed inbound messages are decompressed in any case, so that
+// compression can be enabled on some nodes at a time.
+type CompressionConfig struct {
+	Algorithm Compression // algorithm offered on unicast streams, none disables compression
+	Threshold int         // size in bytes from which payloads are compressed
+	PubSub    bool        // whether pubsub payloads are compressed as well
+}
+
+// DefaultCompressionConfig returns the default compression config, which
+// compresses unicast messages with snappy. As pubsub messages can't be
+// negotiated, their compression must only be enabled once all nodes of the
+// network are able to decompress them.
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Algorithm: CompressionSnappy,
+		Threshold: DefaultCompressionThreshold,
+	}
+}
+
+// compressionProtocolID returns the libp2p protocol ID under which the given
+// compression is negotiated for the codec with the given protocol ID.
+func compressionProtocolID(pid protocol.ID, algorithm Compression) protocol.ID {
+	return protocol.ID(fmt.Sprintf("%s/%s", pid, algorithm))
+}
+
+// compress compresses the payload of the given message with the given
+// algorithm, if the payload reaches the threshold and actually shrinks. It
+// returns false if the message is left uncompressed. Like with transcoding,
+// the message itself is left untouched.
+func compress(msg *message.Message, algorithm Compression, threshold int) (*message.Message, bool, error) {
+	if algorithm == CompressionNone || len(msg.Payload) < threshold {
+		return msg, false, nil
+	}
+
+	var payload []byte
+	switch algorithm {
+	case CompressionSnappy:
+		encoded := snappy.Encode(nil, msg.Payload)
+		payload = make([]byte, 0, len(encoded)+2)
+		payload = append(payload, compressedPayloadMarker, snappyAlgorithmID)
+		payload = append(payload, encoded...)
+	default:
+		return nil, false, fmt.Errorf("unknown compression algorithm: %q", algorithm)
+	}
+
+	if len(payload) >= len(msg.Payload) {
+		return msg, false, nil
+	}
+
+	return withPayload(msg, payload), true, nil
+}
+
+// decompress decompresses the payload of the given message, if it is
+// compressed. It returns false if the payload is not compressed, and an error
+// if the decompressed payload would exceed the given size.
+func decompress(msg *message.Message, maxSize int) (*message.Message, bool, error) {
+	if len(msg.Payload) < 2 || msg.Payload[0] != compressedPayloadMarker {
+		return msg, false, nil
+	}
+
+	var payload []byte
+	switch msg.Payload[1] {
+	case snappyAlgorithmID:
+		encoded := msg.Payload[2:]
+		size, err := snappy.DecodedLen(encoded)
+		if err != nil {
+			return nil, false, fmt.Errorf("could not read decompressed size: %w", err)
+		}
+		if size > maxSize {
+			return nil, false, fmt.Errorf("decompressed size %d exceeds max message size %d", size, maxSize)
+		}
+		payload, err = snappy.Decode(nil, encoded)
+		if err != nil {
+			return nil, false, fmt.Errorf("could not decompress payload: %w", err)
+		}
+	default:
+		return nil, false, fmt.Errorf("unknown compression algorithm identifier: %d", msg.Payload[1])
+	}
+
+	return withPayload(msg, payload), true, nil
+}
+
+// withPayload returns a copy of the given message with the given payload.
+func withPayload(msg *message.Message, payload []byte) *message.Message {
+	return &message.Message{
+		ChannelID: msg.ChannelID,
+		EventID:   msg.EventID,
+		OriginID:  msg.OriginID,
+		TargetIDs: msg.TargetIDs,
+		Payload:   payload,
+		Type:      msg.Type,
+	}
+}
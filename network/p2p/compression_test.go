@@ -0,0 +1,92 @@
+package p2p
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libp2pmessage "github.com/onflow/flow-go/model/libp2p/message"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/codec/cbor"
+	"github.com/onflow/flow-go/network/codec/json"
+	"github.com/onflow/flow-go/network/message"
+)
+
+// TestCompression tests that payloads reaching the threshold are compressed,
+// and decompressed to the original payload.
+func TestCompression(t *testing.T) {
+	msg := &message.Message{
+		ChannelID: "test",
+		EventID:   []byte("event"),
+		Payload:   bytes.Repeat([]byte("flow"), 1024),
+		Type:      "TestMessage",
+	}
+
+	compressed, ok, err := compress(msg, CompressionSnappy, DefaultCompressionThreshold)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Less(t, len(compressed.Payload), len(msg.Payload))
+	assert.Equal(t, msg.EventID, compressed.EventID)
+	assert.Equal(t, msg.Type, compressed.Type)
+
+	decompressed, ok, err := decompress(compressed, len(msg.Payload))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, msg, decompressed)
+
+	t.Run("below threshold", func(t *testing.T) {
+		uncompressed, ok, err := compress(msg, CompressionSnappy, len(msg.Payload)+1)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, msg, uncompressed)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		uncompressed, ok, err := compress(msg, CompressionNone, 0)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, msg, uncompressed)
+	})
+
+	t.Run("exceeding max size", func(t *testing.T) {
+		_, _, err := decompress(compressed, len(msg.Payload)-1)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, _, err := decompress(withPayload(msg, []byte{compressedPayloadMarker, 0x7f, 0x00}), len(msg.Payload))
+		assert.Error(t, err)
+	})
+}
+
+// TestCompression_Incompressible tests that payloads which don't shrink are
+// left uncompressed.
+func TestCompression_Incompressible(t *testing.T) {
+	payload := make([]byte, 2*DefaultCompressionThreshold)
+	_, err := rand.New(rand.NewSource(1)).Read(payload)
+	require.NoError(t, err)
+	msg := &message.Message{Payload: payload}
+
+	uncompressed, ok, err := compress(msg, CompressionSnappy, 0)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, msg, uncompressed)
+}
+
+// TestDecompression_CodecPayloads tests that the payloads of the codecs are
+// never mistaken for compressed payloads.
+func TestDecompression_CodecPayloads(t *testing.T) {
+	for _, codec := range []network.Codec{cbor.NewCodec(), json.NewCodec()} {
+		payload, err := codec.Encode(&libp2pmessage.TestMessage{Text: "hello"})
+		require.NoError(t, err)
+		msg := &message.Message{Payload: payload}
+
+		decompressed, ok, err := decompress(msg, len(payload))
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, msg, decompressed)
+	}
+}
@@ -3,6 +3,7 @@ package p2p
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/network"
@@ -20,6 +21,14 @@ type UnicastFunc func(channel network.Channel, event interface{}, targetID flow.
 // network to randomly chosen subset of nodes from targetIDs
 type MulticastFunc func(channel network.Channel, event interface{}, num uint, targetIDs ...flow.Identifier) error
 
+// TransferFunc is a function that reliably streams the payload of the given size and type to the target ID in the
+// underlying network, which receives it as a transfer.
+type TransferFunc func(channel network.Channel, targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error
+
+// TransferEventFunc is a function that reliably streams the event to the target ID in the underlying network, which
+// receives it as a transfer.
+type TransferEventFunc func(channel network.Channel, event interface{}, targetID flow.Identifier) error
+
 // CloseFunc is a function that unsubscribes the conduit from the channel
 type CloseFunc func(channel network.Channel) error
 
@@ -27,15 +36,19 @@ type CloseFunc func(channel network.Channel) error
 // sending messages within a single engine process. It sends all messages to
 // what can be considered a bus reserved for that specific engine.
 type Conduit struct {
-	ctx       context.Context
-	cancel    context.CancelFunc
-	channel   network.Channel
-	publish   PublishFunc
-	unicast   UnicastFunc
-	multicast MulticastFunc
-	close     CloseFunc
+	ctx           context.Context
+	cancel        context.CancelFunc
+	channel       network.Channel
+	publish       PublishFunc
+	unicast       UnicastFunc
+	multicast     MulticastFunc
+	transfer      TransferFunc
+	transferEvent TransferEventFunc
+	close         CloseFunc
 }
 
+var _ network.TransferConduit = (*Conduit)(nil)
+
 // Publish sends an event to the network layer for unreliable delivery
 // to subscribers of the given event on the network layer. It uses a
 // publish-subscribe layer and can thus not guarantee that the specified
@@ -66,6 +79,24 @@ func (c *Conduit) Multicast(event interface{}, num uint, targetIDs ...flow.Ident
 	return c.multicast(c.channel, event, num, targetIDs...)
 }
 
+// Transfer reliably streams the payload of the given size and type to the given recipient, which receives it as a
+// transfer. The payload is sent in frames, so that it can be consumed incrementally by the recipient.
+func (c *Conduit) Transfer(targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error {
+	if c.ctx.Err() != nil {
+		return fmt.Errorf("conduit for channel %s closed", c.channel)
+	}
+	return c.transfer(c.channel, targetID, payloadType, payload, size)
+}
+
+// TransferEvent reliably streams the event to the given recipient, which receives it as a transfer and decodes it
+// as its frames arrive. The event is used for large payloads, which are not held in memory while they are streamed.
+func (c *Conduit) TransferEvent(event interface{}, targetID flow.Identifier) error {
+	if c.ctx.Err() != nil {
+		return fmt.Errorf("conduit for channel %s closed", c.channel)
+	}
+	return c.transferEvent(c.channel, event, targetID)
+}
+
 func (c *Conduit) Close() error {
 	if c.ctx.Err() != nil {
 		return fmt.Errorf("conduit for channel %s already closed", c.channel)
@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p-core/connmgr"
 	"github.com/libp2p/go-libp2p-core/control"
@@ -15,15 +16,18 @@ var _ connmgr.ConnectionGater = (*connGater)(nil)
 
 // connGater is the implementation of the libp2p connmgr.ConnectionGater interface
 // It provides node allowlisting by libp2p peer.ID which is derived from the node public networking key
+// Allowed peers can additionally be blocked for a while, e.g. for misbehaving.
 type connGater struct {
 	sync.RWMutex
-	peerIDAllowlist map[peer.ID]struct{} // the in-memory map of approved peer IDs
+	peerIDAllowlist map[peer.ID]struct{}  // the in-memory map of approved peer IDs
+	peerIDBlocklist map[peer.ID]time.Time // the in-memory map of blocked peer IDs, by the time they are unblocked
 	log             zerolog.Logger
 }
 
 func newConnGater(log zerolog.Logger) *connGater {
 	cg := &connGater{
-		log: log,
+		log:             log,
+		peerIDBlocklist: make(map[peer.ID]time.Time),
 	}
 	return cg
 }
@@ -47,6 +51,18 @@ func (c *connGater) update(peerInfos []peer.AddrInfo) {
 	c.log.Info().Msg("approved list of peers updated")
 }
 
+// block refuses connections with the given peer until the given time
+func (c *connGater) block(p peer.ID, until time.Time) {
+	c.Lock()
+	c.peerIDBlocklist[p] = until
+	c.Unlock()
+
+	c.log.Info().
+		Str("peer_id", p.Pretty()).
+		Time("until", until).
+		Msg("blocked peer")
+}
+
 // InterceptPeerDial - a callback which allows or disallows outbound connection
 func (c *connGater) InterceptPeerDial(p peer.ID) bool {
 	return c.validPeerID(p)
@@ -92,5 +108,9 @@ func (c *connGater) validPeerID(p peer.ID) bool {
 	c.RLock()
 	defer c.RUnlock()
 	_, ok := c.peerIDAllowlist[p]
-	return ok
+	if !ok {
+		return false
+	}
+	until, blocked := c.peerIDBlocklist[p]
+	return !blocked || time.Now().After(until)
 }
@@ -0,0 +1,199 @@
+package p2p
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// diagnosticsPingConcurrency is the maximum number of peers pinged concurrently
+// when collecting diagnostics.
+const diagnosticsPingConcurrency = 16
+
+// NetworkDiagnostics is a snapshot of the networking layer of a node, which is
+// served to operators to debug connectivity issues.
+type NetworkDiagnostics struct {
+	NodeID   flow.Identifier     `json:"node_id"`
+	PeerID   string              `json:"peer_id"`
+	Peers    []PeerDiagnostics   `json:"peers"`    // connected libp2p peers
+	Fanout   flow.IdentifierList `json:"fanout"`   // nodes the topology connects us to
+	Channels []string            `json:"channels"` // channels with a registered engine
+	Topics   []string            `json:"topics"`   // subscribed pubsub topics
+	Mesh     map[string][]string `json:"mesh"`     // peer IDs in the GossipSub mesh of each topic
+	Traffic  []ChannelTraffic    `json:"traffic"`  // message rates of the channels in the last window
+}
+
+// PeerDiagnostics describes a connected libp2p peer.
+type PeerDiagnostics struct {
+	PeerID    string         `json:"peer_id"`
+	Addresses []string       `json:"addresses"`
+	Identity  *flow.Identity `json:"identity,omitempty"`   // nil if the peer is not a known node
+	RTT       string         `json:"rtt,omitempty"`        // round trip time, if the peer was pinged successfully
+	PingError string         `json:"ping_error,omitempty"` // reason the ping failed, if the peer was pinged
+}
+
+// Diagnostics returns the connected peers with their Flow identities, the
+// subscribed topics, the GossipSub mesh of each topic and the recent message
+// rates of the channels. If ping is set, the round trip times to the connected
+// nodes are measured as well.
+func (m *Middleware) Diagnostics(ping bool) *NetworkDiagnostics {
+	host := m.libP2PNode.Host()
+
+	d := &NetworkDiagnostics{
+		NodeID:  m.me,
+		PeerID:  host.ID().String(),
+		Peers:   make([]PeerDiagnostics, 0),
+		Topics:  make([]string, 0),
+		Mesh:    make(map[string][]string),
+		Traffic: m.traffic.rates(),
+	}
+
+	for _, topic := range m.libP2PNode.Topics() {
+		d.Topics = append(d.Topics, topic.String())
+	}
+	sort.Strings(d.Topics)
+
+	for topic, peerIDs := range m.libP2PNode.MeshPeers() {
+		peers := make([]string, 0, len(peerIDs))
+		for _, peerID := range peerIDs {
+			peers = append(peers, peerID.String())
+		}
+		d.Mesh[topic] = peers
+	}
+
+	peerIDs := host.Network().Peers()
+	sort.Slice(peerIDs, func(i, j int) bool {
+		return peerIDs[i] < peerIDs[j]
+	})
+	for _, peerID := range peerIDs {
+		p := PeerDiagnostics{
+			PeerID:    peerID.String(),
+			Addresses: make([]string, 0),
+		}
+		for _, conn := range host.Network().ConnsToPeer(peerID) {
+			p.Addresses = append(p.Addresses, conn.RemoteMultiaddr().String())
+		}
+		if identity, ok := m.peerIdentity(peerID); ok {
+			p.Identity = identity
+		}
+		d.Peers = append(d.Peers, p)
+	}
+
+	if ping {
+		m.pingPeers(d.Peers)
+	}
+
+	return d
+}
+
+// peerIdentity returns the identity of the node with the given libp2p peer ID.
+func (m *Middleware) peerIdentity(peerID peer.ID) (*flow.Identity, bool) {
+	nodeID, ok := m.origins.nodeID(peerID)
+	if !ok {
+		return nil, false
+	}
+	return m.origins.identity(nodeID)
+}
+
+// pingPeers measures the round trip time to each of the given peers which is a
+// known node, pinging a limited number of them concurrently.
+func (m *Middleware) pingPeers(peers []PeerDiagnostics) {
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, diagnosticsPingConcurrency)
+	for i := range peers {
+		if peers[i].Identity == nil {
+			continue
+		}
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(p *PeerDiagnostics) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			rtt, err := m.Ping(p.Identity.NodeID)
+			if err != nil {
+				p.PingError = err.Error()
+				return
+			}
+			p.RTT = rtt.String()
+		}(&peers[i])
+	}
+	wg.Wait()
+}
+
+// Diagnostics complements the diagnostics of the given middleware, which must
+// be the one of the network, with the current fanout of the topology and the
+// channels with a registered engine.
+func (n *Network) Diagnostics(mw *Middleware, ping bool) (*NetworkDiagnostics, error) {
+	d := mw.Diagnostics(ping)
+
+	fanout, err := n.Topology()
+	if err != nil {
+		return nil, err
+	}
+	d.Fanout = fanout.NodeIDs()
+
+	d.Channels = make([]string, 0)
+	for _, channel := range n.subMngr.Channels() {
+		d.Channels = append(d.Channels, channel.String())
+	}
+	sort.Strings(d.Channels)
+
+	return d, nil
+}
+
+// DiagnosticsHandler serves the diagnostics of the network as JSON. Peers are
+// pinged unless the request sets the `ping` query parameter to false.
+type DiagnosticsHandler struct {
+	log zerolog.Logger
+	net *Network
+	mw  *Middleware
+}
+
+// NewDiagnosticsHandler returns a handler serving the diagnostics of the given
+// network and its middleware.
+func NewDiagnosticsHandler(log zerolog.Logger, net *Network, mw *Middleware) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		log: log.With().Str("component", "network_diagnostics").Logger(),
+		net: net,
+		mw:  mw,
+	}
+}
+
+func (h *DiagnosticsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ping := true
+	if value := r.URL.Query().Get("ping"); value != "" {
+		var err error
+		ping, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "invalid ping parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	d, err := h.net.Diagnostics(h.mw, ping)
+	if err != nil {
+		h.log.Error().Err(err).Msg("could not collect network diagnostics")
+		http.Error(w, "could not collect network diagnostics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(d)
+	if err != nil {
+		h.log.Error().Err(err).Msg("could not write network diagnostics")
+	}
+}
@@ -55,16 +55,23 @@ func DefaultLibP2PNodeFactory(log zerolog.Logger, me flow.Identifier, address st
 	maxPubSubMsgSize int, metrics module.NetworkMetrics) (LibP2PFactoryFunc, error) {
 	// create PubSub options for libp2p to use
 	psOptions := []pubsub.Option{
-		// skip message signing
-		pubsub.WithMessageSigning(false),
-		// skip message signature
-		pubsub.WithStrictSignatureVerification(false),
+		// sign all messages with the networking key of the node, and drop
+		// messages which are not signed by their author
+		pubsub.WithMessageSignaturePolicy(pubsub.StrictSign),
 		// set max message size limit for 1-k PubSub messaging
 		pubsub.WithMaxMessageSize(maxPubSubMsgSize),
 	}
 
 	return func() (*Node, error) {
-		return NewLibP2PNode(log, me, address, NewConnManager(log, metrics), flowKey, true, rootBlockID, psOptions...)
+		// score peers, so that misbehaving peers are disconnected
+		scorer := NewPeerScorer(log, rootBlockID)
+		options := append(append([]pubsub.Option{}, psOptions...), scorer.PubSubOptions()...)
+
+		node, err := NewLibP2PNode(log, me, address, NewConnManager(log, metrics), flowKey, true, rootBlockID, options...)
+		if err != nil {
+			return nil, err
+		}
+		return node.WithPeerScorer(scorer), nil
 	}, nil
 }
 
@@ -80,6 +87,8 @@ type Node struct {
 	subs                 map[flownet.Topic]*pubsub.Subscription // map of a topic string to an actual subscription
 	id                   flow.Identifier                        // used to represent id of flow node running this instance of libP2P node
 	flowLibP2PProtocolID protocol.ID                            // the unique protocol ID
+	scorer               *PeerScorer                            // used to score peers, nil if peer scoring is disabled
+	mesh                 *meshTracer                            // used to keep track of the GossipSub mesh of each topic
 }
 
 func NewLibP2PNode(logger zerolog.Logger,
@@ -100,6 +109,10 @@ func NewLibP2PNode(logger zerolog.Logger,
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// keep track of the mesh peers for diagnostics
+	mesh := newMeshTracer()
+	psOption = append(append([]pubsub.Option{}, psOption...), pubsub.WithEventTracer(mesh))
+
 	libP2PHost, connGater, pubSub, err := bootstrapLibP2PHost(ctx,
 		logger,
 		address,
@@ -123,6 +136,7 @@ func NewLibP2PNode(logger zerolog.Logger,
 		subs:                 make(map[flownet.Topic]*pubsub.Subscription),
 		id:                   id,
 		flowLibP2PProtocolID: flowLibP2PProtocolID,
+		mesh:                 mesh,
 	}
 
 	ip, port, err := n.GetIPPort()
@@ -138,6 +152,14 @@ func NewLibP2PNode(logger zerolog.Logger,
 	return n, nil
 }
 
+// WithPeerScorer attaches the given peer scorer to the node, so that it can disconnect misbehaving peers. The pubsub
+// options of the scorer must have been passed to the node when it was created.
+func (n *Node) WithPeerScorer(scorer *PeerScorer) *Node {
+	scorer.bind(n.host, n.connGater)
+	n.scorer = scorer
+	return n
+}
+
 // Stop stops the libp2p node.
 func (n *Node) Stop() (chan struct{}, error) {
 	var result error
@@ -229,9 +251,14 @@ func (n *Node) RemovePeer(ctx context.Context, identity flow.Identity) error {
 }
 
 // CreateStream returns an existing stream connected to identity, if it exists or adds one to identity as a peer and creates a new stream with it.
-func (n *Node) CreateStream(ctx context.Context, identity flow.Identity) (libp2pnet.Stream, error) {
+// If protocols are given, the stream is negotiated for the first of them supported by the remote peer, in order of
+// preference; otherwise, the Flow protocol ID of the node is used.
+func (n *Node) CreateStream(ctx context.Context, identity flow.Identity, protocols ...protocol.ID) (libp2pnet.Stream, error) {
+	if len(protocols) == 0 {
+		protocols = []protocol.ID{n.flowLibP2PProtocolID}
+	}
 	// Open libp2p Stream with the remote peer (will use an existing TCP connection underneath if it exists)
-	stream, err := n.tryCreateNewStream(ctx, identity, maxConnectAttempt)
+	stream, err := n.tryCreateNewStream(ctx, identity, maxConnectAttempt, protocols...)
 	if err != nil {
 		return nil, flownet.NewPeerUnreachableError(fmt.Errorf("could not create stream (node_id: %s, address: %s): %w", identity.NodeID.String(),
 			identity.Address, err))
@@ -242,7 +269,7 @@ func (n *Node) CreateStream(ctx context.Context, identity flow.Identity) (libp2p
 // tryCreateNewStream makes at most maxAttempts to create a stream with the identity.
 // This was put in as a fix for #2416. PubSub and 1-1 communication compete with each other when trying to connect to
 // remote nodes and once in a while NewStream returns an error 'both yamux endpoints are clients'
-func (n *Node) tryCreateNewStream(ctx context.Context, identity flow.Identity, maxAttempts int) (libp2pnet.Stream, error) {
+func (n *Node) tryCreateNewStream(ctx context.Context, identity flow.Identity, maxAttempts int, protocols ...protocol.ID) (libp2pnet.Stream, error) {
 	_, _, key, err := networkingInfo(identity)
 	if err != nil {
 		return nil, fmt.Errorf("could not get translate identity to networking info %s: %w", identity.NodeID.String(), err)
@@ -296,7 +323,7 @@ func (n *Node) tryCreateNewStream(ctx context.Context, identity flow.Identity, m
 			continue
 		}
 
-		s, err = n.host.NewStream(ctx, peerID, n.flowLibP2PProtocolID)
+		s, err = n.host.NewStream(ctx, peerID, protocols...)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 			continue
@@ -317,8 +344,10 @@ func (n *Node) GetIPPort() (string, string, error) {
 
 // Subscribe subscribes the node to the given topic and returns the subscription
 // Currently only one subscriber is allowed per topic.
+// The given validators are registered for the topic when it is joined; messages which are not accepted by all of them
+// are neither delivered nor forwarded to other peers.
 // NOTE: A node will receive its own published messages.
-func (n *Node) Subscribe(ctx context.Context, topic flownet.Topic) (*pubsub.Subscription, error) {
+func (n *Node) Subscribe(ctx context.Context, topic flownet.Topic, validators ...pubsub.ValidatorEx) (*pubsub.Subscription, error) {
 	n.Lock()
 	defer n.Unlock()
 
@@ -327,11 +356,26 @@ func (n *Node) Subscribe(ctx context.Context, topic flownet.Topic) (*pubsub.Subs
 	tp, found := n.topics[topic]
 	var err error
 	if !found {
+		if len(validators) > 0 {
+			err = n.pubSub.RegisterTopicValidator(topic.String(), composeValidators(validators...))
+			if err != nil {
+				return nil, fmt.Errorf("could not register validator for topic (%s): %w", topic, err)
+			}
+		}
 		tp, err = n.pubSub.Join(topic.String())
 		if err != nil {
 			return nil, fmt.Errorf("could not join topic (%s): %w", topic, err)
 		}
 		n.topics[topic] = tp
+
+		if n.scorer != nil {
+			if params, ok := n.scorer.topicScoreParams(topic); ok {
+				err = tp.SetScoreParams(params)
+				if err != nil {
+					return nil, fmt.Errorf("could not set score parameters for topic (%s): %w", topic, err)
+				}
+			}
+		}
 	}
 
 	// Create a new subscription
@@ -367,6 +411,9 @@ func (n *Node) UnSubscribe(topic flownet.Topic) error {
 		return err
 	}
 
+	// remove the validators of the topic (if any), so they can be registered again on the next subscription
+	_ = n.pubSub.UnregisterTopicValidator(topic.String())
+
 	// attempt to close the topic
 	err := tp.Close()
 	if err != nil {
@@ -450,6 +497,36 @@ func (n *Node) UpdateAllowList(identities flow.IdentityList) error {
 	return nil
 }
 
+// Penalize records a misbehaviour of the given peer, for the given reason, which lowers its score. It is a no-op if
+// peer scoring is disabled.
+func (n *Node) Penalize(peerID peer.ID, reason string) {
+	if n.scorer == nil {
+		return
+	}
+	n.scorer.Penalize(peerID, reason)
+}
+
+// PeerScorer returns the peer scorer of the node, or nil if peer scoring is disabled.
+func (n *Node) PeerScorer() *PeerScorer {
+	return n.scorer
+}
+
+// Topics returns the topics the node is subscribed to.
+func (n *Node) Topics() []flownet.Topic {
+	n.Lock()
+	defer n.Unlock()
+	topics := make([]flownet.Topic, 0, len(n.topics))
+	for topic := range n.topics {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// MeshPeers returns the peers in the GossipSub mesh of each topic.
+func (n *Node) MeshPeers() map[string][]peer.ID {
+	return n.mesh.meshPeers()
+}
+
 // Host returns pointer to host object of node.
 func (n *Node) Host() host.Host {
 	return n.host
@@ -457,7 +534,17 @@ func (n *Node) Host() host.Host {
 
 // SetStreamHandler sets the stream handler of libp2p host of the node.
 func (n *Node) SetStreamHandler(handler libp2pnet.StreamHandler) {
-	n.host.SetStreamHandler(n.flowLibP2PProtocolID, handler)
+	n.SetStreamHandlerForProtocol(n.flowLibP2PProtocolID, handler)
+}
+
+// SetStreamHandlerForProtocol sets the stream handler of libp2p host of the node for the given protocol ID.
+func (n *Node) SetStreamHandlerForProtocol(pid protocol.ID, handler libp2pnet.StreamHandler) {
+	n.host.SetStreamHandler(pid, handler)
+}
+
+// ProtocolID returns the Flow protocol ID of the node, which is unique for the root block.
+func (n *Node) ProtocolID() protocol.ID {
+	return n.flowLibP2PProtocolID
 }
 
 // IsConnected returns true is address is a direct peer of this node else false
@@ -3,6 +3,7 @@ package p2p
 // All utilities for libp2p not natively provided by the library.
 
 import (
+	"context"
 	"fmt"
 	"net"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/protocol"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/multiformats/go-multiaddr"
 
 	"github.com/onflow/flow-go/model/flow"
@@ -159,6 +161,20 @@ func IPPortFromMultiAddress(addrs ...multiaddr.Multiaddr) (string, string, error
 	return "", "", fmt.Errorf("ip address or hostname not found")
 }
 
+// composeValidators combines the given pubsub validators into a single one, which returns the result of the first
+// validator that does not accept the message.
+func composeValidators(validators ...pubsub.ValidatorEx) pubsub.ValidatorEx {
+	return func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		for _, validator := range validators {
+			result := validator(ctx, from, msg)
+			if result != pubsub.ValidationAccept {
+				return result
+			}
+		}
+		return pubsub.ValidationAccept
+	}
+}
+
 func generateProtocolID(rootBlockID string) protocol.ID {
 	return protocol.ID(FlowLibP2PProtocolIDPrefix + rootBlockID)
 }
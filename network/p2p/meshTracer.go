@@ -0,0 +1,93 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+)
+
+// meshTracer is a pubsub event tracer, which keeps track of the peers in the
+// GossipSub mesh of each topic, as the router does not expose its mesh.
+type meshTracer struct {
+	sync.RWMutex
+	mesh map[string]map[peer.ID]struct{} // peers in the mesh of each topic
+}
+
+var _ pubsub.EventTracer = (*meshTracer)(nil)
+
+func newMeshTracer() *meshTracer {
+	return &meshTracer{
+		mesh: make(map[string]map[peer.ID]struct{}),
+	}
+}
+
+// Trace updates the mesh on grafts and prunes of peers, and when the node
+// leaves a topic or a peer is removed. It is called by the pubsub event loop,
+// so it must not block.
+func (t *meshTracer) Trace(evt *pb.TraceEvent) {
+	switch evt.GetType() {
+	case pb.TraceEvent_GRAFT:
+		graft := evt.GetGraft()
+		peerID, err := peer.IDFromBytes(graft.GetPeerID())
+		if err != nil {
+			return
+		}
+		t.Lock()
+		peers, ok := t.mesh[graft.GetTopic()]
+		if !ok {
+			peers = make(map[peer.ID]struct{})
+			t.mesh[graft.GetTopic()] = peers
+		}
+		peers[peerID] = struct{}{}
+		t.Unlock()
+
+	case pb.TraceEvent_PRUNE:
+		prune := evt.GetPrune()
+		peerID, err := peer.IDFromBytes(prune.GetPeerID())
+		if err != nil {
+			return
+		}
+		t.Lock()
+		delete(t.mesh[prune.GetTopic()], peerID)
+		t.Unlock()
+
+	case pb.TraceEvent_LEAVE:
+		t.Lock()
+		delete(t.mesh, evt.GetLeave().GetTopic())
+		t.Unlock()
+
+	case pb.TraceEvent_REMOVE_PEER:
+		// the router drops removed peers from its mesh without pruning them
+		peerID, err := peer.IDFromBytes(evt.GetRemovePeer().GetPeerID())
+		if err != nil {
+			return
+		}
+		t.Lock()
+		for _, peers := range t.mesh {
+			delete(peers, peerID)
+		}
+		t.Unlock()
+	}
+}
+
+// meshPeers returns the peers in the mesh of each topic, in canonical order.
+func (t *meshTracer) meshPeers() map[string][]peer.ID {
+	t.RLock()
+	defer t.RUnlock()
+
+	mesh := make(map[string][]peer.ID, len(t.mesh))
+	for topic, peers := range t.mesh {
+		ids := make([]peer.ID, 0, len(peers))
+		for peerID := range peers {
+			ids = append(ids, peerID)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+		mesh[topic] = ids
+	}
+	return mesh
+}
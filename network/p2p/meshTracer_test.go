@@ -0,0 +1,59 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func meshEvent(typ pb.TraceEvent_Type, peerID peer.ID, topic string) *pb.TraceEvent {
+	peerBytes := []byte(peerID)
+	evt := &pb.TraceEvent{Type: &typ}
+	switch typ {
+	case pb.TraceEvent_GRAFT:
+		evt.Graft = &pb.TraceEvent_Graft{PeerID: peerBytes, Topic: &topic}
+	case pb.TraceEvent_PRUNE:
+		evt.Prune = &pb.TraceEvent_Prune{PeerID: peerBytes, Topic: &topic}
+	case pb.TraceEvent_LEAVE:
+		evt.Leave = &pb.TraceEvent_Leave{Topic: &topic}
+	case pb.TraceEvent_REMOVE_PEER:
+		evt.RemovePeer = &pb.TraceEvent_RemovePeer{PeerID: peerBytes}
+	}
+	return evt
+}
+
+// TestMeshTracer tests that the mesh of each topic follows the grafts and
+// prunes of peers, and is cleared when leaving a topic or removing a peer.
+func TestMeshTracer(t *testing.T) {
+	first, err := peer.Decode("QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN")
+	require.NoError(t, err)
+	second, err := peer.Decode("QmcgpsyWgH8Y8ajJz1Cu72KnS5uo2Aa2LpzU7kinSupNKC")
+	require.NoError(t, err)
+
+	tracer := newMeshTracer()
+	tracer.Trace(meshEvent(pb.TraceEvent_GRAFT, first, "blocks"))
+	tracer.Trace(meshEvent(pb.TraceEvent_GRAFT, second, "blocks"))
+	tracer.Trace(meshEvent(pb.TraceEvent_GRAFT, second, "receipts"))
+	tracer.Trace(meshEvent(pb.TraceEvent_GRAFT, first, "votes"))
+	assert.Equal(t, map[string][]peer.ID{
+		"blocks":   {first, second},
+		"receipts": {second},
+		"votes":    {first},
+	}, tracer.meshPeers())
+
+	tracer.Trace(meshEvent(pb.TraceEvent_PRUNE, first, "blocks"))
+	tracer.Trace(meshEvent(pb.TraceEvent_LEAVE, "", "votes"))
+	assert.Equal(t, map[string][]peer.ID{
+		"blocks":   {second},
+		"receipts": {second},
+	}, tracer.meshPeers())
+
+	tracer.Trace(meshEvent(pb.TraceEvent_REMOVE_PEER, second, ""))
+	assert.Equal(t, map[string][]peer.ID{
+		"blocks":   {},
+		"receipts": {},
+	}, tracer.meshPeers())
+}
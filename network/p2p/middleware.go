@@ -4,13 +4,21 @@ package p2p
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	ggio "github.com/gogo/protobuf/io"
+	"github.com/hashicorp/go-multierror"
 	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/engine"
@@ -72,6 +80,15 @@ type Middleware struct {
 	rootBlockID       string
 	validators        []network.MessageValidator
 	peerManager       *PeerManager
+	codecs            []VersionedCodec // negotiated codecs in order of preference, the first is the local codec
+	pubSubVersion     CodecVersion     // version of the codec pubsub messages are published with
+	origins           *originIndex     // used to authenticate the origin of inbound messages
+	penalties         map[flow.Identifier]uint
+	unicastLimiter    *rateLimiter // limits inbound unicast messages, nil if not rate limited
+	pubSubLimiter     *rateLimiter // limits inbound pubsub messages, nil if not rate limited
+	transfers         *inboundTransfers
+	traffic           *trafficMeter // used to measure the message rates of the channels for diagnostics
+	compression       CompressionConfig
 }
 
 // NewMiddleware creates a new middleware instance with the given config and using the
@@ -83,15 +100,10 @@ func NewMiddleware(log zerolog.Logger,
 	rootBlockID string,
 	validators ...network.MessageValidator) *Middleware {
 
-	if len(validators) == 0 {
-		// add default validators to filter out unwanted messages received by this node
-		validators = defaultValidators(log, flowID)
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// create the node entity and inject dependencies & config
-	return &Middleware{
+	m := &Middleware{
 		ctx:               ctx,
 		cancel:            cancel,
 		log:               log,
@@ -101,13 +113,113 @@ func NewMiddleware(log zerolog.Logger,
 		metrics:           metrics,
 		rootBlockID:       rootBlockID,
 		validators:        validators,
+		penalties:         make(map[flow.Identifier]uint),
+		transfers:         newInboundTransfers(DefaultTransferResumeTimeout, maxInboundTransfersPerOrigin),
+		traffic:           newTrafficMeter(DefaultTrafficWindow),
+	}
+
+	if len(validators) == 0 {
+		// add default validators to filter out unwanted messages received by this node
+		m.validators = m.defaultValidators()
+	}
+
+	return m
+}
+
+// WithCodecs configures the codecs the middleware negotiates on unicast streams,
+// in order of preference. The first codec must be the codec used by the
+// overlay to encode and decode message payloads; messages exchanged with
+// peers which negotiate another codec are transcoded accordingly. As pubsub
+// messages can't be negotiated, the middleware subscribes to the topics of all
+// codecs, each versioned by its codec, and publishes on the topic of the
+// legacy JSON codec unless configured otherwise with WithPubSubCodec.
+//
+// Without codecs, the middleware only speaks the legacy protocol and leaves
+// payloads untouched. It must be called before the middleware is started.
+func (m *Middleware) WithCodecs(codecs ...VersionedCodec) *Middleware {
+	m.codecs = codecs
+	return m
+}
+
+// WithPubSubCodec configures the codec pubsub messages are published with, on
+// the topic versioned by it. It must be one of the configured codecs. As only
+// the nodes supporting the codec receive the messages, it should only be set
+// once all nodes of the network do. It must be called before the middleware
+// is started.
+func (m *Middleware) WithPubSubCodec(version CodecVersion) *Middleware {
+	m.pubSubVersion = version
+	return m
+}
+
+// WithCompression configures the compression of outbound message payloads. On
+// unicast streams, the compression is negotiated for each of the codecs, so it
+// requires codecs to be configured. It must be called before the middleware is
+// started.
+func (m *Middleware) WithCompression(config CompressionConfig) *Middleware {
+	m.compression = config
+	return m
+}
+
+// WithRateLimits configures the rate limits on inbound messages, which are enforced before the messages are handed
+// to the overlay. Senders blocked for exceeding their limit are penalized.
+func (m *Middleware) WithRateLimits(config RateLimiterConfig) *Middleware {
+	onBlock := func(originID flow.Identifier) {
+		m.Penalize(originID, "rate_limit_exceeded")
 	}
+	m.unicastLimiter = newRateLimiter(m.log, m.metrics, RateLimitModeUnicast, config.Unicast, config.BlockDuration, onBlock)
+	m.pubSubLimiter = newRateLimiter(m.log, m.metrics, RateLimitModePubSub, config.PubSub, config.BlockDuration, onBlock)
+	return m
 }
 
-func defaultValidators(log zerolog.Logger, flowID flow.Identifier) []network.MessageValidator {
+func (m *Middleware) defaultValidators() []network.MessageValidator {
 	return []network.MessageValidator{
-		validator.NewSenderValidator(flowID),      // validator to filter out messages sent by this node itself
-		validator.NewTargetValidator(log, flowID), // validator to filter out messages not intended for this node
+		validator.NewSenderValidator(m.me),        // validator to filter out messages sent by this node itself
+		validator.NewTargetValidator(m.log, m.me), // validator to filter out messages not intended for this node
+		// validator to filter out messages which the role of their origin is not authorized to send
+		validator.NewAuthorizedSenderValidator(m.log, m.originIdentity, m.metrics),
+	}
+}
+
+// originIdentity returns the identity of the origin with the given node ID.
+func (m *Middleware) originIdentity(nodeID flow.Identifier) (*flow.Identity, bool) {
+	return m.origins.identity(nodeID)
+}
+
+// Penalize records a penalty for the given node, for the given reason. The penalty also lowers the peer score of the
+// node, so that it is eventually disconnected if it keeps misbehaving.
+func (m *Middleware) Penalize(nodeID flow.Identifier, reason string) {
+	m.Lock()
+	m.penalties[nodeID]++
+	penalties := m.penalties[nodeID]
+	m.Unlock()
+
+	if peerID, ok := m.origins.peerID(nodeID); ok {
+		m.libP2PNode.Penalize(peerID, reason)
+	}
+
+	m.log.Warn().
+		Hex("node_id", nodeID[:]).
+		Str("reason", reason).
+		Uint("penalties", penalties).
+		Msg("penalized node")
+}
+
+// Penalties returns the number of penalties recorded for the given node. The penalties of a node are forgotten once
+// it is removed from the identities of the overlay.
+func (m *Middleware) Penalties(nodeID flow.Identifier) uint {
+	m.Lock()
+	defer m.Unlock()
+	return m.penalties[nodeID]
+}
+
+// prunePenalties forgets the penalties of all nodes but the given ones.
+func (m *Middleware) prunePenalties(identities map[flow.Identifier]flow.Identity) {
+	m.Lock()
+	defer m.Unlock()
+	for nodeID := range m.penalties {
+		if _, ok := identities[nodeID]; !ok {
+			delete(m.penalties, nodeID)
+		}
 	}
 }
 
@@ -129,8 +241,21 @@ func (m *Middleware) Start(ov network.Overlay) error {
 	if err != nil {
 		return fmt.Errorf("could not create libp2p node: %w", err)
 	}
+	if len(m.codecs) > 0 || m.pubSubVersion != CodecVersionJSON {
+		if _, ok := m.codec(m.pubSubVersion); !ok {
+			return fmt.Errorf("pubsub codec is not configured (version: %q)", m.pubSubVersion)
+		}
+	}
+
 	m.libP2PNode = libP2PNode
-	m.libP2PNode.SetStreamHandler(m.handleIncomingStream)
+	m.origins = newOriginIndex(libP2PNode.Host().ID(), m.me)
+	if len(m.codecs) == 0 {
+		m.libP2PNode.SetStreamHandler(m.handleIncomingStream)
+	}
+	for _, pid := range m.protocols() {
+		m.libP2PNode.SetStreamHandlerForProtocol(pid, m.handleIncomingStream)
+	}
+	m.libP2PNode.SetStreamHandlerForProtocol(transferProtocolID(m.libP2PNode.ProtocolID()), m.handleIncomingTransfer)
 
 	// get the node identity map from the overlay
 	idsMap, err := m.ov.Identity()
@@ -143,6 +268,11 @@ func (m *Middleware) Start(ov network.Overlay) error {
 		return fmt.Errorf("could not update approved peer list: %w", err)
 	}
 
+	err = m.origins.update(identityList(idsMap))
+	if err != nil {
+		m.log.Warn().Err(err).Msg("could not index some peers for origin authentication")
+	}
+
 	libp2pConnector, err := newLibp2pConnector(m.libP2PNode.Host(), m.log)
 	if err != nil {
 		return fmt.Errorf("failed to create libp2pConnector: %w", err)
@@ -259,12 +389,30 @@ func (m *Middleware) SendDirect(msg *message.Message, targetID flow.Identifier)
 	// create new stream
 	// (streams don't need to be reused and are fairly inexpensive to be created for each send.
 	// A stream creation does NOT incur an RTT as stream negotiation happens as part of the first message
-	// sent out the the receiver
-	stream, err := m.libP2PNode.CreateStream(ctx, targetIdentity)
+	// sent out the the receiver, unless several codecs are offered to the receiver)
+	stream, err := m.libP2PNode.CreateStream(ctx, targetIdentity, m.protocols()...)
 	if err != nil {
 		return fmt.Errorf("failed to create stream for %s :%w", targetID.String(), err)
 	}
 
+	// encode the payload with the codec negotiated for the stream
+	if codec, ok := m.streamCodec(stream); ok {
+		msg, err = transcode(msg, m.codecs[0], codec)
+		if err != nil {
+			_ = stream.Reset()
+			return fmt.Errorf("failed to transcode message for %s: %w", targetID.String(), err)
+		}
+	}
+
+	// compress the payload if compression was negotiated for the stream
+	if algorithm := m.streamCompression(stream); algorithm != CompressionNone {
+		msg, err = m.compressOutbound(msg, algorithm)
+		if err != nil {
+			_ = stream.Reset()
+			return fmt.Errorf("failed to compress message for %s: %w", targetID.String(), err)
+		}
+	}
+
 	// create a gogo protobuf writer
 	bufw := bufio.NewWriter(stream)
 	writer := ggio.NewDelimitedWriter(bufw)
@@ -288,6 +436,7 @@ func (m *Middleware) SendDirect(msg *message.Message, targetID flow.Identifier)
 
 	// OneToOne communication metrics are reported with topic OneToOne
 	m.metrics.NetworkMessageSent(msg.Size(), metrics.ChannelOneToOne, msg.Type)
+	m.traffic.outbound(msg.ChannelID, msg.Size())
 
 	return nil
 }
@@ -334,30 +483,64 @@ func (m *Middleware) handleIncomingStream(s libp2pnetwork.Stream) {
 
 	log.Info().Msg("incoming connection established")
 
+	// decode payloads with the codec negotiated for the stream
+	callback := m.processMessage
+	if codec, ok := m.streamCodec(s); ok {
+		callback = m.transcodeInbound(codec, log)
+	}
+
+	// decompress payloads before decoding them
+	callback = m.decompressInbound(unicastMaxMsgSize, callback, log)
+
+	// drop messages exceeding the rate limits before decompressing them
+	callback = m.rateLimitInbound(m.unicastLimiter, callback)
+
+	// only accept messages originating from the remote peer itself
+	callback = m.authenticateInbound(s.Conn().RemotePeer(), callback, log)
+
 	//create a new readConnection with the context of the middleware
-	conn := newReadConnection(m.ctx, s, m.processMessage, log, m.metrics, LargeMsgMaxUnicastMsgSize)
+	penalize := func(reason string) {
+		m.libP2PNode.Penalize(s.Conn().RemotePeer(), reason)
+	}
+	conn := newReadConnection(m.ctx, s, callback, penalize, log, m.metrics, LargeMsgMaxUnicastMsgSize)
 
 	// kick off the receive loop to continuously receive messages
 	m.wg.Add(1)
 	go conn.receiveLoop(m.wg)
 }
 
-// Subscribe subscribes the middleware to a channel.
+// Subscribe subscribes the middleware to a channel, on the topics of all
+// configured codecs.
 func (m *Middleware) Subscribe(channel network.Channel) error {
 
-	topic := engine.TopicFromChannel(channel, m.rootBlockID)
+	for _, codec := range m.topicCodecs() {
+		topic := codecTopic(engine.TopicFromChannel(channel, m.rootBlockID), codec.Version)
 
-	s, err := m.libP2PNode.Subscribe(m.ctx, topic)
-	if err != nil {
-		return fmt.Errorf("failed to subscribe for channel %s: %w", channel, err)
-	}
+		s, err := m.libP2PNode.Subscribe(m.ctx, topic, m.validateTopicMessage)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe for channel %s: %w", channel, err)
+		}
 
-	// create a new readSubscription with the context of the middleware
-	rs := newReadSubscription(m.ctx, s, m.processMessage, m.log, m.metrics)
-	m.wg.Add(1)
+		// decode payloads with the codec of the topic
+		callback := m.processMessage
+		if len(m.codecs) > 0 {
+			callback = m.transcodeInbound(codec, m.log)
+		}
 
-	// kick off the receive loop to continuously receive messages
-	go rs.receiveLoop(m.wg)
+		// decompress payloads before decoding them
+		callback = m.decompressInbound(pubSubMaxMsgSize, callback, m.log)
+
+		// drop messages exceeding the rate limits before decompressing them; their
+		// origin is authenticated by the topic validator
+		callback = m.rateLimitInbound(m.pubSubLimiter, callback)
+
+		// create a new readSubscription with the context of the middleware
+		rs := newReadSubscription(m.ctx, s, callback, m.log, m.metrics)
+		m.wg.Add(1)
+
+		// kick off the receive loop to continuously receive messages
+		go rs.receiveLoop(m.wg)
+	}
 
 	// update peers to add some nodes interested in the same topic as direct peers
 	m.peerManager.RequestPeerUpdate()
@@ -365,12 +548,15 @@ func (m *Middleware) Subscribe(channel network.Channel) error {
 	return nil
 }
 
-// Unsubscribe unsubscribes the middleware from a channel.
+// Unsubscribe unsubscribes the middleware from a channel, on the topics of all
+// configured codecs.
 func (m *Middleware) Unsubscribe(channel network.Channel) error {
-	topic := engine.TopicFromChannel(channel, m.rootBlockID)
-	err := m.libP2PNode.UnSubscribe(topic)
-	if err != nil {
-		return fmt.Errorf("failed to unsubscribe from channel %s: %w", channel, err)
+	for _, codec := range m.topicCodecs() {
+		topic := codecTopic(engine.TopicFromChannel(channel, m.rootBlockID), codec.Version)
+		err := m.libP2PNode.UnSubscribe(topic)
+		if err != nil {
+			return fmt.Errorf("failed to unsubscribe from channel %s: %w", channel, err)
+		}
 	}
 	// update peers to remove nodes subscribed to channel
 	m.peerManager.RequestPeerUpdate()
@@ -378,15 +564,76 @@ func (m *Middleware) Unsubscribe(channel network.Channel) error {
 	return nil
 }
 
+// authenticateInbound returns a callback, which drops inbound messages whose
+// origin is not the node of the given libp2p peer before processing them.
+func (m *Middleware) authenticateInbound(peerID peer.ID, callback func(msg *message.Message), log zerolog.Logger) func(msg *message.Message) {
+	return func(msg *message.Message) {
+		err := m.origins.authenticate(msg, peerID)
+		if err != nil {
+			log.Warn().Err(err).Str("peer_id", peerID.String()).Msg("dropping unicast message with unauthenticated origin")
+			m.libP2PNode.Penalize(peerID, "unauthenticated_origin")
+			return
+		}
+		callback(msg)
+	}
+}
+
+// rateLimitInbound returns a callback, which drops inbound messages exceeding the rate limits of the given limiter
+// before processing them. If the limiter is nil, the callback is returned as is.
+func (m *Middleware) rateLimitInbound(limiter *rateLimiter, callback func(msg *message.Message)) func(msg *message.Message) {
+	if limiter == nil {
+		return callback
+	}
+	return func(msg *message.Message) {
+		// our own messages are dropped by the sender validator, they don't count towards the limits
+		if flow.HashToID(msg.OriginID) != m.me && !limiter.allow(msg) {
+			return
+		}
+		callback(msg)
+	}
+}
+
+// validateTopicMessage is the pubsub validator for all topics of the
+// middleware. It rejects messages whose origin is not the node of the libp2p
+// peer which authored and signed them, so that they are neither delivered nor
+// forwarded. Accepted messages are attached to the pubsub message, so they
+// don't need to be decoded again on delivery.
+func (m *Middleware) validateTopicMessage(_ context.Context, from peer.ID, rawMsg *pubsub.Message) pubsub.ValidationResult {
+	var msg message.Message
+	err := msg.Unmarshal(rawMsg.Data)
+	if err != nil {
+		m.log.Warn().Err(err).Str("peer_id", from.String()).Msg("rejecting malformed pubsub message")
+		return pubsub.ValidationReject
+	}
+
+	// the author of pubsub messages is authenticated by their signature
+	err = m.origins.authenticate(&msg, rawMsg.GetFrom())
+	if err != nil {
+		m.log.Warn().Err(err).
+			Str("peer_id", from.String()).
+			Str("author_id", rawMsg.GetFrom().String()).
+			Msg("rejecting pubsub message with unauthenticated origin")
+		return pubsub.ValidationReject
+	}
+
+	rawMsg.ValidatorData = &msg
+	return pubsub.ValidationAccept
+}
+
 // processMessage processes a message and eventually passes it to the overlay
 func (m *Middleware) processMessage(msg *message.Message) {
 
-	// run through all the message validators
-	for _, v := range m.validators {
-		// if any one fails, stop message propagation
-		if !v.Validate(*msg) {
-			return
+	m.traffic.inbound(msg.ChannelID, msg.Size())
+
+	valid, reason := m.validate(msg)
+	if !valid {
+		// pubsub delivers our own messages and messages targeted at other
+		// nodes to us as well, which doesn't make their origin misbehave
+		originID := flow.HashToID(msg.OriginID)
+		if originID != m.me && m.targeted(msg) {
+			m.Penalize(originID, reason)
 		}
+		return
 	}
 
 	// if validation passed, send the message to the overlay
@@ -396,11 +643,327 @@ func (m *Middleware) processMessage(msg *message.Message) {
 	}
 }
 
+// validate runs the message through all the message validators, and returns false if any one of them fails, together
+// with the reason the origin of the message is penalized for
+func (m *Middleware) validate(msg *message.Message) (bool, string) {
+	for _, v := range m.validators {
+		if !v.Validate(*msg) {
+			reason := "invalid_message"
+			if pv, ok := v.(network.PenalizingValidator); ok {
+				reason = pv.PenaltyReason()
+			}
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// targeted returns true if this node is one of the targets of the message.
+func (m *Middleware) targeted(msg *message.Message) bool {
+	for _, targetID := range msg.TargetIDs {
+		if bytes.Equal(targetID, m.me[:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// SendTransfer reliably streams the payload of the given size and type to the target ID on the channel, in frames
+// which the target receives as a transfer. A transfer interrupted by a stream failure is resumed on a new stream,
+// from the first frame the target is missing.
+func (m *Middleware) SendTransfer(channel network.Channel, targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error {
+	targetIdentity, err := m.identity(targetID)
+	if err != nil {
+		return fmt.Errorf("could not find identity for target id: %w", err)
+	}
+
+	var transferID flow.Identifier
+	_, err = rand.Read(transferID[:])
+	if err != nil {
+		return fmt.Errorf("could not generate transfer id: %w", err)
+	}
+
+	header := transferHeader{
+		TransferID: transferID,
+		ChannelID:  channel.String(),
+		OriginID:   m.me,
+		Type:       payloadType,
+		Size:       size,
+		FrameSize:  DefaultTransferFrameSize,
+	}
+
+	var errs error
+	for attempt := 1; attempt <= maxTransferAttempts; attempt++ {
+		retry, err := m.sendTransfer(header, targetIdentity, payload)
+		if err == nil {
+			// OneToOne communication metrics are reported with topic OneToOne
+			m.metrics.NetworkMessageSent(int(size), metrics.ChannelOneToOne, payloadType)
+			return nil
+		}
+		errs = multierror.Append(errs, err)
+		if !retry || m.ctx.Err() != nil {
+			break
+		}
+
+		m.log.Warn().Err(err).
+			Hex("target_id", targetID[:]).
+			Hex("transfer_id", transferID[:]).
+			Int("attempt", attempt).
+			Msg("could not complete transfer, resuming it")
+	}
+
+	return fmt.Errorf("failed to transfer payload to %s: %w", targetID.String(), errs)
+}
+
+// sendTransfer sends the frames of the transfer the target is missing on a new stream. It returns whether the
+// transfer should be resumed on another stream if it fails.
+func (m *Middleware) sendTransfer(header transferHeader, targetIdentity flow.Identity, payload io.ReaderAt) (bool, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, DefaultUnicastTimeout)
+	stream, err := m.libP2PNode.CreateStream(ctx, targetIdentity, transferProtocolID(m.libP2PNode.ProtocolID()))
+	cancel()
+	if err != nil {
+		return true, fmt.Errorf("failed to create stream for %s: %w", targetIdentity.NodeID.String(), err)
+	}
+
+	retry, err := m.writeTransfer(stream, header, payload)
+	if err != nil {
+		_ = stream.Reset()
+		return retry, err
+	}
+
+	err = stream.Close()
+	if err != nil {
+		return false, fmt.Errorf("failed to close the stream for %s: %w", targetIdentity.NodeID.String(), err)
+	}
+	return false, nil
+}
+
+// writeTransfer writes the transfer on the given stream, starting at the first frame the recipient is missing.
+func (m *Middleware) writeTransfer(stream libp2pnetwork.Stream, header transferHeader, payload io.ReaderAt) (bool, error) {
+	_ = stream.SetDeadline(time.Now().Add(transferFrameTimeout))
+	err := writeTransferHeader(stream, header)
+	if err != nil {
+		return true, err
+	}
+	next, err := readTransferAck(stream)
+	if err != nil {
+		return true, fmt.Errorf("could not read transfer acknowledgement: %w", err)
+	}
+	if next > header.frames() {
+		return false, fmt.Errorf("invalid transfer acknowledgement %d of %d frames", next, header.frames())
+	}
+
+	bufw := bufio.NewWriterSize(stream, int(header.FrameSize))
+	data := make([]byte, header.FrameSize)
+	for index := next; index < header.frames(); index++ {
+		frame := data[:header.frameLength(index)]
+		n, err := payload.ReadAt(frame, int64(index)*int64(header.FrameSize))
+		if n < len(frame) {
+			return false, fmt.Errorf("could not read frame %d of payload: %w", index, err)
+		}
+
+		_ = stream.SetWriteDeadline(time.Now().Add(transferFrameTimeout))
+		err = writeFrame(bufw, index, frame)
+		if err == nil {
+			err = bufw.Flush()
+		}
+		if err != nil {
+			return true, fmt.Errorf("could not write frame %d: %w", index, err)
+		}
+	}
+
+	// the recipient acknowledges the last frame with the number of frames
+	_ = stream.SetReadDeadline(time.Now().Add(transferFrameTimeout))
+	done, err := readTransferAck(stream)
+	if err != nil {
+		return true, fmt.Errorf("could not read transfer completion: %w", err)
+	}
+	if done != header.frames() {
+		return true, fmt.Errorf("invalid transfer completion %d of %d frames", done, header.frames())
+	}
+	return false, nil
+}
+
+// handleIncomingTransfer handles an incoming transfer stream from a remote peer. A new transfer is validated as a
+// unicast message and handed to the overlay, which consumes its frames while they are received. A known transfer is
+// resumed from the first missing frame.
+func (m *Middleware) handleIncomingTransfer(s libp2pnetwork.Stream) {
+	log := streamLogger(m.log, s)
+	peerID := s.Conn().RemotePeer()
+
+	_ = s.SetReadDeadline(time.Now().Add(transferFrameTimeout))
+	header, err := readTransferHeader(s)
+	if err == nil {
+		err = header.validate()
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("could not read transfer header")
+		_ = s.Reset()
+		return
+	}
+
+	log = log.With().
+		Hex("origin_id", header.OriginID[:]).
+		Hex("transfer_id", header.TransferID[:]).
+		Str("channel", header.ChannelID).
+		Str("type", header.Type).
+		Logger()
+
+	// the transfer is validated as a unicast message without payload
+	msg := &message.Message{
+		ChannelID: header.ChannelID,
+		EventID:   header.TransferID[:],
+		OriginID:  header.OriginID[:],
+		TargetIDs: [][]byte{m.me[:]},
+		Type:      header.Type,
+	}
+
+	// only accept transfers originating from the remote peer itself
+	err = m.origins.authenticate(msg, peerID)
+	if err != nil {
+		log.Warn().Err(err).Str("peer_id", peerID.String()).Msg("dropping transfer with unauthenticated origin")
+		m.libP2PNode.Penalize(peerID, "unauthenticated_origin")
+		_ = s.Reset()
+		return
+	}
+
+	key := transferKey{originID: header.OriginID, transferID: header.TransferID}
+	t, known := m.transfers.get(key)
+	if !known {
+		if m.unicastLimiter != nil && !m.unicastLimiter.allow(msg) {
+			_ = s.Reset()
+			return
+		}
+		valid, reason := m.validate(msg)
+		if !valid {
+			m.Penalize(flow.HashToID(msg.OriginID), reason)
+			_ = s.Reset()
+			return
+		}
+		t, err = m.transfers.add(m.ctx, key, header)
+		if errors.Is(err, errTooManyTransfers) {
+			log.Warn().Err(err).Msg("dropping transfer")
+			_ = s.Reset()
+			return
+		}
+		if err != nil {
+			// the sender raced itself resuming the transfer
+			_ = s.Reset()
+			return
+		}
+
+		m.wg.Add(1)
+		go m.consumeTransfer(key, t, log)
+	}
+
+	if t.header != header {
+		log.Warn().Msg("dropping transfer resumed with a different header")
+		_ = s.Reset()
+		return
+	}
+
+	err = m.receiveTransfer(s, t)
+	if err != nil {
+		log.Warn().Err(err).Msg("transfer interrupted")
+		_ = s.Reset()
+		return
+	}
+
+	err = s.Close()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to close transfer stream")
+	}
+}
+
+// receiveTransfer receives the missing frames of the transfer on the given stream, and hands them to its consumer.
+func (m *Middleware) receiveTransfer(s libp2pnetwork.Stream, t *inboundTransfer) error {
+	// a transfer resumed after being received completely is acknowledged as missing no frames
+	if t.complete() {
+		err := writeTransferAck(s, t.header.frames())
+		if err != nil {
+			return fmt.Errorf("could not write transfer acknowledgement: %w", err)
+		}
+		return writeTransferAck(s, t.header.frames())
+	}
+
+	next, ok := t.attach()
+	if !ok {
+		return fmt.Errorf("transfer is being received on another stream or abandoned")
+	}
+
+	err := m.receiveFrames(s, t, next)
+	if err != nil {
+		t.detach(err, m.transfers.resumeTimeout)
+		return err
+	}
+	t.release()
+
+	return writeTransferAck(s, t.header.frames())
+}
+
+// receiveFrames receives the frames of the transfer starting at the given index.
+func (m *Middleware) receiveFrames(s libp2pnetwork.Stream, t *inboundTransfer, next uint32) error {
+	err := writeTransferAck(s, next)
+	if err != nil {
+		return fmt.Errorf("could not write transfer acknowledgement: %w", err)
+	}
+
+	r := bufio.NewReaderSize(s, int(t.header.FrameSize))
+	for index := next; index < t.header.frames(); index++ {
+		_ = s.SetReadDeadline(time.Now().Add(transferFrameTimeout))
+		frame, err := readFrame(r, t.header, index)
+		if err != nil {
+			return err
+		}
+		if !t.deliver(frame) {
+			return fmt.Errorf("transfer abandoned by its consumer")
+		}
+	}
+	return nil
+}
+
+// consumeTransfer hands the transfer to the overlay, which consumes its frames.
+func (m *Middleware) consumeTransfer(key transferKey, t *inboundTransfer, log zerolog.Logger) {
+	defer m.wg.Done()
+	defer m.transfers.finish(key, t)
+
+	err := m.ov.ReceiveTransfer(key.originID, t)
+	if err != nil {
+		log.Error().Err(err).Msg("could not deliver transfer")
+		return
+	}
+
+	// OneToOne communication metrics are reported with topic OneToOne
+	m.metrics.NetworkMessageReceived(int(t.Size()), metrics.ChannelOneToOne, t.Type())
+}
+
 // Publish publishes a message on the channel. It models a distributed broadcast where the message is meant for all or
 // a many nodes subscribing to the channel. It does not guarantee the delivery though, and operates on a best
 // effort.
 func (m *Middleware) Publish(msg *message.Message, channel network.Channel) error {
 
+	// encode the payload with the codec pubsub messages are published with
+	if len(m.codecs) > 0 {
+		codec, _ := m.codec(m.pubSubVersion)
+		var err error
+		msg, err = transcode(msg, m.codecs[0], codec)
+		if err != nil {
+			return fmt.Errorf("failed to transcode the message: %w", err)
+		}
+	}
+
+	// compress the payload if enabled for pubsub, the size limit applies to the uncompressed message
+	if m.compression.PubSub && m.compression.Algorithm != CompressionNone {
+		if msg.Size() > DefaultMaxPubSubMsgSize {
+			return fmt.Errorf("message size %d exceeds configured max message size %d", msg.Size(), DefaultMaxPubSubMsgSize)
+		}
+		var err error
+		msg, err = m.compressOutbound(msg, m.compression.Algorithm)
+		if err != nil {
+			return fmt.Errorf("failed to compress the message: %w", err)
+		}
+	}
+
 	// convert the message to bytes to be put on the wire.
 	data, err := msg.Marshal()
 	if err != nil {
@@ -414,7 +977,7 @@ func (m *Middleware) Publish(msg *message.Message, channel network.Channel) erro
 		return fmt.Errorf("message size %d exceeds configured max message size %d", msgSize, DefaultMaxPubSubMsgSize)
 	}
 
-	topic := engine.TopicFromChannel(channel, m.rootBlockID)
+	topic := codecTopic(engine.TopicFromChannel(channel, m.rootBlockID), m.pubSubVersion)
 
 	// publish the bytes on the topic
 	err = m.libP2PNode.Publish(m.ctx, topic, data)
@@ -423,10 +986,118 @@ func (m *Middleware) Publish(msg *message.Message, channel network.Channel) erro
 	}
 
 	m.metrics.NetworkMessageSent(len(data), string(channel), msg.Type)
+	m.traffic.outbound(string(channel), len(data))
 
 	return nil
 }
 
+// topicCodecs returns the codecs of the topics the middleware subscribes to.
+// Without codecs, it only subscribes to the topic of the legacy protocol.
+func (m *Middleware) topicCodecs() []VersionedCodec {
+	if len(m.codecs) == 0 {
+		return []VersionedCodec{{Version: CodecVersionJSON}}
+	}
+	return m.codecs
+}
+
+// codec returns the configured codec of the given version. It returns false
+// if no codec of the version is configured.
+func (m *Middleware) codec(version CodecVersion) (VersionedCodec, bool) {
+	for _, codec := range m.codecs {
+		if codec.Version == version {
+			return codec, true
+		}
+	}
+	return VersionedCodec{}, false
+}
+
+// protocols returns the libp2p protocol IDs of the configured codecs, in order
+// of preference. If compression is configured, each codec is preferred with
+// compression.
+func (m *Middleware) protocols() []protocol.ID {
+	protocols := make([]protocol.ID, 0, 2*len(m.codecs))
+	for _, codec := range m.codecs {
+		pid := codecProtocolID(m.libP2PNode.ProtocolID(), codec.Version)
+		if m.compression.Algorithm != CompressionNone {
+			protocols = append(protocols, compressionProtocolID(pid, m.compression.Algorithm))
+		}
+		protocols = append(protocols, pid)
+	}
+	return protocols
+}
+
+// streamCodec returns the codec negotiated for the given stream. It returns
+// false if no codecs are configured.
+func (m *Middleware) streamCodec(s libp2pnetwork.Stream) (VersionedCodec, bool) {
+	for _, codec := range m.codecs {
+		pid := codecProtocolID(m.libP2PNode.ProtocolID(), codec.Version)
+		if pid == s.Protocol() {
+			return codec, true
+		}
+		if m.compression.Algorithm != CompressionNone && compressionProtocolID(pid, m.compression.Algorithm) == s.Protocol() {
+			return codec, true
+		}
+	}
+	return VersionedCodec{}, false
+}
+
+// streamCompression returns the compression negotiated for the given stream.
+func (m *Middleware) streamCompression(s libp2pnetwork.Stream) Compression {
+	if m.compression.Algorithm == CompressionNone {
+		return CompressionNone
+	}
+	for _, codec := range m.codecs {
+		pid := codecProtocolID(m.libP2PNode.ProtocolID(), codec.Version)
+		if compressionProtocolID(pid, m.compression.Algorithm) == s.Protocol() {
+			return m.compression.Algorithm
+		}
+	}
+	return CompressionNone
+}
+
+// compressOutbound compresses the payload of the given message with the given
+// algorithm, if it reaches the configured threshold.
+func (m *Middleware) compressOutbound(msg *message.Message, algorithm Compression) (*message.Message, error) {
+	compressed, ok, err := compress(msg, algorithm, m.compression.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		m.metrics.NetworkMessageCompressed(msg.ChannelID, metrics.DirectionOutbound, len(msg.Payload), len(compressed.Payload))
+	}
+	return compressed, nil
+}
+
+// decompressInbound returns a callback, which decompresses the payload of
+// inbound messages before processing them. Messages whose payload would exceed
+// the max message size once decompressed are dropped.
+func (m *Middleware) decompressInbound(maxMsgSize func(msg *message.Message) int, callback func(msg *message.Message), log zerolog.Logger) func(msg *message.Message) {
+	return func(msg *message.Message) {
+		decompressed, ok, err := decompress(msg, maxMsgSize(msg))
+		if err != nil {
+			log.Error().Err(err).Str("channel_id", msg.ChannelID).Msg("could not decompress inbound message")
+			return
+		}
+		if ok {
+			m.metrics.NetworkMessageCompressed(msg.ChannelID, metrics.DirectionInbound, len(decompressed.Payload), len(msg.Payload))
+		}
+		callback(decompressed)
+	}
+}
+
+// transcodeInbound returns a callback, which converts the payload of inbound
+// messages from the given codec to the local codec before processing them.
+func (m *Middleware) transcodeInbound(codec VersionedCodec, log zerolog.Logger) func(msg *message.Message) {
+	return func(msg *message.Message) {
+		converted, err := transcode(msg, codec, m.codecs[0])
+		if err != nil {
+			log.Error().Err(err).Str("codec_version", string(codec.Version)).Msg("could not transcode inbound message")
+			return
+		}
+		m.processMessage(converted)
+	}
+}
+
 // Ping pings the target node and returns the ping RTT or an error
 func (m *Middleware) Ping(targetID flow.Identifier) (time.Duration, error) {
 	targetIdentity, err := m.identity(targetID)
@@ -452,6 +1123,15 @@ func (m *Middleware) UpdateAllowList() error {
 		return fmt.Errorf("failed to update approved peer list: %w", err)
 	}
 
+	// update the nodes we accept messages from
+	err = m.origins.update(identityList(idsMap))
+	if err != nil {
+		m.log.Warn().Err(err).Msg("could not index some peers for origin authentication")
+	}
+
+	// forget the penalties of nodes which left the network
+	m.prunePenalties(idsMap)
+
 	// update peer connections
 	m.peerManager.RequestPeerUpdate()
 
@@ -463,6 +1143,11 @@ func (m *Middleware) IsConnected(identity flow.Identity) (bool, error) {
 	return m.libP2PNode.IsConnected(identity)
 }
 
+// pubSubMaxMsgSize returns the max permissible size for a pubsub message
+func pubSubMaxMsgSize(_ *message.Message) int {
+	return DefaultMaxPubSubMsgSize
+}
+
 // unicastMaxMsgSize returns the max permissible size for a unicast message
 func unicastMaxMsgSize(msg *message.Message) int {
 	switch msg.Type {
@@ -0,0 +1,111 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestProcessMessagePenalizesInvalidMessages tests that the origin of a
+// message failing validation is penalized once, for the reason of the failing
+// validator, which lowers its peer score, while our own messages and messages
+// targeted at other nodes are dropped silently.
+func TestProcessMessagePenalizesInvalidMessages(t *testing.T) {
+	me := unittest.IdentifierFixture()
+	other := unittest.IdentifierFixture()
+
+	// the origin is an execution node, which may not propose blocks
+	origin := unittest.IdentityFixture(unittest.WithRole(flow.RoleExecution), unittest.WithAddress("0.0.0.0:0"))
+	origin.NetworkPubKey = generateNetworkingKey(t).PublicKey()
+	info, err := PeerAddressInfo(*origin)
+	require.NoError(t, err)
+
+	logs := &bytes.Buffer{}
+	m := NewMiddleware(zerolog.New(logs), nil, me, metrics.NewNoopCollector(), rootBlockID)
+	scorer := NewPeerScorer(zerolog.Nop(), rootBlockID)
+	m.libP2PNode = (&Node{}).WithPeerScorer(scorer)
+	m.origins = newOriginIndex(peer.ID("self"), me)
+	require.NoError(t, m.origins.update(flow.IdentityList{origin}))
+
+	proposal := func(originID flow.Identifier, targetID flow.Identifier) *message.Message {
+		return &message.Message{
+			ChannelID: engine.ConsensusCommittee.String(),
+			EventID:   []byte("1"),
+			OriginID:  originID[:],
+			TargetIDs: [][]byte{targetID[:]},
+			Type:      "messages.BlockProposal",
+		}
+	}
+
+	// reasons returns the reasons of the penalties logged since the last call
+	reasons := func() []string {
+		var reasons []string
+		for _, line := range bytes.Split(logs.Bytes(), []byte("\n")) {
+			var entry struct {
+				Message string `json:"message"`
+				Reason  string `json:"reason"`
+			}
+			if json.Unmarshal(line, &entry) == nil && entry.Message == "penalized node" {
+				reasons = append(reasons, entry.Reason)
+			}
+		}
+		logs.Reset()
+		return reasons
+	}
+
+	t.Run("own message", func(t *testing.T) {
+		m.processMessage(proposal(me, me))
+		assert.Zero(t, m.Penalties(me))
+		assert.Empty(t, reasons())
+	})
+
+	t.Run("message targeted at another node", func(t *testing.T) {
+		m.processMessage(proposal(origin.NodeID, other))
+		assert.Zero(t, m.Penalties(origin.NodeID))
+		assert.Zero(t, scorer.appSpecificScore(info.ID))
+		assert.Empty(t, reasons())
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		m.processMessage(proposal(origin.NodeID, me))
+		assert.Equal(t, uint(1), m.Penalties(origin.NodeID))
+		assert.Equal(t, []string{"unauthorized_sender"}, reasons())
+		assert.Less(t, scorer.appSpecificScore(info.ID), float64(0))
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		unknown := unittest.IdentifierFixture()
+		m.processMessage(proposal(unknown, me))
+		assert.Equal(t, uint(1), m.Penalties(unknown))
+		assert.Equal(t, []string{"unauthorized_sender"}, reasons())
+	})
+}
+
+// TestPrunePenalties tests that the penalties of nodes which left the network
+// are forgotten.
+func TestPrunePenalties(t *testing.T) {
+	m := NewMiddleware(zerolog.Nop(), nil, unittest.IdentifierFixture(), metrics.NewNoopCollector(), rootBlockID)
+	m.libP2PNode = &Node{}
+	m.origins = newOriginIndex(peer.ID("self"), m.me)
+
+	staying := unittest.IdentityFixture()
+	leaving := unittest.IdentityFixture()
+	m.Penalize(staying.NodeID, "test")
+	m.Penalize(leaving.NodeID, "test")
+
+	m.prunePenalties(map[flow.Identifier]flow.Identity{staying.NodeID: *staying})
+	assert.Equal(t, uint(1), m.Penalties(staying.NodeID))
+	assert.Zero(t, m.Penalties(leaving.NodeID))
+	assert.Len(t, m.penalties, 1)
+}
@@ -3,6 +3,9 @@ package p2p
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -129,13 +132,15 @@ func (n *Network) Register(channel network.Channel, engine network.Engine) (netw
 
 	// create the conduit
 	conduit := &Conduit{
-		ctx:       ctx,
-		cancel:    cancel,
-		channel:   channel,
-		publish:   n.publish,
-		unicast:   n.unicast,
-		multicast: n.multicast,
-		close:     n.unregister,
+		ctx:           ctx,
+		cancel:        cancel,
+		channel:       channel,
+		publish:       n.publish,
+		unicast:       n.unicast,
+		multicast:     n.multicast,
+		transfer:      n.transfer,
+		transferEvent: n.transferEvent,
+		close:         n.unregister,
 	}
 
 	return conduit, nil
@@ -184,6 +189,45 @@ func (n *Network) Receive(nodeID flow.Identifier, msg *message.Message) error {
 	return nil
 }
 
+// ReceiveTransfer hands an inbound transfer to the engine registered for its channel. Engines which consume the
+// frames of transfers themselves receive the transfer, while the transfers to other engines are decoded as they are
+// received, and the decoded event is processed by the engine.
+func (n *Network) ReceiveTransfer(nodeID flow.Identifier, transfer network.Transfer) error {
+	eng, err := n.subMngr.GetEngine(transfer.Channel())
+	if err != nil {
+		return fmt.Errorf("could not get engine for channel %s: %w", transfer.Channel(), err)
+	}
+
+	te, ok := eng.(network.TransferEngine)
+	if ok {
+		err = te.ProcessTransfer(nodeID, transfer)
+		if err != nil {
+			return fmt.Errorf("could not process transfer: %w", err)
+		}
+		return nil
+	}
+
+	// the decoder reads the frames of the transfer as it needs them, so that
+	// the encoded payload is never assembled in memory
+	event, err := n.codec.NewDecoder(network.NewTransferReader(transfer)).Decode()
+	if err != nil {
+		return fmt.Errorf("could not decode transfer: %w", err)
+	}
+
+	// the authorization of the sender is checked against the declared payload
+	// type, so it must match the type of the event
+	eventType := strings.TrimLeft(fmt.Sprintf("%T", event), "*")
+	if eventType != transfer.Type() {
+		return fmt.Errorf("transfer type %s does not match event type %s", transfer.Type(), eventType)
+	}
+
+	err = eng.Process(nodeID, event)
+	if err != nil {
+		return fmt.Errorf("could not process transferred event: %w", err)
+	}
+	return nil
+}
+
 // SetIDs updates the identity list cached by the network layer
 func (n *Network) SetIDs(ids flow.IdentityList) error {
 
@@ -227,6 +271,13 @@ func (n *Network) processNetworkMessage(senderID flow.Identifier, message *messa
 		return fmt.Errorf("could not decode event: %w", err)
 	}
 
+	// the authorization of the sender is checked against the declared message
+	// type, so it must match the type of the payload
+	msgType := strings.TrimLeft(fmt.Sprintf("%T", decodedMessage), "*")
+	if msgType != message.Type {
+		return fmt.Errorf("message type %s does not match payload type %s", message.Type, msgType)
+	}
+
 	// create queue message
 	qm := queue.QMessage{
 		Payload:  decodedMessage,
@@ -315,6 +366,52 @@ func (n *Network) unicast(channel network.Channel, message interface{}, targetID
 	return nil
 }
 
+// transfer reliably streams the payload of the given size and type to the target ID, which receives it as a
+// transfer.
+func (n *Network) transfer(channel network.Channel, targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error {
+	if targetID == n.me.NodeID() {
+		n.logger.Debug().Msg("network skips self transfer")
+		return nil
+	}
+
+	err := n.mw.SendTransfer(channel, targetID, payloadType, payload, size)
+	if err != nil {
+		return fmt.Errorf("failed to transfer payload to %x: %w", targetID, err)
+	}
+
+	return nil
+}
+
+// transferEvent reliably streams the event to the target ID, which receives it as a transfer. The encoded event is
+// spooled to a temporary file rather than held in memory, as the transfer may take a while to complete and be resumed.
+func (n *Network) transferEvent(channel network.Channel, event interface{}, targetID flow.Identifier) error {
+	if targetID == n.me.NodeID() {
+		n.logger.Debug().Msg("network skips self transfer")
+		return nil
+	}
+
+	spool, err := ioutil.TempFile("", "flow-transfer-")
+	if err != nil {
+		return fmt.Errorf("could not create transfer spool: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	err = n.codec.NewEncoder(spool).Encode(event)
+	if err != nil {
+		return fmt.Errorf("could not encode event: %w", err)
+	}
+	info, err := spool.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat transfer spool: %w", err)
+	}
+
+	payloadType := strings.TrimLeft(fmt.Sprintf("%T", event), "*")
+	return n.transfer(channel, targetID, payloadType, spool, uint64(info.Size()))
+}
+
 // publish sends the message in an unreliable way to the given recipients.
 // In this context, unreliable means that the message is published over a libp2p pub-sub
 // channel and can be read by any node subscribed to that channel.
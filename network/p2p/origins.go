@@ -0,0 +1,117 @@
+package p2p
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network/message"
+)
+
+// originIndex maps the libp2p peer IDs of staked nodes to their Flow
+// identifiers, so that the origin ID of inbound messages can be authenticated
+// against the libp2p peer which authored them. The local node is always
+// indexed, as pubsub validates our own messages as well. It also indexes the
+// identities of origins, so that messages can be validated against them.
+type originIndex struct {
+	sync.RWMutex
+	self       peer.ID
+	me         flow.Identifier
+	nodeIDs    map[peer.ID]flow.Identifier
+	peerIDs    map[flow.Identifier]peer.ID
+	identities map[flow.Identifier]*flow.Identity
+}
+
+func newOriginIndex(self peer.ID, me flow.Identifier) *originIndex {
+	o := &originIndex{
+		self:       self,
+		me:         me,
+		nodeIDs:    map[peer.ID]flow.Identifier{self: me},
+		peerIDs:    map[flow.Identifier]peer.ID{me: self},
+		identities: make(map[flow.Identifier]*flow.Identity),
+	}
+	return o
+}
+
+// update replaces the indexed identities with the given ones. Identities
+// whose networking key can't be converted are left out of the index, so
+// messages originating from them are dropped.
+func (o *originIndex) update(identities flow.IdentityList) error {
+	var errs error
+	nodeIDs := make(map[peer.ID]flow.Identifier, len(identities)+1)
+	nodeIDs[o.self] = o.me
+	peerIDs := make(map[flow.Identifier]peer.ID, len(identities)+1)
+	peerIDs[o.me] = o.self
+	byNodeID := make(map[flow.Identifier]*flow.Identity, len(identities))
+	for _, identity := range identities {
+		byNodeID[identity.NodeID] = identity
+		_, _, key, err := networkingInfo(*identity)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		peerID, err := peer.IDFromPublicKey(key)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("could not get peer ID for %s: %w", identity.NodeID, err))
+			continue
+		}
+		nodeIDs[peerID] = identity.NodeID
+		peerIDs[identity.NodeID] = peerID
+	}
+
+	o.Lock()
+	o.nodeIDs = nodeIDs
+	o.peerIDs = peerIDs
+	o.identities = byNodeID
+	o.Unlock()
+
+	return errs
+}
+
+// identity returns the identity of the origin with the given node ID.
+func (o *originIndex) identity(nodeID flow.Identifier) (*flow.Identity, bool) {
+	o.RLock()
+	defer o.RUnlock()
+	identity, ok := o.identities[nodeID]
+	return identity, ok
+}
+
+// peerID returns the libp2p peer ID of the node with the given node ID.
+func (o *originIndex) peerID(nodeID flow.Identifier) (peer.ID, bool) {
+	o.RLock()
+	defer o.RUnlock()
+	peerID, ok := o.peerIDs[nodeID]
+	return peerID, ok
+}
+
+// nodeID returns the node ID of the node with the given libp2p peer ID.
+func (o *originIndex) nodeID(peerID peer.ID) (flow.Identifier, bool) {
+	o.RLock()
+	defer o.RUnlock()
+	nodeID, ok := o.nodeIDs[peerID]
+	return nodeID, ok
+}
+
+// authenticate checks that the given message originates from the staked node
+// with the given libp2p peer ID.
+func (o *originIndex) authenticate(msg *message.Message, peerID peer.ID) error {
+	o.RLock()
+	nodeID, ok := o.nodeIDs[peerID]
+	o.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("peer %s is not a staked node", peerID)
+	}
+	if len(msg.OriginID) != len(nodeID) {
+		return fmt.Errorf("invalid origin ID length %d", len(msg.OriginID))
+	}
+	originID := flow.HashToID(msg.OriginID)
+	if nodeID != originID {
+		return fmt.Errorf("origin %s does not match peer %s of node %s", originID, peerID, nodeID)
+	}
+
+	return nil
+}
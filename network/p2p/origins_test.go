@@ -0,0 +1,66 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestOriginIndex(t *testing.T) {
+
+	// creates identities with networking keys and their peer IDs
+	identities := unittest.IdentityListFixture(3)
+	peerIDs := make([]peer.ID, 0, len(identities))
+	for _, identity := range identities {
+		identity.Address = "0.0.0.0:0"
+		identity.NetworkPubKey = generateNetworkingKey(t).PublicKey()
+		info, err := PeerAddressInfo(*identity)
+		require.NoError(t, err)
+		peerIDs = append(peerIDs, info.ID)
+	}
+
+	self := peer.ID("self")
+	me := unittest.IdentifierFixture()
+	origins := newOriginIndex(self, me)
+	require.NoError(t, origins.update(identities))
+
+	messageFrom := func(originID flow.Identifier) *message.Message {
+		return &message.Message{OriginID: originID[:]}
+	}
+
+	t.Run("matching origin", func(t *testing.T) {
+		for i, identity := range identities {
+			assert.NoError(t, origins.authenticate(messageFrom(identity.NodeID), peerIDs[i]))
+		}
+	})
+
+	t.Run("self", func(t *testing.T) {
+		assert.NoError(t, origins.authenticate(messageFrom(me), self))
+	})
+
+	t.Run("impersonated origin", func(t *testing.T) {
+		assert.Error(t, origins.authenticate(messageFrom(identities[1].NodeID), peerIDs[0]))
+	})
+
+	t.Run("unknown peer", func(t *testing.T) {
+		assert.Error(t, origins.authenticate(messageFrom(identities[0].NodeID), peer.ID("unknown")))
+	})
+
+	t.Run("truncated origin", func(t *testing.T) {
+		msg := messageFrom(identities[0].NodeID)
+		msg.OriginID = msg.OriginID[:16]
+		assert.Error(t, origins.authenticate(msg, peerIDs[0]))
+	})
+
+	t.Run("removed identity", func(t *testing.T) {
+		require.NoError(t, origins.update(identities[1:]))
+		assert.Error(t, origins.authenticate(messageFrom(identities[0].NodeID), peerIDs[0]))
+		assert.NoError(t, origins.authenticate(messageFrom(me), self))
+	})
+}
@@ -0,0 +1,272 @@
+package p2p
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/network"
+)
+
+const (
+	// DefaultPeerScoreInspectInterval is the interval at which peer scores are
+	// checked against the disconnect threshold.
+	DefaultPeerScoreInspectInterval = time.Second
+
+	// DefaultPeerBlockDuration is the time for which peers are refused
+	// connections after being disconnected for their score.
+	DefaultPeerBlockDuration = 10 * time.Minute
+
+	// penaltyWeight is the (negative) contribution of a single misbehaviour to
+	// the application specific score of a peer.
+	penaltyWeight = -10
+
+	// penaltyDecayInterval is the time it takes for a recorded penalty to decay
+	// to zero.
+	penaltyDecayInterval = 10 * time.Minute
+
+	// the score thresholds, below which gossip to and from a peer is
+	// suppressed, messages are not published to a peer, messages of a peer are
+	// ignored altogether, and a peer is disconnected, respectively
+	gossipThreshold     = -40
+	publishThreshold    = -80
+	graylistThreshold   = -100
+	disconnectThreshold = graylistThreshold
+)
+
+// PeerScorer scores the peers of a libp2p node with the GossipSub peer score.
+// Besides the score GossipSub keeps for the topics, e.g. for invalid messages
+// rejected by the topic validators, it keeps track of misbehaviours reported
+// by the application, like invalid unicast messages, which feed into the
+// application specific score. Peers whose score drops below the disconnect
+// threshold are disconnected and refused connections for a while.
+type PeerScorer struct {
+	sync.Mutex
+	log           zerolog.Logger
+	params        *pubsub.PeerScoreParams
+	topics        map[string]struct{}   // topics whose score parameters are set up front
+	penalties     map[peer.ID]*penalty  // penalties reported by the application, by peer
+	scores        map[peer.ID]float64   // most recent scores of the peers
+	blocked       map[peer.ID]time.Time // disconnected peers, by the time they are unblocked
+	blockDuration time.Duration         // time for which disconnected peers are blocked
+	host          host.Host             // host of the scored peers, set once the node is created
+	connGater     *connGater            // used to refuse connections with blocked peers
+	now           func() time.Time      // used to decay penalties
+}
+
+// penalty is a decaying counter of misbehaviours of a peer.
+type penalty struct {
+	value   float64
+	updated time.Time
+}
+
+// NewPeerScorer creates a new peer scorer, which derives the score parameters
+// of the topics from the channels of the network with the given root block.
+func NewPeerScorer(log zerolog.Logger, rootBlockID string) *PeerScorer {
+	s := &PeerScorer{
+		log:           log.With().Str("component", "peer_scorer").Logger(),
+		penalties:     make(map[peer.ID]*penalty),
+		scores:        make(map[peer.ID]float64),
+		blocked:       make(map[peer.ID]time.Time),
+		blockDuration: DefaultPeerBlockDuration,
+		now:           time.Now,
+	}
+	s.params = peerScoreParams(rootBlockID, s.appSpecificScore)
+	// the topic parameters are owned by pubsub once it is created, so the
+	// topics set up front are kept separately
+	s.topics = make(map[string]struct{}, len(s.params.Topics))
+	for topic := range s.params.Topics {
+		s.topics[topic] = struct{}{}
+	}
+	return s
+}
+
+// PubSubOptions returns the pubsub options which enable peer scoring with the
+// scorer.
+func (s *PeerScorer) PubSubOptions() []pubsub.Option {
+	return []pubsub.Option{
+		pubsub.WithPeerScore(s.params, peerScoreThresholds()),
+		pubsub.WithPeerScoreInspect(s.inspect, DefaultPeerScoreInspectInterval),
+	}
+}
+
+// bind attaches the scorer to the host of the scored peers and its connection
+// gater (if any), so that peers can be disconnected.
+func (s *PeerScorer) bind(h host.Host, cg *connGater) {
+	s.Lock()
+	defer s.Unlock()
+	s.host = h
+	s.connGater = cg
+}
+
+// Penalize records a misbehaviour of the given peer, for the given reason.
+func (s *PeerScorer) Penalize(peerID peer.ID, reason string) {
+	s.Lock()
+	p, ok := s.penalties[peerID]
+	if !ok {
+		p = &penalty{updated: s.now()}
+		s.penalties[peerID] = p
+	}
+	p.value = s.decayed(p) + 1
+	p.updated = s.now()
+	value := p.value
+	s.Unlock()
+
+	s.log.Debug().
+		Str("peer_id", peerID.String()).
+		Str("reason", reason).
+		Float64("penalty", value).
+		Msg("penalized peer")
+}
+
+// Score returns the most recent score of the given peer. It returns false if
+// the peer has not been scored yet.
+func (s *PeerScorer) Score(peerID peer.ID) (float64, bool) {
+	s.Lock()
+	defer s.Unlock()
+	score, ok := s.scores[peerID]
+	return score, ok
+}
+
+// topicScoreParams returns the score parameters of the given topic. It returns
+// false if the topic parameters are known to pubsub already.
+func (s *PeerScorer) topicScoreParams(topic network.Topic) (*pubsub.TopicScoreParams, bool) {
+	if _, known := s.topics[topic.String()]; known {
+		return nil, false
+	}
+	// cluster topics are not known in advance, as they change with the epochs
+	return defaultTopicScoreParams(), true
+}
+
+// appSpecificScore returns the application specific score of the given peer,
+// which is made up of the penalties recorded for it.
+// NOTE: it is called by pubsub while holding its score lock, so it must not
+// call back into pubsub.
+func (s *PeerScorer) appSpecificScore(peerID peer.ID) float64 {
+	s.Lock()
+	defer s.Unlock()
+	p, ok := s.penalties[peerID]
+	if !ok {
+		return 0
+	}
+	value := s.decayed(p)
+	if value < pubsub.DefaultDecayToZero {
+		delete(s.penalties, peerID)
+		return 0
+	}
+	return -value
+}
+
+// decayed returns the value of the given penalty decayed to the current time.
+func (s *PeerScorer) decayed(p *penalty) float64 {
+	elapsed := s.now().Sub(p.updated)
+	return p.value * math.Pow(pubsub.DefaultDecayToZero, float64(elapsed)/float64(penaltyDecayInterval))
+}
+
+// inspect is called periodically by pubsub with the scores of all peers. It
+// disconnects and blocks the peers whose score is below the disconnect
+// threshold.
+func (s *PeerScorer) inspect(scores map[peer.ID]float64) {
+	s.Lock()
+	s.scores = scores
+	h, cg := s.host, s.connGater
+	now := s.now()
+	var disconnect []peer.ID
+	for peerID, score := range scores {
+		if score >= disconnectThreshold {
+			continue
+		}
+		if until, blocked := s.blocked[peerID]; blocked && now.Before(until) {
+			continue
+		}
+		s.blocked[peerID] = now.Add(s.blockDuration)
+		disconnect = append(disconnect, peerID)
+	}
+	for peerID, until := range s.blocked {
+		if !now.Before(until) {
+			delete(s.blocked, peerID)
+		}
+	}
+	s.Unlock()
+
+	for _, peerID := range disconnect {
+		s.log.Warn().
+			Str("peer_id", peerID.String()).
+			Float64("score", scores[peerID]).
+			Msg("disconnecting peer with low score")
+
+		if cg != nil {
+			cg.block(peerID, now.Add(s.blockDuration))
+		}
+		if h != nil {
+			err := h.Network().ClosePeer(peerID)
+			if err != nil {
+				s.log.Error().Err(err).Str("peer_id", peerID.String()).Msg("could not disconnect peer")
+			}
+		}
+	}
+}
+
+// peerScoreParams returns the peer score parameters, with the topic score
+// parameters of all channels of the network with the given root block.
+func peerScoreParams(rootBlockID string, appSpecificScore func(peer.ID) float64) *pubsub.PeerScoreParams {
+	topics := make(map[string]*pubsub.TopicScoreParams)
+	for _, channel := range engine.Channels() {
+		if _, isCluster := engine.ClusterChannel(channel); isCluster {
+			// cluster topics are only known once the node joins a cluster
+			continue
+		}
+		topics[engine.TopicFromChannel(channel, rootBlockID).String()] = defaultTopicScoreParams()
+	}
+
+	return &pubsub.PeerScoreParams{
+		Topics: topics,
+		// limits the positive score peers can build up by delivering messages,
+		// so that they can't build up slack for misbehaving later
+		TopicScoreCap:     10,
+		AppSpecificScore:  appSpecificScore,
+		AppSpecificWeight: -penaltyWeight,
+		// staked nodes may be colocated, so their IPs are not penalized
+		IPColocationFactorWeight:  0,
+		BehaviourPenaltyWeight:    -10,
+		BehaviourPenaltyThreshold: 6,
+		BehaviourPenaltyDecay:     pubsub.ScoreParameterDecay(10 * time.Minute),
+		DecayInterval:             pubsub.DefaultDecayInterval,
+		DecayToZero:               pubsub.DefaultDecayToZero,
+		RetainScore:               time.Hour,
+	}
+}
+
+// defaultTopicScoreParams returns the score parameters of a topic. Messages on
+// the Flow topics are infrequent and bursty, so peers are not penalized for
+// not delivering messages in the mesh, only for delivering invalid ones.
+func defaultTopicScoreParams() *pubsub.TopicScoreParams {
+	return &pubsub.TopicScoreParams{
+		TopicWeight:                    1,
+		TimeInMeshWeight:               0.01,
+		TimeInMeshQuantum:              time.Second,
+		TimeInMeshCap:                  300,
+		FirstMessageDeliveriesWeight:   0.5,
+		FirstMessageDeliveriesDecay:    pubsub.ScoreParameterDecay(10 * time.Minute),
+		FirstMessageDeliveriesCap:      10,
+		InvalidMessageDeliveriesWeight: -10,
+		InvalidMessageDeliveriesDecay:  pubsub.ScoreParameterDecay(time.Hour),
+	}
+}
+
+// peerScoreThresholds returns the thresholds of the peer score.
+func peerScoreThresholds() *pubsub.PeerScoreThresholds {
+	return &pubsub.PeerScoreThresholds{
+		GossipThreshold:             gossipThreshold,
+		PublishThreshold:            publishThreshold,
+		GraylistThreshold:           graylistThreshold,
+		AcceptPXThreshold:           10,
+		OpportunisticGraftThreshold: 1,
+	}
+}
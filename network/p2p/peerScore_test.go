@@ -0,0 +1,129 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestPeerScorerPenalties tests that penalties lower the application specific
+// score of a peer, and decay over time.
+func TestPeerScorerPenalties(t *testing.T) {
+	now := time.Now()
+	scorer := NewPeerScorer(zerolog.Nop(), rootBlockID)
+	scorer.now = func() time.Time { return now }
+
+	peerID := peer.ID("peer")
+	assert.Equal(t, float64(0), scorer.appSpecificScore(peerID))
+
+	scorer.Penalize(peerID, "test")
+	scorer.Penalize(peerID, "test")
+	assert.Equal(t, float64(-2), scorer.appSpecificScore(peerID))
+
+	// the penalties decay to the decay-to-zero fraction after an interval
+	now = now.Add(penaltyDecayInterval)
+	assert.InDelta(t, -0.02, scorer.appSpecificScore(peerID), 1e-9)
+
+	// and are forgotten once they decay below it
+	now = now.Add(penaltyDecayInterval)
+	assert.Equal(t, float64(0), scorer.appSpecificScore(peerID))
+	assert.Empty(t, scorer.penalties)
+}
+
+// TestPeerScorerInspect tests that peers whose score drops below the
+// disconnect threshold are blocked by the connection gater.
+func TestPeerScorerInspect(t *testing.T) {
+	scorer := NewPeerScorer(zerolog.Nop(), rootBlockID)
+	cg := newConnGater(zerolog.Nop())
+	scorer.bind(nil, cg)
+
+	misbehaving := peer.ID("misbehaving")
+	noisy := peer.ID("noisy")
+	cg.update([]peer.AddrInfo{{ID: misbehaving}, {ID: noisy}})
+
+	scorer.inspect(map[peer.ID]float64{
+		misbehaving: disconnectThreshold - 1,
+		noisy:       gossipThreshold - 1,
+	})
+
+	score, ok := scorer.Score(misbehaving)
+	require.True(t, ok)
+	assert.Equal(t, float64(disconnectThreshold-1), score)
+
+	assert.False(t, cg.validPeerID(misbehaving))
+	assert.True(t, cg.validPeerID(noisy))
+}
+
+// TestPeerScoringDisconnectsMisbehavingPeer tests that a node disconnects a
+// peer, and refuses its connections, once it has been penalized below the
+// disconnect threshold.
+func TestPeerScoringDisconnectsMisbehavingPeer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := zerolog.Nop()
+	nodes := make([]*Node, 2)
+	identities := make(flow.IdentityList, 2)
+	for i := range nodes {
+		key := generateNetworkingKey(t)
+		identity := unittest.IdentityFixture(unittest.WithNetworkingKey(key.PublicKey()), unittest.WithAddress(defaultAddress))
+
+		scorer := NewPeerScorer(logger, rootBlockID)
+		n, err := NewLibP2PNode(logger,
+			identity.NodeID,
+			identity.Address,
+			NewConnManager(logger, metrics.NewNoopCollector()),
+			key,
+			true,
+			rootBlockID,
+			scorer.PubSubOptions()...)
+		require.NoError(t, err)
+		n.SetStreamHandler(func(network.Stream) {})
+		nodes[i] = n.WithPeerScorer(scorer)
+		defer StopNode(t, n)
+
+		ip, port, err := n.GetIPPort()
+		require.NoError(t, err)
+		identity.Address = ip + ":" + port
+		identities[i] = identity
+	}
+
+	for _, n := range nodes {
+		require.NoError(t, n.UpdateAllowList(identities))
+	}
+
+	// connect the nodes
+	_, err := nodes[0].CreateStream(ctx, *identities[1])
+	require.NoError(t, err)
+
+	// wait for the second node to score the first one
+	peerID := nodes[0].Host().ID()
+	require.Eventually(t, func() bool {
+		_, ok := nodes[1].PeerScorer().Score(peerID)
+		return ok
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// penalize the first node until its score drops below the disconnect threshold
+	for i := 0; i <= disconnectThreshold/penaltyWeight; i++ {
+		nodes[1].Penalize(peerID, "test")
+	}
+
+	require.Eventually(t, func() bool {
+		connected, err := nodes[1].IsConnected(*identities[0])
+		return err == nil && !connected
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// the first node can't reconnect while blocked
+	_, err = nodes[0].CreateStream(ctx, *identities[1])
+	require.Error(t, err)
+}
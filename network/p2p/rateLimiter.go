@@ -0,0 +1,190 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/message"
+)
+
+// modes of communication with separate inbound rate limits
+const (
+	RateLimitModeUnicast = "unicast"
+	RateLimitModePubSub  = "pubsub"
+)
+
+// reasons for which rate limited messages are dropped
+const (
+	rateLimitReasonPeer    = "peer"
+	rateLimitReasonChannel = "channel"
+	rateLimitReasonBlocked = "blocked"
+)
+
+// RateLimit is a token bucket limit, which refills at the given rate of
+// messages per second and holds up to the given burst of messages. The zero
+// value does not limit messages.
+type RateLimit struct {
+	Rate  rate.Limit
+	Burst int
+}
+
+// enabled returns true if the rate limit limits messages.
+func (r RateLimit) enabled() bool {
+	return r.Rate > 0 && r.Burst > 0
+}
+
+// RateLimits are the limits on the inbound messages of a mode of
+// communication.
+type RateLimits struct {
+	// PerSender limits the messages of each sender, across all channels.
+	PerSender RateLimit
+	// PerChannel limits the messages on each channel, across all senders.
+	PerChannel RateLimit
+}
+
+// RateLimiterConfig configures the inbound rate limits of the middleware.
+type RateLimiterConfig struct {
+	Unicast RateLimits
+	PubSub  RateLimits
+	// BlockDuration is the time for which all messages of a sender exceeding
+	// its limit are dropped. Zero disables blocking senders.
+	BlockDuration time.Duration
+}
+
+// DefaultRateLimiterConfig returns the default inbound rate limits. They are
+// generous enough not to affect honest nodes, but keep a single flooding node
+// from starving the others. Senders are not blocked by default.
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	limits := RateLimits{
+		PerSender:  RateLimit{Rate: 1000, Burst: 2000},
+		PerChannel: RateLimit{Rate: 5000, Burst: 10000},
+	}
+	return RateLimiterConfig{
+		Unicast: limits,
+		PubSub:  limits,
+	}
+}
+
+// rateLimiter enforces the inbound rate limits of a mode of communication. The
+// messages of each sender and on each channel are limited by a token bucket.
+// Senders exceeding their limit are optionally blocked for a while.
+type rateLimiter struct {
+	sync.Mutex
+	log           zerolog.Logger
+	metrics       module.NetworkMetrics
+	mode          string
+	limits        RateLimits
+	blockDuration time.Duration
+	senders       map[flow.Identifier]*rate.Limiter
+	channels      map[network.Channel]*rate.Limiter
+	blocked       map[flow.Identifier]time.Time  // blocked senders, by the time they are unblocked
+	onBlock       func(originID flow.Identifier) // called when a sender is blocked
+	now           func() time.Time
+}
+
+func newRateLimiter(log zerolog.Logger,
+	metrics module.NetworkMetrics,
+	mode string,
+	limits RateLimits,
+	blockDuration time.Duration,
+	onBlock func(originID flow.Identifier)) *rateLimiter {
+
+	r := &rateLimiter{
+		log:           log.With().Str("rate_limit_mode", mode).Logger(),
+		metrics:       metrics,
+		mode:          mode,
+		limits:        limits,
+		blockDuration: blockDuration,
+		senders:       make(map[flow.Identifier]*rate.Limiter),
+		channels:      make(map[network.Channel]*rate.Limiter),
+		blocked:       make(map[flow.Identifier]time.Time),
+		onBlock:       onBlock,
+		now:           time.Now,
+	}
+	return r
+}
+
+// allow returns true if the given message is within the rate limits of its
+// sender and channel, else it returns false and the message should be dropped.
+// The origin of the message must have been authenticated.
+func (r *rateLimiter) allow(msg *message.Message) bool {
+	originID := flow.HashToID(msg.OriginID)
+	channel := network.Channel(msg.ChannelID)
+
+	allowed, reason, blocked := r.check(originID, channel)
+	if allowed {
+		return true
+	}
+
+	r.metrics.NetworkRateLimitedMessagesDropped(msg.ChannelID, r.mode, reason)
+	r.log.Debug().
+		Hex("origin_id", msg.OriginID).
+		Str("channel", msg.ChannelID).
+		Str("reason", reason).
+		Msg("dropping rate limited message")
+
+	if blocked {
+		r.log.Warn().
+			Hex("origin_id", msg.OriginID).
+			Dur("block_duration", r.blockDuration).
+			Msg("blocking sender exceeding its rate limit")
+		if r.onBlock != nil {
+			r.onBlock(originID)
+		}
+	}
+
+	return false
+}
+
+// check takes a token from the buckets of the given sender and channel. It
+// returns whether the message is allowed, the reason if it is not, and whether
+// the sender was blocked just now.
+func (r *rateLimiter) check(originID flow.Identifier, channel network.Channel) (bool, string, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now()
+	if until, ok := r.blocked[originID]; ok {
+		if now.Before(until) {
+			return false, rateLimitReasonBlocked, false
+		}
+		delete(r.blocked, originID)
+	}
+
+	if r.limits.PerSender.enabled() {
+		limiter, ok := r.senders[originID]
+		if !ok {
+			limiter = rate.NewLimiter(r.limits.PerSender.Rate, r.limits.PerSender.Burst)
+			r.senders[originID] = limiter
+		}
+		if !limiter.AllowN(now, 1) {
+			if r.blockDuration <= 0 {
+				return false, rateLimitReasonPeer, false
+			}
+			r.blocked[originID] = now.Add(r.blockDuration)
+			return false, rateLimitReasonPeer, true
+		}
+	}
+
+	// messages on unknown channels are dropped by the validators, so they
+	// don't get a limiter of their own
+	if r.limits.PerChannel.enabled() && engine.Exists(channel) {
+		limiter, ok := r.channels[channel]
+		if !ok {
+			limiter = rate.NewLimiter(r.limits.PerChannel.Rate, r.limits.PerChannel.Burst)
+			r.channels[channel] = limiter
+		}
+		if !limiter.AllowN(now, 1) {
+			return false, rateLimitReasonChannel, false
+		}
+	}
+
+	return true, "", false
+}
@@ -0,0 +1,121 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	mockmodule "github.com/onflow/flow-go/module/mock"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func rateLimitedMessage(originID flow.Identifier, channel string) *message.Message {
+	return &message.Message{
+		ChannelID: channel,
+		OriginID:  originID[:],
+	}
+}
+
+// TestRateLimiterPerSender tests that the messages of each sender are limited
+// separately, and that the limit refills over time.
+func TestRateLimiterPerSender(t *testing.T) {
+	now := time.Now()
+	collector := &mockmodule.NetworkMetrics{}
+	limits := RateLimits{PerSender: RateLimit{Rate: 1, Burst: 2}}
+	limiter := newRateLimiter(zerolog.Nop(), collector, RateLimitModeUnicast, limits, 0, nil)
+	limiter.now = func() time.Time { return now }
+
+	flooding := unittest.IdentifierFixture()
+	other := unittest.IdentifierFixture()
+	channel := engine.PushBlocks.String()
+
+	collector.On("NetworkRateLimitedMessagesDropped", channel, RateLimitModeUnicast, rateLimitReasonPeer).Once()
+
+	assert.True(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+	assert.True(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+	assert.False(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+
+	// other senders are not affected
+	assert.True(t, limiter.allow(rateLimitedMessage(other, channel)))
+
+	// the bucket of the flooding sender refills
+	now = now.Add(time.Second)
+	assert.True(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+
+	collector.AssertExpectations(t)
+}
+
+// TestRateLimiterPerChannel tests that the messages on each channel are
+// limited across all senders.
+func TestRateLimiterPerChannel(t *testing.T) {
+	limits := RateLimits{PerChannel: RateLimit{Rate: 1, Burst: 1}}
+	limiter := newRateLimiter(zerolog.Nop(), metrics.NewNoopCollector(), RateLimitModePubSub, limits, 0, nil)
+	limiter.now = func() time.Time { return time.Unix(0, 0) }
+
+	channel := engine.PushBlocks.String()
+	assert.True(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), channel)))
+	assert.False(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), channel)))
+
+	// other channels are not affected
+	assert.True(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), engine.PushReceipts.String())))
+
+	// cluster channels are limited as any other channel
+	cluster := engine.ChannelSyncCluster(flow.ChainID("cluster")).String()
+	assert.True(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), cluster)))
+	assert.False(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), cluster)))
+
+	// unknown channels are left to the validators
+	assert.True(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), "unknown")))
+	assert.True(t, limiter.allow(rateLimitedMessage(unittest.IdentifierFixture(), "unknown")))
+}
+
+// TestRateLimiterBlocking tests that senders exceeding their limit are blocked
+// for the block duration.
+func TestRateLimiterBlocking(t *testing.T) {
+	now := time.Now()
+	collector := &mockmodule.NetworkMetrics{}
+	collector.On("NetworkRateLimitedMessagesDropped", mock.Anything, RateLimitModeUnicast, mock.Anything)
+
+	var blocked []flow.Identifier
+	onBlock := func(originID flow.Identifier) {
+		blocked = append(blocked, originID)
+	}
+
+	limits := RateLimits{PerSender: RateLimit{Rate: 1, Burst: 1}}
+	limiter := newRateLimiter(zerolog.Nop(), collector, RateLimitModeUnicast, limits, time.Minute, onBlock)
+	limiter.now = func() time.Time { return now }
+
+	flooding := unittest.IdentifierFixture()
+	channel := engine.PushBlocks.String()
+
+	assert.True(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+	assert.False(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+	assert.Equal(t, []flow.Identifier{flooding}, blocked)
+
+	// the sender remains blocked although its bucket refilled
+	now = now.Add(30 * time.Second)
+	assert.False(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+	collector.AssertCalled(t, "NetworkRateLimitedMessagesDropped", channel, RateLimitModeUnicast, rateLimitReasonBlocked)
+
+	// and is unblocked after the block duration
+	now = now.Add(30 * time.Second)
+	assert.True(t, limiter.allow(rateLimitedMessage(flooding, channel)))
+	assert.Len(t, blocked, 1)
+}
+
+// TestRateLimiterDisabled tests that zero rate limits don't limit messages.
+func TestRateLimiterDisabled(t *testing.T) {
+	limiter := newRateLimiter(zerolog.Nop(), metrics.NewNoopCollector(), RateLimitModeUnicast, RateLimits{}, time.Minute, nil)
+
+	originID := unittest.IdentifierFixture()
+	for i := 0; i < 100; i++ {
+		assert.True(t, limiter.allow(rateLimitedMessage(originID, engine.PushBlocks.String())))
+	}
+}
@@ -23,12 +23,14 @@ type readConnection struct {
 	metrics    module.NetworkMetrics
 	maxMsgSize int
 	callback   func(msg *message.Message)
+	penalize   func(reason string) // penalizes the remote peer for sending invalid messages
 }
 
 // newReadConnection creates a new readConnection
 func newReadConnection(ctx context.Context,
 	stream libp2pnetwork.Stream,
 	callback func(msg *message.Message),
+	penalize func(reason string),
 	log zerolog.Logger,
 	metrics module.NetworkMetrics,
 	maxMsgSize int) *readConnection {
@@ -43,6 +45,7 @@ func newReadConnection(ctx context.Context,
 		ctx:        ctx,
 		stream:     stream,
 		callback:   callback,
+		penalize:   penalize,
 		log:        streamLogger,
 		metrics:    metrics,
 		maxMsgSize: maxMsgSize,
@@ -96,6 +99,7 @@ func (rc *readConnection) receiveLoop(wg *sync.WaitGroup) {
 				Str("channel", msg.ChannelID).
 				Int("maxSize", maxSize).
 				Msg("received message exceeded permissible message maxSize")
+			rc.penalize("oversized_message")
 			return
 		}
 
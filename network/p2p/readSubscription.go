@@ -76,18 +76,22 @@ func (r *readSubscription) receiveLoop(wg *sync.WaitGroup) {
 			return
 		}
 
-		var msg message.Message
-		// convert the incoming raw message payload to Message type
-		err = msg.Unmarshal(rawMsg.Data)
-		if err != nil {
-			r.log.Err(err).Str("topic_message", msg.String()).Msg("failed to unmarshal message")
-			return
+		// messages are usually decoded by the topic validator already
+		msg, ok := rawMsg.ValidatorData.(*message.Message)
+		if !ok {
+			msg = &message.Message{}
+			// convert the incoming raw message payload to Message type
+			err = msg.Unmarshal(rawMsg.Data)
+			if err != nil {
+				r.log.Err(err).Str("topic_message", msg.String()).Msg("failed to unmarshal message")
+				return
+			}
 		}
 
 		// log metrics
 		r.metrics.NetworkMessageReceived(msg.Size(), msg.ChannelID, msg.Type)
 
 		// call the callback
-		r.callback(&msg)
+		r.callback(msg)
 	}
 }
@@ -0,0 +1,123 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTrafficWindow is the length of the window over which the message
+// rates of the channels are measured.
+const DefaultTrafficWindow = 10 * time.Second
+
+// ChannelTraffic is the rate of the messages received and sent on a channel,
+// measured over the last complete window.
+type ChannelTraffic struct {
+	Channel             string  `json:"channel"`
+	InboundMessageRate  float64 `json:"inbound_messages_per_second"`
+	InboundByteRate     float64 `json:"inbound_bytes_per_second"`
+	OutboundMessageRate float64 `json:"outbound_messages_per_second"`
+	OutboundByteRate    float64 `json:"outbound_bytes_per_second"`
+}
+
+// trafficCount counts the messages and bytes of a channel in a window.
+type trafficCount struct {
+	inboundMessages  uint64
+	inboundBytes     uint64
+	outboundMessages uint64
+	outboundBytes    uint64
+}
+
+// trafficMeter measures the per-channel message rates of the middleware over
+// fixed windows. Rates are reported for the last complete window, so that they
+// don't depend on how far the current window has progressed.
+type trafficMeter struct {
+	sync.Mutex
+	window  time.Duration
+	start   time.Time                // start of the current window
+	current map[string]*trafficCount // counts of the current window
+	last    map[string]*trafficCount // counts of the last complete window
+	now     func() time.Time
+}
+
+func newTrafficMeter(window time.Duration) *trafficMeter {
+	m := &trafficMeter{
+		window:  window,
+		current: make(map[string]*trafficCount),
+		last:    make(map[string]*trafficCount),
+		now:     time.Now,
+	}
+	m.start = m.now()
+	return m
+}
+
+// inbound counts a message of the given size received on the channel.
+func (m *trafficMeter) inbound(channel string, size int) {
+	m.Lock()
+	defer m.Unlock()
+	count := m.count(channel)
+	count.inboundMessages++
+	count.inboundBytes += uint64(size)
+}
+
+// outbound counts a message of the given size sent on the channel.
+func (m *trafficMeter) outbound(channel string, size int) {
+	m.Lock()
+	defer m.Unlock()
+	count := m.count(channel)
+	count.outboundMessages++
+	count.outboundBytes += uint64(size)
+}
+
+// count returns the count of the channel in the current window. It must be
+// called with the lock held.
+func (m *trafficMeter) count(channel string) *trafficCount {
+	m.rotate()
+	count, ok := m.current[channel]
+	if !ok {
+		count = &trafficCount{}
+		m.current[channel] = count
+	}
+	return count
+}
+
+// rotate starts a new window once the current one is complete. If no message
+// was counted for more than a window, the last window is empty. It must be
+// called with the lock held.
+func (m *trafficMeter) rotate() {
+	elapsed := m.now().Sub(m.start)
+	if elapsed < m.window {
+		return
+	}
+	if elapsed < 2*m.window {
+		m.last = m.current
+	} else {
+		m.last = make(map[string]*trafficCount)
+	}
+	m.current = make(map[string]*trafficCount)
+	m.start = m.start.Add(elapsed - elapsed%m.window)
+}
+
+// rates returns the message rates of the channels with traffic in the last
+// complete window, ordered by channel.
+func (m *trafficMeter) rates() []ChannelTraffic {
+	m.Lock()
+	defer m.Unlock()
+	m.rotate()
+
+	seconds := m.window.Seconds()
+	rates := make([]ChannelTraffic, 0, len(m.last))
+	for channel, count := range m.last {
+		rates = append(rates, ChannelTraffic{
+			Channel:             channel,
+			InboundMessageRate:  float64(count.inboundMessages) / seconds,
+			InboundByteRate:     float64(count.inboundBytes) / seconds,
+			OutboundMessageRate: float64(count.outboundMessages) / seconds,
+			OutboundByteRate:    float64(count.outboundBytes) / seconds,
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Channel < rates[j].Channel
+	})
+	return rates
+}
@@ -0,0 +1,41 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTrafficMeter tests that the message rates of the channels are reported
+// for the last complete window.
+func TestTrafficMeter(t *testing.T) {
+	now := time.Now()
+	meter := newTrafficMeter(10 * time.Second)
+	meter.now = func() time.Time { return now }
+	meter.start = now
+
+	meter.inbound("blocks", 100)
+	meter.inbound("blocks", 100)
+	meter.outbound("receipts", 50)
+
+	// the current window is not reported until it is complete
+	assert.Empty(t, meter.rates())
+
+	now = now.Add(15 * time.Second)
+	meter.inbound("blocks", 10)
+	assert.Equal(t, []ChannelTraffic{
+		{Channel: "blocks", InboundMessageRate: 0.2, InboundByteRate: 20},
+		{Channel: "receipts", OutboundMessageRate: 0.1, OutboundByteRate: 5},
+	}, meter.rates())
+
+	// windows are aligned to the first one
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, []ChannelTraffic{
+		{Channel: "blocks", InboundMessageRate: 0.1, InboundByteRate: 1},
+	}, meter.rates())
+
+	// without traffic in the last window, no rates are reported
+	now = now.Add(20 * time.Second)
+	assert.Empty(t, meter.rates())
+}
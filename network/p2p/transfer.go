@@ -0,0 +1,437 @@
+package p2p
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network"
+)
+
+const (
+	// DefaultTransferFrameSize is the size of the frames large payloads are
+	// streamed in.
+	DefaultTransferFrameSize = 1 << 20 // 1 MiB
+
+	// DefaultTransferResumeTimeout is the time an interrupted inbound transfer
+	// waits for its sender to reconnect, before it fails.
+	DefaultTransferResumeTimeout = 30 * time.Second
+
+	// maxTransferFrameSize is the largest frame size accepted from senders.
+	maxTransferFrameSize = 4 << 20 // 4 MiB
+
+	// maxTransferHeaderSize is the largest encoded transfer header accepted.
+	maxTransferHeaderSize = 1 << 10 // 1 KiB
+
+	// transferFrameTimeout is the time to read or write a single frame.
+	transferFrameTimeout = 30 * time.Second
+
+	// transferFrameBuffer is the number of frames received ahead of the
+	// consumer of a transfer, which bounds the memory held by a transfer.
+	transferFrameBuffer = 4
+
+	// maxTransferAttempts is the number of streams a transfer is attempted on.
+	maxTransferAttempts = 3
+
+	// maxInboundTransfersPerOrigin is the number of transfers received from
+	// the same origin at a time, so that a single node can't hold an unbounded
+	// number of frame buffers and consumers.
+	maxInboundTransfersPerOrigin = 4
+
+	// transferProtocolSuffix is appended to the Flow protocol ID to get the
+	// protocol ID of transfers.
+	transferProtocolSuffix = "transfer/1"
+)
+
+var (
+	// errTransferExists is returned when adding a transfer which is known
+	// already, e.g. when the sender raced itself resuming the transfer.
+	errTransferExists = errors.New("transfer exists already")
+
+	// errTooManyTransfers is returned when adding a transfer from an origin
+	// which already has the maximum number of inbound transfers.
+	errTooManyTransfers = errors.New("too many inbound transfers from origin")
+)
+
+// crc32c is the table of the frame checksums.
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// transferProtocolID returns the libp2p protocol ID of transfers.
+func transferProtocolID(pid protocol.ID) protocol.ID {
+	return protocol.ID(fmt.Sprintf("%s/%s", pid, transferProtocolSuffix))
+}
+
+// The transfer protocol works as follows:
+//  1. the sender opens a stream and writes the transfer header
+//  2. the recipient acknowledges the header with the index of the first frame
+//     it is missing, which is zero unless the transfer is resumed
+//  3. the sender writes the frames from that index on, each with its index,
+//     length and checksum
+//  4. the recipient acknowledges the last frame with the number of frames,
+//     after which the sender closes the stream
+// If the stream fails, the sender resumes the transfer on a new stream.
+
+// transferHeader describes a transfer to its recipient.
+type transferHeader struct {
+	TransferID flow.Identifier
+	ChannelID  string
+	OriginID   flow.Identifier
+	Type       string
+	Size       uint64
+	FrameSize  uint32
+}
+
+// frames returns the number of frames of the transfer.
+func (h transferHeader) frames() uint32 {
+	return uint32((h.Size + uint64(h.FrameSize) - 1) / uint64(h.FrameSize))
+}
+
+// frameLength returns the length of the frame with the given index.
+func (h transferHeader) frameLength(index uint32) uint32 {
+	if index == h.frames()-1 && h.Size%uint64(h.FrameSize) != 0 {
+		return uint32(h.Size % uint64(h.FrameSize))
+	}
+	return h.FrameSize
+}
+
+// validate checks that the header describes a transfer which can be received.
+func (h transferHeader) validate() error {
+	if h.FrameSize == 0 || h.FrameSize > maxTransferFrameSize {
+		return fmt.Errorf("invalid frame size %d", h.FrameSize)
+	}
+	if h.Size/uint64(h.FrameSize) >= 1<<32 {
+		return fmt.Errorf("too many frames for size %d", h.Size)
+	}
+	return nil
+}
+
+func writeTransferHeader(w io.Writer, header transferHeader) error {
+	data, err := cbor.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("could not encode transfer header: %w", err)
+	}
+	err = binary.Write(w, binary.BigEndian, uint32(len(data)))
+	if err != nil {
+		return fmt.Errorf("could not write transfer header length: %w", err)
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write transfer header: %w", err)
+	}
+	return nil
+}
+
+func readTransferHeader(r io.Reader) (transferHeader, error) {
+	var header transferHeader
+	var length uint32
+	err := binary.Read(r, binary.BigEndian, &length)
+	if err != nil {
+		return header, fmt.Errorf("could not read transfer header length: %w", err)
+	}
+	if length > maxTransferHeaderSize {
+		return header, fmt.Errorf("transfer header length %d exceeds maximum %d", length, maxTransferHeaderSize)
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		return header, fmt.Errorf("could not read transfer header: %w", err)
+	}
+	err = cbor.Unmarshal(data, &header)
+	if err != nil {
+		return header, fmt.Errorf("could not decode transfer header: %w", err)
+	}
+	return header, nil
+}
+
+func writeTransferAck(w io.Writer, next uint32) error {
+	return binary.Write(w, binary.BigEndian, next)
+}
+
+func readTransferAck(r io.Reader) (uint32, error) {
+	var next uint32
+	err := binary.Read(r, binary.BigEndian, &next)
+	return next, err
+}
+
+// writeFrame writes the frame with the given index and its checksum.
+func writeFrame(w io.Writer, index uint32, data []byte) error {
+	var prefix [12]byte
+	binary.BigEndian.PutUint32(prefix[0:4], index)
+	binary.BigEndian.PutUint32(prefix[4:8], uint32(len(data)))
+	binary.BigEndian.PutUint32(prefix[8:12], crc32.Checksum(data, crc32c))
+	_, err := w.Write(prefix[:])
+	if err != nil {
+		return fmt.Errorf("could not write frame prefix: %w", err)
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write frame data: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads the frame with the given index of the transfer, and checks
+// it against its checksum.
+func readFrame(r io.Reader, header transferHeader, index uint32) ([]byte, error) {
+	var prefix [12]byte
+	_, err := io.ReadFull(r, prefix[:])
+	if err != nil {
+		return nil, fmt.Errorf("could not read frame prefix: %w", err)
+	}
+	if got := binary.BigEndian.Uint32(prefix[0:4]); got != index {
+		return nil, fmt.Errorf("unexpected frame index %d, expected %d", got, index)
+	}
+	if length := binary.BigEndian.Uint32(prefix[4:8]); length != header.frameLength(index) {
+		return nil, fmt.Errorf("unexpected length %d of frame %d, expected %d", length, index, header.frameLength(index))
+	}
+	data := make([]byte, header.frameLength(index))
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not read frame data: %w", err)
+	}
+	if crc32.Checksum(data, crc32c) != binary.BigEndian.Uint32(prefix[8:12]) {
+		return nil, fmt.Errorf("checksum mismatch of frame %d", index)
+	}
+	return data, nil
+}
+
+var _ network.Transfer = (*inboundTransfer)(nil)
+
+// inboundTransfer is a transfer being received. Its frames are received on one
+// stream at a time, and handed to the consumer through a bounded buffer. If the
+// stream fails, the transfer waits for the sender to resume it on a new stream.
+type inboundTransfer struct {
+	sync.Mutex
+	ctx      context.Context
+	header   transferHeader
+	frames   chan []byte   // frames received ahead of the consumer
+	failed   chan struct{} // closed when the transfer can't be completed
+	done     chan struct{} // closed once the consumer is done with the transfer
+	err      error         // reason of the failure
+	next     uint32        // index of the next frame to be received
+	attached bool          // whether a stream is receiving the frames
+	resume   *time.Timer   // fails the transfer if it is not resumed in time
+	returned uint32        // number of frames returned to the consumer
+}
+
+func newInboundTransfer(ctx context.Context, header transferHeader) *inboundTransfer {
+	t := &inboundTransfer{
+		ctx:    ctx,
+		header: header,
+		frames: make(chan []byte, transferFrameBuffer),
+		failed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	return t
+}
+
+func (t *inboundTransfer) ID() flow.Identifier {
+	return t.header.TransferID
+}
+
+func (t *inboundTransfer) Channel() network.Channel {
+	return network.Channel(t.header.ChannelID)
+}
+
+func (t *inboundTransfer) Type() string {
+	return t.header.Type
+}
+
+func (t *inboundTransfer) Size() uint64 {
+	return t.header.Size
+}
+
+// Next returns the next frame of the payload, blocking until it is received.
+// Frames received before a failure are still returned.
+func (t *inboundTransfer) Next() ([]byte, error) {
+	if t.returned == t.header.frames() {
+		return nil, io.EOF
+	}
+	select {
+	case frame := <-t.frames:
+		t.returned++
+		return frame, nil
+	default:
+	}
+	select {
+	case frame := <-t.frames:
+		t.returned++
+		return frame, nil
+	case <-t.failed:
+		return nil, t.err
+	case <-t.ctx.Done():
+		return nil, t.ctx.Err()
+	}
+}
+
+// attach attaches a stream to the transfer, and returns the index of the next
+// frame to be received on it. It returns false if the transfer is receiving
+// frames on another stream, has failed, or its consumer is done.
+func (t *inboundTransfer) attach() (uint32, bool) {
+	t.Lock()
+	defer t.Unlock()
+	if t.attached || t.isClosed(t.failed) || t.isClosed(t.done) {
+		return 0, false
+	}
+	if t.resume != nil {
+		t.resume.Stop()
+		t.resume = nil
+	}
+	t.attached = true
+	return t.next, true
+}
+
+// detach detaches the stream from the transfer after it failed with the given
+// error. The transfer fails unless it is resumed within the given timeout.
+func (t *inboundTransfer) detach(err error, timeout time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+	t.attached = false
+	t.resume = time.AfterFunc(timeout, func() {
+		t.fail(fmt.Errorf("transfer was not resumed within %s: %w", timeout, err))
+	})
+}
+
+// deliver hands the next received frame to the consumer, blocking while the
+// buffer is full. It returns false if the consumer is done with the transfer.
+func (t *inboundTransfer) deliver(frame []byte) bool {
+	select {
+	case t.frames <- frame:
+	case <-t.done:
+		return false
+	case <-t.ctx.Done():
+		return false
+	}
+	t.Lock()
+	t.next++
+	t.Unlock()
+	return true
+}
+
+// release releases the stream from the transfer once all frames have been
+// received on it.
+func (t *inboundTransfer) release() {
+	t.Lock()
+	defer t.Unlock()
+	t.attached = false
+}
+
+// fail fails the transfer with the given error, unless it is completed.
+func (t *inboundTransfer) fail(err error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.attached || t.next == t.header.frames() || t.isClosed(t.failed) {
+		return
+	}
+	t.err = err
+	close(t.failed)
+}
+
+// finish marks the consumer as done with the transfer.
+func (t *inboundTransfer) finish() {
+	t.Lock()
+	defer t.Unlock()
+	if t.resume != nil {
+		t.resume.Stop()
+	}
+	close(t.done)
+}
+
+// complete returns true if all frames have been received.
+func (t *inboundTransfer) complete() bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.next == t.header.frames()
+}
+
+func (t *inboundTransfer) isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// transferKey identifies an inbound transfer, as transfer IDs are only unique
+// for their origin.
+type transferKey struct {
+	originID   flow.Identifier
+	transferID flow.Identifier
+}
+
+// inboundTransfers keeps track of the inbound transfers of the middleware.
+// Transfers are kept for the resume timeout after their consumer is done, so
+// that a sender resuming a completed transfer is told it is complete. Only the
+// transfers whose consumer is not done count towards the limit per origin.
+type inboundTransfers struct {
+	sync.Mutex
+	resumeTimeout time.Duration
+	maxPerOrigin  uint
+	transfers     map[transferKey]*inboundTransfer
+	active        map[flow.Identifier]uint // number of transfers being consumed by origin
+}
+
+func newInboundTransfers(resumeTimeout time.Duration, maxPerOrigin uint) *inboundTransfers {
+	return &inboundTransfers{
+		resumeTimeout: resumeTimeout,
+		maxPerOrigin:  maxPerOrigin,
+		transfers:     make(map[transferKey]*inboundTransfer),
+		active:        make(map[flow.Identifier]uint),
+	}
+}
+
+// get returns the transfer with the given key, if it is known.
+func (it *inboundTransfers) get(key transferKey) (*inboundTransfer, bool) {
+	it.Lock()
+	defer it.Unlock()
+	t, ok := it.transfers[key]
+	return t, ok
+}
+
+// add adds a new transfer with the given header. It returns errTransferExists
+// if a transfer with the same key is known already, and errTooManyTransfers if
+// the origin of the transfer has the maximum number of transfers being
+// consumed.
+func (it *inboundTransfers) add(ctx context.Context, key transferKey, header transferHeader) (*inboundTransfer, error) {
+	it.Lock()
+	defer it.Unlock()
+	if _, ok := it.transfers[key]; ok {
+		return nil, errTransferExists
+	}
+	if it.active[key.originID] >= it.maxPerOrigin {
+		return nil, errTooManyTransfers
+	}
+	t := newInboundTransfer(ctx, header)
+	it.transfers[key] = t
+	it.active[key.originID]++
+	return t, nil
+}
+
+// finish marks the consumer of the given transfer as done, and forgets the
+// transfer after the resume timeout.
+func (it *inboundTransfers) finish(key transferKey, t *inboundTransfer) {
+	t.finish()
+
+	it.Lock()
+	it.active[key.originID]--
+	if it.active[key.originID] == 0 {
+		delete(it.active, key.originID)
+	}
+	it.Unlock()
+
+	time.AfterFunc(it.resumeTimeout, func() {
+		it.Lock()
+		defer it.Unlock()
+		delete(it.transfers, key)
+	})
+}
@@ -0,0 +1,220 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func transferHeaderFixture(size uint64, frameSize uint32) transferHeader {
+	return transferHeader{
+		TransferID: unittest.IdentifierFixture(),
+		ChannelID:  engine.ProvideChunks.String(),
+		OriginID:   unittest.IdentifierFixture(),
+		Type:       "messages.ChunkDataResponse",
+		Size:       size,
+		FrameSize:  frameSize,
+	}
+}
+
+// TestTransferHeaderFrames tests the number and lengths of the frames of a
+// transfer.
+func TestTransferHeaderFrames(t *testing.T) {
+	header := transferHeaderFixture(10, 4)
+	assert.Equal(t, uint32(3), header.frames())
+	assert.Equal(t, uint32(4), header.frameLength(0))
+	assert.Equal(t, uint32(4), header.frameLength(1))
+	assert.Equal(t, uint32(2), header.frameLength(2))
+
+	header = transferHeaderFixture(8, 4)
+	assert.Equal(t, uint32(2), header.frames())
+	assert.Equal(t, uint32(4), header.frameLength(1))
+
+	header = transferHeaderFixture(0, 4)
+	assert.Equal(t, uint32(0), header.frames())
+
+	assert.NoError(t, transferHeaderFixture(10, DefaultTransferFrameSize).validate())
+	assert.Error(t, transferHeaderFixture(10, 0).validate())
+	assert.Error(t, transferHeaderFixture(10, maxTransferFrameSize+1).validate())
+	assert.Error(t, transferHeaderFixture(1<<40, 1).validate())
+}
+
+// TestTransferWireFormat tests that headers and frames are read as written,
+// and that corrupted frames are detected.
+func TestTransferWireFormat(t *testing.T) {
+	header := transferHeaderFixture(10, 4)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTransferHeader(&buf, header))
+	decoded, err := readTransferHeader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, header, decoded)
+
+	t.Run("frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeFrame(&buf, 2, []byte{1, 2}))
+		frame, err := readFrame(&buf, header, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2}, frame)
+	})
+
+	t.Run("unexpected index", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeFrame(&buf, 0, []byte{1, 2, 3, 4}))
+		_, err := readFrame(&buf, header, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("unexpected length", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeFrame(&buf, 0, []byte{1, 2}))
+		_, err := readFrame(&buf, header, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeFrame(&buf, 0, []byte{1, 2, 3, 4}))
+		data := buf.Bytes()
+		data[len(data)-1] ^= 0xff
+		_, err := readFrame(bytes.NewReader(data), header, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("oversized header", func(t *testing.T) {
+		header := transferHeaderFixture(10, 4)
+		header.Type = string(make([]byte, maxTransferHeaderSize))
+		var buf bytes.Buffer
+		require.NoError(t, writeTransferHeader(&buf, header))
+		_, err := readTransferHeader(&buf)
+		assert.Error(t, err)
+	})
+}
+
+// TestInboundTransferResume tests that an inbound transfer interrupted by a
+// stream failure is completed on a new stream, without its consumer noticing.
+func TestInboundTransferResume(t *testing.T) {
+	tr := newInboundTransfer(context.Background(), transferHeaderFixture(10, 4))
+
+	next, ok := tr.attach()
+	require.True(t, ok)
+	assert.Equal(t, uint32(0), next)
+
+	// only one stream receives the frames at a time
+	_, ok = tr.attach()
+	assert.False(t, ok)
+
+	require.True(t, tr.deliver([]byte{0, 1, 2, 3}))
+	tr.detach(errors.New("stream reset"), time.Minute)
+
+	next, ok = tr.attach()
+	require.True(t, ok)
+	assert.Equal(t, uint32(1), next)
+	require.True(t, tr.deliver([]byte{4, 5, 6, 7}))
+	require.True(t, tr.deliver([]byte{8, 9}))
+	tr.release()
+	assert.True(t, tr.complete())
+
+	var payload []byte
+	for {
+		frame, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		payload = append(payload, frame...)
+	}
+	assert.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, payload)
+}
+
+// TestInboundTransferFailure tests that an inbound transfer fails if it is not
+// resumed in time, after its received frames have been returned.
+func TestInboundTransferFailure(t *testing.T) {
+	tr := newInboundTransfer(context.Background(), transferHeaderFixture(10, 4))
+
+	_, ok := tr.attach()
+	require.True(t, ok)
+	require.True(t, tr.deliver([]byte{0, 1, 2, 3}))
+	tr.detach(errors.New("stream reset"), 10*time.Millisecond)
+
+	frame, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2, 3}, frame)
+
+	_, err = tr.Next()
+	assert.Error(t, err)
+
+	// a failed transfer can't be resumed
+	_, ok = tr.attach()
+	assert.False(t, ok)
+}
+
+// TestInboundTransferAbandoned tests that the delivery of frames stops once
+// the consumer is done with the transfer.
+func TestInboundTransferAbandoned(t *testing.T) {
+	tr := newInboundTransfer(context.Background(), transferHeaderFixture(100, 4))
+
+	_, ok := tr.attach()
+	require.True(t, ok)
+	for i := 0; i < transferFrameBuffer; i++ {
+		require.True(t, tr.deliver([]byte{0, 1, 2, 3}))
+	}
+
+	delivered := make(chan bool)
+	go func() {
+		delivered <- tr.deliver([]byte{0, 1, 2, 3})
+	}()
+	tr.finish()
+
+	select {
+	case ok := <-delivered:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("delivery did not stop")
+	}
+}
+
+// TestInboundTransfersPerOrigin tests that only a limited number of transfers
+// from the same origin are consumed at a time.
+func TestInboundTransfersPerOrigin(t *testing.T) {
+	transfers := newInboundTransfers(time.Minute, 2)
+	originID := unittest.IdentifierFixture()
+
+	add := func(originID flow.Identifier) (transferKey, *inboundTransfer, error) {
+		header := transferHeaderFixture(10, 4)
+		header.OriginID = originID
+		key := transferKey{originID: originID, transferID: header.TransferID}
+		tr, err := transfers.add(context.Background(), key, header)
+		return key, tr, err
+	}
+
+	key, tr, err := add(originID)
+	require.NoError(t, err)
+	_, _, err = add(originID)
+	require.NoError(t, err)
+
+	// the limit is reached for the origin, but not for other origins
+	_, _, err = add(originID)
+	assert.True(t, errors.Is(err, errTooManyTransfers))
+	_, _, err = add(unittest.IdentifierFixture())
+	assert.NoError(t, err)
+
+	// a transfer can't be added twice
+	_, err = transfers.add(context.Background(), key, tr.header)
+	assert.True(t, errors.Is(err, errTransferExists))
+
+	// once a consumer is done, another transfer can be received
+	transfers.finish(key, tr)
+	_, _, err = add(originID)
+	assert.NoError(t, err)
+}
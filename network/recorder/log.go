@@ -0,0 +1,306 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/message"
+)
+
+// logMagic starts every message log, and identifies the version of its format.
+const logMagic = "flow-netlog/1\n"
+
+const (
+	// maxRecordSize is the largest encoded record accepted when reading a log.
+	maxRecordSize = 64 << 20 // 64 MiB
+
+	// maxMessageSize is the largest message recorded with its payload. Larger
+	// messages, i.e. large unicast messages, are recorded without their
+	// payload, so that their records can be read back.
+	maxMessageSize = maxRecordSize - 1<<10
+
+	// DefaultFlushInterval is the interval at which buffered records are
+	// written to the log, so that a crash of the recording node loses at most
+	// the messages of the last interval.
+	DefaultFlushInterval = time.Second
+)
+
+// Direction is the direction in which a recorded message passed the middleware.
+type Direction uint8
+
+const (
+	Inbound Direction = iota + 1
+	Outbound
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Inbound:
+		return "inbound"
+	case Outbound:
+		return "outbound"
+	default:
+		return fmt.Sprintf("unknown(%d)", d)
+	}
+}
+
+// Record is a message recorded by the middleware.
+type Record struct {
+	Timestamp   time.Time
+	Direction   Direction
+	Message     *message.Message
+	Truncated   bool // the payload of the message exceeded the maximum record size and was not recorded
+	PayloadSize int  // the size of the payload of the message, even if it was truncated
+}
+
+// Channel returns the channel the recorded message was sent on.
+func (r *Record) Channel() network.Channel {
+	return network.Channel(r.Message.ChannelID)
+}
+
+// OriginID returns the node ID of the origin of the recorded message.
+func (r *Record) OriginID() flow.Identifier {
+	return flow.HashToID(r.Message.OriginID)
+}
+
+// encodedRecord is the compact encoding of a record in the log. The message is
+// kept in its wire encoding.
+type encodedRecord struct {
+	Timestamp int64     `cbor:"1,keyasint"`
+	Direction Direction `cbor:"2,keyasint"`
+	Message   []byte    `cbor:"3,keyasint"`
+
+	// the size of the payload of truncated messages, which is not recorded
+	TruncatedSize int `cbor:"4,keyasint,omitempty"`
+}
+
+// Writer writes records to a message log. Each record is prefixed by its
+// length, so that the log can be read back incrementally. It is safe for
+// concurrent use.
+type Writer struct {
+	sync.Mutex
+	w       *bufio.Writer
+	closer  io.Closer
+	started bool
+	closed  bool
+	done    chan struct{}
+	now     func() time.Time
+}
+
+// NewWriter returns a writer of a message log to the given writer. The log is
+// buffered, and flushed every DefaultFlushInterval until it is closed. The
+// given writer is closed along with the log if it is an io.Closer.
+func NewWriter(w io.Writer) *Writer {
+	closer, _ := w.(io.Closer)
+	writer := &Writer{
+		w:      bufio.NewWriter(w),
+		closer: closer,
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+	go writer.flushLoop(DefaultFlushInterval)
+	return writer
+}
+
+// flushLoop periodically flushes the buffered records until the log is closed.
+func (w *Writer) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			// a failed flush is returned by the next write or flush
+			_ = w.Flush()
+		}
+	}
+}
+
+// OpenFile returns a writer appending to the message log in the given file,
+// which is created if it doesn't exist, so that restarts of the recording
+// node extend the same log.
+func OpenFile(path string) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open message log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("could not stat message log: %w", err)
+	}
+
+	w := NewWriter(file)
+	// the header of a non-empty log was written when it was created
+	w.started = info.Size() > 0
+	return w, nil
+}
+
+// Write appends the given message to the log.
+func (w *Writer) Write(direction Direction, msg *message.Message) error {
+	data, err := msg.Marshal()
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %w", err)
+	}
+
+	// record oversized messages without their payload
+	truncatedSize := 0
+	if len(data) > maxMessageSize {
+		truncated := *msg
+		truncated.Payload = nil
+		data, err = truncated.Marshal()
+		if err != nil {
+			return fmt.Errorf("could not marshal truncated message: %w", err)
+		}
+		truncatedSize = len(msg.Payload)
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	record, err := cbor.Marshal(encodedRecord{
+		Timestamp:     w.now().UnixNano(),
+		Direction:     direction,
+		Message:       data,
+		TruncatedSize: truncatedSize,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode record: %w", err)
+	}
+
+	if !w.started {
+		_, err = w.w.WriteString(logMagic)
+		if err != nil {
+			return fmt.Errorf("could not write log header: %w", err)
+		}
+		w.started = true
+	}
+
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(record)))
+	_, err = w.w.Write(prefix[:n])
+	if err != nil {
+		return fmt.Errorf("could not write record length: %w", err)
+	}
+	_, err = w.w.Write(record)
+	if err != nil {
+		return fmt.Errorf("could not write record: %w", err)
+	}
+	return nil
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.w.Flush()
+}
+
+// Close flushes the buffered records and closes the underlying writer.
+func (w *Writer) Close() error {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.Unlock()
+
+	err := w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush log: %w", err)
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
+
+// Reader reads the records of a message log in the order they were written.
+type Reader struct {
+	r       *bufio.Reader
+	started bool
+}
+
+// NewReader returns a reader of the message log read from the given reader.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next returns the next record of the log, or io.EOF once all records have
+// been read. A log truncated in the middle of a record, e.g. by a crash of the
+// recording node, returns io.ErrUnexpectedEOF.
+func (r *Reader) Next() (*Record, error) {
+	if !r.started {
+		magic := make([]byte, len(logMagic))
+		_, err := io.ReadFull(r.r, magic)
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("could not read log header: %w", err)
+			}
+			return nil, err
+		}
+		if string(magic) != logMagic {
+			return nil, fmt.Errorf("invalid log header %q", magic)
+		}
+		r.started = true
+	}
+
+	length, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("could not read record length: %w", err)
+	}
+	if length > maxRecordSize {
+		return nil, fmt.Errorf("record length %d exceeds maximum %d", length, maxRecordSize)
+	}
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(r.r, data)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("could not read record: %w", err)
+	}
+
+	var encoded encodedRecord
+	err = cbor.Unmarshal(data, &encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode record: %w", err)
+	}
+
+	msg := &message.Message{}
+	err = msg.Unmarshal(encoded.Message)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal recorded message: %w", err)
+	}
+
+	record := &Record{
+		Timestamp:   time.Unix(0, encoded.Timestamp),
+		Direction:   encoded.Direction,
+		Message:     msg,
+		Truncated:   encoded.TruncatedSize > 0,
+		PayloadSize: len(msg.Payload),
+	}
+	if record.Truncated {
+		record.PayloadSize = encoded.TruncatedSize
+	}
+	return record, nil
+}
@@ -0,0 +1,178 @@
+package recorder
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func messageFixture(originID flow.Identifier) *message.Message {
+	targetID := unittest.IdentifierFixture()
+	eventID := unittest.IdentifierFixture()
+	return &message.Message{
+		ChannelID: engine.PushBlocks.String(),
+		EventID:   eventID[:],
+		OriginID:  originID[:],
+		TargetIDs: [][]byte{targetID[:]},
+		Payload:   unittest.RandomBytes(100),
+		Type:      "messages.BlockProposal",
+	}
+}
+
+// TestLogRoundTrip tests that records are read back as they were written, in
+// the same order.
+func TestLogRoundTrip(t *testing.T) {
+	now := time.Unix(0, 1234567890)
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.now = func() time.Time { return now }
+
+	originID := unittest.IdentifierFixture()
+	inbound := messageFixture(originID)
+	outbound := messageFixture(originID)
+	require.NoError(t, w.Write(Inbound, inbound))
+	require.NoError(t, w.Write(Outbound, outbound))
+	require.NoError(t, w.Close())
+
+	r := NewReader(&buf)
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, Inbound, record.Direction)
+	assert.Equal(t, now, record.Timestamp)
+	assert.Equal(t, inbound, record.Message)
+	assert.Equal(t, engine.PushBlocks, record.Channel())
+	assert.Equal(t, originID, record.OriginID())
+
+	record, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, Outbound, record.Direction)
+	assert.Equal(t, outbound, record.Message)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+// TestLogTruncated tests that a log truncated in the middle of a record is
+// read up to the truncated record.
+func TestLogTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	require.NoError(t, w.Write(Inbound, messageFixture(unittest.IdentifierFixture())))
+	require.NoError(t, w.Write(Inbound, messageFixture(unittest.IdentifierFixture())))
+	require.NoError(t, w.Flush())
+
+	data := buf.Bytes()
+	r := NewReader(bytes.NewReader(data[:len(data)-10]))
+	_, err := r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+// TestLogOversizedMessage tests that messages exceeding the maximum record
+// size are recorded without their payload, and marked as truncated.
+func TestLogOversizedMessage(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	oversized := messageFixture(unittest.IdentifierFixture())
+	oversized.Payload = make([]byte, maxRecordSize)
+	require.NoError(t, w.Write(Inbound, oversized))
+	require.NoError(t, w.Write(Inbound, messageFixture(unittest.IdentifierFixture())))
+	require.NoError(t, w.Close())
+
+	r := NewReader(&buf)
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.True(t, record.Truncated)
+	assert.Equal(t, maxRecordSize, record.PayloadSize)
+	assert.Empty(t, record.Message.Payload)
+	assert.Equal(t, oversized.EventID, record.Message.EventID)
+
+	record, err = r.Next()
+	require.NoError(t, err)
+	assert.False(t, record.Truncated)
+	assert.Equal(t, 100, record.PayloadSize)
+}
+
+// syncBuffer is a buffer which is safe for concurrent use.
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Len()
+}
+
+// TestLogFlushesPeriodically tests that records are written to the log without
+// flushing or closing it.
+func TestLogFlushesPeriodically(t *testing.T) {
+	var buf syncBuffer
+	w := NewWriter(&buf)
+	defer w.Close()
+
+	require.NoError(t, w.Write(Inbound, messageFixture(unittest.IdentifierFixture())))
+	require.Eventually(t, func() bool {
+		return buf.Len() > 0
+	}, 3*DefaultFlushInterval, 100*time.Millisecond)
+}
+
+// TestLogInvalidHeader tests that files which are no message logs are rejected.
+func TestLogInvalidHeader(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("this is not a message log")))
+	_, err := r.Next()
+	assert.Error(t, err)
+
+	// an empty log has no records
+	r = NewReader(bytes.NewReader(nil))
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+// TestOpenFileAppends tests that a log file reopened by a restarted node is
+// extended, and can be read as a whole.
+func TestOpenFileAppends(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "messages.log")
+
+		for i := 0; i < 2; i++ {
+			w, err := OpenFile(path)
+			require.NoError(t, err)
+			require.NoError(t, w.Write(Inbound, messageFixture(unittest.IdentifierFixture())))
+			require.NoError(t, w.Close())
+		}
+
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		r := NewReader(file)
+		for i := 0; i < 2; i++ {
+			_, err := r.Next()
+			require.NoError(t, err)
+		}
+		_, err = r.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+}
@@ -0,0 +1,100 @@
+package recorder
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/message"
+)
+
+var _ network.Middleware = (*Middleware)(nil)
+
+// Middleware wraps a middleware, and records all messages it sends and
+// receives to a message log. The recorded logs can be replayed into the
+// engines of a node, to reproduce its behaviour offline. Transfers are not
+// recorded, as their payloads are streamed.
+type Middleware struct {
+	network.Middleware
+	log    zerolog.Logger
+	writer *Writer
+}
+
+// NewMiddleware returns a middleware recording the messages of the given
+// middleware with the given writer. The writer is closed when the middleware
+// is stopped.
+func NewMiddleware(log zerolog.Logger, mw network.Middleware, writer *Writer) *Middleware {
+	m := &Middleware{
+		Middleware: mw,
+		log:        log.With().Str("component", "network_recorder").Logger(),
+		writer:     writer,
+	}
+	return m
+}
+
+// Start starts the wrapped middleware with an overlay recording the inbound
+// messages.
+func (m *Middleware) Start(ov network.Overlay) error {
+	return m.Middleware.Start(&overlay{Overlay: ov, record: m.record})
+}
+
+// Stop stops the wrapped middleware, and closes the message log.
+func (m *Middleware) Stop() {
+	m.Middleware.Stop()
+	err := m.writer.Close()
+	if err != nil {
+		m.log.Error().Err(err).Msg("could not close message log")
+	}
+}
+
+func (m *Middleware) Send(channel network.Channel, msg *message.Message, targetIDs ...flow.Identifier) error {
+	err := m.Middleware.Send(channel, msg, targetIDs...)
+	if err != nil {
+		return err
+	}
+	m.record(Outbound, msg)
+	return nil
+}
+
+func (m *Middleware) SendDirect(msg *message.Message, targetID flow.Identifier) error {
+	err := m.Middleware.SendDirect(msg, targetID)
+	if err != nil {
+		return err
+	}
+	m.record(Outbound, msg)
+	return nil
+}
+
+func (m *Middleware) Publish(msg *message.Message, channel network.Channel) error {
+	err := m.Middleware.Publish(msg, channel)
+	if err != nil {
+		return err
+	}
+	m.record(Outbound, msg)
+	return nil
+}
+
+// record appends the message to the log. Failing to record a message is
+// logged, but does not affect its delivery.
+func (m *Middleware) record(direction Direction, msg *message.Message) {
+	err := m.writer.Write(direction, msg)
+	if err != nil {
+		m.log.Error().Err(err).
+			Str("direction", direction.String()).
+			Str("channel", msg.ChannelID).
+			Hex("event_id", msg.EventID).
+			Msg("could not record message")
+	}
+}
+
+// overlay records the messages received by the middleware before handing
+// them to the wrapped overlay.
+type overlay struct {
+	network.Overlay
+	record func(direction Direction, msg *message.Message)
+}
+
+func (o *overlay) Receive(nodeID flow.Identifier, msg *message.Message) error {
+	o.record(Inbound, msg)
+	return o.Overlay.Receive(nodeID, msg)
+}
@@ -0,0 +1,74 @@
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestMiddlewareRecords tests that the messages sent and received by the
+// wrapped middleware are recorded in order, and that messages which could not
+// be sent are not.
+func TestMiddlewareRecords(t *testing.T) {
+	var buf bytes.Buffer
+	wrapped := &mocknetwork.Middleware{}
+	mw := NewMiddleware(zerolog.Nop(), wrapped, NewWriter(&buf))
+
+	// capture the recording overlay the wrapped middleware is started with
+	ov := &mocknetwork.Overlay{}
+	var recording network.Overlay
+	wrapped.On("Start", mock.Anything).Run(func(args mock.Arguments) {
+		recording = args.Get(0).(network.Overlay)
+	}).Return(nil)
+	wrapped.On("Stop").Return()
+	require.NoError(t, mw.Start(ov))
+
+	me := unittest.IdentifierFixture()
+	other := unittest.IdentifierFixture()
+
+	received := messageFixture(other)
+	ov.On("Receive", other, received).Return(nil).Once()
+	require.NoError(t, recording.Receive(other, received))
+
+	direct := messageFixture(me)
+	wrapped.On("SendDirect", direct, other).Return(nil).Once()
+	require.NoError(t, mw.SendDirect(direct, other))
+
+	published := messageFixture(me)
+	wrapped.On("Publish", published, engine.PushBlocks).Return(nil).Once()
+	require.NoError(t, mw.Publish(published, engine.PushBlocks))
+
+	failed := messageFixture(me)
+	wrapped.On("SendDirect", failed, other).Return(errors.New("unreachable")).Once()
+	require.Error(t, mw.SendDirect(failed, other))
+
+	mw.Stop()
+	wrapped.AssertExpectations(t)
+	ov.AssertExpectations(t)
+
+	r := NewReader(&buf)
+	expected := []*Record{
+		{Direction: Inbound, Message: received},
+		{Direction: Outbound, Message: direct},
+		{Direction: Outbound, Message: published},
+	}
+	for _, exp := range expected {
+		record, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, exp.Direction, record.Direction)
+		assert.Equal(t, exp.Message, record.Message)
+	}
+	_, err := r.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -81,6 +81,14 @@ func (n *Network) Unregister(channel network.Channel) error {
 	return nil
 }
 
+// registered returns true if an engine of the attached node is registered to the channel.
+func (n *Network) registered(channel network.Channel) bool {
+	n.Lock()
+	defer n.Unlock()
+	_, ok := n.engines[channel]
+	return ok
+}
+
 // submit is called when the attached Engine to the channel is sending an event to an
 // Engine attached to the same channel on another node or nodes.
 func (n *Network) submit(channel network.Channel, event interface{}, targetIDs ...flow.Identifier) error {
@@ -0,0 +1,75 @@
+package stub
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/recorder"
+)
+
+// Replayer feeds the inbound messages of a recorded message log into the
+// engines of a node attached to the hub. The messages are delivered one at a
+// time in the order they were recorded, and each is processed before the next
+// is delivered, so that a replay is deterministic. Messages sent by the engines
+// in response are delivered to the other nodes attached to the hub, if any.
+type Replayer struct {
+	hub    *Hub
+	nodeID flow.Identifier
+	codec  network.Codec
+}
+
+// NewReplayer returns a replayer into the engines of the given node, which
+// decodes the recorded payloads with the given codec. The codec must be the
+// one of the overlay of the recording node.
+func NewReplayer(hub *Hub, nodeID flow.Identifier, codec network.Codec) *Replayer {
+	return &Replayer{
+		hub:    hub,
+		nodeID: nodeID,
+		codec:  codec,
+	}
+}
+
+// Replay replays all inbound messages read from the given log, and returns the
+// number of replayed messages. Messages on channels without an engine of the
+// node are skipped, as are recorded outbound messages. Inbound messages which
+// were recorded without their payload, as they exceeded the maximum record
+// size, can't be replayed and abort the replay.
+func (r *Replayer) Replay(log *recorder.Reader) (uint, error) {
+	net, ok := r.hub.GetNetwork(r.nodeID)
+	if !ok {
+		return 0, fmt.Errorf("node %x is not attached to the hub", r.nodeID)
+	}
+
+	var replayed uint
+	for {
+		record, err := log.Next()
+		if err == io.EOF {
+			return replayed, nil
+		}
+		if err != nil {
+			return replayed, fmt.Errorf("could not read record: %w", err)
+		}
+		if record.Direction != recorder.Inbound || !net.registered(record.Channel()) {
+			continue
+		}
+		if record.Truncated {
+			return replayed, fmt.Errorf("could not replay message without its recorded payload (type: %s, size: %d)", record.Message.Type, record.PayloadSize)
+		}
+
+		event, err := r.codec.Decode(record.Message.Payload)
+		if err != nil {
+			return replayed, fmt.Errorf("could not decode payload of recorded message: %w", err)
+		}
+
+		net.buffer(&PendingMessage{
+			From:      record.OriginID(),
+			Channel:   record.Channel(),
+			Event:     event,
+			TargetIDs: []flow.Identifier{r.nodeID},
+		})
+		net.DeliverAll(true)
+		replayed++
+	}
+}
@@ -0,0 +1,68 @@
+package stub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	libp2pmessage "github.com/onflow/flow-go/model/libp2p/message"
+	mockmodule "github.com/onflow/flow-go/module/mock"
+	"github.com/onflow/flow-go/network/codec/cbor"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/network/recorder"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestReplay tests that the inbound messages of a recorded log are processed
+// by the engines of the replaying node in the order they were recorded.
+func TestReplay(t *testing.T) {
+	codec := cbor.NewCodec()
+	nodeID := unittest.IdentifierFixture()
+	originID := unittest.IdentifierFixture()
+
+	record := func(w *recorder.Writer, direction recorder.Direction, channel string, text string) {
+		payload, err := codec.Encode(&libp2pmessage.TestMessage{Text: text})
+		require.NoError(t, err)
+		require.NoError(t, w.Write(direction, &message.Message{
+			ChannelID: channel,
+			OriginID:  originID[:],
+			TargetIDs: [][]byte{nodeID[:]},
+			Payload:   payload,
+		}))
+	}
+
+	var buf bytes.Buffer
+	w := recorder.NewWriter(&buf)
+	record(w, recorder.Inbound, engine.TestNetwork.String(), "first")
+	record(w, recorder.Outbound, engine.TestNetwork.String(), "response")
+	record(w, recorder.Inbound, engine.PushBlocks.String(), "unregistered")
+	record(w, recorder.Inbound, engine.TestNetwork.String(), "second")
+	require.NoError(t, w.Close())
+
+	me := &mockmodule.Local{}
+	me.On("NodeID").Return(nodeID)
+	hub := NewNetworkHub()
+	net := NewNetwork(nil, me, hub)
+
+	var processed []string
+	eng := &mocknetwork.Engine{}
+	eng.On("Process", originID, mock.Anything).Run(func(args mock.Arguments) {
+		processed = append(processed, args.Get(1).(*libp2pmessage.TestMessage).Text)
+	}).Return(nil)
+	_, err := net.Register(engine.TestNetwork, eng)
+	require.NoError(t, err)
+
+	replayed, err := NewReplayer(hub, nodeID, codec).Replay(recorder.NewReader(&buf))
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), replayed)
+	assert.Equal(t, []string{"first", "second"}, processed)
+
+	// the replaying node must be attached to the hub
+	_, err = NewReplayer(hub, unittest.IdentifierFixture(), codec).Replay(recorder.NewReader(&buf))
+	assert.Error(t, err)
+}
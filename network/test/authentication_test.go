@@ -0,0 +1,90 @@
+package test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-log"
+	"github.com/rs/zerolog"
+	mockery "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/network/mocknetwork"
+)
+
+// TestOriginAuthentication checks that middlewares drop unicast and pubsub
+// messages whose origin ID doesn't belong to the libp2p peer which sent them.
+func TestOriginAuthentication(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	log.SetAllLoggers(log.LevelError)
+
+	// the first node impersonates the last one towards the second one
+	ids, mws := GenerateIDsAndMiddlewares(t, 3, !DryRun, logger)
+	sender, target, victim := 0, 1, 2
+
+	identities := make(map[flow.Identifier]flow.Identity)
+	for _, id := range ids {
+		identities[id.NodeID] = *id
+	}
+	received := make(chan *message.Message, 10)
+	for i, mw := range mws {
+		overlay := &mocknetwork.Overlay{}
+		overlay.On("Identity").Maybe().Return(identities, nil)
+		overlay.On("Topology").Maybe().Return(flow.IdentityList(ids), nil)
+		if i == target {
+			overlay.On("Receive", mockery.Anything, mockery.Anything).Return(nil).
+				Run(func(args mockery.Arguments) {
+					received <- args.Get(1).(*message.Message)
+				})
+		}
+		require.NoError(t, mw.Start(overlay))
+		require.NoError(t, mw.UpdateAllowList())
+	}
+	defer func() {
+		for _, mw := range mws {
+			mw.Stop()
+		}
+	}()
+
+	// expect checks that exactly the genuine message reaches the target
+	expect := func(t *testing.T, send func(msg *message.Message) error) {
+		forged := createMessage(ids[victim].NodeID, ids[target].NodeID, "forged")
+		require.NoError(t, send(forged))
+		genuine := createMessage(ids[sender].NodeID, ids[target].NodeID, "genuine")
+		require.NoError(t, send(genuine))
+
+		select {
+		case msg := <-received:
+			require.Equal(t, genuine.Payload, msg.Payload)
+		case <-time.After(5 * time.Second):
+			t.Fatal("genuine message not received")
+		}
+		select {
+		case msg := <-received:
+			t.Fatalf("unexpected message received: %s", msg.Payload)
+		case <-time.After(time.Second):
+		}
+	}
+
+	t.Run("unicast", func(t *testing.T) {
+		expect(t, func(msg *message.Message) error {
+			return mws[sender].SendDirect(msg, ids[target].NodeID)
+		})
+	})
+
+	t.Run("pubsub", func(t *testing.T) {
+		for _, mw := range mws {
+			require.NoError(t, mw.Subscribe(testChannel))
+		}
+
+		// wait for nodes to form a mesh
+		time.Sleep(2 * time.Second)
+
+		expect(t, func(msg *message.Message) error {
+			return mws[sender].Publish(msg, testChannel)
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-log"
+	"github.com/rs/zerolog"
+	mockery "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	libp2pmessage "github.com/onflow/flow-go/model/libp2p/message"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/codec/cbor"
+	"github.com/onflow/flow-go/network/codec/json"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/network/p2p"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestCodecNegotiation checks that middlewares negotiate the preferred codec
+// supported by both ends of a unicast stream, and transcode message payloads
+// for peers which only speak the legacy JSON protocol. Pubsub messages are
+// published with JSON on the legacy topic, unless configured otherwise, and
+// received on the topics of all codecs.
+func TestCodecNegotiation(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	log.SetAllLoggers(log.LevelError)
+
+	cborCodec := p2p.VersionedCodec{Version: p2p.CodecVersionCBOR, Codec: cbor.NewCodec()}
+	jsonCodec := p2p.VersionedCodec{Version: p2p.CodecVersionJSON, Codec: json.NewCodec()}
+
+	// the first two middlewares prefer CBOR, while the last one is a legacy
+	// middleware which only speaks JSON; the second middleware publishes
+	// pubsub messages with CBOR
+	ids, nodes := GenerateIDs(t, logger, 3, !DryRun)
+	mws := GenerateMiddlewares(t, logger, ids, nodes)
+	mws[0].WithCodecs(cborCodec, jsonCodec)
+	mws[1].WithCodecs(cborCodec, jsonCodec).WithPubSubCodec(p2p.CodecVersionCBOR)
+	codecs := []network.Codec{cborCodec.Codec, cborCodec.Codec, jsonCodec.Codec}
+
+	identities := make(map[flow.Identifier]flow.Identity)
+	for _, id := range ids {
+		identities[id.NodeID] = *id
+	}
+	received := make([]chan *message.Message, len(mws))
+	for i, mw := range mws {
+		overlay := &mocknetwork.Overlay{}
+		overlay.On("Identity").Maybe().Return(identities, nil)
+		overlay.On("Topology").Maybe().Return(ids, nil)
+		ch := make(chan *message.Message, 1)
+		overlay.On("Receive", mockery.Anything, mockery.Anything).Return(nil).
+			Run(func(args mockery.Arguments) {
+				ch <- args.Get(1).(*message.Message)
+			})
+		received[i] = ch
+		require.NoError(t, mw.Start(overlay))
+		require.NoError(t, mw.UpdateAllowList())
+	}
+	defer func() {
+		for _, mw := range mws {
+			mw.Stop()
+		}
+	}()
+
+	send := func(t *testing.T, from int, to int) {
+		event := &libp2pmessage.TestMessage{Text: "hello"}
+		msg := createMessage(ids[from].NodeID, ids[to].NodeID)
+		payload, err := codecs[from].Encode(event)
+		require.NoError(t, err)
+		msg.Payload = payload
+
+		require.NoError(t, mws[from].SendDirect(msg, ids[to].NodeID))
+
+		select {
+		case msg := <-received[to]:
+			decoded, err := codecs[to].Decode(msg.Payload)
+			require.NoError(t, err)
+			require.Equal(t, event, decoded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not received")
+		}
+	}
+
+	t.Run("both ends prefer CBOR", func(t *testing.T) {
+		send(t, 0, 1)
+	})
+
+	t.Run("to legacy peer", func(t *testing.T) {
+		send(t, 0, 2)
+	})
+
+	t.Run("from legacy peer", func(t *testing.T) {
+		send(t, 2, 1)
+	})
+
+	t.Run("transcoding preserves event ID", func(t *testing.T) {
+		msg := createMessage(ids[0].NodeID, ids[2].NodeID)
+		eventID := unittest.IdentifierFixture()
+		msg.EventID = eventID[:]
+		payload, err := cborCodec.Codec.Encode(&libp2pmessage.TestMessage{Text: "hello"})
+		require.NoError(t, err)
+		msg.Payload = payload
+
+		require.NoError(t, mws[0].SendDirect(msg, ids[2].NodeID))
+		select {
+		case received := <-received[2]:
+			require.Equal(t, msg.EventID, received.EventID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not received")
+		}
+	})
+
+	for _, mw := range mws {
+		require.NoError(t, mw.Subscribe(testChannel))
+	}
+
+	// wait for nodes to form a mesh
+	time.Sleep(2 * time.Second)
+
+	publish := func(t *testing.T, from int, to ...int) *libp2pmessage.TestMessage {
+		event := &libp2pmessage.TestMessage{Text: "hello"}
+		msg := createMessage(ids[from].NodeID, ids[to[0]].NodeID)
+		for _, i := range to[1:] {
+			msg.TargetIDs = append(msg.TargetIDs, ids[i].NodeID[:])
+		}
+		payload, err := codecs[from].Encode(event)
+		require.NoError(t, err)
+		msg.Payload = payload
+
+		require.NoError(t, mws[from].Publish(msg, testChannel))
+		return event
+	}
+
+	receive := func(t *testing.T, to int, event *libp2pmessage.TestMessage) {
+		select {
+		case msg := <-received[to]:
+			decoded, err := codecs[to].Decode(msg.Payload)
+			require.NoError(t, err)
+			require.Equal(t, event, decoded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not received")
+		}
+	}
+
+	t.Run("pubsub to legacy peer", func(t *testing.T) {
+		event := publish(t, 0, 1, 2)
+		receive(t, 1, event)
+		receive(t, 2, event)
+	})
+
+	t.Run("pubsub from legacy peer", func(t *testing.T) {
+		event := publish(t, 2, 0, 1)
+		receive(t, 0, event)
+		receive(t, 1, event)
+	})
+
+	t.Run("pubsub on versioned topic", func(t *testing.T) {
+		event := publish(t, 1, 0, 2)
+		receive(t, 0, event)
+
+		// the legacy peer is not subscribed to the topic versioned by CBOR
+		select {
+		case msg := <-received[2]:
+			t.Fatalf("unexpected message received by legacy peer: %x", msg.Payload)
+		case <-time.After(time.Second):
+		}
+	})
+}
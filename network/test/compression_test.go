@@ -0,0 +1,145 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-log"
+	"github.com/rs/zerolog"
+	mockery "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	libp2pmessage "github.com/onflow/flow-go/model/libp2p/message"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/network/codec/cbor"
+	"github.com/onflow/flow-go/network/codec/json"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/network/p2p"
+)
+
+// compressionMetrics counts the compressed messages of a middleware.
+type compressionMetrics struct {
+	*metrics.NoopCollector
+	sync.Mutex
+	compressed map[string]int // compressed messages by direction
+}
+
+func (c *compressionMetrics) NetworkMessageCompressed(_ string, direction string, _ int, _ int) {
+	c.Lock()
+	defer c.Unlock()
+	c.compressed[direction]++
+}
+
+func (c *compressionMetrics) count(direction string) int {
+	c.Lock()
+	defer c.Unlock()
+	return c.compressed[direction]
+}
+
+// TestCompressionNegotiation checks that middlewares compress large unicast
+// payloads if both ends of the stream support compression, and send them
+// uncompressed otherwise.
+func TestCompressionNegotiation(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	log.SetAllLoggers(log.LevelError)
+
+	codec := cbor.NewCodec()
+	codecs := []p2p.VersionedCodec{
+		{Version: p2p.CodecVersionCBOR, Codec: codec},
+		{Version: p2p.CodecVersionJSON, Codec: json.NewCodec()},
+	}
+
+	// the first two middlewares compress, while the last one doesn't
+	ids, nodes := GenerateIDs(t, logger, 3, !DryRun)
+	collectors := make([]*compressionMetrics, len(ids))
+	mws := make([]*p2p.Middleware, len(ids))
+	for i, id := range ids {
+		node := nodes[i]
+		collectors[i] = &compressionMetrics{
+			NoopCollector: metrics.NewNoopCollector(),
+			compressed:    make(map[string]int),
+		}
+		mws[i] = p2p.NewMiddleware(logger,
+			func() (*p2p.Node, error) { return node, nil },
+			id.NodeID,
+			collectors[i],
+			rootBlockID).
+			WithCodecs(codecs...)
+	}
+	mws[0].WithCompression(p2p.DefaultCompressionConfig())
+	mws[1].WithCompression(p2p.DefaultCompressionConfig())
+
+	identities := make(map[flow.Identifier]flow.Identity)
+	for _, id := range ids {
+		identities[id.NodeID] = *id
+	}
+	received := make([]chan *message.Message, len(mws))
+	for i, mw := range mws {
+		overlay := &mocknetwork.Overlay{}
+		overlay.On("Identity").Maybe().Return(identities, nil)
+		overlay.On("Topology").Maybe().Return(ids, nil)
+		ch := make(chan *message.Message, 1)
+		overlay.On("Receive", mockery.Anything, mockery.Anything).Return(nil).
+			Run(func(args mockery.Arguments) {
+				ch <- args.Get(1).(*message.Message)
+			})
+		received[i] = ch
+		require.NoError(t, mw.Start(overlay))
+		require.NoError(t, mw.UpdateAllowList())
+	}
+	defer func() {
+		for _, mw := range mws {
+			mw.Stop()
+		}
+	}()
+
+	send := func(t *testing.T, from int, to int, text string) {
+		event := &libp2pmessage.TestMessage{Text: text}
+		msg := createMessage(ids[from].NodeID, ids[to].NodeID)
+		payload, err := codec.Encode(event)
+		require.NoError(t, err)
+		msg.Payload = payload
+
+		require.NoError(t, mws[from].SendDirect(msg, ids[to].NodeID))
+
+		select {
+		case msg := <-received[to]:
+			decoded, err := codec.Decode(msg.Payload)
+			require.NoError(t, err)
+			require.Equal(t, event, decoded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not received")
+		}
+	}
+
+	large := strings.Repeat("flow", p2p.DefaultCompressionThreshold)
+
+	t.Run("both ends compress", func(t *testing.T) {
+		send(t, 0, 1, large)
+		require.Equal(t, 1, collectors[0].count(metrics.DirectionOutbound))
+		require.Equal(t, 1, collectors[1].count(metrics.DirectionInbound))
+	})
+
+	t.Run("below threshold", func(t *testing.T) {
+		send(t, 0, 1, "hello")
+		require.Equal(t, 1, collectors[0].count(metrics.DirectionOutbound))
+		require.Equal(t, 1, collectors[1].count(metrics.DirectionInbound))
+	})
+
+	t.Run("to peer without compression", func(t *testing.T) {
+		send(t, 0, 2, large)
+		require.Equal(t, 1, collectors[0].count(metrics.DirectionOutbound))
+		require.Equal(t, 0, collectors[2].count(metrics.DirectionInbound))
+	})
+
+	t.Run("from peer without compression", func(t *testing.T) {
+		send(t, 2, 1, large)
+		require.Equal(t, 0, collectors[2].count(metrics.DirectionOutbound))
+		require.Equal(t, 1, collectors[1].count(metrics.DirectionInbound))
+	})
+}
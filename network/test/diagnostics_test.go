@@ -0,0 +1,86 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/network/p2p"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestDiagnostics checks that the diagnostics of a node report its connected
+// peers with their identities and round trip times, the subscribed channels and
+// topics, and the GossipSub mesh of the topics.
+func TestDiagnostics(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	log.SetAllLoggers(log.LevelError)
+
+	ids, mws, nets := GenerateIDsMiddlewaresNetworks(t, 3, logger, 100, nil, !DryRun, unittest.WithAllRoles())
+	defer stopNetworks(t, nets, 3*time.Second)
+	GenerateEngines(t, nets)
+
+	topic := engine.TopicFromChannel(engine.TestNetwork, rootBlockID).String()
+
+	// waits for the peers to be connected and grafted into the mesh of the topic
+	require.Eventually(t, func() bool {
+		d, err := nets[0].Diagnostics(mws[0], false)
+		require.NoError(t, err)
+		return len(d.Peers) == len(ids)-1 && len(d.Mesh[topic]) == len(ids)-1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	server := httptest.NewServer(p2p.NewDiagnosticsHandler(logger, nets[0], mws[0]))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the identities are decoded partially, as the networking keys of the test identities are not ECDSA_P256 keys
+	var d struct {
+		NodeID   flow.Identifier     `json:"node_id"`
+		Fanout   flow.IdentifierList `json:"fanout"`
+		Channels []string            `json:"channels"`
+		Topics   []string            `json:"topics"`
+		Peers    []struct {
+			Addresses []string `json:"addresses"`
+			Identity  *struct {
+				NodeID flow.Identifier
+			} `json:"identity"`
+			RTT       string `json:"rtt"`
+			PingError string `json:"ping_error"`
+		} `json:"peers"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&d))
+
+	assert.Equal(t, ids[0].NodeID, d.NodeID)
+	assert.Equal(t, []string{engine.TestNetwork.String()}, d.Channels)
+	assert.Equal(t, []string{topic}, d.Topics)
+	assert.ElementsMatch(t, ids[1:].NodeIDs(), d.Fanout)
+
+	require.Len(t, d.Peers, len(ids)-1)
+	for _, peer := range d.Peers {
+		require.NotNil(t, peer.Identity)
+		assert.Contains(t, ids[1:].NodeIDs(), peer.Identity.NodeID)
+		assert.NotEmpty(t, peer.Addresses)
+		assert.Empty(t, peer.PingError)
+		assert.NotEmpty(t, peer.RTT)
+	}
+
+	// only GET requests are served
+	resp, err = http.Post(server.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
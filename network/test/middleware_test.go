@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/model/flow"
 	libp2pmessage "github.com/onflow/flow-go/model/libp2p/message"
 	"github.com/onflow/flow-go/module/metrics"
@@ -24,7 +26,8 @@ import (
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
-const testChannel = "test-channel"
+// testChannel is open to all roles, so that messages between the test identities are authorized
+const testChannel = engine.TestNetwork
 
 type MiddlewareTestSuite struct {
 	suite.Suite
@@ -363,15 +366,23 @@ func (m *MiddlewareTestSuite) TestUnsubscribe() {
 	originID := m.ids[origin].NodeID
 	message1 := createMessage(firstNode, lastNode, "hello1")
 
-	m.ov[target].On("Receive", originID, mockery.Anything).Return(nil).Once()
+	// received is set once the target node receives the message; as messages
+	// are signed and their origin authenticated, delivery may take longer than
+	// the first tick, so we can't assert on the calls before it
+	var received uint32
+	m.ov[target].On("Receive", originID, mockery.Anything).Return(nil).Once().
+		Run(func(mockery.Arguments) {
+			atomic.StoreUint32(&received, 1)
+		})
 
 	// first test that when both nodes are subscribed to the channel, the target node receives the message
 	err := m.mws[origin].Publish(message1, testChannel)
 	assert.NoError(m.T(), err)
 
 	assert.Eventually(m.T(), func() bool {
-		return m.ov[target].AssertCalled(m.T(), "Receive", originID, mockery.Anything)
+		return atomic.LoadUint32(&received) == 1
 	}, 2*time.Second, time.Millisecond)
+	m.ov[target].AssertCalled(m.T(), "Receive", originID, mockery.Anything)
 
 	// now unsubscribe the target node from the channel
 	err = m.mws[target].Unsubscribe(testChannel)
@@ -396,7 +407,7 @@ func createMessage(originID flow.Identifier, targetID flow.Identifier, msg ...st
 	}
 
 	return &message.Message{
-		ChannelID: testChannel,
+		ChannelID: testChannel.String(),
 		EventID:   []byte("1"),
 		OriginID:  originID[:],
 		TargetIDs: [][]byte{targetID[:]},
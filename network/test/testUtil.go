@@ -182,10 +182,8 @@ func generateLibP2PNode(t *testing.T,
 
 	// create PubSub options for libp2p to use
 	psOptions := []pubsub.Option{
-		// skip message signing
-		pubsub.WithMessageSigning(false),
-		// skip message signature
-		pubsub.WithStrictSignatureVerification(false),
+		// sign all messages and drop unsigned ones
+		pubsub.WithMessageSignaturePolicy(pubsub.StrictSign),
 		// set max message size limit for 1-k PubSub messaging
 		pubsub.WithMaxMessageSize(p2p.DefaultMaxPubSubMsgSize),
 	}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/libp2p/message"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/network/p2p"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// transferOverlay consumes transfers with the given function, as the mock overlay
+// would read the transfers concurrently to their receipt when matching its calls.
+type transferOverlay struct {
+	*mocknetwork.Overlay
+	receive func(originID flow.Identifier, transfer network.Transfer) error
+}
+
+func (o *transferOverlay) ReceiveTransfer(originID flow.Identifier, transfer network.Transfer) error {
+	return o.receive(originID, transfer)
+}
+
+// TestTransfer checks that payloads are streamed between middlewares in
+// frames, and are received completely and in order.
+func TestTransfer(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	log.SetAllLoggers(log.LevelError)
+
+	ids, mws := GenerateIDsAndMiddlewares(t, 2, !DryRun, logger)
+
+	identities := make(map[flow.Identifier]flow.Identity)
+	for _, id := range ids {
+		identities[id.NodeID] = *id
+	}
+
+	type receivedTransfer struct {
+		originID flow.Identifier
+		channel  network.Channel
+		typ      string
+		payload  []byte
+	}
+	received := make(chan receivedTransfer, 1)
+	for _, mw := range mws {
+		mockOverlay := &mocknetwork.Overlay{}
+		mockOverlay.On("Identity").Maybe().Return(identities, nil)
+		mockOverlay.On("Topology").Maybe().Return(flow.IdentityList(ids), nil)
+		overlay := &transferOverlay{
+			Overlay: mockOverlay,
+			receive: func(originID flow.Identifier, transfer network.Transfer) error {
+				var payload []byte
+				for {
+					frame, err := transfer.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						return err
+					}
+					payload = append(payload, frame...)
+				}
+				received <- receivedTransfer{
+					originID: originID,
+					channel:  transfer.Channel(),
+					typ:      transfer.Type(),
+					payload:  payload,
+				}
+				return nil
+			},
+		}
+		require.NoError(t, mw.Start(overlay))
+		require.NoError(t, mw.UpdateAllowList())
+	}
+	defer func() {
+		for _, mw := range mws {
+			mw.Stop()
+		}
+	}()
+
+	transfer := func(t *testing.T, size int) {
+		payload := unittest.RandomBytes(size)
+		err := mws[0].SendTransfer(testChannel, ids[1].NodeID, "test", bytes.NewReader(payload), uint64(size))
+		require.NoError(t, err)
+
+		select {
+		case r := <-received:
+			require.Equal(t, ids[0].NodeID, r.originID)
+			require.Equal(t, testChannel, r.channel)
+			require.Equal(t, "test", r.typ)
+			require.True(t, bytes.Equal(payload, r.payload))
+		case <-time.After(10 * time.Second):
+			t.Fatal("transfer not received")
+		}
+	}
+
+	t.Run("payload larger than a frame", func(t *testing.T) {
+		transfer(t, 2*p2p.DefaultTransferFrameSize+p2p.DefaultTransferFrameSize/2)
+	})
+
+	t.Run("payload of whole frames", func(t *testing.T) {
+		transfer(t, 2*p2p.DefaultTransferFrameSize)
+	})
+
+	t.Run("payload smaller than a frame", func(t *testing.T) {
+		transfer(t, 100)
+	})
+
+	t.Run("empty payload", func(t *testing.T) {
+		transfer(t, 0)
+	})
+}
+
+// TestTransferEvent checks that events transferred on a conduit are decoded
+// from the frames of the transfer and processed by the engine of the recipient.
+func TestTransferEvent(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	log.SetAllLoggers(log.LevelError)
+
+	ids, _, nets := GenerateIDsMiddlewaresNetworks(t, 2, logger, 100, nil, !DryRun)
+	defer stopNetworks(t, nets, 3*time.Second)
+
+	sender := NewMeshEngine(t, nets[0], 1, testChannel)
+	receiver := NewMeshEngine(t, nets[1], 1, testChannel)
+
+	tc, ok := sender.con.(network.TransferConduit)
+	require.True(t, ok)
+
+	// the event spans several frames
+	event := &message.TestMessage{Text: string(networkPayloadFixture(t, 2*p2p.DefaultTransferFrameSize+100))}
+	require.NoError(t, tc.TransferEvent(event, ids[1].NodeID))
+
+	select {
+	case received := <-receiver.event:
+		require.Equal(t, event, received)
+		require.Equal(t, ids[0].NodeID, receiver.originID)
+	case <-time.After(10 * time.Second):
+		t.Fatal("transferred event not received")
+	}
+}
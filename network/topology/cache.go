@@ -1,6 +1,8 @@
 package topology
 
 import (
+	"time"
+
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/model/flow"
@@ -23,6 +25,9 @@ type Cache struct {
 	cachedFanout flow.IdentityList // most recently generated fanout list by invoking underlying topology.
 	idsFP        flow.Identifier   // unique fingerprint of input IdentityList for cached fanout.
 	chansFP      flow.Identifier   // unique fingerprint of input ChannelsList for cached fanout.
+	refresh      time.Duration     // age after which the cached fanout is regenerated, zero if never.
+	generated    time.Time         // time the cached fanout was generated.
+	now          func() time.Time
 }
 
 //NewCache creates and returns a topology Cache given an instance of topology implementation.
@@ -33,9 +38,18 @@ func NewCache(log zerolog.Logger, top network.Topology) *Cache {
 		cachedFanout: nil,
 		idsFP:        flow.Identifier{},
 		chansFP:      flow.Identifier{},
+		now:          time.Now,
 	}
 }
 
+// WithRefreshInterval makes the cache regenerate its fanout once it is older than the given interval, even if the
+// input lists did not change. This lets topologies which depend on changing measurements, such as round trip times,
+// adapt to them.
+func (c *Cache) WithRefreshInterval(refresh time.Duration) *Cache {
+	c.refresh = refresh
+	return c
+}
+
 // GenerateFanout receives IdentityList of entire network and constructs the fanout IdentityList
 // of this instance.
 // It caches the most recently generated fanout list, so as long as the input list is the same, it returns
@@ -59,7 +73,8 @@ func (c *Cache) GenerateFanout(ids flow.IdentityList, channels network.ChannelLi
 		Int("input_ids_size", len(ids)).
 		Int("input_channels_size", len(channels)).Logger()
 
-	if inputIdsFP == c.idsFP && inputChansFP == c.chansFP {
+	expired := c.refresh > 0 && c.now().Sub(c.generated) >= c.refresh
+	if inputIdsFP == c.idsFP && inputChansFP == c.chansFP && !expired {
 		// cache hit
 		log.Trace().Msg("topology cache hit")
 		return c.cachedFanout, nil
@@ -82,6 +97,7 @@ func (c *Cache) GenerateFanout(ids flow.IdentityList, channels network.ChannelLi
 	c.cachedFanout = fanout
 	c.idsFP = inputIdsFP
 	c.chansFP = inputChansFP
+	c.generated = c.now()
 
 	log.Trace().Msg("topology cache invalidated and updated")
 	return c.cachedFanout, nil
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
@@ -201,3 +202,29 @@ func requireDeterministicBehavior(t *testing.T, cache *Cache, fanout flow.Identi
 		require.Equal(t, fanout, newFanout)
 	}
 }
+
+// TestCache_RefreshInterval evaluates that a cache with a refresh interval regenerates its fanout once it aged,
+// even if the input did not change.
+func TestCache_RefreshInterval(t *testing.T) {
+	top := &mocknetwork.Topology{}
+	ids := unittest.IdentityListFixture(100)
+	channels := network.ChannelList{"Channel1", "Channel2", "Channel3"}
+	fanout := ids.Sample(10)
+	refreshed := ids.Sample(10)
+	top.On("GenerateFanout", ids, channels).Return(fanout, nil).Once()
+	top.On("GenerateFanout", ids, channels).Return(refreshed, nil).Once()
+
+	now := time.Now()
+	cache := NewCache(zerolog.Nop(), top).WithRefreshInterval(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	// the fanout is cached until it is older than the refresh interval
+	requireDeterministicBehavior(t, cache, fanout, ids, channels)
+	now = now.Add(30 * time.Second)
+	requireDeterministicBehavior(t, cache, fanout, ids, channels)
+
+	now = now.Add(30 * time.Second)
+	requireDeterministicBehavior(t, cache, refreshed, ids, channels)
+
+	mock.AssertExpectationsForObjects(t, top)
+}
@@ -0,0 +1,147 @@
+package topology
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const (
+	// DefaultLatencyProbeInterval is the interval between two rounds of probing
+	// the round trip times to other nodes.
+	DefaultLatencyProbeInterval = 10 * time.Second
+
+	// DefaultLatencyProbeBatch is the number of nodes probed in each round.
+	DefaultLatencyProbeBatch = 10
+
+	// latencyRefreshAge is the age after which the round trip time to a node is
+	// measured again.
+	latencyRefreshAge = 10 * time.Minute
+
+	// rttSmoothing is the weight of a new measurement in the moving average of
+	// the round trip time to a node.
+	rttSmoothing = 0.25
+)
+
+// Pinger measures the round trip time to other nodes, e.g. the middleware.
+type Pinger interface {
+	Ping(targetID flow.Identifier) (time.Duration, error)
+}
+
+// LatencyMonitor measures the round trip times to the nodes the topology asks
+// for, by pinging a batch of them periodically. Nodes are probed once they are
+// asked for, and again after their last probe aged, so that the monitor is
+// idle unless it is used by a topology.
+type LatencyMonitor struct {
+	sync.Mutex
+	unit     *engine.Unit
+	log      zerolog.Logger
+	pinger   Pinger
+	interval time.Duration
+	batch    int
+	rtts     map[flow.Identifier]time.Duration // smoothed round trip times of the measured nodes
+	probed   map[flow.Identifier]time.Time     // time of the last probe of each node, successful or not
+	wanted   map[flow.Identifier]struct{}      // nodes whose round trip time was asked for
+	now      func() time.Time
+}
+
+// NewLatencyMonitor returns a latency monitor, which pings a batch of the given
+// size in each interval.
+func NewLatencyMonitor(log zerolog.Logger, pinger Pinger, interval time.Duration, batch int) *LatencyMonitor {
+	m := &LatencyMonitor{
+		unit:     engine.NewUnit(),
+		log:      log.With().Str("component", "latency_monitor").Logger(),
+		pinger:   pinger,
+		interval: interval,
+		batch:    batch,
+		rtts:     make(map[flow.Identifier]time.Duration),
+		probed:   make(map[flow.Identifier]time.Time),
+		wanted:   make(map[flow.Identifier]struct{}),
+		now:      time.Now,
+	}
+	return m
+}
+
+// Ready starts probing the round trip times periodically.
+func (m *LatencyMonitor) Ready() <-chan struct{} {
+	m.unit.LaunchPeriodically(m.probe, m.interval, m.interval)
+	return m.unit.Ready()
+}
+
+// Done stops probing the round trip times.
+func (m *LatencyMonitor) Done() <-chan struct{} {
+	return m.unit.Done()
+}
+
+// RTT returns the smoothed round trip time to the given node, and false if it
+// has not been measured yet, in which case it is probed in one of the next
+// rounds.
+func (m *LatencyMonitor) RTT(nodeID flow.Identifier) (time.Duration, bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.wanted[nodeID] = struct{}{}
+	rtt, ok := m.rtts[nodeID]
+	return rtt, ok
+}
+
+// record adds a measured round trip time to the moving average of the node.
+func (m *LatencyMonitor) record(nodeID flow.Identifier, rtt time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	smoothed, ok := m.rtts[nodeID]
+	if !ok {
+		m.rtts[nodeID] = rtt
+		return
+	}
+	m.rtts[nodeID] = time.Duration(rttSmoothing*float64(rtt) + (1-rttSmoothing)*float64(smoothed))
+}
+
+// due returns the next batch of nodes to probe, and marks them as probed. Nodes
+// which have never been probed come first, followed by the nodes with the
+// oldest probes.
+func (m *LatencyMonitor) due() flow.IdentifierList {
+	m.Lock()
+	defer m.Unlock()
+
+	now := m.now()
+	var unprobed, aged flow.IdentifierList
+	for nodeID := range m.wanted {
+		probed, ok := m.probed[nodeID]
+		if !ok {
+			unprobed = append(unprobed, nodeID)
+			continue
+		}
+		if now.Sub(probed) >= latencyRefreshAge {
+			aged = append(aged, nodeID)
+		}
+	}
+	sort.Slice(aged, func(i, j int) bool {
+		return m.probed[aged[i]].Before(m.probed[aged[j]])
+	})
+
+	due := append(unprobed, aged...)
+	if len(due) > m.batch {
+		due = due[:m.batch]
+	}
+	for _, nodeID := range due {
+		m.probed[nodeID] = now
+	}
+	return due
+}
+
+// probe pings the next batch of nodes, and records their round trip times.
+func (m *LatencyMonitor) probe() {
+	for _, nodeID := range m.due() {
+		rtt, err := m.pinger.Ping(nodeID)
+		if err != nil {
+			m.log.Debug().Err(err).Hex("node_id", nodeID[:]).Msg("could not measure round trip time")
+			continue
+		}
+		m.record(nodeID, rtt)
+	}
+}
@@ -0,0 +1,95 @@
+package topology
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// pingerFunc is a pinger backed by a function.
+type pingerFunc func(targetID flow.Identifier) (time.Duration, error)
+
+func (p pingerFunc) Ping(targetID flow.Identifier) (time.Duration, error) {
+	return p(targetID)
+}
+
+// TestLatencyMonitor_ProbesWantedNodes evaluates that the monitor only probes the nodes asked for, in
+// batches, and smooths their round trip times.
+func TestLatencyMonitor_ProbesWantedNodes(t *testing.T) {
+	rtt := 100 * time.Millisecond
+	pinged := make(map[flow.Identifier]int)
+	pinger := pingerFunc(func(targetID flow.Identifier) (time.Duration, error) {
+		pinged[targetID]++
+		return rtt, nil
+	})
+
+	now := time.Now()
+	monitor := NewLatencyMonitor(zerolog.Nop(), pinger, time.Second, 2)
+	monitor.now = func() time.Time { return now }
+
+	// the monitor is idle as long as no round trip times are asked for
+	monitor.probe()
+	assert.Empty(t, pinged)
+
+	nodeIDs := unittest.IdentifierListFixture(3)
+	for _, nodeID := range nodeIDs {
+		_, ok := monitor.RTT(nodeID)
+		assert.False(t, ok)
+	}
+
+	// the nodes are probed in batches
+	monitor.probe()
+	assert.Len(t, pinged, 2)
+	monitor.probe()
+	assert.Len(t, pinged, 3)
+	for _, nodeID := range nodeIDs {
+		measured, ok := monitor.RTT(nodeID)
+		require.True(t, ok)
+		assert.Equal(t, rtt, measured)
+	}
+
+	// measurements are refreshed once they aged, and smoothed
+	monitor.probe()
+	assert.Len(t, pinged, 3)
+	now = now.Add(latencyRefreshAge)
+	rtt = 200 * time.Millisecond
+	monitor.probe()
+	monitor.probe()
+	for _, nodeID := range nodeIDs {
+		assert.Equal(t, 2, pinged[nodeID])
+		measured, _ := monitor.RTT(nodeID)
+		assert.Equal(t, 125*time.Millisecond, measured)
+	}
+}
+
+// TestLatencyMonitor_UnreachableNodes evaluates that nodes which can't be pinged don't keep the monitor
+// from probing other nodes.
+func TestLatencyMonitor_UnreachableNodes(t *testing.T) {
+	unreachable := unittest.IdentifierFixture()
+	reachable := unittest.IdentifierFixture()
+	pinger := pingerFunc(func(targetID flow.Identifier) (time.Duration, error) {
+		if targetID == unreachable {
+			return 0, errors.New("unreachable")
+		}
+		return time.Millisecond, nil
+	})
+
+	monitor := NewLatencyMonitor(zerolog.Nop(), pinger, time.Second, 1)
+	_, _ = monitor.RTT(unreachable)
+	_, _ = monitor.RTT(reachable)
+
+	monitor.probe()
+	monitor.probe()
+
+	_, ok := monitor.RTT(unreachable)
+	assert.False(t, ok)
+	_, ok = monitor.RTT(reachable)
+	assert.True(t, ok)
+}
@@ -19,6 +19,7 @@ type TopicBasedTopology struct {
 	state    protocol.State  // used to keep a read only protocol state
 	logger   zerolog.Logger
 	seed     int64
+	sample   func(ids flow.IdentityList, size uint) flow.IdentityList // used to sample the fanout of each component
 }
 
 // NewTopicBasedTopology returns an instance of the TopicBasedTopology.
@@ -34,6 +35,9 @@ func NewTopicBasedTopology(nodeID flow.Identifier, logger zerolog.Logger, state
 		seed:     seed,
 		logger:   logger.With().Str("component:", "topic-based-topology").Logger(),
 	}
+	t.sample = func(ids flow.IdentityList, size uint) flow.IdentityList {
+		return ids.DeterministicSample(size, seed)
+	}
 
 	return t, nil
 }
@@ -149,7 +153,7 @@ func (t TopicBasedTopology) sampleConnectedGraph(all flow.IdentityList, shouldHa
 		// choose (n+1)/2 random nodes so that each node in the graph will have a degree >= (n+1) / 2,
 		// guaranteeing a connected graph.
 		size := uint(LinearFanout(len(all)))
-		return all.DeterministicSample(size, t.seed), nil
+		return t.sample(all, size), nil
 
 	}
 	// checks `shouldHave` be a subset of `all`
@@ -171,7 +175,7 @@ func (t TopicBasedTopology) sampleConnectedGraph(all flow.IdentityList, shouldHa
 
 	// others are all excluding should have ones
 	others := all.Filter(filter.Not(filter.In(shouldHave)))
-	others = others.DeterministicSample(uint(subsetSize), t.seed)
+	others = t.sample(others, uint(subsetSize))
 
 	return others.Union(shouldHave), nil
 
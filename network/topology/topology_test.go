@@ -2,8 +2,10 @@ package topology_test
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/bsipos/thist"
 	"github.com/rs/zerolog"
@@ -28,6 +30,7 @@ type TopologyTestSuite struct {
 	logger          zerolog.Logger
 	linearFanoutTop factory
 	randomizedTop   factory
+	weightedTop     factory
 }
 
 // TestTopologyTestSuite runs all tests in this test suite
@@ -53,6 +56,15 @@ func (suite *TopologyTestSuite) SetupTest() {
 
 		return top
 	}
+
+	// all nodes measure the same random round trip times, so that they prefer the same peers
+	latencies := make(randomLatencies)
+	suite.weightedTop = func(t *testing.T, identifier flow.Identifier, state protocol.State, manager network.SubscriptionManager) network.Topology {
+		top, err := topology.NewWeightedTopology(identifier, suite.logger, state, latencies)
+		require.NoError(t, err)
+
+		return top
+	}
 }
 
 // TestLowScaleLinearFanout creates systems with
@@ -79,6 +91,18 @@ func (suite *TopologyTestSuite) TestLowScaleRandomized() {
 	suite.multiSystemEndToEndConnectedness(suite.randomizedTop, 1, 10, 100, 120, 5, 100, 4)
 }
 
+// TestLowScaleWeighted creates systems with
+// 10 access nodes
+// 100 collection nodes in 4 clusters
+// 120 consensus nodes
+// 5 execution nodes
+// 100 verification nodes
+// and builds a latency and stake weighted topology for the systems.
+// For each system, it then checks the end-to-end connectedness of the topology graph.
+func (suite *TopologyTestSuite) TestLowScaleWeighted() {
+	suite.multiSystemEndToEndConnectedness(suite.weightedTop, 1, 10, 100, 120, 5, 100, 4)
+}
+
 // TestModerateScaleLinearFanout creates systems with
 // 20 access nodes
 // 200 collection nodes in 8 clusters
@@ -103,6 +127,18 @@ func (suite *TopologyTestSuite) TestModerateScaleRandomized() {
 	suite.multiSystemEndToEndConnectedness(suite.randomizedTop, 1, 20, 200, 240, 10, 200, 8)
 }
 
+// TestModerateScaleWeighted creates systems with
+// 20 access nodes
+// 200 collection nodes in 8 clusters
+// 240 consensus nodes
+// 10 execution nodes
+// 100 verification nodes
+// and builds a latency and stake weighted topology for the systems.
+// For each system, it then checks the end-to-end connectedness of the topology graph.
+func (suite *TopologyTestSuite) TestModerateScaleWeighted() {
+	suite.multiSystemEndToEndConnectedness(suite.weightedTop, 1, 20, 200, 240, 10, 200, 8)
+}
+
 // TestHighScaleLinearFanout creates systems with
 // 40 access nodes
 // 400 collection nodes in 16 clusters
@@ -235,3 +271,15 @@ func (suite *TopologyTestSuite) trace() bool {
 	_, found := os.LookupEnv("trace")
 	return found
 }
+
+// randomLatencies is a latency provider, which assigns a random round trip time to each node on first use.
+type randomLatencies map[flow.Identifier]time.Duration
+
+func (r randomLatencies) RTT(nodeID flow.Identifier) (time.Duration, bool) {
+	rtt, ok := r[nodeID]
+	if !ok {
+		rtt = time.Duration(rand.Intn(300)) * time.Millisecond
+		r[nodeID] = rtt
+	}
+	return rtt, true
+}
@@ -0,0 +1,121 @@
+package topology
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/order"
+	"github.com/onflow/flow-go/state/protocol"
+)
+
+const (
+	// DefaultTopologyRefreshInterval is the interval at which the fanout of a
+	// weighted topology should be regenerated, so that it adapts to the
+	// measured round trip times.
+	DefaultTopologyRefreshInterval = 10 * time.Minute
+
+	// unknownRTT is the round trip time assumed for nodes which have not been
+	// measured yet.
+	unknownRTT = 100 * time.Millisecond
+
+	// rttWeightScale is the round trip time which halves the weight of a node.
+	rttWeightScale = 50 * time.Millisecond
+
+	// minStakeWeight is the smallest stake weight of a node relative to the
+	// node with the highest stake, so that nodes with little or no stake are
+	// still sampled occasionally.
+	minStakeWeight = 0.05
+)
+
+// LatencyProvider provides the measured round trip times to other nodes.
+type LatencyProvider interface {
+	// RTT returns the round trip time to the given node, and false if it has
+	// not been measured yet.
+	RTT(nodeID flow.Identifier) (time.Duration, bool)
+}
+
+// WeightedTopology is a topic-based topology, which prefers nodes with a low
+// round trip time and a high stake when sampling the fanout of each connected
+// component. As the size of the fanout is the same as in the topic-based
+// topology, the connectedness of the components is preserved.
+type WeightedTopology struct {
+	TopicBasedTopology
+	latencies LatencyProvider // used to look up the measured round trip times to other nodes
+}
+
+// NewWeightedTopology returns an instance of the WeightedTopology, which weights
+// the nodes by the round trip times of the given latency provider.
+func NewWeightedTopology(nodeID flow.Identifier, logger zerolog.Logger, state protocol.State, latencies LatencyProvider) (*WeightedTopology, error) {
+	top, err := NewTopicBasedTopology(nodeID, logger, state)
+	if err != nil {
+		return nil, fmt.Errorf("could not create topic-based topology: %w", err)
+	}
+
+	t := &WeightedTopology{
+		TopicBasedTopology: *top,
+		latencies:          latencies,
+	}
+	t.logger = logger.With().Str("component:", "weighted-topology").Logger()
+	t.sample = t.weightedSample
+
+	return t, nil
+}
+
+// weightedSample returns a sample of the given size from the identity list, in
+// which each identity is included with a probability proportional to its
+// weight. The sample is deterministic for the same identities and weights.
+func (t *WeightedTopology) weightedSample(ids flow.IdentityList, size uint) flow.IdentityList {
+	if size >= uint(len(ids)) {
+		return ids.Copy()
+	}
+
+	// orders the identities canonically, so that the sample only depends on the set of identities
+	candidates := ids.Copy()
+	sort.Slice(candidates, func(i, j int) bool {
+		return order.ByNodeIDAsc(candidates[i], candidates[j])
+	})
+
+	var maxStake uint64
+	for _, id := range candidates {
+		if id.Stake > maxStake {
+			maxStake = id.Stake
+		}
+	}
+
+	// samples without replacement by drawing a key u^(1/w) for each identity with
+	// a uniform u and weight w, and keeping the identities with the largest keys
+	rng := rand.New(rand.NewSource(t.seed))
+	keys := make(map[flow.Identifier]float64, len(candidates))
+	for _, id := range candidates {
+		keys[id.NodeID] = math.Log(rng.Float64()) / t.weight(id, maxStake)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return keys[candidates[i].NodeID] > keys[candidates[j].NodeID]
+	})
+
+	return candidates[:size]
+}
+
+// weight returns the sampling weight of the given identity, which is the
+// product of its stake relative to the highest stake and a factor decreasing
+// with its round trip time.
+func (t *WeightedTopology) weight(id *flow.Identity, maxStake uint64) float64 {
+	stake := 1.0
+	if maxStake > 0 {
+		stake = math.Max(float64(id.Stake)/float64(maxStake), minStakeWeight)
+	}
+
+	rtt, ok := t.latencies.RTT(id.NodeID)
+	if !ok {
+		rtt = unknownRTT
+	}
+	latency := float64(rttWeightScale) / float64(rttWeightScale+rtt)
+
+	return stake * latency
+}
@@ -0,0 +1,127 @@
+package topology
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/filter"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// staticLatencies is a latency provider with fixed round trip times.
+type staticLatencies map[flow.Identifier]time.Duration
+
+func (s staticLatencies) RTT(nodeID flow.Identifier) (time.Duration, bool) {
+	rtt, ok := s[nodeID]
+	return rtt, ok
+}
+
+// TestWeightedTopology_PrefersLowLatency evaluates that nodes with a low round trip time are
+// preferred in the fanout, while the fanout keeps the size of the topic-based topology.
+func TestWeightedTopology_PrefersLowLatency(t *testing.T) {
+	ids := unittest.IdentityListFixture(101, unittest.WithRole(flow.RoleConsensus))
+	me := ids[0]
+	others := ids.Filter(filter.Not(filter.HasNodeID(me.NodeID)))
+
+	latencies := staticLatencies{}
+	near := make(map[flow.Identifier]bool)
+	for i, id := range others {
+		if i%2 == 0 {
+			latencies[id.NodeID] = 5 * time.Millisecond
+			near[id.NodeID] = true
+		} else {
+			latencies[id.NodeID] = 200 * time.Millisecond
+		}
+	}
+
+	top, err := NewWeightedTopology(me.NodeID, zerolog.Nop(), nil, latencies)
+	require.NoError(t, err)
+
+	fanout, err := top.GenerateFanout(ids, engine.ChannelsByRole(flow.RoleConsensus))
+	require.NoError(t, err)
+	require.Len(t, fanout, LinearFanout(len(others)))
+
+	nearCount := 0
+	for _, id := range fanout {
+		if near[id.NodeID] {
+			nearCount++
+		}
+	}
+	assert.Greater(t, nearCount, len(fanout)-nearCount)
+
+	// the fanout is deterministic for the same input and measurements
+	again, err := top.GenerateFanout(ids, engine.ChannelsByRole(flow.RoleConsensus))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, fanout, again)
+}
+
+// TestWeightedTopology_PrefersStake evaluates that nodes with a high stake are preferred in the fanout.
+func TestWeightedTopology_PrefersStake(t *testing.T) {
+	ids := unittest.IdentityListFixture(101, unittest.WithRole(flow.RoleConsensus))
+	me := ids[0]
+
+	staked := make(map[flow.Identifier]bool)
+	for i, id := range ids {
+		if i%2 == 0 {
+			id.Stake = 1000
+			staked[id.NodeID] = true
+		} else {
+			id.Stake = 10
+		}
+	}
+
+	top, err := NewWeightedTopology(me.NodeID, zerolog.Nop(), nil, staticLatencies{})
+	require.NoError(t, err)
+
+	fanout, err := top.GenerateFanout(ids, engine.ChannelsByRole(flow.RoleConsensus))
+	require.NoError(t, err)
+
+	stakedCount := 0
+	for _, id := range fanout {
+		if staked[id.NodeID] {
+			stakedCount++
+		}
+	}
+	assert.Greater(t, stakedCount, len(fanout)-stakedCount)
+}
+
+// TestWeightedTopology_Connectedness evaluates that the weighted topology keeps the components of the
+// channels connected, even if all nodes prefer the same peers.
+func TestWeightedTopology_Connectedness(t *testing.T) {
+	collectors := unittest.IdentityListFixture(30, unittest.WithRole(flow.RoleCollection))
+	others := unittest.IdentityListFixture(200, unittest.WithAllRolesExcept(flow.RoleCollection))
+	all := append(others, collectors...)
+	state, clusters := MockStateForCollectionNodes(t, collectors, 3)
+
+	// all nodes measure the same round trip times, so they prefer the same peers
+	latencies := staticLatencies{}
+	for i, id := range all {
+		latencies[id.NodeID] = time.Duration(i) * time.Millisecond
+	}
+
+	adjMap := make(map[flow.Identifier]flow.IdentityList)
+	for _, id := range all {
+		top, err := NewWeightedTopology(id.NodeID, zerolog.Nop(), state, latencies)
+		require.NoError(t, err)
+
+		fanout, err := top.GenerateFanout(all, engine.ChannelsByRole(id.Role))
+		require.NoError(t, err)
+		adjMap[id.NodeID] = fanout
+	}
+
+	for _, channel := range engine.Channels() {
+		if _, ok := engine.ClusterChannel(channel); ok {
+			continue
+		}
+		connectednessByChannel(t, adjMap, all, channel)
+	}
+	for _, cluster := range clusters {
+		connectedByCluster(t, adjMap, all, cluster)
+	}
+}
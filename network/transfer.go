@@ -0,0 +1,81 @@
+package network
+
+import (
+	"io"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Transfer is an inbound transfer of a large payload, e.g. a chunk data
+// response. The payload is streamed in fixed-size frames, which are checked
+// against their checksums on receipt, so that it can be consumed incrementally
+// instead of being held in memory as a whole.
+type Transfer interface {
+	// ID returns the identifier of the transfer, which is unique for its origin.
+	ID() flow.Identifier
+
+	// Channel returns the channel the transfer is sent on.
+	Channel() Channel
+
+	// Type returns the type of the transferred payload, in the format of the
+	// `Type` field of network messages.
+	Type() string
+
+	// Size returns the size of the payload in bytes.
+	Size() uint64
+
+	// Next returns the next frame of the payload. It blocks until the frame is
+	// received, and returns io.EOF once all frames have been returned.
+	Next() ([]byte, error)
+}
+
+// TransferEngine is implemented by engines which consume the frames of the
+// large payloads they receive as transfers themselves. The transfers to other
+// engines are decoded as events by the network, and processed by the engine
+// like the events sent to it as messages.
+type TransferEngine interface {
+	// ProcessTransfer processes an inbound transfer from the given origin. The
+	// frames of the transfer are only available until the method returns.
+	ProcessTransfer(originID flow.Identifier, transfer Transfer) error
+}
+
+// TransferConduit is implemented by conduits which can stream large payloads
+// to a recipient as a transfer.
+type TransferConduit interface {
+	Conduit
+
+	// Transfer reliably streams the payload of the given size and type to the
+	// given recipient, which receives it as a Transfer. A transfer interrupted
+	// by a connection failure is resumed from the first frame the recipient
+	// is missing.
+	Transfer(targetID flow.Identifier, payloadType string, payload io.ReaderAt, size uint64) error
+
+	// TransferEvent reliably streams the given event to the given recipient
+	// as a transfer, which the recipient decodes as it receives its frames.
+	TransferEvent(event interface{}, targetID flow.Identifier) error
+}
+
+// transferReader reads the payload of a transfer frame by frame.
+type transferReader struct {
+	transfer Transfer
+	frame    []byte
+}
+
+// NewTransferReader returns a reader over the payload of the given transfer,
+// which only holds the frame being read in memory.
+func NewTransferReader(transfer Transfer) io.Reader {
+	return &transferReader{transfer: transfer}
+}
+
+func (r *transferReader) Read(p []byte) (int, error) {
+	for len(r.frame) == 0 {
+		frame, err := r.transfer.Next()
+		if err != nil {
+			return 0, err
+		}
+		r.frame = frame
+	}
+	n := copy(p, r.frame)
+	r.frame = r.frame[n:]
+	return n, nil
+}
@@ -7,3 +7,12 @@ type MessageValidator interface {
 	// Validate validates the message and returns true if the message is to be retained and false if it needs to be dropped
 	Validate(msg message.Message) bool
 }
+
+// PenalizingValidator is a message validator whose failures are due to the
+// misbehaviour of the message origin. The middleware penalizes the origin of
+// messages failing the validator for the reason it returns.
+type PenalizingValidator interface {
+	MessageValidator
+	// PenaltyReason returns the reason the origins of dropped messages are penalized for.
+	PenaltyReason() string
+}
@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/network"
+	"github.com/onflow/flow-go/network/message"
+)
+
+var _ network.PenalizingValidator = &AuthorizedSenderValidator{}
+
+// IdentityFunc returns the identity of the node with the given ID, if it is
+// part of the identity table.
+type IdentityFunc func(nodeID flow.Identifier) (*flow.Identity, bool)
+
+// AuthorizedSenderValidator filters out messages whose origin's role is not
+// authorized to send their message type on their channel.
+type AuthorizedSenderValidator struct {
+	log      zerolog.Logger
+	identity IdentityFunc
+	metrics  module.NetworkMetrics
+}
+
+// NewAuthorizedSenderValidator returns a new AuthorizedSenderValidator, which
+// looks up the roles of origins with the given identity function.
+func NewAuthorizedSenderValidator(log zerolog.Logger, identity IdentityFunc, metrics module.NetworkMetrics) *AuthorizedSenderValidator {
+	av := &AuthorizedSenderValidator{
+		log:      log.With().Str("validator", "authorized_sender").Logger(),
+		identity: identity,
+		metrics:  metrics,
+	}
+	return av
+}
+
+// PenaltyReason returns the reason the origins of unauthorized messages are
+// penalized for.
+func (av *AuthorizedSenderValidator) PenaltyReason() string {
+	return "unauthorized_sender"
+}
+
+// Validate returns true if the role of the message origin is authorized to
+// send the message type on the message channel, else it returns false.
+func (av *AuthorizedSenderValidator) Validate(msg message.Message) bool {
+	originID := flow.HashToID(msg.OriginID)
+	channel := network.Channel(msg.ChannelID)
+
+	identity, ok := av.identity(originID)
+	if !ok {
+		av.log.Warn().
+			Hex("origin_id", msg.OriginID).
+			Str("channel", msg.ChannelID).
+			Str("message_type", msg.Type).
+			Msg("dropping message from unknown origin")
+		av.metrics.NetworkUnauthorizedMessagesDropped(msg.ChannelID, msg.Type, "unknown")
+		return false
+	}
+
+	roles, ok := engine.SenderRolesByChannel(channel, msg.Type)
+	if ok && roles.Contains(identity.Role) {
+		return true
+	}
+
+	av.log.Warn().
+		Hex("origin_id", msg.OriginID).
+		Str("role", identity.Role.String()).
+		Str("channel", msg.ChannelID).
+		Str("message_type", msg.Type).
+		Msg("dropping message from unauthorized sender")
+	av.metrics.NetworkUnauthorizedMessagesDropped(msg.ChannelID, msg.Type, identity.Role.String())
+
+	return false
+}
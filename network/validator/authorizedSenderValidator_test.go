@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	mockmodule "github.com/onflow/flow-go/module/mock"
+	"github.com/onflow/flow-go/network/message"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestAuthorizedSenderValidator tests that messages are only retained if the
+// role of their origin is authorized to send their type on their channel.
+func TestAuthorizedSenderValidator(t *testing.T) {
+	collection := unittest.IdentityFixture(unittest.WithRole(flow.RoleCollection))
+	consensus := unittest.IdentityFixture(unittest.WithRole(flow.RoleConsensus))
+	identities := map[flow.Identifier]*flow.Identity{
+		collection.NodeID: collection,
+		consensus.NodeID:  consensus,
+	}
+	identity := func(nodeID flow.Identifier) (*flow.Identity, bool) {
+		id, ok := identities[nodeID]
+		return id, ok
+	}
+
+	msg := func(originID flow.Identifier, channel string, messageType string) message.Message {
+		return message.Message{
+			ChannelID: channel,
+			OriginID:  originID[:],
+			Type:      messageType,
+		}
+	}
+
+	t.Run("authorized sender", func(t *testing.T) {
+		metrics := &mockmodule.NetworkMetrics{}
+		av := NewAuthorizedSenderValidator(zerolog.Nop(), identity, metrics)
+
+		assert.True(t, av.Validate(msg(consensus.NodeID, engine.ConsensusCommittee.String(), "messages.BlockProposal")))
+		assert.True(t, av.Validate(msg(collection.NodeID, engine.PushGuarantees.String(), "flow.CollectionGuarantee")))
+
+		// channels without restricted message types fall back to the roles of the channel
+		assert.True(t, av.Validate(msg(collection.NodeID, engine.TestNetwork.String(), "messages.TestMessage")))
+
+		metrics.AssertNotCalled(t, "NetworkUnauthorizedMessagesDropped", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("authorized sender on cluster channel", func(t *testing.T) {
+		metrics := &mockmodule.NetworkMetrics{}
+		av := NewAuthorizedSenderValidator(zerolog.Nop(), identity, metrics)
+
+		channel := engine.ChannelConsensusCluster(flow.ChainID("cluster")).String()
+		assert.True(t, av.Validate(msg(collection.NodeID, channel, "messages.ClusterBlockProposal")))
+	})
+
+	t.Run("unauthorized role", func(t *testing.T) {
+		metrics := &mockmodule.NetworkMetrics{}
+		av := NewAuthorizedSenderValidator(zerolog.Nop(), identity, metrics)
+
+		channel := engine.ConsensusCommittee.String()
+		metrics.On("NetworkUnauthorizedMessagesDropped", channel, "messages.BlockProposal", flow.RoleCollection.String()).Once()
+
+		assert.False(t, av.Validate(msg(collection.NodeID, channel, "messages.BlockProposal")))
+		assert.Equal(t, "unauthorized_sender", av.PenaltyReason())
+
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("unauthorized message type", func(t *testing.T) {
+		metrics := &mockmodule.NetworkMetrics{}
+		av := NewAuthorizedSenderValidator(zerolog.Nop(), identity, metrics)
+
+		channel := engine.PushBlocks.String()
+		metrics.On("NetworkUnauthorizedMessagesDropped", channel, "messages.BlockVote", flow.RoleConsensus.String()).Once()
+
+		assert.False(t, av.Validate(msg(consensus.NodeID, channel, "messages.BlockVote")))
+
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		metrics := &mockmodule.NetworkMetrics{}
+		av := NewAuthorizedSenderValidator(zerolog.Nop(), identity, metrics)
+
+		channel := engine.ConsensusCommittee.String()
+		metrics.On("NetworkUnauthorizedMessagesDropped", channel, "messages.BlockProposal", "unknown").Once()
+
+		assert.False(t, av.Validate(msg(unittest.IdentifierFixture(), channel, "messages.BlockProposal")))
+
+		metrics.AssertExpectations(t)
+	})
+}
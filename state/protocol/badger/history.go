@@ -0,0 +1,108 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+type History struct {
+	state *State
+}
+
+func (h *History) Epochs() ([]*protocol.EpochInfo, error) {
+
+	var records []flow.EpochRecord
+	err := h.state.db.View(operation.LookupEpochRecords(&records))
+	if err != nil {
+		return nil, fmt.Errorf("could not look up epoch records: %w", err)
+	}
+
+	epochs := make([]*protocol.EpochInfo, 0, len(records))
+	for _, record := range records {
+		epoch, err := h.info(record)
+		if err != nil {
+			return nil, fmt.Errorf("could not get epoch (counter=%d): %w", record.Counter, err)
+		}
+		epochs = append(epochs, epoch)
+	}
+
+	return epochs, nil
+}
+
+func (h *History) Epoch(counter uint64) (*protocol.EpochInfo, error) {
+
+	var record flow.EpochRecord
+	err := h.state.db.View(operation.RetrieveEpochRecord(counter, &record))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve epoch record: %w", err)
+	}
+
+	return h.info(record)
+}
+
+func (h *History) AtHeight(height uint64) (*protocol.EpochInfo, error) {
+
+	var counter uint64
+	err := h.state.db.View(func(tx *badger.Txn) error {
+		var finalized uint64
+		err := operation.RetrieveFinalizedHeight(&finalized)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized height: %w", err)
+		}
+		if height > finalized {
+			return fmt.Errorf("height %d is above finalized height %d: %w", height, finalized, storage.ErrNotFound)
+		}
+		return operation.LookupEpochAtHeight(height, &counter)(tx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not look up epoch at height %d: %w", height, err)
+	}
+
+	return h.Epoch(counter)
+}
+
+func (h *History) Identity(nodeID flow.Identifier, height uint64) (*flow.Identity, error) {
+
+	epoch, err := h.AtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, ok := epoch.Setup.Participants.ByNodeID(nodeID)
+	if !ok {
+		return nil, protocol.IdentityNotFoundError{NodeID: nodeID}
+	}
+
+	return identity, nil
+}
+
+// info completes the given epoch record with the service events of the epoch.
+func (h *History) info(record flow.EpochRecord) (*protocol.EpochInfo, error) {
+
+	setup, err := h.state.epoch.setups.ByID(record.SetupID)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve epoch setup: %w", err)
+	}
+
+	var commit *flow.EpochCommit
+	if record.CommitID != flow.ZeroID {
+		commit, err = h.state.epoch.commits.ByID(record.CommitID)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve epoch commit: %w", err)
+		}
+	}
+
+	epoch := &protocol.EpochInfo{
+		EpochRecord: record,
+		Setup:       setup,
+		Commit:      commit,
+	}
+
+	return epoch, nil
+}
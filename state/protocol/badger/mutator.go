@@ -501,6 +501,10 @@ func (m *FollowerState) Finalize(blockID flow.Identifier) error {
 	if err != nil {
 		return fmt.Errorf("could not retrieve epoch state: %w", err)
 	}
+	parentStatus, err := m.epoch.statuses.ByBlockID(header.ParentID)
+	if err != nil {
+		return fmt.Errorf("could not retrieve parent epoch state: %w", err)
+	}
 	setup, err := m.epoch.setups.ByID(epochStatus.CurrentEpoch.SetupID)
 	if err != nil {
 		return fmt.Errorf("could not retrieve setup event for current epoch: %w", err)
@@ -539,7 +543,7 @@ func (m *FollowerState) Finalize(blockID flow.Identifier) error {
 	}
 
 	// FINALLY: any block that is finalized is already a valid extension;
-	// in order to make it final, we need to do just three things:
+	// in order to make it final, we need to do just four things:
 	// 1) Map its height to its index; there can no longer be other blocks at
 	// this height, as it becomes immutable.
 	// 2) Forward the last finalized height to its height as well. We now have
@@ -547,6 +551,8 @@ func (m *FollowerState) Finalize(blockID flow.Identifier) error {
 	// 3) Forward the last sealed height to the height of the block its last
 	// seal sealed. This could actually stay the same if it has no seals in its
 	// payload, in which case the parent's seal is the same.
+	// 4) Record epoch transitions and phase changes in the epoch history,
+	// which is kept even when the blocks of past epochs are pruned.
 
 	err = operation.RetryOnConflict(m.db.Update, func(tx *badger.Txn) error {
 		err = operation.IndexBlockHeight(header.Height, blockID)(tx)
@@ -561,6 +567,10 @@ func (m *FollowerState) Finalize(blockID flow.Identifier) error {
 		if err != nil {
 			return fmt.Errorf("could not update sealed height: %w", err)
 		}
+		err = operation.IndexEpochHistory(header, parentStatus, epochStatus)(tx)
+		if err != nil {
+			return fmt.Errorf("could not index epoch history: %w", err)
+		}
 		return nil
 	})
 	if err != nil {
@@ -650,6 +650,27 @@ func TestExtendEpochTransitionValid(t *testing.T) {
 		err = state.Finalize(block9.ID())
 		require.NoError(t, err)
 		consumer.AssertCalled(t, "EpochTransition", epoch2Setup.Counter, block9.Header)
+
+		// the epoch history should record the phases and the transition
+		epochs, err := state.History().Epochs()
+		require.NoError(t, err)
+		require.Len(t, epochs, 2)
+		assert.Equal(t, epoch1Setup.Counter, epochs[0].Counter)
+		assert.Equal(t, block3.Header.Height, epochs[0].SetupPhaseHeight)
+		assert.Equal(t, block6.Header.Height, epochs[0].CommittedPhaseHeight)
+		assert.Equal(t, block8.Header.Height, epochs[0].FinalHeight)
+		assert.Equal(t, epoch2Setup.ID(), epochs[1].Setup.ID())
+		assert.Equal(t, epoch2Commit.ID(), epochs[1].Commit.ID())
+		assert.Equal(t, block9.Header.Height, epochs[1].FirstHeight)
+
+		epoch, err := state.History().AtHeight(block8.Header.Height)
+		require.NoError(t, err)
+		assert.Equal(t, epoch1Setup.Counter, epoch.Counter)
+
+		participant := epoch2Setup.Participants[0]
+		identity, err := state.History().Identity(participant.NodeID, block9.Header.Height)
+		require.NoError(t, err)
+		assert.Equal(t, participant.ID(), identity.ID())
 	})
 }
 
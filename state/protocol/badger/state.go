@@ -364,6 +364,14 @@ func (state *State) bootstrapEpoch(root protocol.Snapshot) func(*badger.Txn) err
 			}
 		}
 
+		// record the epochs known as of the root block, which is the head of
+		// the segment, in the epoch history
+		head := segment[len(segment)-1]
+		err = operation.IndexEpochHistory(head.Header, nil, status)(tx)
+		if err != nil {
+			return fmt.Errorf("could not index epoch history: %w", err)
+		}
+
 		return nil
 	}
 }
@@ -395,6 +403,10 @@ func (s *State) Params() protocol.Params {
 	return &Params{state: s}
 }
 
+func (s *State) History() protocol.History {
+	return &History{state: s}
+}
+
 func (s *State) Sealed() protocol.Snapshot {
 	// retrieve the latest sealed height
 	var sealed uint64
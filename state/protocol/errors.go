@@ -20,6 +20,10 @@ var (
 	// not been committed and information is queried that is only accessible
 	// in the EpochCommitted phase.
 	ErrEpochNotCommitted = fmt.Errorf("queried info from EpochCommit event before it was emitted")
+
+	// ErrNoBootstrapHeight is a sentinel error returned when no finalized
+	// block of an epoch can be the reference block of a root snapshot.
+	ErrNoBootstrapHeight = fmt.Errorf("no finalized block of the epoch can be bootstrapped from")
 )
 
 type IdentityNotFoundError struct {
@@ -0,0 +1,38 @@
+package protocol
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// History gives access to all epochs the local node knows of. Unlike the
+// epochs of a snapshot, which are relative to its reference block, the history
+// is independent of whether the blocks of an epoch are still stored, so it
+// also covers epochs whose blocks were pruned.
+type History interface {
+
+	// Epochs returns all known epochs, ordered by counter.
+	Epochs() ([]*EpochInfo, error)
+
+	// Epoch returns the epoch with the given counter. It returns
+	// storage.ErrNotFound if the epoch is not known.
+	Epoch(counter uint64) (*EpochInfo, error)
+
+	// AtHeight returns the epoch which the finalized block with the given
+	// height belongs to. It returns storage.ErrNotFound if the height is
+	// below the first known height of the history or above the finalized
+	// height.
+	AtHeight(height uint64) (*EpochInfo, error)
+
+	// Identity returns the identity of the node with the given ID, with its
+	// role and stake, as a participant of the epoch of the finalized block
+	// with the given height. It returns an IdentityNotFoundError if the node
+	// did not participate in that epoch.
+	Identity(nodeID flow.Identifier, height uint64) (*flow.Identity, error)
+}
+
+// EpochInfo contains all information about an epoch known to the local node.
+type EpochInfo struct {
+	flow.EpochRecord                   // the service events and height boundaries of the epoch
+	Setup            *flow.EpochSetup  // the participants, views and cluster assignments of the epoch
+	Commit           *flow.EpochCommit // the cluster QCs and DKG keys of the epoch, nil if not committed yet
+}
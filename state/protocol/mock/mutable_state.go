@@ -90,6 +90,22 @@ func (_m *MutableState) Finalize(blockID flow.Identifier) error {
 	return r0
 }
 
+// History provides a mock function with given fields:
+func (_m *MutableState) History() protocol.History {
+	ret := _m.Called()
+
+	var r0 protocol.History
+	if rf, ok := ret.Get(0).(func() protocol.History); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(protocol.History)
+		}
+	}
+
+	return r0
+}
+
 // MarkValid provides a mock function with given fields: blockID
 func (_m *MutableState) MarkValid(blockID flow.Identifier) error {
 	ret := _m.Called(blockID)
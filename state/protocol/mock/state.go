@@ -62,6 +62,22 @@ func (_m *State) Final() protocol.Snapshot {
 	return r0
 }
 
+// History provides a mock function with given fields:
+func (_m *State) History() protocol.History {
+	ret := _m.Called()
+
+	var r0 protocol.History
+	if rf, ok := ret.Get(0).(func() protocol.History); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(protocol.History)
+		}
+	}
+
+	return r0
+}
+
 // Params provides a mock function with given fields:
 func (_m *State) Params() protocol.Params {
 	ret := _m.Called()
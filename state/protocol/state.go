@@ -33,6 +33,10 @@ type State interface {
 	// the protocol state, and can thus represent an ambiguous state that was or
 	// will never be finalized.
 	AtBlockID(blockID flow.Identifier) Snapshot
+
+	// History gives access to all epochs known to the local node, including
+	// the epochs whose blocks are no longer stored.
+	History() History
 }
 
 type MutableState interface {
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -22,3 +23,65 @@ func IsNodeStakedAt(snapshot Snapshot, id flow.Identifier) (bool, error) {
 
 	return staked && !ejected, nil
 }
+
+// EpochBootstrapHeight returns the lowest finalized height in the epoch with
+// the given counter, at which a root snapshot can be taken to bootstrap a node.
+// As the sealing segment of a root snapshot must be within a single epoch, it
+// is the lowest height of the epoch whose latest sealed block is in the epoch
+// too. Returns ErrNoBootstrapHeight if there is no such finalized height yet,
+// or the epoch ended before any of its blocks was sealed.
+func EpochBootstrapHeight(state State, counter uint64) (uint64, error) {
+	root, err := state.Params().Root()
+	if err != nil {
+		return 0, fmt.Errorf("could not get root: %w", err)
+	}
+	final, err := state.Final().Head()
+	if err != nil {
+		return 0, fmt.Errorf("could not get finalized head: %w", err)
+	}
+
+	// the epoch of the sealed block is non-decreasing with the height of the
+	// finalized blocks, so we search the lowest height whose sealed block is
+	// in the epoch or a later one
+	var searchErr error
+	epochOfSeal := func(height uint64) (uint64, error) {
+		_, seal, err := state.AtHeight(height).SealedResult()
+		if err != nil {
+			return 0, fmt.Errorf("could not get seal at height %d: %w", height, err)
+		}
+		return state.AtBlockID(seal.BlockID).Epochs().Current().Counter()
+	}
+	offset := sort.Search(int(final.Height-root.Height)+1, func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		sealed, err := epochOfSeal(root.Height + uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return sealed >= counter
+	})
+	if searchErr != nil {
+		return 0, fmt.Errorf("could not search epoch bootstrap height: %w", searchErr)
+	}
+	if offset > int(final.Height-root.Height) {
+		return 0, ErrNoBootstrapHeight
+	}
+
+	// both the block at the height and its sealed block must be in the epoch
+	height := root.Height + uint64(offset)
+	sealed, err := epochOfSeal(height)
+	if err != nil {
+		return 0, err
+	}
+	head, err := state.AtHeight(height).Epochs().Current().Counter()
+	if err != nil {
+		return 0, fmt.Errorf("could not get epoch at height %d: %w", height, err)
+	}
+	if sealed != counter || head != counter {
+		return 0, ErrNoBootstrapHeight
+	}
+
+	return height, nil
+}
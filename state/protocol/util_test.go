@@ -0,0 +1,79 @@
+package protocol_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestEpochBootstrapHeight(t *testing.T) {
+
+	// the chain from the root height 10 to the finalized height 30 switches
+	// from epoch 1 to epoch 2 at height 20, and each block seals the block
+	// three heights below it
+	const rootHeight, finalHeight, switchHeight = 10, 30, 20
+	headers := make(map[uint64]*flow.Header)
+	state := new(mock.State)
+	for height := uint64(rootHeight); height <= finalHeight; height++ {
+		header := unittest.BlockHeaderFixture()
+		header.Height = height
+		headers[height] = &header
+
+		counter := uint64(1)
+		if height >= switchHeight {
+			counter = 2
+		}
+		epoch := new(mock.Epoch)
+		epoch.On("Counter").Return(counter, nil)
+		epochs := new(mock.EpochQuery)
+		epochs.On("Current").Return(epoch)
+
+		sealed := height - 3
+		if sealed < rootHeight {
+			sealed = rootHeight
+		}
+
+		snapshot := new(mock.Snapshot)
+		snapshot.On("Head").Return(&header, nil)
+		snapshot.On("Epochs").Return(epochs)
+		snapshot.On("SealedResult").Return(
+			func() *flow.ExecutionResult { return nil },
+			func() *flow.Seal { return &flow.Seal{BlockID: headers[sealed].ID()} },
+			func() error { return nil },
+		)
+		state.On("AtHeight", height).Return(snapshot)
+		state.On("AtBlockID", header.ID()).Return(snapshot)
+	}
+
+	params := new(mock.Params)
+	params.On("Root").Return(headers[rootHeight], nil)
+	state.On("Params").Return(params)
+	final := new(mock.Snapshot)
+	final.On("Head").Return(headers[finalHeight], nil)
+	state.On("Final").Return(final)
+
+	t.Run("first epoch", func(t *testing.T) {
+		height, err := protocol.EpochBootstrapHeight(state, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(rootHeight), height)
+	})
+
+	t.Run("epoch switch", func(t *testing.T) {
+		// the first block whose sealed block is in epoch 2
+		height, err := protocol.EpochBootstrapHeight(state, 2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(switchHeight+3), height)
+	})
+
+	t.Run("future epoch", func(t *testing.T) {
+		_, err := protocol.EpochBootstrapHeight(state, 3)
+		assert.True(t, errors.Is(err, protocol.ErrNoBootstrapHeight))
+	})
+}
@@ -2,6 +2,8 @@ package badger
 
 import (
 	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/storage"
 )
 
 type Batch struct {
@@ -17,7 +19,7 @@ func NewBatch(db *badger.DB) *Batch {
 	}
 }
 
-func (b *Batch) GetWriter() *badger.WriteBatch {
+func (b *Batch) GetWriter() storage.Writer {
 	return b.writer
 }
 
@@ -3,9 +3,11 @@ package badger
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/dgraph-io/badger/v2"
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/vmihailenco/msgpack/v4"
 
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/metrics"
@@ -58,6 +60,11 @@ func withResource(resource string) func(*Cache) {
 	}
 }
 
+// sizeSampleInterval is the number of resources added to a cache between two
+// samples of their encoded size, from which the memory size of the cache is
+// estimated, as encoding every resource would be too expensive.
+const sizeSampleInterval = 100
+
 type Cache struct {
 	metrics  module.CacheMetrics
 	limit    uint
@@ -65,6 +72,13 @@ type Cache struct {
 	retrieve retrieveFunc
 	resource string
 	cache    *lru.Cache
+	usage    struct {
+		sync.Mutex
+		hits      uint64 // hits since the usage was last reset
+		misses    uint64 // misses since the usage was last reset
+		added     uint64 // number of resources added, for sampling their size
+		entrySize uint64 // moving average of the sampled resource sizes, in bytes
+	}
 }
 
 func newCache(collector module.CacheMetrics, options ...func(*Cache)) *Cache {
@@ -78,8 +92,18 @@ func newCache(collector module.CacheMetrics, options ...func(*Cache)) *Cache {
 	for _, option := range options {
 		option(&c)
 	}
-	c.cache, _ = lru.New(int(c.limit))
+	c.cache, _ = lru.NewWithEvict(int(c.limit), func(interface{}, interface{}) {
+		c.metrics.CacheEviction(c.resource)
+	})
 	c.metrics.CacheEntries(c.resource, uint(c.cache.Len()))
+	c.metrics.CacheLimit(c.resource, c.limit)
+
+	// caches created with a cache budget are resized within the budget
+	budget, ok := collector.(*CacheBudget)
+	if ok {
+		budget.register(&c)
+	}
+
 	return &c
 }
 
@@ -92,6 +116,9 @@ func (c *Cache) Get(key interface{}) func(*badger.Txn) (interface{}, error) {
 		resource, cached := c.cache.Get(key)
 		if cached {
 			c.metrics.CacheHit(c.resource)
+			c.usage.Lock()
+			c.usage.hits++
+			c.usage.Unlock()
 			return resource, nil
 		}
 
@@ -105,12 +132,12 @@ func (c *Cache) Get(key interface{}) func(*badger.Txn) (interface{}, error) {
 		}
 
 		c.metrics.CacheMiss(c.resource)
+		c.usage.Lock()
+		c.usage.misses++
+		c.usage.Unlock()
 
 		// cache the resource and eject least recently used one if we reached limit
-		evicted := c.cache.Add(key, resource)
-		if !evicted {
-			c.metrics.CacheEntries(c.resource, uint(c.cache.Len()))
-		}
+		c.add(key, resource)
 
 		return resource, nil
 	}
@@ -127,11 +154,73 @@ func (c *Cache) Put(key interface{}, resource interface{}) func(*badger.Txn) err
 		}
 
 		// cache the resource and eject least recently used one if we reached limit
-		evicted := c.cache.Add(key, resource)
-		if !evicted {
-			c.metrics.CacheEntries(c.resource, uint(c.cache.Len()))
-		}
+		c.add(key, resource)
 
 		return nil
 	}
 }
+
+// add adds the resource to the cache, and samples its size every
+// sizeSampleInterval resources to estimate the memory size of the cache.
+func (c *Cache) add(key interface{}, resource interface{}) {
+
+	evicted := c.cache.Add(key, resource)
+
+	c.usage.Lock()
+	sample := c.usage.added%sizeSampleInterval == 0
+	c.usage.added++
+	c.usage.Unlock()
+
+	if sample {
+		data, err := msgpack.Marshal(resource)
+		if err == nil {
+			size := uint64(len(data))
+			c.usage.Lock()
+			if c.usage.entrySize == 0 {
+				c.usage.entrySize = size
+			} else {
+				c.usage.entrySize = (7*c.usage.entrySize + size) / 8
+			}
+			c.usage.Unlock()
+		}
+	}
+
+	if !evicted || sample {
+		c.reportSize()
+	}
+}
+
+// reportSize reports the number of entries of the cache and its estimated
+// memory size.
+func (c *Cache) reportSize() {
+	entries := c.cache.Len()
+	c.metrics.CacheEntries(c.resource, uint(entries))
+	c.metrics.CacheSize(c.resource, uint64(entries)*c.entrySize())
+}
+
+// entrySize returns the estimated memory size of a resource in the cache, or
+// zero if no resource was added yet.
+func (c *Cache) entrySize() uint64 {
+	c.usage.Lock()
+	defer c.usage.Unlock()
+	return c.usage.entrySize
+}
+
+// resetUsage returns the number of hits and misses since the last reset, and
+// resets them.
+func (c *Cache) resetUsage() (hits uint64, misses uint64) {
+	c.usage.Lock()
+	defer c.usage.Unlock()
+	hits, misses = c.usage.hits, c.usage.misses
+	c.usage.hits, c.usage.misses = 0, 0
+	return hits, misses
+}
+
+// resize changes the number of resources the cache can hold, evicting the
+// least recently used resources if it holds more than that.
+func (c *Cache) resize(limit uint) {
+	c.cache.Resize(int(limit))
+	c.limit = limit
+	c.metrics.CacheLimit(c.resource, limit)
+	c.reportSize()
+}
@@ -0,0 +1,133 @@
+package badger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/module"
+)
+
+const (
+	// DefaultCacheBudgetInterval is the default interval between two rounds
+	// of resizing the caches within the budget.
+	DefaultCacheBudgetInterval = time.Minute
+
+	// cacheTargetHitRate is the hit rate below which a full cache is grown.
+	cacheTargetHitRate = 0.9
+
+	// cacheGrowthFactor is the factor by which a cache is grown per round.
+	cacheGrowthFactor = 1.25
+
+	// minCacheLimit is the number of resources a cache can hold at least,
+	// however small the budget.
+	minCacheLimit = 16
+)
+
+// CacheBudget resizes the caches of the storage layer within a global memory
+// budget. Caches are resized by the budget when they are created with it as
+// their cache metrics, which it forwards to the wrapped collector, so that a
+// node configures it once for all of its storage.
+//
+// In each round, the caches which are full and have a hit rate below the
+// target since the previous round are grown. If the estimated memory size of
+// all caches at their new limits exceeds the budget, all caches are shrunk in
+// proportion, which over time moves memory from the caches with the highest
+// hit rates to the ones with the lowest.
+type CacheBudget struct {
+	module.CacheMetrics
+	unit     *engine.Unit
+	log      zerolog.Logger
+	budget   uint64
+	interval time.Duration
+	mu       sync.Mutex
+	caches   []*Cache
+}
+
+// NewCacheBudget creates a new budget of the given number of bytes for the
+// caches created with it, which are resized in the given interval.
+func NewCacheBudget(log zerolog.Logger, collector module.CacheMetrics, budget uint64, interval time.Duration) (*CacheBudget, error) {
+
+	if budget == 0 {
+		return nil, fmt.Errorf("cache budget must be positive")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("cache budget interval must be positive (%s)", interval)
+	}
+
+	b := &CacheBudget{
+		CacheMetrics: collector,
+		unit:         engine.NewUnit(),
+		log:          log.With().Str("component", "cache_budget").Logger(),
+		budget:       budget,
+		interval:     interval,
+	}
+
+	return b, nil
+}
+
+// Ready returns a channel that is closed once the caches are resized
+// periodically.
+func (b *CacheBudget) Ready() <-chan struct{} {
+	b.unit.LaunchPeriodically(b.Resize, b.interval, b.interval)
+	return b.unit.Ready()
+}
+
+// Done returns a channel that is closed once the running round of resizing
+// has finished.
+func (b *CacheBudget) Done() <-chan struct{} {
+	return b.unit.Done()
+}
+
+// register adds the cache to the caches resized within the budget.
+func (b *CacheBudget) register(cache *Cache) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.caches = append(b.caches, cache)
+}
+
+// Resize resizes the caches within the budget, based on their hit rates since
+// the previous round.
+func (b *CacheBudget) Resize() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// grow the full caches which miss too often
+	limits := make([]float64, len(b.caches))
+	var size float64
+	for i, cache := range b.caches {
+		hits, misses := cache.resetUsage()
+		limit := float64(cache.limit)
+		full := uint(cache.cache.Len()) >= cache.limit
+		if full && misses > 0 && float64(hits)/float64(hits+misses) < cacheTargetHitRate {
+			limit *= cacheGrowthFactor
+		}
+		limits[i] = limit
+		size += limit * float64(cache.entrySize())
+	}
+
+	// shrink all caches in proportion if they exceed the budget
+	scale := 1.0
+	if size > float64(b.budget) {
+		scale = float64(b.budget) / size
+	}
+
+	for i, cache := range b.caches {
+		limit := uint(limits[i] * scale)
+		if limit < minCacheLimit {
+			limit = minCacheLimit
+		}
+		if limit == cache.limit {
+			continue
+		}
+		b.log.Debug().
+			Str("resource", cache.resource).
+			Uint("from_limit", cache.limit).
+			Uint("to_limit", limit).
+			Msg("resizing cache")
+		cache.resize(limit)
+	}
+}
@@ -0,0 +1,114 @@
+package badger
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+// evictionCollector counts the evictions reported by the caches.
+type evictionCollector struct {
+	*metrics.NoopCollector
+	evictions map[string]int
+}
+
+func (e *evictionCollector) CacheEviction(resource string) {
+	e.evictions[resource]++
+}
+
+// testCache creates a cache of the given limit, which retrieves resources of
+// 100 bytes for any key.
+func testCache(budget *CacheBudget, resource string, limit uint) *Cache {
+	retrieve := func(key interface{}) func(*badger.Txn) (interface{}, error) {
+		return func(*badger.Txn) (interface{}, error) {
+			return make([]byte, 100), nil
+		}
+	}
+	return newCache(budget, withLimit(limit), withRetrieve(retrieve), withResource(resource))
+}
+
+// get gets the resources with the given keys from the cache.
+func get(t *testing.T, cache *Cache, keys ...int) {
+	for _, key := range keys {
+		_, err := cache.Get(key)(nil)
+		require.NoError(t, err)
+	}
+}
+
+func TestCacheBudget_Grow(t *testing.T) {
+	collector := &evictionCollector{NoopCollector: metrics.NewNoopCollector(), evictions: make(map[string]int)}
+	budget, err := NewCacheBudget(zerolog.Nop(), collector, 1<<20, DefaultCacheBudgetInterval)
+	require.NoError(t, err)
+
+	// the missing cache is full and misses on every access, while the hitting
+	// cache hits on most accesses
+	missing := testCache(budget, "missing", 100)
+	hitting := testCache(budget, "hitting", 100)
+	for i := 0; i < 200; i++ {
+		get(t, missing, i)
+	}
+	get(t, hitting, 1, 2, 3)
+	for i := 0; i < 100; i++ {
+		get(t, hitting, 1, 2, 3)
+	}
+
+	assert.Equal(t, 100, collector.evictions["missing"])
+	assert.Equal(t, 0, collector.evictions["hitting"])
+
+	budget.Resize()
+	assert.Equal(t, uint(125), missing.limit)
+	assert.Equal(t, uint(100), hitting.limit)
+
+	// without accesses, no cache is resized
+	budget.Resize()
+	assert.Equal(t, uint(125), missing.limit)
+	assert.Equal(t, uint(100), hitting.limit)
+}
+
+func TestCacheBudget_Shrink(t *testing.T) {
+	budget, err := NewCacheBudget(zerolog.Nop(), metrics.NewNoopCollector(), 10000, DefaultCacheBudgetInterval)
+	require.NoError(t, err)
+
+	// both caches hold 100 resources of roughly 100 bytes, so they exceed the
+	// budget of 10000 bytes by about twice
+	first := testCache(budget, "first", 100)
+	second := testCache(budget, "second", 100)
+	for i := 0; i < 100; i++ {
+		get(t, first, i)
+		get(t, second, i)
+	}
+
+	budget.Resize()
+	assert.Less(t, first.limit, uint(50))
+	assert.Equal(t, first.limit, second.limit)
+	assert.Equal(t, int(first.limit), first.cache.Len())
+
+	size := uint64(first.limit)*first.entrySize() + uint64(second.limit)*second.entrySize()
+	assert.LessOrEqual(t, size, uint64(10000))
+}
+
+func TestCacheBudget_MinLimit(t *testing.T) {
+	budget, err := NewCacheBudget(zerolog.Nop(), metrics.NewNoopCollector(), 1, DefaultCacheBudgetInterval)
+	require.NoError(t, err)
+
+	cache := testCache(budget, "cache", 100)
+	for i := 0; i < 100; i++ {
+		get(t, cache, i)
+	}
+
+	budget.Resize()
+	assert.Equal(t, uint(minCacheLimit), cache.limit)
+}
+
+func TestNewCacheBudget_Invalid(t *testing.T) {
+	_, err := NewCacheBudget(zerolog.Nop(), metrics.NewNoopCollector(), 0, DefaultCacheBudgetInterval)
+	assert.Error(t, err)
+
+	_, err = NewCacheBudget(zerolog.Nop(), metrics.NewNoopCollector(), 1<<20, 0)
+	assert.Error(t, err)
+}
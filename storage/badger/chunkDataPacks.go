@@ -3,18 +3,19 @@ package badger
 import (
 	"fmt"
 
-	"github.com/dgraph-io/badger/v2"
-
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/storage/badger/operation"
 )
 
+// ChunkDataPacks stores chunk data packs in a backend-neutral key-value store,
+// so that their large values can be kept in a different database than the
+// rest of the execution state.
 type ChunkDataPacks struct {
-	db *badger.DB
+	db storage.KVStore
 }
 
-func NewChunkDataPacks(db *badger.DB) *ChunkDataPacks {
+func NewChunkDataPacks(db storage.KVStore) *ChunkDataPacks {
 	ch := ChunkDataPacks{
 		db: db,
 	}
@@ -22,7 +23,7 @@ func NewChunkDataPacks(db *badger.DB) *ChunkDataPacks {
 }
 
 func (ch *ChunkDataPacks) Store(c *flow.ChunkDataPack) error {
-	err := operation.RetryOnConflict(ch.db.Update, operation.SkipDuplicates(operation.InsertChunkDataPack(c)))
+	err := ch.write(operation.BatchInsertChunkDataPack(c))
 	if err != nil {
 		return fmt.Errorf("could not store chunk datapack: %w", err)
 	}
@@ -30,23 +31,45 @@ func (ch *ChunkDataPacks) Store(c *flow.ChunkDataPack) error {
 }
 
 func (ch *ChunkDataPacks) Remove(chunkID flow.Identifier) error {
-	err := operation.RetryOnConflict(ch.db.Update, operation.RemoveChunkDataPack(chunkID))
+	err := ch.write(operation.BatchRemoveChunkDataPack(chunkID))
 	if err != nil {
 		return fmt.Errorf("could not remove chunk datapack: %w", err)
 	}
 	return nil
 }
 
+// BatchStore stores the chunk data pack in the given batch if the chunk data
+// packs are kept in the node database. Otherwise, the batch runs on another
+// database, and the chunk data pack is stored right away instead, so that it
+// is persisted before the batch indexing it is flushed.
 func (ch *ChunkDataPacks) BatchStore(c *flow.ChunkDataPack, batch storage.BatchStorage) error {
-	writeBatch := batch.GetWriter()
-	return operation.BatchInsertChunkDataPack(c)(writeBatch)
+	if _, ok := ch.db.(*KVStore); !ok {
+		return ch.Store(c)
+	}
+	err := operation.BatchInsertChunkDataPack(c)(batch.GetWriter())
+	if err != nil {
+		return fmt.Errorf("could not store chunk datapack to batch: %w", err)
+	}
+	return nil
 }
 
 func (ch *ChunkDataPacks) ByChunkID(chunkID flow.Identifier) (*flow.ChunkDataPack, error) {
 	var c flow.ChunkDataPack
-	err := ch.db.View(operation.RetrieveChunkDataPack(chunkID, &c))
+	err := operation.ReadChunkDataPack(chunkID, &c)(ch.db)
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve chunk datapack: %w", err)
 	}
 	return &c, nil
 }
+
+// write applies the given writes in a single batch of the key-value store.
+func (ch *ChunkDataPacks) write(op func(storage.Writer) error) error {
+	batch := ch.db.NewBatch()
+	defer batch.Discard()
+
+	err := op(batch)
+	if err != nil {
+		return err
+	}
+	return batch.Commit()
+}
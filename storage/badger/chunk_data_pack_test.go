@@ -9,32 +9,100 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/pebble"
 	"github.com/onflow/flow-go/utils/unittest"
 
 	badgerstorage "github.com/onflow/flow-go/storage/badger"
 )
 
 func TestChunkDataPack(t *testing.T) {
+	backends := map[string]func(t *testing.T, f func(storage.KVStore)){
+		"badger": func(t *testing.T, f func(storage.KVStore)) {
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+				f(badgerstorage.NewKVStore(db))
+			})
+		},
+		"pebble": func(t *testing.T, f func(storage.KVStore)) {
+			unittest.RunWithTempDir(t, func(dir string) {
+				db, err := pebble.Open(dir, nil)
+				require.NoError(t, err)
+				defer db.Close()
+				f(db)
+			})
+		},
+	}
+
+	for name, withStore := range backends {
+		withStore := withStore
+		t.Run(name, func(t *testing.T) {
+			withStore(t, func(db storage.KVStore) {
+				store := badgerstorage.NewChunkDataPacks(db)
+
+				// attempt to get an invalid
+				_, err := store.ByChunkID(unittest.IdentifierFixture())
+				assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+				// store in db
+				chunkID := unittest.IdentifierFixture()
+				expected := unittest.ChunkDataPackFixture(chunkID)
+				err = store.Store(expected)
+				require.NoError(t, err)
+
+				// retrieve the transaction by ID
+				actual, err := store.ByChunkID(chunkID)
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual)
+
+				// re-insert - should be idempotent
+				err = store.Store(expected)
+				require.NoError(t, err)
+
+				// remove - the chunk data pack is gone
+				err = store.Remove(chunkID)
+				require.NoError(t, err)
+				_, err = store.ByChunkID(chunkID)
+				assert.True(t, errors.Is(err, storage.ErrNotFound))
+			})
+		})
+	}
+}
+
+// TestChunkDataPack_BatchStore tests that chunk data packs kept in the node
+// database are written with the batch, while chunk data packs kept in pebble
+// are written right away.
+func TestChunkDataPack_BatchStore(t *testing.T) {
 	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
-		store := badgerstorage.NewChunkDataPacks(db)
-
-		// attempt to get an invalid
-		_, err := store.ByChunkID(unittest.IdentifierFixture())
-		assert.True(t, errors.Is(err, storage.ErrNotFound))
-
-		// store in db
-		chunkID := unittest.IdentifierFixture()
-		expected := unittest.ChunkDataPackFixture(chunkID)
-		err = store.Store(expected)
-		require.NoError(t, err)
-
-		// retrieve the transaction by ID
-		actual, err := store.ByChunkID(chunkID)
-		require.NoError(t, err)
-		assert.Equal(t, expected, actual)
-
-		// re-insert - should be idempotent
-		err = store.Store(expected)
-		require.NoError(t, err)
+
+		t.Run("badger", func(t *testing.T) {
+			store := badgerstorage.NewChunkDataPacks(badgerstorage.NewKVStore(db))
+			chunkID := unittest.IdentifierFixture()
+			batch := badgerstorage.NewBatch(db)
+
+			err := store.BatchStore(unittest.ChunkDataPackFixture(chunkID), batch)
+			require.NoError(t, err)
+			_, err = store.ByChunkID(chunkID)
+			assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+			require.NoError(t, batch.Flush())
+			_, err = store.ByChunkID(chunkID)
+			assert.NoError(t, err)
+		})
+
+		t.Run("pebble", func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(dir string) {
+				kv, err := pebble.Open(dir, nil)
+				require.NoError(t, err)
+				defer kv.Close()
+
+				store := badgerstorage.NewChunkDataPacks(kv)
+				chunkID := unittest.IdentifierFixture()
+				batch := badgerstorage.NewBatch(db)
+
+				err = store.BatchStore(unittest.ChunkDataPackFixture(chunkID), batch)
+				require.NoError(t, err)
+				_, err = store.ByChunkID(chunkID)
+				assert.NoError(t, err)
+			})
+		})
 	})
 }
@@ -0,0 +1,103 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+// KVStore adapts a badger database to the backend-neutral key-value store.
+// Batches are badger transactions, so that they are applied atomically, which
+// limits their size to the transaction size of badger.
+type KVStore struct {
+	db *badger.DB
+}
+
+var _ storage.KVStore = (*KVStore)(nil)
+
+// NewKVStore returns a key-value store on the given badger database.
+func NewKVStore(db *badger.DB) *KVStore {
+	return &KVStore{db: db}
+}
+
+func (s *KVStore) Get(key []byte) ([]byte, error) {
+	var val []byte
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(key)
+		if err != nil {
+			return err
+		}
+		val, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load data: %w", err)
+	}
+	return val, nil
+}
+
+func (s *KVStore) Iterate(prefix []byte, fn func(key []byte, val []byte) error) error {
+	return s.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			err := item.Value(func(val []byte) error {
+				return fn(item.Key(), val)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *KVStore) NewBatch() storage.KVBatch {
+	return &kvBatch{tx: s.db.NewTransaction(true)}
+}
+
+func (s *KVStore) Close() error {
+	return s.db.Close()
+}
+
+// kvBatch is a batch of writes in a badger transaction.
+type kvBatch struct {
+	tx *badger.Txn
+}
+
+func (b *kvBatch) Set(key, val []byte) error {
+	// badger references the slices until the transaction is committed
+	err := b.tx.Set(append([]byte(nil), key...), append([]byte(nil), val...))
+	if err != nil {
+		return fmt.Errorf("could not store data: %w", err)
+	}
+	return nil
+}
+
+func (b *kvBatch) Delete(key []byte) error {
+	err := b.tx.Delete(append([]byte(nil), key...))
+	if err != nil {
+		return fmt.Errorf("could not delete data: %w", err)
+	}
+	return nil
+}
+
+func (b *kvBatch) Commit() error {
+	return b.tx.Commit()
+}
+
+func (b *kvBatch) Discard() {
+	b.tx.Discard()
+}
@@ -0,0 +1,20 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/storage"
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/kvtest"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestKVStoreConformance(t *testing.T) {
+	kvtest.Run(t, func(t *testing.T, f func(storage.KVStore)) {
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			f(badgerstorage.NewKVStore(db))
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// migrateEpochHistory builds the epoch history from the epoch statuses of the
+// finalized blocks. Blocks which were already pruned are skipped, in which
+// case the history starts at the lowest finalized block still stored.
+func migrateEpochHistory(log zerolog.Logger, db *badger.DB) error {
+
+	var root, finalized uint64
+	err := db.View(func(tx *badger.Txn) error {
+		err := operation.RetrieveRootHeight(&root)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve root height: %w", err)
+		}
+		err = operation.RetrieveFinalizedHeight(&finalized)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve finalized height: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Info().Msg("no protocol state to build epoch history from")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var parent *flow.EpochStatus
+	for start := root; start <= finalized; start += batchSize {
+		end := start + batchSize - 1
+		if end > finalized {
+			end = finalized
+		}
+		err = db.Update(func(tx *badger.Txn) error {
+			for height := start; height <= end; height++ {
+				status, err := indexEpochHistoryAtHeight(height, parent)(tx)
+				if err != nil {
+					return fmt.Errorf("could not index epoch history at height %d: %w", height, err)
+				}
+				parent = status
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info().
+		Uint64("root_height", root).
+		Uint64("finalized_height", finalized).
+		Msg("built epoch history")
+
+	return nil
+}
+
+// indexEpochHistoryAtHeight indexes the epoch history for the finalized block
+// at the given height, given the epoch status of its parent, and returns the
+// epoch status of the block. If the block was pruned, the returned status is
+// nil.
+func indexEpochHistoryAtHeight(height uint64, parent *flow.EpochStatus) func(*badger.Txn) (*flow.EpochStatus, error) {
+	return func(tx *badger.Txn) (*flow.EpochStatus, error) {
+
+		var blockID flow.Identifier
+		err := operation.LookupBlockHeight(height, &blockID)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not look up block: %w", err)
+		}
+
+		var header flow.Header
+		err = operation.RetrieveHeader(blockID, &header)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve header: %w", err)
+		}
+
+		var status flow.EpochStatus
+		err = operation.RetrieveEpochStatus(blockID, &status)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve epoch status: %w", err)
+		}
+
+		err = operation.IndexEpochHistory(&header, parent, &status)(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &status, nil
+	}
+}
@@ -0,0 +1,173 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// Migration migrates the database from the previous version of the schema to
+// its version. As a node may be stopped while migrating, in which case the
+// migration is run again on the next start, migrations must be idempotent.
+type Migration struct {
+	Version     uint32
+	Description string
+	Migrate     func(log zerolog.Logger, db *badger.DB) error
+}
+
+// migrations is the registry of all migrations, ordered by version. The
+// version of the last migration is the version of the current schema.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "move execution receipt metas from the key prefix of execution results to their own",
+		Migrate:     migrateExecutionReceiptMetas,
+	},
+	{
+		Version:     2,
+		Description: "build the epoch history from the epoch statuses of finalized blocks",
+		Migrate:     migrateEpochHistory,
+	},
+}
+
+// Migrations returns all migrations, ordered by version.
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// Latest returns the version of the current schema.
+func Latest() uint32 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Version returns the schema version of the database. Databases created
+// before the schema was versioned have version zero, while empty databases
+// have no version yet, in which case storage.ErrNotFound is returned.
+func Version(db *badger.DB) (uint32, error) {
+
+	var version uint32
+	err := db.View(operation.RetrieveSchemaVersion(&version))
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return 0, fmt.Errorf("could not retrieve schema version: %w", err)
+	}
+
+	var finalized uint64
+	err = db.View(operation.RetrieveFinalizedHeight(&finalized))
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, storage.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve finalized height: %w", err)
+	}
+
+	return 0, nil
+}
+
+// Pending returns the migrations which were not yet applied to the database,
+// ordered by version. Empty databases have no pending migrations.
+func Pending(db *badger.DB) ([]Migration, error) {
+
+	version, err := Version(db)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Migrate migrates the database to the current schema. Empty databases are
+// bootstrapped with the current schema, so they are only marked with its
+// version.
+func Migrate(log zerolog.Logger, db *badger.DB) error {
+	return MigrateTo(log, db, Latest())
+}
+
+// MigrateTo migrates the database up to the given version of the schema,
+// persisting the version after each migration. Migrating to an older version
+// than the one of the database is not supported.
+func MigrateTo(log zerolog.Logger, db *badger.DB, target uint32) error {
+
+	log = log.With().Str("component", "migration").Logger()
+
+	if target > Latest() {
+		return fmt.Errorf("unknown schema version %d (latest: %d)", target, Latest())
+	}
+
+	version, err := Version(db)
+	if errors.Is(err, storage.ErrNotFound) {
+		err = db.Update(operation.InsertSchemaVersion(Latest()))
+		if err != nil {
+			return fmt.Errorf("could not insert schema version: %w", err)
+		}
+		log.Info().Uint32("version", Latest()).Msg("initialized schema version of empty database")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if version > Latest() {
+		return fmt.Errorf("database schema version %d is newer than the latest supported version %d", version, Latest())
+	}
+	if version > target {
+		return fmt.Errorf("can not roll back database schema version %d to %d", version, target)
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= version || migration.Version > target {
+			continue
+		}
+
+		log.Info().
+			Uint32("from_version", version).
+			Uint32("to_version", migration.Version).
+			Str("description", migration.Description).
+			Msg("migrating database")
+
+		err = migration.Migrate(log, db)
+		if err != nil {
+			return fmt.Errorf("could not migrate database to version %d: %w", migration.Version, err)
+		}
+
+		err = db.Update(setVersion(migration.Version))
+		if err != nil {
+			return fmt.Errorf("could not update schema version to %d: %w", migration.Version, err)
+		}
+		version = migration.Version
+	}
+
+	return nil
+}
+
+// setVersion sets the schema version, which databases created before the
+// schema was versioned don't have yet.
+func setVersion(version uint32) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		err := operation.UpdateSchemaVersion(version)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			err = operation.InsertSchemaVersion(version)(tx)
+		}
+		return err
+	}
+}
@@ -0,0 +1,226 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestMigrate_EmptyDatabase(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		_, err := Version(db)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		pending, err := Pending(db)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+
+		err = Migrate(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		version, err := Version(db)
+		require.NoError(t, err)
+		assert.Equal(t, Latest(), version)
+	})
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		receipts := []*flow.ExecutionReceipt{
+			unittest.ExecutionReceiptFixture(),
+			unittest.ExecutionReceiptFixture(),
+		}
+		err := db.Update(func(tx *badger.Txn) error {
+			err := operation.InsertFinalizedHeight(10)(tx)
+			if err != nil {
+				return err
+			}
+			for _, receipt := range receipts {
+				err = operation.InsertExecutionResult(&receipt.ExecutionResult)(tx)
+				if err != nil {
+					return err
+				}
+				err = operation.InsertLegacyExecutionReceiptMeta(receipt.ID(), receipt.Meta())(tx)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		version, err := Version(db)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0), version)
+
+		pending, err := Pending(db)
+		require.NoError(t, err)
+		assert.Len(t, pending, len(migrations))
+
+		err = Migrate(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		version, err = Version(db)
+		require.NoError(t, err)
+		assert.Equal(t, Latest(), version)
+
+		pending, err = Pending(db)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+
+		for _, receipt := range receipts {
+			var meta flow.ExecutionReceiptMeta
+			err = db.View(operation.RetrieveExecutionReceiptMeta(receipt.ID(), &meta))
+			require.NoError(t, err)
+			assert.Equal(t, receipt.Meta(), &meta)
+
+			var result flow.ExecutionResult
+			err = db.View(operation.RetrieveExecutionResult(receipt.ExecutionResult.ID(), &result))
+			require.NoError(t, err)
+			assert.Equal(t, receipt.ExecutionResult.ID(), result.ID())
+		}
+
+		var legacy []flow.Identifier
+		err = db.View(operation.LookupLegacyExecutionReceiptMetas(&legacy))
+		require.NoError(t, err)
+		assert.Empty(t, legacy)
+	})
+}
+
+// TestMigrate_Idempotent verifies that the migrations can be run again, as
+// happens if the node is stopped before the schema version is updated.
+func TestMigrate_Idempotent(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		receipt := unittest.ExecutionReceiptFixture()
+		err := db.Update(func(tx *badger.Txn) error {
+			err := operation.InsertFinalizedHeight(10)(tx)
+			if err != nil {
+				return err
+			}
+			return operation.InsertLegacyExecutionReceiptMeta(receipt.ID(), receipt.Meta())(tx)
+		})
+		require.NoError(t, err)
+
+		for _, migration := range migrations {
+			err = migration.Migrate(zerolog.Nop(), db)
+			require.NoError(t, err)
+			err = migration.Migrate(zerolog.Nop(), db)
+			require.NoError(t, err)
+		}
+
+		var meta flow.ExecutionReceiptMeta
+		err = db.View(operation.RetrieveExecutionReceiptMeta(receipt.ID(), &meta))
+		require.NoError(t, err)
+		assert.Equal(t, receipt.Meta(), &meta)
+	})
+}
+
+func TestMigrateTo_Invalid(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		err := MigrateTo(zerolog.Nop(), db, Latest()+1)
+		assert.Error(t, err)
+
+		// databases of newer versions are not opened
+		err = db.Update(operation.InsertSchemaVersion(Latest() + 1))
+		require.NoError(t, err)
+		err = Migrate(zerolog.Nop(), db)
+		assert.Error(t, err)
+
+		// schemas are not rolled back
+		err = db.Update(operation.UpdateSchemaVersion(Latest()))
+		require.NoError(t, err)
+		err = MigrateTo(zerolog.Nop(), db, Latest()-1)
+		assert.Error(t, err)
+	})
+}
+
+// TestMigrateEpochHistory verifies that the epoch history is built from the
+// epoch statuses of the finalized blocks which were not pruned.
+func TestMigrateEpochHistory(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		setup1 := unittest.EpochSetupFixture(unittest.SetupWithCounter(1))
+		setup2 := unittest.EpochSetupFixture(unittest.SetupWithCounter(2))
+		commitIDs := unittest.IdentifierListFixture(2)
+
+		current := flow.EventIDs{SetupID: setup1.ID(), CommitID: commitIDs[0]}
+		next := flow.EventIDs{SetupID: setup2.ID(), CommitID: commitIDs[1]}
+		statuses := []*flow.EpochStatus{
+			{CurrentEpoch: current},
+			{CurrentEpoch: current},
+			{CurrentEpoch: current, NextEpoch: flow.EventIDs{SetupID: next.SetupID}},
+			{CurrentEpoch: current, NextEpoch: next},
+			{PreviousEpoch: current, CurrentEpoch: next},
+		}
+
+		// the blocks from height 10 to 14, of which the first one was pruned
+		err := db.Update(func(tx *badger.Txn) error {
+			for _, setup := range []*flow.EpochSetup{setup1, setup2} {
+				err := operation.InsertEpochSetup(setup.ID(), setup)(tx)
+				if err != nil {
+					return err
+				}
+			}
+			for i, status := range statuses[1:] {
+				header := unittest.BlockHeaderFixture()
+				header.Height = uint64(11 + i)
+				err := operation.InsertHeader(header.ID(), &header)(tx)
+				if err != nil {
+					return err
+				}
+				err = operation.IndexBlockHeight(header.Height, header.ID())(tx)
+				if err != nil {
+					return err
+				}
+				err = operation.InsertEpochStatus(header.ID(), status)(tx)
+				if err != nil {
+					return err
+				}
+			}
+			err := operation.InsertRootHeight(10)(tx)
+			if err != nil {
+				return err
+			}
+			return operation.InsertFinalizedHeight(14)(tx)
+		})
+		require.NoError(t, err)
+
+		err = migrateEpochHistory(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		var records []flow.EpochRecord
+		err = db.View(operation.LookupEpochRecords(&records))
+		require.NoError(t, err)
+		assert.Equal(t, []flow.EpochRecord{
+			{
+				Counter:              1,
+				SetupID:              current.SetupID,
+				CommitID:             current.CommitID,
+				SetupPhaseHeight:     12,
+				CommittedPhaseHeight: 13,
+				FinalHeight:          13,
+			},
+			{
+				Counter:     2,
+				SetupID:     next.SetupID,
+				CommitID:    next.CommitID,
+				FirstHeight: 14,
+			},
+		}, records)
+
+		var counter uint64
+		err = db.View(operation.LookupEpochAtHeight(11, &counter))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), counter)
+		err = db.View(operation.LookupEpochAtHeight(10, &counter))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+	})
+}
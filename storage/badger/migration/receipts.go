@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// batchSize is the number of entries migrated per transaction, which keeps
+// transactions below the size limit of badger.
+const batchSize = 1000
+
+// migrateExecutionReceiptMetas moves the execution receipt metas, which shared
+// the key prefix of execution results, to their own key prefix.
+func migrateExecutionReceiptMetas(log zerolog.Logger, db *badger.DB) error {
+
+	var receiptIDs []flow.Identifier
+	err := db.View(operation.LookupLegacyExecutionReceiptMetas(&receiptIDs))
+	if err != nil {
+		return fmt.Errorf("could not look up legacy receipt metas: %w", err)
+	}
+
+	for start := 0; start < len(receiptIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(receiptIDs) {
+			end = len(receiptIDs)
+		}
+		err = db.Update(func(tx *badger.Txn) error {
+			for _, receiptID := range receiptIDs[start:end] {
+				err := operation.MoveLegacyExecutionReceiptMeta(receiptID)(tx)
+				if err != nil {
+					return fmt.Errorf("could not move receipt meta %x: %w", receiptID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info().Int("receipts", len(receiptIDs)).Msg("moved execution receipt metas")
+
+	return nil
+}
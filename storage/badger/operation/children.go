@@ -20,3 +20,8 @@ func UpdateBlockChildren(blockID flow.Identifier, childrenIDs []flow.Identifier)
 func RetrieveBlockChildren(blockID flow.Identifier, childrenIDs *[]flow.Identifier) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeBlockChildren, blockID), childrenIDs)
 }
+
+// RemoveBlockChildren removes the index of the children of a block.
+func RemoveBlockChildren(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeBlockChildren, blockID))
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 // InsertChunkDataPack inserts a chunk data pack keyed by chunk ID.
@@ -12,7 +13,7 @@ func InsertChunkDataPack(c *flow.ChunkDataPack) func(*badger.Txn) error {
 }
 
 // BatchInsertChunkDataPack upserts a chunk data pack keyed by chunk ID into a batch
-func BatchInsertChunkDataPack(c *flow.ChunkDataPack) func(batch *badger.WriteBatch) error {
+func BatchInsertChunkDataPack(c *flow.ChunkDataPack) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeChunkDataPack, c.ChunkID), c)
 }
 
@@ -21,6 +22,17 @@ func RetrieveChunkDataPack(chunkID flow.Identifier, c *flow.ChunkDataPack) func(
 	return retrieve(makePrefix(codeChunkDataPack, chunkID), c)
 }
 
+// ReadChunkDataPack reads a chunk data pack by chunk ID from a key-value store.
+func ReadChunkDataPack(chunkID flow.Identifier, c *flow.ChunkDataPack) func(storage.Reader) error {
+	return read(makePrefix(codeChunkDataPack, chunkID), c)
+}
+
+// BatchRemoveChunkDataPack removes the chunk data pack with the given chunk ID
+// in a batch.
+func BatchRemoveChunkDataPack(chunkID flow.Identifier) func(batch storage.Writer) error {
+	return batchRemove(makePrefix(codeChunkDataPack, chunkID))
+}
+
 // RemoveChunkDataPack removes the chunk data pack with the given chunk ID.
 func RemoveChunkDataPack(chunkID flow.Identifier) func(*badger.Txn) error {
 	return remove(makePrefix(codeChunkDataPack, chunkID))
@@ -6,6 +6,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 // IndexStateCommitment indexes a state commitment.
@@ -18,7 +19,7 @@ func IndexStateCommitment(blockID flow.Identifier, commit flow.StateCommitment)
 // BatchIndexStateCommitment indexes a state commitment into a batch
 //
 // State commitments are keyed by the block whose execution results in the state with the given commit.
-func BatchIndexStateCommitment(blockID flow.Identifier, commit flow.StateCommitment) func(batch *badger.WriteBatch) error {
+func BatchIndexStateCommitment(blockID flow.Identifier, commit flow.StateCommitment) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeCommit, blockID), commit)
 }
 
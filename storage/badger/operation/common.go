@@ -15,10 +15,10 @@ import (
 )
 
 // batchInsert will encode the given entity using msgpack and will upsert the resulting
-// binary data in the badger wrote batch under the provided key - if the value already exists
+// binary data in the write batch under the provided key - if the value already exists
 // in the database it will be overridden
-func batchInsert(key []byte, entity interface{}) func(writeBatch *badger.WriteBatch) error {
-	return func(writeBatch *badger.WriteBatch) error {
+func batchInsert(key []byte, entity interface{}) func(writeBatch storage.Writer) error {
+	return func(writeBatch storage.Writer) error {
 
 		// update the maximum key size if the inserted key is bigger
 		if uint32(len(key)) > max {
@@ -44,6 +44,42 @@ func batchInsert(key []byte, entity interface{}) func(writeBatch *badger.WriteBa
 	}
 }
 
+// batchRemove removes the entity with the given key in the write batch. If it
+// doesn't exist, this is a no-op.
+func batchRemove(key []byte) func(writeBatch storage.Writer) error {
+	return func(writeBatch storage.Writer) error {
+		err := writeBatch.Delete(key)
+		if err != nil {
+			return fmt.Errorf("could not delete data: %w", err)
+		}
+		return nil
+	}
+}
+
+// read will retrieve the binary data under the given key from the key-value
+// store of any backend and decode it into the given entity. The provided
+// entity needs to be a pointer to an initialized entity of the correct type.
+func read(key []byte, entity interface{}) func(reader storage.Reader) error {
+	return func(reader storage.Reader) error {
+
+		// retrieve the value from the key-value store
+		val, err := reader.Get(key)
+		if errors.Is(err, storage.ErrNotFound) {
+			return storage.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not load data: %w", err)
+		}
+
+		err = msgpack.Unmarshal(val, entity)
+		if err != nil {
+			return fmt.Errorf("could not decode entity: %w", err)
+		}
+
+		return nil
+	}
+}
+
 // insert will encode the given entity using msgpack and will insert the resulting
 // binary data in the badger DB under the provided key. It will error if the
 // key already exists.
@@ -132,6 +168,37 @@ func remove(key []byte) func(*badger.Txn) error {
 	}
 }
 
+// removeByPrefix removes all entities with keys starting with the given prefix.
+// If there are none, this is a no-op.
+func removeByPrefix(prefix []byte) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		if len(prefix) == 0 {
+			return fmt.Errorf("prefix must not be empty")
+		}
+
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+
+		// collect the keys first, so that they are not removed while iterating
+		var keys [][]byte
+		it := tx.NewIterator(opts)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+		it.Close()
+
+		for _, key := range keys {
+			err := tx.Delete(key)
+			if err != nil {
+				return fmt.Errorf("could not delete key: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
 // retrieve will retrieve the binary data under the given key from the badger DB
 // and decode it into the given entity. The provided entity needs to be a
 // pointer to an initialized entity of the correct type.
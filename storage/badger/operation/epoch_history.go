@@ -0,0 +1,217 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// RetrieveEpochRecord retrieves the record of the epoch with the given counter.
+func RetrieveEpochRecord(counter uint64, record *flow.EpochRecord) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeEpochRecord, counter), record)
+}
+
+// InsertEpochRecord inserts the record of an epoch which was not learned by
+// finalizing blocks, such as the epochs verified by light clients.
+func InsertEpochRecord(record *flow.EpochRecord) func(*badger.Txn) error {
+	return insert(makePrefix(codeEpochRecord, record.Counter), record)
+}
+
+// UpdateEpochRecord updates the record of an epoch which was not learned by
+// finalizing blocks.
+func UpdateEpochRecord(record *flow.EpochRecord) func(*badger.Txn) error {
+	return update(makePrefix(codeEpochRecord, record.Counter), record)
+}
+
+// LookupEpochRecords looks up the records of all known epochs, ordered by
+// counter.
+func LookupEpochRecords(records *[]flow.EpochRecord) func(*badger.Txn) error {
+	*records = make([]flow.EpochRecord, 0)
+	iteration := func() (checkFunc, createFunc, handleFunc) {
+		check := func(key []byte) bool {
+			return true
+		}
+		var record flow.EpochRecord
+		create := func() interface{} {
+			return &record
+		}
+		handle := func() error {
+			*records = append(*records, record)
+			return nil
+		}
+		return check, create, handle
+	}
+	return traverse(makePrefix(codeEpochRecord), iteration)
+}
+
+// LookupEpochAtHeight looks up the counter of the epoch which the finalized
+// block with the given height belongs to. It returns storage.ErrNotFound if
+// the height is below the first height for which epochs are known. The height
+// is not checked against the finalized height.
+func LookupEpochAtHeight(height uint64, counter *uint64) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		prefix := makePrefix(codeEpochHeight)
+		seek := makePrefix(codeEpochHeight, height)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = prefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// in reverse order, badger seeks to the largest key lower or equal to
+		// the seek key, which is the latest epoch started at or below the height
+		it.Seek(seek)
+		if !it.ValidForPrefix(prefix) {
+			return storage.ErrNotFound
+		}
+		err := it.Item().Value(func(val []byte) error {
+			return msgpack.Unmarshal(val, counter)
+		})
+		if err != nil {
+			return fmt.Errorf("could not decode epoch counter: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// IndexEpochHistory updates the epoch records and the epoch height index with
+// the changes between the epoch status of the finalized block with the given
+// header and the one of its parent. If the parent status is nil, the block is
+// the first one for which epochs are known, such as the root block, and the
+// epochs of its status are recorded as far as they are known. Indexing is
+// idempotent, so the history can be rebuilt from the stored epoch statuses.
+func IndexEpochHistory(header *flow.Header, parent *flow.EpochStatus, status *flow.EpochStatus) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		var setup flow.EpochSetup
+		err := RetrieveEpochSetup(status.CurrentEpoch.SetupID, &setup)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve current epoch setup: %w", err)
+		}
+		counter := setup.Counter
+
+		// the first block known in the current epoch, for which we only know
+		// its height if it is the first block of the epoch
+		if parent == nil || parent.CurrentEpoch.SetupID != status.CurrentEpoch.SetupID {
+			err = indexEpochHeight(header.Height, counter)(tx)
+			if err != nil {
+				return fmt.Errorf("could not index epoch height: %w", err)
+			}
+			err = modifyEpochRecord(counter, func(record *flow.EpochRecord) {
+				record.SetupID = status.CurrentEpoch.SetupID
+				record.CommitID = status.CurrentEpoch.CommitID
+				if parent != nil || header.View == setup.FirstView {
+					record.FirstHeight = header.Height
+				}
+			})(tx)
+			if err != nil {
+				return fmt.Errorf("could not record current epoch: %w", err)
+			}
+
+			if status.PreviousEpoch.SetupID != flow.ZeroID {
+				err = modifyEpochRecord(counter-1, func(record *flow.EpochRecord) {
+					record.SetupID = status.PreviousEpoch.SetupID
+					record.CommitID = status.PreviousEpoch.CommitID
+					if header.Height > 0 && (parent != nil || header.View == setup.FirstView) {
+						record.FinalHeight = header.Height - 1
+					}
+				})(tx)
+				if err != nil {
+					return fmt.Errorf("could not record previous epoch: %w", err)
+				}
+			}
+
+			// the next epoch preparation can not start in the first block of
+			// an epoch, while for the first known block, we only know the
+			// events of the next epoch, but not when its phases started
+			parent = &flow.EpochStatus{}
+		}
+
+		// the next epoch was set up in this block
+		if status.NextEpoch.SetupID != parent.NextEpoch.SetupID {
+			err = modifyEpochRecord(counter, func(record *flow.EpochRecord) {
+				if parent.CurrentEpoch.SetupID != flow.ZeroID {
+					record.SetupPhaseHeight = header.Height
+				}
+			})(tx)
+			if err != nil {
+				return fmt.Errorf("could not record setup phase: %w", err)
+			}
+			err = modifyEpochRecord(counter+1, func(record *flow.EpochRecord) {
+				record.SetupID = status.NextEpoch.SetupID
+			})(tx)
+			if err != nil {
+				return fmt.Errorf("could not record next epoch setup: %w", err)
+			}
+		}
+
+		// the next epoch was committed in this block
+		if status.NextEpoch.CommitID != parent.NextEpoch.CommitID {
+			err = modifyEpochRecord(counter, func(record *flow.EpochRecord) {
+				if parent.CurrentEpoch.SetupID != flow.ZeroID {
+					record.CommittedPhaseHeight = header.Height
+				}
+			})(tx)
+			if err != nil {
+				return fmt.Errorf("could not record committed phase: %w", err)
+			}
+			err = modifyEpochRecord(counter+1, func(record *flow.EpochRecord) {
+				record.CommitID = status.NextEpoch.CommitID
+			})(tx)
+			if err != nil {
+				return fmt.Errorf("could not record next epoch commit: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// indexEpochHeight indexes the given height as the first known height of the
+// epoch with the given counter. Indexing the same counter again is a no-op.
+func indexEpochHeight(height uint64, counter uint64) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		var existing uint64
+		err := retrieve(makePrefix(codeEpochHeight, height), &existing)(tx)
+		if err == nil {
+			if existing != counter {
+				return fmt.Errorf("height %d is already indexed for epoch %d", height, existing)
+			}
+			return nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not retrieve indexed epoch: %w", err)
+		}
+
+		return insert(makePrefix(codeEpochHeight, height), counter)(tx)
+	}
+}
+
+// modifyEpochRecord applies the given modification to the record of the epoch
+// with the given counter, creating the record if it does not exist yet.
+func modifyEpochRecord(counter uint64, modify func(record *flow.EpochRecord)) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		record := flow.EpochRecord{Counter: counter}
+		err := RetrieveEpochRecord(counter, &record)(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not retrieve epoch record: %w", err)
+		}
+		exists := err == nil
+
+		modify(&record)
+
+		if exists {
+			return update(makePrefix(codeEpochRecord, counter), &record)(tx)
+		}
+		return insert(makePrefix(codeEpochRecord, counter), &record)(tx)
+	}
+}
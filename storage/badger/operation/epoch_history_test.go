@@ -0,0 +1,147 @@
+package operation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestIndexEpochHistory(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+		setup1 := unittest.EpochSetupFixture(unittest.SetupWithCounter(1), unittest.WithFirstView(100))
+		commit1ID := unittest.IdentifierFixture()
+		setup2 := unittest.EpochSetupFixture(unittest.SetupWithCounter(2), unittest.WithFirstView(200))
+		commit2ID := unittest.IdentifierFixture()
+		for _, setup := range []*flow.EpochSetup{setup1, setup2} {
+			require.NoError(t, db.Update(InsertEpochSetup(setup.ID(), setup)))
+		}
+
+		header := func(height uint64, view uint64) *flow.Header {
+			header := unittest.BlockHeaderFixture()
+			header.Height = height
+			header.View = view
+			return &header
+		}
+
+		// the root block is the first block of epoch 1, followed by the setup
+		// phase, the committed phase and the transition to epoch 2
+		root := &flow.EpochStatus{
+			CurrentEpoch: flow.EventIDs{SetupID: setup1.ID(), CommitID: commit1ID},
+		}
+		setupPhase := &flow.EpochStatus{
+			CurrentEpoch: root.CurrentEpoch,
+			NextEpoch:    flow.EventIDs{SetupID: setup2.ID()},
+		}
+		committedPhase := &flow.EpochStatus{
+			CurrentEpoch: root.CurrentEpoch,
+			NextEpoch:    flow.EventIDs{SetupID: setup2.ID(), CommitID: commit2ID},
+		}
+		transition := &flow.EpochStatus{
+			PreviousEpoch: root.CurrentEpoch,
+			CurrentEpoch:  committedPhase.NextEpoch,
+		}
+
+		err := db.Update(IndexEpochHistory(header(10, 100), nil, root))
+		require.NoError(t, err)
+		err = db.Update(IndexEpochHistory(header(11, 101), root, root))
+		require.NoError(t, err)
+		err = db.Update(IndexEpochHistory(header(12, 102), root, setupPhase))
+		require.NoError(t, err)
+		err = db.Update(IndexEpochHistory(header(13, 103), setupPhase, committedPhase))
+		require.NoError(t, err)
+		err = db.Update(IndexEpochHistory(header(14, 200), committedPhase, transition))
+		require.NoError(t, err)
+
+		// indexing is idempotent
+		err = db.Update(IndexEpochHistory(header(13, 103), setupPhase, committedPhase))
+		require.NoError(t, err)
+
+		var records []flow.EpochRecord
+		err = db.View(LookupEpochRecords(&records))
+		require.NoError(t, err)
+		assert.Equal(t, []flow.EpochRecord{
+			{
+				Counter:              1,
+				SetupID:              setup1.ID(),
+				CommitID:             commit1ID,
+				FirstHeight:          10,
+				SetupPhaseHeight:     12,
+				CommittedPhaseHeight: 13,
+				FinalHeight:          13,
+			},
+			{
+				Counter:     2,
+				SetupID:     setup2.ID(),
+				CommitID:    commit2ID,
+				FirstHeight: 14,
+			},
+		}, records)
+
+		var record flow.EpochRecord
+		err = db.View(RetrieveEpochRecord(2, &record))
+		require.NoError(t, err)
+		assert.Equal(t, records[1], record)
+
+		expected := map[uint64]uint64{10: 1, 13: 1, 14: 2, 1000: 2}
+		for height, counter := range expected {
+			var actual uint64
+			err = db.View(LookupEpochAtHeight(height, &actual))
+			require.NoError(t, err)
+			assert.Equal(t, counter, actual, "height %d", height)
+		}
+
+		var counter uint64
+		err = db.View(LookupEpochAtHeight(9, &counter))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+	})
+}
+
+func TestIndexEpochHistory_RootWithinEpoch(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+		setup1 := unittest.EpochSetupFixture(unittest.SetupWithCounter(1))
+		commit1ID := unittest.IdentifierFixture()
+		setup2 := unittest.EpochSetupFixture(unittest.SetupWithCounter(2), unittest.WithFirstView(100))
+		commit2ID := unittest.IdentifierFixture()
+		setup3 := unittest.EpochSetupFixture(unittest.SetupWithCounter(3))
+		for _, setup := range []*flow.EpochSetup{setup1, setup2, setup3} {
+			require.NoError(t, db.Update(InsertEpochSetup(setup.ID(), setup)))
+		}
+
+		// the root block is in the setup phase of epoch 2, so only the heights
+		// after the root block are known
+		root := &flow.EpochStatus{
+			PreviousEpoch: flow.EventIDs{SetupID: setup1.ID(), CommitID: commit1ID},
+			CurrentEpoch:  flow.EventIDs{SetupID: setup2.ID(), CommitID: commit2ID},
+			NextEpoch:     flow.EventIDs{SetupID: setup3.ID()},
+		}
+		header := unittest.BlockHeaderFixture()
+		header.Height = 50
+		header.View = 150
+
+		err := db.Update(IndexEpochHistory(&header, nil, root))
+		require.NoError(t, err)
+
+		var records []flow.EpochRecord
+		err = db.View(LookupEpochRecords(&records))
+		require.NoError(t, err)
+		assert.Equal(t, []flow.EpochRecord{
+			{Counter: 1, SetupID: setup1.ID(), CommitID: commit1ID},
+			{Counter: 2, SetupID: setup2.ID(), CommitID: commit2ID},
+			{Counter: 3, SetupID: setup3.ID()},
+		}, records)
+
+		var counter uint64
+		err = db.View(LookupEpochAtHeight(50, &counter))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), counter)
+	})
+}
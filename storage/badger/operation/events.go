@@ -6,6 +6,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 func eventPrefix(prefix byte, blockID flow.Identifier, event flow.Event) []byte {
@@ -16,7 +17,7 @@ func InsertEvent(blockID flow.Identifier, event flow.Event) func(*badger.Txn) er
 	return insert(eventPrefix(codeEvent, blockID, event), event)
 }
 
-func BatchInsertEvent(blockID flow.Identifier, event flow.Event) func(batch *badger.WriteBatch) error {
+func BatchInsertEvent(blockID flow.Identifier, event flow.Event) func(batch storage.Writer) error {
 	return batchInsert(eventPrefix(codeEvent, blockID, event), event)
 }
 
@@ -24,7 +25,7 @@ func InsertServiceEvent(blockID flow.Identifier, event flow.Event) func(*badger.
 	return insert(eventPrefix(codeServiceEvent, blockID, event), event)
 }
 
-func BatchInsertServiceEvent(blockID flow.Identifier, event flow.Event) func(batch *badger.WriteBatch) error {
+func BatchInsertServiceEvent(blockID flow.Identifier, event flow.Event) func(batch storage.Writer) error {
 	return batchInsert(eventPrefix(codeServiceEvent, blockID, event), event)
 }
 
@@ -48,6 +49,16 @@ func LookupEventsByBlockIDEventType(blockID flow.Identifier, eventType flow.Even
 	return traverse(makePrefix(codeEvent, blockID), iterationFunc)
 }
 
+// RemoveEventsByBlockID removes all events of the given block.
+func RemoveEventsByBlockID(blockID flow.Identifier) func(*badger.Txn) error {
+	return removeByPrefix(makePrefix(codeEvent, blockID))
+}
+
+// RemoveServiceEventsByBlockID removes all service events of the given block.
+func RemoveServiceEventsByBlockID(blockID flow.Identifier) func(*badger.Txn) error {
+	return removeByPrefix(makePrefix(codeServiceEvent, blockID))
+}
+
 // eventIterationFunc returns an in iteration function which returns all events found during traversal or iteration
 func eventIterationFunc(events *[]flow.Event) func() (checkFunc, createFunc, handleFunc) {
 	return func() (checkFunc, createFunc, handleFunc) {
@@ -135,3 +135,35 @@ func sortEvent(events []flow.Event) {
 
 	})
 }
+
+// TestRemoveEventsByBlockID tests that all events of a block are removed, while the events of other blocks are kept.
+func TestRemoveEventsByBlockID(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		blockIDs := unittest.IdentifierListFixture(2)
+		for _, blockID := range blockIDs {
+			for i := 0; i < 3; i++ {
+				event := unittest.EventFixture(flow.EventAccountCreated, uint32(i), uint32(i), unittest.IdentifierFixture())
+				require.NoError(t, db.Update(InsertEvent(blockID, event)))
+				require.NoError(t, db.Update(InsertServiceEvent(blockID, event)))
+			}
+		}
+
+		require.NoError(t, db.Update(RemoveEventsByBlockID(blockIDs[0])))
+		require.NoError(t, db.Update(RemoveServiceEventsByBlockID(blockIDs[0])))
+
+		// removing the events again is a no-op
+		require.NoError(t, db.Update(RemoveEventsByBlockID(blockIDs[0])))
+
+		var events []flow.Event
+		require.NoError(t, db.View(LookupEventsByBlockID(blockIDs[0], &events)))
+		require.Empty(t, events)
+		require.NoError(t, db.View(LookupServiceEventsByBlockID(blockIDs[0], &events)))
+		require.Empty(t, events)
+
+		require.NoError(t, db.View(LookupEventsByBlockID(blockIDs[1], &events)))
+		require.Len(t, events, 3)
+		events = nil
+		require.NoError(t, db.View(LookupServiceEventsByBlockID(blockIDs[1], &events)))
+		require.Len(t, events, 3)
+	})
+}
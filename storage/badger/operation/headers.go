@@ -3,9 +3,12 @@
 package operation
 
 import (
+	"encoding/binary"
+
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 func InsertHeader(headerID flow.Identifier, header *flow.Header) func(*badger.Txn) error {
@@ -27,6 +30,39 @@ func LookupBlockHeight(height uint64, blockID *flow.Identifier) func(*badger.Txn
 	return retrieve(makePrefix(codeHeightToBlock, height), blockID)
 }
 
+// TraverseBlockHeights calls the given function for each indexed height of a
+// finalized block, in ascending order of heights.
+func TraverseBlockHeights(fn func(height uint64, blockID flow.Identifier) error) func(*badger.Txn) error {
+	return traverse(makePrefix(codeHeightToBlock), func() (checkFunc, createFunc, handleFunc) {
+		var height uint64
+		check := func(key []byte) bool {
+			if len(key) != 9 {
+				return false
+			}
+			height = binary.BigEndian.Uint64(key[1:])
+			return true
+		}
+		var blockID flow.Identifier
+		create := func() interface{} {
+			return &blockID
+		}
+		handle := func() error {
+			return fn(height, blockID)
+		}
+		return check, create, handle
+	})
+}
+
+// RemoveHeader removes the header of the block with the given ID.
+func RemoveHeader(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeHeader, blockID))
+}
+
+// RemoveBlockHeight removes the index of the finalized block at the given height.
+func RemoveBlockHeight(height uint64) func(*badger.Txn) error {
+	return remove(makePrefix(codeHeightToBlock, height))
+}
+
 // InsertBlockValidity marks a block as valid or invalid, defined by the consensus algorithm.
 func InsertBlockValidity(blockID flow.Identifier, valid bool) func(*badger.Txn) error {
 	return insert(makePrefix(codeBlockValidity, blockID), valid)
@@ -37,6 +73,11 @@ func RetrieveBlockValidity(blockID flow.Identifier, valid *bool) func(*badger.Tx
 	return retrieve(makePrefix(codeBlockValidity, blockID), valid)
 }
 
+// RemoveBlockValidity removes the validity of a block.
+func RemoveBlockValidity(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeBlockValidity, blockID))
+}
+
 func InsertExecutedBlock(blockID flow.Identifier) func(*badger.Txn) error {
 	return insert(makePrefix(codeExecutedBlock), blockID)
 }
@@ -59,7 +100,7 @@ func IndexBlockIDByChunkID(chunkID, blockID flow.Identifier) func(*badger.Txn) e
 }
 
 // BatchIndexBlockByChunkID indexes blockID by chunkID into a batch
-func BatchIndexBlockByChunkID(blockID, chunkID flow.Identifier) func(batch *badger.WriteBatch) error {
+func BatchIndexBlockByChunkID(blockID, chunkID flow.Identifier) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeIndexBlockByChunkID, chunkID), blockID)
 }
 
@@ -68,6 +109,16 @@ func LookupCollectionBlock(collID flow.Identifier, blockID *flow.Identifier) fun
 	return retrieve(makePrefix(codeCollectionBlock, collID), blockID)
 }
 
+// RemoveCollectionBlock removes the index of a block by a collection within that block.
+func RemoveCollectionBlock(collID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeCollectionBlock, collID))
+}
+
+// RemoveBlockIDByChunkID removes the index of a block by a chunk of its execution result.
+func RemoveBlockIDByChunkID(chunkID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeIndexBlockByChunkID, chunkID))
+}
+
 // LookupBlockIDByChunkID looks up a block by a collection within that block.
 func LookupBlockIDByChunkID(chunkID flow.Identifier, blockID *flow.Identifier) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeIndexBlockByChunkID, chunkID), blockID)
@@ -72,3 +72,30 @@ func TestBlockHeightIndexLookup(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestTraverseBlockHeights(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+		expected := map[uint64]flow.Identifier{
+			1:   unittest.IdentifierFixture(),
+			2:   unittest.IdentifierFixture(),
+			256: unittest.IdentifierFixture(),
+		}
+		for height, blockID := range expected {
+			err := db.Update(IndexBlockHeight(height, blockID))
+			require.Nil(t, err)
+		}
+
+		var heights []uint64
+		actual := make(map[uint64]flow.Identifier)
+		err := db.View(TraverseBlockHeights(func(height uint64, blockID flow.Identifier) error {
+			heights = append(heights, height)
+			actual[height] = blockID
+			return nil
+		}))
+		require.Nil(t, err)
+
+		assert.Equal(t, []uint64{1, 2, 256}, heights)
+		assert.Equal(t, expected, actual)
+	})
+}
@@ -49,3 +49,18 @@ func UpdateLastCompleteBlockHeight(height uint64) func(*badger.Txn) error {
 func RetrieveLastCompleteBlockHeight(height *uint64) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeLastCompleteBlockHeight), height)
 }
+
+// InsertPrunedHeight inserts the height up to which the given type of data was pruned.
+func InsertPrunedHeight(data string, height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codePrunedHeight, data), height)
+}
+
+// UpdatePrunedHeight updates the height up to which the given type of data was pruned.
+func UpdatePrunedHeight(data string, height uint64) func(*badger.Txn) error {
+	return update(makePrefix(codePrunedHeight, data), height)
+}
+
+// RetrievePrunedHeight retrieves the height up to which the given type of data was pruned.
+func RetrievePrunedHeight(data string, height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codePrunedHeight, data), height)
+}
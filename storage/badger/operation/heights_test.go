@@ -3,12 +3,14 @@
 package operation
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -83,3 +85,31 @@ func TestLastCompleteBlockHeightInsertUpdateRetrieve(t *testing.T) {
 		assert.Equal(t, retrieved, height)
 	})
 }
+
+func TestPrunedHeightInsertUpdateRetrieve(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		height := uint64(1337)
+
+		err := db.Update(InsertPrunedHeight("events", height))
+		require.Nil(t, err)
+
+		var retrieved uint64
+		err = db.View(RetrievePrunedHeight("events", &retrieved))
+		require.Nil(t, err)
+
+		assert.Equal(t, retrieved, height)
+
+		// the heights of other types of data are tracked separately
+		err = db.View(RetrievePrunedHeight("blocks", &retrieved))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		height = 9999
+		err = db.Update(UpdatePrunedHeight("events", height))
+		require.Nil(t, err)
+
+		err = db.View(RetrievePrunedHeight("events", &retrieved))
+		require.Nil(t, err)
+
+		assert.Equal(t, retrieved, height)
+	})
+}
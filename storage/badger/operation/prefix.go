@@ -12,7 +12,8 @@ import (
 const (
 
 	// codes for special database markers
-	codeMax = 1 // keeps track of the maximum key size
+	codeMax           = 1 // keeps track of the maximum key size
+	codeSchemaVersion = 2 // version of the database schema, see storage/badger/migration
 
 	// codes for views with special meaning
 	codeStartedView           = 10 // latest view hotstuff started
@@ -26,6 +27,7 @@ const (
 	codeExecutedBlock           = 23 // latest executed block with max height
 	codeRootHeight              = 24 // the height of the first loaded block
 	codeLastCompleteBlockHeight = 25 // the height of the last block for which all collections were received
+	codePrunedHeight            = 26 // the height up to which a type of data was pruned
 
 	// codes for single entity storage
 	// 31 was used for identities before epochs
@@ -35,9 +37,9 @@ const (
 	codeTransaction          = 34
 	codeCollection           = 35
 	codeExecutionResult      = 36
-	codeExecutionReceiptMeta = 36
 	codeResultApproval       = 37
 	codeChunk                = 38
+	codeExecutionReceiptMeta = 39 // shared code 36 with execution results before schema version 1
 
 	// codes for indexing single identifier by identifier
 	codeHeightToBlock       = 40 // index mapping height to block ID
@@ -61,12 +63,18 @@ const (
 	// codes related to epoch information
 	codeEpochSetup  = 61 // EpochSetup service event, keyed by ID
 	codeEpochCommit = 62 // EpochCommit service event, keyed by ID
+	codeEpochRecord = 63 // record of the service events and heights of an epoch, keyed by counter
+	codeEpochHeight = 64 // index mapping the first known height of an epoch to its counter
 
 	// job queue consumers and producers
 	codeJobConsumerProcessed = 70
 	codeJobQueue             = 71
 	codeJobQueuePointer      = 72
 
+	// transaction indexes of access nodes
+	codeTransactionLocation = 80 // index mapping transaction ID to its finalized block
+	codeAccountTransactions = 81 // index mapping account address and height to transaction IDs
+
 	// legacy codes (should be cleaned up)
 	codeChunkDataPack                = 100
 	codeCommit                       = 101
@@ -113,6 +121,8 @@ func b(v interface{}) []byte {
 		return i[:]
 	case flow.ChainID:
 		return []byte(i)
+	case flow.Address:
+		return i[:]
 	default:
 		panic(fmt.Sprintf("unsupported type to convert (%T)", v))
 	}
@@ -4,6 +4,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 // InsertExecutionReceiptMeta inserts an execution receipt meta by ID.
@@ -12,7 +13,7 @@ func InsertExecutionReceiptMeta(receiptID flow.Identifier, meta *flow.ExecutionR
 }
 
 // BatchInsertExecutionReceiptMeta inserts an execution receipt meta by ID.
-func BatchInsertExecutionReceiptMeta(receiptID flow.Identifier, meta *flow.ExecutionReceiptMeta) func(batch *badger.WriteBatch) error {
+func BatchInsertExecutionReceiptMeta(receiptID flow.Identifier, meta *flow.ExecutionReceiptMeta) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeExecutionReceiptMeta, receiptID), meta)
 }
 
@@ -27,7 +28,7 @@ func IndexOwnExecutionReceipt(blockID flow.Identifier, receiptID flow.Identifier
 }
 
 // BatchIndexOwnExecutionReceipt inserts an execution receipt ID keyed by block ID into a batch
-func BatchIndexOwnExecutionReceipt(blockID flow.Identifier, receiptID flow.Identifier) func(batch *badger.WriteBatch) error {
+func BatchIndexOwnExecutionReceipt(blockID flow.Identifier, receiptID flow.Identifier) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeOwnBlockReceipt, blockID), receiptID)
 }
 
@@ -43,7 +44,7 @@ func IndexExecutionReceipts(blockID, receiptID flow.Identifier) func(*badger.Txn
 }
 
 // BatchIndexExecutionReceipts inserts an execution receipt ID keyed by block ID and receipt ID into a batch
-func BatchIndexExecutionReceipts(blockID, receiptID flow.Identifier) func(batch *badger.WriteBatch) error {
+func BatchIndexExecutionReceipts(blockID, receiptID flow.Identifier) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeAllBlockReceipts, blockID, receiptID), receiptID)
 }
 
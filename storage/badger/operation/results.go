@@ -4,6 +4,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 // InsertExecutionResult inserts an execution result by ID.
@@ -12,7 +13,7 @@ func InsertExecutionResult(result *flow.ExecutionResult) func(*badger.Txn) error
 }
 
 // UpsertExecutionResult inserts an execution result by ID.
-func BatchInsertExecutionResult(result *flow.ExecutionResult) func(batch *badger.WriteBatch) error {
+func BatchInsertExecutionResult(result *flow.ExecutionResult) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeExecutionResult, result.ID()), result)
 }
 
@@ -27,7 +28,7 @@ func IndexExecutionResult(blockID flow.Identifier, resultID flow.Identifier) fun
 }
 
 // BatchIndexExecutionResult inserts an execution result ID keyed by block ID into a batch
-func BatchIndexExecutionResult(blockID flow.Identifier, resultID flow.Identifier) func(batch *badger.WriteBatch) error {
+func BatchIndexExecutionResult(blockID flow.Identifier, resultID flow.Identifier) func(batch storage.Writer) error {
 	return batchInsert(makePrefix(codeIndexExecutionResultByBlock, blockID), resultID)
 }
 
@@ -0,0 +1,86 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// InsertSchemaVersion inserts the version of the database schema.
+func InsertSchemaVersion(version uint32) func(*badger.Txn) error {
+	return insert(makePrefix(codeSchemaVersion), version)
+}
+
+// UpdateSchemaVersion updates the version of the database schema.
+func UpdateSchemaVersion(version uint32) func(*badger.Txn) error {
+	return update(makePrefix(codeSchemaVersion), version)
+}
+
+// RetrieveSchemaVersion retrieves the version of the database schema.
+func RetrieveSchemaVersion(version *uint32) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeSchemaVersion), version)
+}
+
+// codeLegacyExecutionReceiptMeta is the code under which execution receipt
+// metas were stored along with execution results before schema version 1.
+const codeLegacyExecutionReceiptMeta = codeExecutionResult
+
+// LookupLegacyExecutionReceiptMetas finds the IDs of the execution receipt
+// metas stored under the code of execution results. As both are keyed by ID,
+// they are told apart by the executor ID only receipt metas have.
+func LookupLegacyExecutionReceiptMetas(receiptIDs *[]flow.Identifier) func(*badger.Txn) error {
+	*receiptIDs = make([]flow.Identifier, 0)
+	iteration := func() (checkFunc, createFunc, handleFunc) {
+		var entityID flow.Identifier
+		check := func(key []byte) bool {
+			copy(entityID[:], key[1:])
+			return true
+		}
+		var fields map[string]interface{}
+		create := func() interface{} {
+			return &fields
+		}
+		handle := func() error {
+			if _, ok := fields["ExecutorID"]; ok {
+				*receiptIDs = append(*receiptIDs, entityID)
+			}
+			return nil
+		}
+		return check, create, handle
+	}
+	return traverse(makePrefix(codeLegacyExecutionReceiptMeta), iteration)
+}
+
+// MoveLegacyExecutionReceiptMeta moves the execution receipt meta with the
+// given ID from the code of execution results to its own code. It is a no-op
+// if the receipt meta was already moved.
+func MoveLegacyExecutionReceiptMeta(receiptID flow.Identifier) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		var meta flow.ExecutionReceiptMeta
+		err := retrieve(makePrefix(codeLegacyExecutionReceiptMeta, receiptID), &meta)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not retrieve legacy receipt meta: %w", err)
+		}
+
+		err = InsertExecutionReceiptMeta(receiptID, &meta)(tx)
+		if err != nil && !errors.Is(err, storage.ErrAlreadyExists) {
+			return fmt.Errorf("could not insert receipt meta: %w", err)
+		}
+
+		return remove(makePrefix(codeLegacyExecutionReceiptMeta, receiptID))(tx)
+	}
+}
+
+// InsertLegacyExecutionReceiptMeta inserts an execution receipt meta under the
+// code of execution results, as it was stored before schema version 1. It only
+// exists to test the migration.
+func InsertLegacyExecutionReceiptMeta(receiptID flow.Identifier, meta *flow.ExecutionReceiptMeta) func(*badger.Txn) error {
+	return insert(makePrefix(codeLegacyExecutionReceiptMeta, receiptID), meta)
+}
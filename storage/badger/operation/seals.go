@@ -46,6 +46,22 @@ func LookupBlockSeal(blockID flow.Identifier, sealID *flow.Identifier) func(*bad
 	return retrieve(makePrefix(codeBlockToSeal, blockID), &sealID)
 }
 
+func RemovePayloadSeals(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codePayloadSeals, blockID))
+}
+
+func RemovePayloadReceipts(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codePayloadReceipts, blockID))
+}
+
+func RemovePayloadResults(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codePayloadResults, blockID))
+}
+
+func RemoveBlockSeal(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeBlockToSeal, blockID))
+}
+
 func InsertExecutionForkEvidence(conflictingSeals []*flow.IncorporatedResultSeal) func(*badger.Txn) error {
 	return insert(makePrefix(codeExecutionFork), conflictingSeals)
 }
@@ -0,0 +1,100 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// IndexTransactionLocation indexes the finalized block which included the
+// transaction with the given ID.
+func IndexTransactionLocation(location *flow.TransactionLocation) func(*badger.Txn) error {
+	return insert(makePrefix(codeTransactionLocation, location.TransactionID), location)
+}
+
+// LookupTransactionLocation looks up the finalized block which included the
+// transaction with the given ID.
+func LookupTransactionLocation(txID flow.Identifier, location *flow.TransactionLocation) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeTransactionLocation, txID), location)
+}
+
+// RemoveTransactionLocation removes the location of the transaction with the
+// given ID.
+func RemoveTransactionLocation(txID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeTransactionLocation, txID))
+}
+
+// IndexAccountTransaction indexes the given transaction as involving the
+// account with the given address, ordered by the height of its block.
+func IndexAccountTransaction(address flow.Address, location *flow.TransactionLocation) func(*badger.Txn) error {
+	return insert(makePrefix(codeAccountTransactions, address, location.Height, location.TransactionID), location.BlockID)
+}
+
+// RemoveAccountTransaction removes the given transaction at the given height
+// from the transactions involving the account with the given address.
+func RemoveAccountTransaction(address flow.Address, height uint64, txID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeAccountTransactions, address, height, txID))
+}
+
+// LookupAccountTransactions looks up to limit transactions involving the
+// account with the given address, from the newest to the oldest. The lookup
+// starts below the given transaction, which is the last transaction of the
+// previous page, or with the newest transaction if it is nil.
+func LookupAccountTransactions(address flow.Address, start *flow.TransactionLocation, limit uint, locations *[]flow.TransactionLocation) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		*locations = make([]flow.TransactionLocation, 0)
+		if limit == 0 {
+			return nil
+		}
+
+		prefix := makePrefix(codeAccountTransactions, address)
+
+		// seek to the start key, or past the last key of the account, in
+		// reverse order; as badger seeks to the largest key lower or equal to
+		// the seek key, we skip the start transaction itself
+		var seek []byte
+		if start != nil {
+			seek = makePrefix(codeAccountTransactions, address, start.Height, start.TransactionID)
+		} else {
+			seek = append(makePrefix(codeAccountTransactions, address), bytes.Repeat([]byte{0xff}, 8+len(flow.ZeroID))...)
+		}
+
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = prefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			key := item.Key()
+			if start != nil && bytes.Equal(key, seek) {
+				continue
+			}
+
+			var location flow.TransactionLocation
+			location.Height = binary.BigEndian.Uint64(key[len(prefix) : len(prefix)+8])
+			copy(location.TransactionID[:], key[len(prefix)+8:])
+			err := item.Value(func(val []byte) error {
+				return msgpack.Unmarshal(val, &location.BlockID)
+			})
+			if err != nil {
+				return fmt.Errorf("could not decode block ID: %w", err)
+			}
+
+			*locations = append(*locations, location)
+			if uint(len(*locations)) == limit {
+				break
+			}
+		}
+
+		return nil
+	}
+}
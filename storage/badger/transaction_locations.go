@@ -0,0 +1,70 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// TransactionLocations implements the transaction indexes of access nodes.
+type TransactionLocations struct {
+	db *badger.DB
+}
+
+var _ storage.TransactionLocations = (*TransactionLocations)(nil)
+
+func NewTransactionLocations(db *badger.DB) *TransactionLocations {
+	return &TransactionLocations{db: db}
+}
+
+func (t *TransactionLocations) Index(header *flow.Header, transactions []*flow.TransactionBody) error {
+	blockID := header.ID()
+	return operation.RetryOnConflict(t.db.Update, func(tx *badger.Txn) error {
+		for _, transaction := range transactions {
+			location := &flow.TransactionLocation{
+				TransactionID: transaction.ID(),
+				BlockID:       blockID,
+				Height:        header.Height,
+			}
+
+			err := operation.IndexTransactionLocation(location)(tx)
+			if errors.Is(err, storage.ErrAlreadyExists) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not index transaction location: %w", err)
+			}
+
+			for _, address := range transaction.Accounts() {
+				err = operation.IndexAccountTransaction(address, location)(tx)
+				if err != nil {
+					return fmt.Errorf("could not index transaction for account %s: %w", address, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (t *TransactionLocations) ByTransactionID(txID flow.Identifier) (*flow.TransactionLocation, error) {
+	var location flow.TransactionLocation
+	err := t.db.View(operation.LookupTransactionLocation(txID, &location))
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (t *TransactionLocations) ByAccount(address flow.Address, start *flow.TransactionLocation, limit uint) ([]flow.TransactionLocation, error) {
+	var locations []flow.TransactionLocation
+	err := t.db.View(operation.LookupAccountTransactions(address, start, limit, &locations))
+	if err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
@@ -0,0 +1,112 @@
+package badger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+func TestTransactionLocations(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		store := badgerstorage.NewTransactionLocations(db)
+
+		alice := unittest.RandomAddressFixture()
+		bob := unittest.RandomAddressFixture()
+		carol := unittest.RandomAddressFixture()
+
+		transaction := func(proposer flow.Address, payer flow.Address, authorizers ...flow.Address) *flow.TransactionBody {
+			tx := unittest.TransactionBodyFixture(func(tx *flow.TransactionBody) {
+				tx.ProposalKey.Address = proposer
+				tx.Payer = payer
+				tx.Authorizers = authorizers
+			})
+			return &tx
+		}
+
+		header1 := unittest.BlockHeaderFixture()
+		header1.Height = 10
+		tx1 := transaction(alice, alice, bob)
+		tx2 := transaction(bob, bob, bob)
+		header2 := unittest.BlockHeaderWithParentFixture(&header1)
+		tx3 := transaction(alice, carol, alice)
+
+		err := store.Index(&header1, []*flow.TransactionBody{tx1, tx2})
+		require.NoError(t, err)
+		err = store.Index(&header2, []*flow.TransactionBody{tx3})
+		require.NoError(t, err)
+
+		t.Run("by transaction ID", func(t *testing.T) {
+			location, err := store.ByTransactionID(tx1.ID())
+			require.NoError(t, err)
+			assert.Equal(t, &flow.TransactionLocation{TransactionID: tx1.ID(), BlockID: header1.ID(), Height: 10}, location)
+
+			_, err = store.ByTransactionID(unittest.IdentifierFixture())
+			assert.True(t, errors.Is(err, storage.ErrNotFound))
+		})
+
+		t.Run("indexing is idempotent", func(t *testing.T) {
+			other := unittest.BlockHeaderFixture()
+			err := store.Index(&other, []*flow.TransactionBody{tx1})
+			require.NoError(t, err)
+
+			location, err := store.ByTransactionID(tx1.ID())
+			require.NoError(t, err)
+			assert.Equal(t, header1.ID(), location.BlockID)
+		})
+
+		t.Run("by account", func(t *testing.T) {
+			locations, err := store.ByAccount(alice, nil, 10)
+			require.NoError(t, err)
+			require.Len(t, locations, 2)
+			assert.Equal(t, tx3.ID(), locations[0].TransactionID)
+			assert.Equal(t, header2.Height, locations[0].Height)
+			assert.Equal(t, header2.ID(), locations[0].BlockID)
+			assert.Equal(t, tx1.ID(), locations[1].TransactionID)
+
+			locations, err = store.ByAccount(bob, nil, 10)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []flow.Identifier{tx1.ID(), tx2.ID()}, []flow.Identifier{locations[0].TransactionID, locations[1].TransactionID})
+
+			locations, err = store.ByAccount(carol, nil, 10)
+			require.NoError(t, err)
+			require.Len(t, locations, 1)
+			assert.Equal(t, tx3.ID(), locations[0].TransactionID)
+
+			locations, err = store.ByAccount(unittest.RandomAddressFixture(), nil, 10)
+			require.NoError(t, err)
+			assert.Empty(t, locations)
+		})
+
+		t.Run("pagination", func(t *testing.T) {
+			all, err := store.ByAccount(bob, nil, 10)
+			require.NoError(t, err)
+
+			var paged []flow.TransactionLocation
+			var start *flow.TransactionLocation
+			for {
+				page, err := store.ByAccount(bob, start, 1)
+				require.NoError(t, err)
+				if len(page) == 0 {
+					break
+				}
+				require.Len(t, page, 1)
+				paged = append(paged, page...)
+				start = &page[0]
+			}
+			assert.Equal(t, all, paged)
+
+			locations, err := store.ByAccount(bob, nil, 0)
+			require.NoError(t, err)
+			assert.Empty(t, locations)
+		})
+	})
+}
@@ -1,14 +1,19 @@
 package storage
 
-import "github.com/dgraph-io/badger/v2"
-
 type Transaction interface {
 	Set(key, val []byte) error
 }
 
+// Writer writes key-value pairs to the storage backend, without depending on
+// the database the backend runs on.
+type Writer interface {
+	Set(key, val []byte) error
+	Delete(key []byte) error
+}
+
 // BatchStorage
 type BatchStorage interface {
-	GetWriter() *badger.WriteBatch
+	GetWriter() Writer
 
 	// OnSucceed adds a callback to execute after the batch has
 	// been successfully flushed.
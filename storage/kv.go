@@ -0,0 +1,40 @@
+package storage
+
+// Reader reads key-value pairs from the storage backend.
+type Reader interface {
+
+	// Get returns a copy of the value stored under the given key, or
+	// ErrNotFound if the key does not exist.
+	Get(key []byte) ([]byte, error)
+
+	// Iterate calls the given function for each key-value pair whose key has
+	// the given prefix, in ascending order of the keys. Key and value are only
+	// valid during the call. Iteration stops at the first error, which is
+	// returned.
+	Iterate(prefix []byte, fn func(key []byte, val []byte) error) error
+}
+
+// KVBatch collects writes, which are applied atomically when the batch is
+// committed. A batch must be either committed or discarded.
+type KVBatch interface {
+	Writer
+
+	// Commit durably applies the writes of the batch.
+	Commit() error
+
+	// Discard drops the writes of the batch. It is a no-op on committed
+	// batches.
+	Discard()
+}
+
+// KVStore is a key-value store the storage layer can run on, which decouples
+// it from the database of the backend.
+type KVStore interface {
+	Reader
+
+	// NewBatch returns a new, empty batch of writes.
+	NewBatch() KVBatch
+
+	// Close closes the store, along with the database of the backend.
+	Close() error
+}
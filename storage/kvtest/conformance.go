@@ -0,0 +1,205 @@
+// Package kvtest is a conformance test suite for the implementations of the
+// backend-neutral key-value store, which verifies that the storage layer can
+// run on any of them.
+package kvtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+// WithStore runs the given function with a new, empty key-value store.
+type WithStore func(t *testing.T, f func(store storage.KVStore))
+
+// Run runs the conformance test suite against the key-value stores provided by
+// the given function.
+func Run(t *testing.T, withStore WithStore) {
+	tests := map[string]func(t *testing.T, store storage.KVStore){
+		"get missing key":   testGetMissing,
+		"set and get":       testSetGet,
+		"overwrite":         testOverwrite,
+		"delete":            testDelete,
+		"batch isolation":   testBatchIsolation,
+		"batch discard":     testBatchDiscard,
+		"value ownership":   testValueOwnership,
+		"iterate prefix":    testIteratePrefix,
+		"iterate error":     testIterateError,
+		"iterate max bytes": testIterateMaxBytes,
+		"large values":      testLargeValues,
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			withStore(t, func(store storage.KVStore) {
+				test(t, store)
+			})
+		})
+	}
+}
+
+// write applies the given key-value pairs in a single batch.
+func write(t *testing.T, store storage.KVStore, pairs ...[]byte) {
+	require.True(t, len(pairs)%2 == 0, "pairs must consist of keys and values")
+	batch := store.NewBatch()
+	defer batch.Discard()
+	for i := 0; i < len(pairs); i += 2 {
+		require.NoError(t, batch.Set(pairs[i], pairs[i+1]))
+	}
+	require.NoError(t, batch.Commit())
+}
+
+func testGetMissing(t *testing.T, store storage.KVStore) {
+	_, err := store.Get([]byte("missing"))
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func testSetGet(t *testing.T, store storage.KVStore) {
+	write(t, store, []byte("key1"), []byte("value1"), []byte("key2"), []byte("value2"))
+
+	val, err := store.Get([]byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+
+	val, err = store.Get([]byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), val)
+}
+
+func testOverwrite(t *testing.T, store storage.KVStore) {
+	write(t, store, []byte("key"), []byte("value1"))
+	write(t, store, []byte("key"), []byte("value2"))
+
+	val, err := store.Get([]byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), val)
+}
+
+func testDelete(t *testing.T, store storage.KVStore) {
+	write(t, store, []byte("key"), []byte("value"))
+
+	batch := store.NewBatch()
+	require.NoError(t, batch.Delete([]byte("key")))
+	// deleting a missing key is not an error
+	require.NoError(t, batch.Delete([]byte("missing")))
+	require.NoError(t, batch.Commit())
+
+	_, err := store.Get([]byte("key"))
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func testBatchIsolation(t *testing.T, store storage.KVStore) {
+	batch := store.NewBatch()
+	defer batch.Discard()
+	require.NoError(t, batch.Set([]byte("key"), []byte("value")))
+
+	// writes are only visible once the batch is committed
+	_, err := store.Get([]byte("key"))
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+	require.NoError(t, batch.Commit())
+	val, err := store.Get([]byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), val)
+}
+
+func testBatchDiscard(t *testing.T, store storage.KVStore) {
+	batch := store.NewBatch()
+	require.NoError(t, batch.Set([]byte("key"), []byte("value")))
+	batch.Discard()
+	batch.Discard()
+
+	_, err := store.Get([]byte("key"))
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func testValueOwnership(t *testing.T, store storage.KVStore) {
+	key := []byte("key")
+	val := []byte("value")
+	batch := store.NewBatch()
+	require.NoError(t, batch.Set(key, val))
+
+	// the batch must not keep references to the given slices
+	copy(key, "xxx")
+	copy(val, "xxxxx")
+	require.NoError(t, batch.Commit())
+
+	stored, err := store.Get([]byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), stored)
+
+	// the returned value must not reference memory of the store
+	copy(stored, "xxxxx")
+	stored, err = store.Get([]byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), stored)
+}
+
+func testIteratePrefix(t *testing.T, store storage.KVStore) {
+	write(t, store,
+		[]byte{0x01, 0x02}, []byte("b"),
+		[]byte{0x01, 0x01}, []byte("a"),
+		[]byte{0x01, 0x03, 0x00}, []byte("c"),
+		[]byte{0x00, 0xff}, []byte("before"),
+		[]byte{0x02}, []byte("after"),
+	)
+
+	var keys [][]byte
+	var vals []string
+	err := store.Iterate([]byte{0x01}, func(key []byte, val []byte) error {
+		keys = append(keys, append([]byte(nil), key...))
+		vals = append(vals, string(val))
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]byte{{0x01, 0x01}, {0x01, 0x02}, {0x01, 0x03, 0x00}}, keys)
+	assert.Equal(t, []string{"a", "b", "c"}, vals)
+}
+
+func testIterateError(t *testing.T, store storage.KVStore) {
+	write(t, store, []byte("a1"), []byte("1"), []byte("a2"), []byte("2"))
+
+	sentinel := fmt.Errorf("sentinel")
+	calls := 0
+	err := store.Iterate([]byte("a"), func(key []byte, val []byte) error {
+		calls++
+		return sentinel
+	})
+	assert.True(t, errors.Is(err, sentinel))
+	assert.Equal(t, 1, calls)
+}
+
+func testIterateMaxBytes(t *testing.T, store storage.KVStore) {
+	write(t, store,
+		[]byte{0xff, 0xff}, []byte("a"),
+		[]byte{0xff, 0xff, 0x01}, []byte("b"),
+		[]byte{0xff, 0xfe}, []byte("other"),
+	)
+
+	var vals []string
+	err := store.Iterate([]byte{0xff, 0xff}, func(key []byte, val []byte) error {
+		vals = append(vals, string(val))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, vals)
+}
+
+func testLargeValues(t *testing.T, store storage.KVStore) {
+	val := make([]byte, 4<<20)
+	_, _ = rand.New(rand.NewSource(1)).Read(val)
+
+	write(t, store, []byte("large"), val)
+
+	stored, err := store.Get([]byte("large"))
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(val, stored))
+}
@@ -3,8 +3,9 @@
 package mock
 
 import (
-	badger "github.com/dgraph-io/badger/v2"
 	mock "github.com/stretchr/testify/mock"
+
+	storage "github.com/onflow/flow-go/storage"
 )
 
 // BatchStorage is an autogenerated mock type for the BatchStorage type
@@ -27,15 +28,15 @@ func (_m *BatchStorage) Flush() error {
 }
 
 // GetWriter provides a mock function with given fields:
-func (_m *BatchStorage) GetWriter() *badger.WriteBatch {
+func (_m *BatchStorage) GetWriter() storage.Writer {
 	ret := _m.Called()
 
-	var r0 *badger.WriteBatch
-	if rf, ok := ret.Get(0).(func() *badger.WriteBatch); ok {
+	var r0 storage.Writer
+	if rf, ok := ret.Get(0).(func() storage.Writer); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*badger.WriteBatch)
+			r0 = ret.Get(0).(storage.Writer)
 		}
 	}
 
@@ -0,0 +1,162 @@
+package pebble
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+// KVStore is a key-value store on Pebble, an LSM store which keeps values in
+// its sstables, so that unlike with the value log of badger, the space of
+// overwritten and deleted values is reclaimed by regular compactions.
+type KVStore struct {
+	db *pebble.DB
+}
+
+var _ storage.KVStore = (*KVStore)(nil)
+
+// Open opens the Pebble database in the given directory, creating it if it
+// doesn't exist yet.
+func Open(dir string, opts *pebble.Options) (*KVStore, error) {
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not open pebble database: %w", err)
+	}
+	return NewKVStore(db), nil
+}
+
+// NewKVStore returns a key-value store on the given Pebble database.
+func NewKVStore(db *pebble.DB) *KVStore {
+	return &KVStore{db: db}
+}
+
+func (s *KVStore) Get(key []byte) ([]byte, error) {
+	val, closer, err := s.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load data: %w", err)
+	}
+	defer closer.Close()
+
+	return append([]byte(nil), val...), nil
+}
+
+func (s *KVStore) Iterate(prefix []byte, fn func(key []byte, val []byte) error) error {
+	it := s.db.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: prefixUpperBound(prefix),
+	})
+
+	for valid := it.First(); valid; valid = it.Next() {
+		err := fn(it.Key(), it.Value())
+		if err != nil {
+			_ = it.Close()
+			return err
+		}
+	}
+
+	return it.Close()
+}
+
+func (s *KVStore) NewBatch() storage.KVBatch {
+	return &kvBatch{batch: s.db.NewBatch()}
+}
+
+func (s *KVStore) Close() error {
+	return s.db.Close()
+}
+
+// Backup copies a consistent state of the store into the given empty
+// directory, as a checkpoint of the database, and returns the paths of the
+// copied files relative to the directory. It is used to back up the store
+// along with the protocol state of the node.
+func (s *KVStore) Backup(dir string) ([]string, error) {
+
+	// the checkpoint creates its directory itself
+	err := os.Remove(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not remove checkpoint directory: %w", err)
+	}
+	err = s.db.Checkpoint(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not create checkpoint: %w", err)
+	}
+
+	var names []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(name))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list checkpoint files: %w", err)
+	}
+
+	return names, nil
+}
+
+// prefixUpperBound returns the smallest key which is larger than all keys with
+// the given prefix, or nil if there is none, as the prefix only consists of
+// 0xff bytes.
+func prefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			bound := append([]byte(nil), prefix[:i+1]...)
+			bound[i]++
+			return bound
+		}
+	}
+	return nil
+}
+
+// kvBatch is a batch of writes in a Pebble batch.
+type kvBatch struct {
+	batch  *pebble.Batch
+	closed bool
+}
+
+func (b *kvBatch) Set(key, val []byte) error {
+	err := b.batch.Set(key, val, nil)
+	if err != nil {
+		return fmt.Errorf("could not store data: %w", err)
+	}
+	return nil
+}
+
+func (b *kvBatch) Delete(key []byte) error {
+	err := b.batch.Delete(key, nil)
+	if err != nil {
+		return fmt.Errorf("could not delete data: %w", err)
+	}
+	return nil
+}
+
+func (b *kvBatch) Commit() error {
+	err := b.batch.Commit(pebble.Sync)
+	if err != nil {
+		return fmt.Errorf("could not commit batch: %w", err)
+	}
+	b.Discard()
+	return nil
+}
+
+func (b *kvBatch) Discard() {
+	if b.closed {
+		return
+	}
+	b.closed = true
+	_ = b.batch.Close()
+}
@@ -0,0 +1,62 @@
+package pebble
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/kvtest"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestKVStoreConformance(t *testing.T) {
+	kvtest.Run(t, func(t *testing.T, f func(storage.KVStore)) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			store, err := Open(dir, nil)
+			require.NoError(t, err)
+			defer store.Close()
+			f(store)
+		})
+	})
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	assert.Equal(t, []byte{0x01, 0x03}, prefixUpperBound([]byte{0x01, 0x02}))
+	assert.Equal(t, []byte{0x02}, prefixUpperBound([]byte{0x01, 0xff}))
+	assert.Nil(t, prefixUpperBound([]byte{0xff, 0xff}))
+	assert.Nil(t, prefixUpperBound(nil))
+}
+
+// TestBackup tests that a backup of the store can be opened as a store with
+// the same content.
+func TestBackup(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		store, err := Open(filepath.Join(dir, "store"), nil)
+		require.NoError(t, err)
+		defer store.Close()
+
+		batch := store.NewBatch()
+		require.NoError(t, batch.Set([]byte("key"), []byte("value")))
+		require.NoError(t, batch.Commit())
+
+		backup := filepath.Join(dir, "backup")
+		require.NoError(t, os.Mkdir(backup, 0700))
+		names, err := store.Backup(backup)
+		require.NoError(t, err)
+		assert.NotEmpty(t, names)
+		for _, name := range names {
+			assert.FileExists(t, filepath.Join(backup, filepath.FromSlash(name)))
+		}
+
+		restored, err := Open(backup, nil)
+		require.NoError(t, err)
+		defer restored.Close()
+		val, err := restored.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), val)
+	})
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// TransactionLocations indexes the transactions of finalized blocks by their
+// ID and by the accounts involved in them.
+type TransactionLocations interface {
+
+	// Index indexes the given transactions as included in the finalized block
+	// with the given header. Transactions which are already indexed are
+	// skipped.
+	Index(header *flow.Header, transactions []*flow.TransactionBody) error
+
+	// ByTransactionID returns the location of the transaction with the given ID.
+	ByTransactionID(txID flow.Identifier) (*flow.TransactionLocation, error)
+
+	// ByAccount returns up to limit locations of the transactions involving the
+	// account with the given address, from the newest to the oldest. It starts
+	// below the given location, which is the last one of the previous page, or
+	// with the newest transaction if it is nil.
+	ByAccount(address flow.Address, start *flow.TransactionLocation, limit uint) ([]flow.TransactionLocation, error)
+}
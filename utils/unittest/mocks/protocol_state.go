@@ -60,6 +60,10 @@ func (ps *ProtocolState) Params() protocol.Params {
 	}
 }
 
+func (ps *ProtocolState) History() protocol.History {
+	return new(protocolmock.History)
+}
+
 func (ps *ProtocolState) AtBlockID(blockID flow.Identifier) protocol.Snapshot {
 	ps.Lock()
 	defer ps.Unlock()